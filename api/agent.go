@@ -157,6 +157,33 @@ func (a *Agent) SetServers(addrs []string) error {
 	return err
 }
 
+// NodeMeta is used to query the meta of a client node.
+func (a *Agent) NodeMeta() (map[string]string, error) {
+	var resp map[string]string
+	_, err := a.client.query("/v1/client/metadata", &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ApplyNodeMeta is used to update the meta of a client node at runtime. A
+// nil value removes the key. The resulting meta is returned.
+func (a *Agent) ApplyNodeMeta(updates map[string]*string) (map[string]string, error) {
+	req := &NodeMetaApplyRequest{Meta: updates}
+	var resp map[string]string
+	_, err := a.client.write("/v1/client/metadata", req, &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NodeMetaApplyRequest is used to update the meta of a client node
+type NodeMetaApplyRequest struct {
+	Meta map[string]*string
+}
+
 // joinResponse is used to decode the response we get while
 // sending a member join request.
 type joinResponse struct {
@@ -157,6 +157,42 @@ func TestAgent_SetServers(t *testing.T) {
 	}
 }
 
+func TestAgent_ApplyNodeMeta(t *testing.T) {
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	a := c.Agent()
+
+	// Set a key
+	rack := "r1"
+	out, err := a.ApplyNodeMeta(map[string]*string{"rack": &rack})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out["rack"] != "r1" {
+		t.Fatalf("bad meta: %v", out)
+	}
+
+	// Reading returns the new meta
+	out, err = a.NodeMeta()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out["rack"] != "r1" {
+		t.Fatalf("bad meta: %v", out)
+	}
+
+	// Unset the key
+	out, err = a.ApplyNodeMeta(map[string]*string{"rack": nil})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := out["rack"]; ok {
+		t.Fatalf("bad meta: %v", out)
+	}
+}
+
 func (a *AgentMember) String() string {
 	return "{Name: " + a.Name + " Region: " + a.Tags["region"] + " DC: " + a.Tags["dc"] + "}"
 }
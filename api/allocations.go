@@ -1,7 +1,14 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
 	"sort"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -40,6 +47,237 @@ func (a *Allocations) Info(allocID string, q *QueryOptions) (*Allocation, *Query
 	return &resp, qm, nil
 }
 
+// Stop is used to stop an allocation. The scheduler places a replacement
+// according to the job.
+func (a *Allocations) Stop(alloc *Allocation, q *WriteOptions) (*AllocStopResponse, error) {
+	var resp AllocStopResponse
+	wm, err := a.client.write("/v1/allocation/"+alloc.ID+"/stop", nil, &resp, q)
+	if err != nil {
+		return nil, err
+	}
+	resp.WriteMeta = *wm
+	return &resp, nil
+}
+
+// Restart is used to restart a task of an allocation, or all of its tasks if
+// no task is given. The request is sent to the node running the allocation.
+func (a *Allocations) Restart(alloc *Allocation, taskName string, q *QueryOptions) error {
+	nodeClient, err := a.client.getNodeClient(alloc.NodeID, q)
+	if err != nil {
+		return err
+	}
+
+	req := allocRestartRequest{
+		TaskName: taskName,
+	}
+	_, err = nodeClient.write("/v1/client/allocation/"+alloc.ID+"/restart", &req, nil, nil)
+	return err
+}
+
+// Signal is used to send a signal to a task of an allocation, or to all of
+// its tasks if no task is given. The request is sent to the node running the
+// allocation.
+func (a *Allocations) Signal(alloc *Allocation, taskName, signal string, q *QueryOptions) error {
+	nodeClient, err := a.client.getNodeClient(alloc.NodeID, q)
+	if err != nil {
+		return err
+	}
+
+	req := allocSignalRequest{
+		TaskName: taskName,
+		Signal:   signal,
+	}
+	_, err = nodeClient.write("/v1/client/allocation/"+alloc.ID+"/signal", &req, nil, nil)
+	return err
+}
+
+// Stats is used to read the samples of the resource usage of a task of an
+// allocation, or of all of its tasks if no task is given, by task name. The
+// samples are sorted oldest first. The request is sent to the node running
+// the allocation, or through the servers if the node is not reachable.
+func (a *Allocations) Stats(alloc *Allocation, taskName string, q *QueryOptions) (map[string][]*TaskResourceUsage, error) {
+	nodeClient, err := a.client.getNodeClientOrSelf(alloc.NodeID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp map[string][]*TaskResourceUsage
+	endpoint := "/v1/client/allocation/" + alloc.ID + "/stats"
+	if taskName != "" {
+		endpoint += "?task=" + url.QueryEscape(taskName)
+	}
+	if _, err := nodeClient.query(endpoint, &resp, q); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Exec is used to run a command inside a task of an allocation and returns
+// its exit code. The task may be omitted if the allocation has a single task.
+// When tty is set, the output of the command is only written to stdout and
+// the size of the terminal of the user is read from resizeCh. The request is
+// sent to the node running the allocation.
+func (a *Allocations) Exec(alloc *Allocation, taskName string, tty bool, command []string,
+	stdin io.Reader, stdout, stderr io.Writer, resizeCh <-chan TerminalSize, q *QueryOptions) (int, error) {
+	nodeClient, err := a.client.getNodeClient(alloc.NodeID, q)
+	if err != nil {
+		return 0, err
+	}
+
+	cmd, err := json.Marshal(command)
+	if err != nil {
+		return 0, err
+	}
+	params := url.Values{}
+	params.Set("task", taskName)
+	params.Set("tty", strconv.FormatBool(tty))
+	params.Set("command", string(cmd))
+	endpoint := "/v1/client/allocation/" + alloc.ID + "/exec?" + params.Encode()
+
+	conn, r, err := nodeClient.upgrade(endpoint, "nomad-exec", q)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var sendLock sync.Mutex
+	enc := json.NewEncoder(conn)
+	send := func(frame *execFrame) error {
+		sendLock.Lock()
+		defer sendLock.Unlock()
+		return enc.Encode(frame)
+	}
+
+	// Forward the input and the size of the terminal until the command exits
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	if stdin != nil {
+		go func() {
+			buf := make([]byte, 4096)
+			for {
+				n, err := stdin.Read(buf)
+				if n > 0 {
+					if err := send(&execFrame{Stdin: buf[:n]}); err != nil {
+						return
+					}
+				}
+				if err == io.EOF {
+					send(&execFrame{StdinClosed: true})
+					return
+				} else if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	if resizeCh != nil {
+		go func() {
+			for {
+				select {
+				case size, ok := <-resizeCh:
+					if !ok {
+						return
+					}
+					if err := send(&execFrame{TtySize: &size}); err != nil {
+						return
+					}
+				case <-doneCh:
+					return
+				}
+			}
+		}()
+	}
+
+	dec := json.NewDecoder(r)
+	for {
+		var frame execFrame
+		if err := dec.Decode(&frame); err != nil {
+			return 0, fmt.Errorf("failed to read exec output: %v", err)
+		}
+		if len(frame.Stdout) != 0 && stdout != nil {
+			stdout.Write(frame.Stdout)
+		}
+		if len(frame.Stderr) != 0 && stderr != nil {
+			stderr.Write(frame.Stderr)
+		}
+		if frame.Error != "" {
+			return 0, errors.New(frame.Error)
+		}
+		if frame.Exited {
+			return frame.ExitCode, nil
+		}
+	}
+}
+
+// AllocStopResponse is the response to stopping an allocation.
+type AllocStopResponse struct {
+	EvalID string
+	WriteMeta
+}
+
+// allocRestartRequest is used to restart the tasks of an allocation.
+type allocRestartRequest struct {
+	TaskName string
+}
+
+// allocSignalRequest is used to signal the tasks of an allocation.
+type allocSignalRequest struct {
+	TaskName string
+	Signal   string
+}
+
+// TerminalSize is the size of a terminal in characters.
+type TerminalSize struct {
+	Height uint16
+	Width  uint16
+}
+
+// execFrame is a frame of an exec session.
+type execFrame struct {
+	Stdin       []byte        `json:",omitempty"`
+	StdinClosed bool          `json:",omitempty"`
+	TtySize     *TerminalSize `json:",omitempty"`
+	Stdout      []byte        `json:",omitempty"`
+	Stderr      []byte        `json:",omitempty"`
+	Exited      bool          `json:",omitempty"`
+	ExitCode    int           `json:",omitempty"`
+	Error       string        `json:",omitempty"`
+}
+
+// MemoryStats holds the memory usage of a task, in bytes.
+type MemoryStats struct {
+	RSS      uint64
+	Cache    uint64
+	Swap     uint64
+	MaxUsage uint64
+	Measured []string
+}
+
+// CpuStats holds the CPU usage of a task. Percent and TotalTicks are the usage
+// since the previous sample, respectively relative to a core and in MHz.
+type CpuStats struct {
+	SystemMode       float64
+	UserMode         float64
+	Percent          float64
+	TotalTicks       float64
+	ThrottledPeriods uint64
+	ThrottledTime    uint64
+	Measured         []string
+}
+
+// ResourceUsage holds the resource usage of a task.
+type ResourceUsage struct {
+	MemoryStats *MemoryStats
+	CpuStats    *CpuStats
+}
+
+// TaskResourceUsage is a sample of the resource usage of a task, taken at a
+// time in Unix nanoseconds.
+type TaskResourceUsage struct {
+	ResourceUsage *ResourceUsage
+	Timestamp     int64
+}
+
 // Allocation is used for serialization of allocations.
 type Allocation struct {
 	ID                 string
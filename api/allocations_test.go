@@ -3,6 +3,7 @@ package api
 import (
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -98,6 +99,19 @@ func TestAllocations_PrefixList(t *testing.T) {
 	}
 }
 
+func TestAllocations_Stop(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	a := c.Allocations()
+
+	// Stopping an unknown allocation returns an error
+	alloc := &Allocation{ID: "8ba85cef-26cc-40d4-9cf6-3ed4f0c1a8b5"}
+	_, err := a.Stop(alloc, nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+}
+
 func TestAllocations_CreateIndexSort(t *testing.T) {
 	allocs := []*AllocationListStub{
 		&AllocationListStub{CreateIndex: 2},
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -14,6 +16,12 @@ import (
 	"github.com/hashicorp/go-cleanhttp"
 )
 
+const (
+	// nodeDialTimeout is the timeout to check whether the HTTP API of a node
+	// is reachable
+	nodeDialTimeout = 2 * time.Second
+)
+
 // QueryOptions are used to parameterize a query
 type QueryOptions struct {
 	// Providing a datacenter overwrites the region provided
@@ -125,6 +133,87 @@ func NewClient(config *Config) (*Client, error) {
 	return client, nil
 }
 
+// getNodeClient returns a client that talks to the HTTP API of the given
+// node, such as to reach the allocations running on it
+func (c *Client) getNodeClient(nodeID string, q *QueryOptions) (*Client, error) {
+	node, _, err := c.Nodes().Info(nodeID, q)
+	if err != nil {
+		return nil, err
+	}
+	if node.HTTPAddr == "" {
+		return nil, fmt.Errorf("http addr of the node %q is not advertised", nodeID)
+	}
+
+	conf := c.config
+	conf.Address = "http://" + node.HTTPAddr
+	return NewClient(&conf)
+}
+
+// getNodeClientOrSelf returns a client that talks to the HTTP API of the
+// given node if it is reachable, and the client itself otherwise. Agents
+// forward the requests for nodes they can not reach directly through the
+// servers.
+func (c *Client) getNodeClientOrSelf(nodeID string, q *QueryOptions) (*Client, error) {
+	node, _, err := c.Nodes().Info(nodeID, q)
+	if err != nil {
+		return nil, err
+	}
+	if node.HTTPAddr == "" {
+		return c, nil
+	}
+	conn, err := net.DialTimeout("tcp", node.HTTPAddr, nodeDialTimeout)
+	if err != nil {
+		return c, nil
+	}
+	conn.Close()
+
+	conf := c.config
+	conf.Address = "http://" + node.HTTPAddr
+	return NewClient(&conf)
+}
+
+// upgrade is used to send a request upgrading the connection to the given
+// protocol. It returns the connection once it is upgraded, along with a reader
+// which must be used to read from it.
+func (c *Client) upgrade(endpoint, protocol string, q *QueryOptions) (net.Conn, *bufio.Reader, error) {
+	r := c.newRequest("POST", endpoint)
+	r.setQueryOptions(q)
+	req, err := r.toHTTP()
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", protocol)
+
+	addr := req.URL.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "80")
+	}
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		var buf bytes.Buffer
+		io.Copy(&buf, resp.Body)
+		resp.Body.Close()
+		conn.Close()
+		return nil, nil, fmt.Errorf("Unexpected response code: %d (%s)", resp.StatusCode, buf.Bytes())
+	}
+	return conn, br, nil
+}
+
 // request is used to help build up a request
 type request struct {
 	config *Config
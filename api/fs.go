@@ -6,7 +6,6 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"strconv"
 	"time"
 )
@@ -20,6 +19,21 @@ type AllocFileInfo struct {
 	ModTime  time.Time
 }
 
+// StreamFrame is a chunk of a streamed file. Frames with neither data nor
+// file event are heartbeats; one is sent as soon as the end of the file is
+// reached when following it.
+type StreamFrame struct {
+	Offset    int64
+	Data      []byte
+	File      string
+	FileEvent string
+}
+
+// IsHeartbeat returns whether the frame is a heartbeat
+func (s *StreamFrame) IsHeartbeat() bool {
+	return len(s.Data) == 0 && s.FileEvent == ""
+}
+
 // AllocFS is used to introspect an allocation directory on a Nomad client
 type AllocFS struct {
 	client *Client
@@ -32,31 +46,19 @@ func (c *Client) AllocFS() *AllocFS {
 
 // List is used to list the files at a given path of an allocation directory
 func (a *AllocFS) List(alloc *Allocation, path string, q *QueryOptions) ([]*AllocFileInfo, *QueryMeta, error) {
-	node, _, err := a.client.Nodes().Info(alloc.NodeID, &QueryOptions{})
+	nodeClient, err := a.client.getNodeClientOrSelf(alloc.NodeID, q)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if node.HTTPAddr == "" {
-		return nil, nil, fmt.Errorf("http addr of the node where alloc %q is running is not advertised", alloc.ID)
-	}
-	u := &url.URL{
-		Scheme: "http",
-		Host:   node.HTTPAddr,
-		Path:   fmt.Sprintf("/v1/client/fs/ls/%s", alloc.ID),
-	}
-	v := url.Values{}
-	v.Set("path", path)
-	u.RawQuery = v.Encode()
-	req := &http.Request{
-		Method: "GET",
-		URL:    u,
-	}
-	c := http.Client{}
-	resp, err := c.Do(req)
+	r := nodeClient.newRequest("GET", fmt.Sprintf("/v1/client/fs/ls/%s", alloc.ID))
+	r.setQueryOptions(q)
+	r.params.Set("path", path)
+	_, resp, err := nodeClient.doRequest(r)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		return nil, nil, a.getErrorMsg(resp)
 	}
@@ -70,31 +72,19 @@ func (a *AllocFS) List(alloc *Allocation, path string, q *QueryOptions) ([]*Allo
 
 // Stat is used to stat a file at a given path of an allocation directory
 func (a *AllocFS) Stat(alloc *Allocation, path string, q *QueryOptions) (*AllocFileInfo, *QueryMeta, error) {
-	node, _, err := a.client.Nodes().Info(alloc.NodeID, &QueryOptions{})
+	nodeClient, err := a.client.getNodeClientOrSelf(alloc.NodeID, q)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if node.HTTPAddr == "" {
-		return nil, nil, fmt.Errorf("http addr of the node where alloc %q is running is not advertised", alloc.ID)
-	}
-	u := &url.URL{
-		Scheme: "http",
-		Host:   node.HTTPAddr,
-		Path:   fmt.Sprintf("/v1/client/fs/stat/%s", alloc.ID),
-	}
-	v := url.Values{}
-	v.Set("path", path)
-	u.RawQuery = v.Encode()
-	req := &http.Request{
-		Method: "GET",
-		URL:    u,
-	}
-	c := http.Client{}
-	resp, err := c.Do(req)
+	r := nodeClient.newRequest("GET", fmt.Sprintf("/v1/client/fs/stat/%s", alloc.ID))
+	r.setQueryOptions(q)
+	r.params.Set("path", path)
+	_, resp, err := nodeClient.doRequest(r)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		return nil, nil, a.getErrorMsg(resp)
 	}
@@ -109,34 +99,117 @@ func (a *AllocFS) Stat(alloc *Allocation, path string, q *QueryOptions) (*AllocF
 // ReadAt is used to read bytes at a given offset until limit at the given path
 // in an allocation directory
 func (a *AllocFS) ReadAt(alloc *Allocation, path string, offset int64, limit int64, q *QueryOptions) (io.Reader, *QueryMeta, error) {
-	node, _, err := a.client.Nodes().Info(alloc.NodeID, &QueryOptions{})
+	nodeClient, err := a.client.getNodeClientOrSelf(alloc.NodeID, q)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if node.HTTPAddr == "" {
-		return nil, nil, fmt.Errorf("http addr of the node where alloc %q is running is not advertised", alloc.ID)
+	r := nodeClient.newRequest("GET", fmt.Sprintf("/v1/client/fs/readat/%s", alloc.ID))
+	r.setQueryOptions(q)
+	r.params.Set("path", path)
+	r.params.Set("offset", strconv.FormatInt(offset, 10))
+	r.params.Set("limit", strconv.FormatInt(limit, 10))
+	_, resp, err := nodeClient.doRequest(r)
+	if err != nil {
+		return nil, nil, err
 	}
-	u := &url.URL{
-		Scheme: "http",
-		Host:   node.HTTPAddr,
-		Path:   fmt.Sprintf("/v1/client/fs/readat/%s", alloc.ID),
+	return resp.Body, nil, nil
+}
+
+// Logs is used to stream the logs of a task of an allocation. The log type
+// is either "stdout" or "stderr". The logs are read from the offset relative
+// to the origin, either "start" or "end" of the logs. If follow is set, the
+// logs are streamed as they are written until the cancel channel is closed.
+// Frames are sent on the returned channel, which is closed once the stream
+// ends. Errors are sent on the error channel.
+func (a *AllocFS) Logs(alloc *Allocation, follow bool, task, logType, origin string,
+	offset int64, cancel <-chan struct{}, q *QueryOptions) (<-chan *StreamFrame, <-chan error) {
+	nodeClient, err := a.client.getNodeClientOrSelf(alloc.NodeID, q)
+	if err != nil {
+		errCh := make(chan error, 1)
+		errCh <- err
+		return nil, errCh
 	}
-	v := url.Values{}
-	v.Set("path", path)
-	v.Set("offset", strconv.FormatInt(offset, 10))
-	v.Set("limit", strconv.FormatInt(limit, 10))
-	u.RawQuery = v.Encode()
-	req := &http.Request{
-		Method: "GET",
-		URL:    u,
+
+	r := nodeClient.newRequest("GET", fmt.Sprintf("/v1/client/fs/logs/%s", alloc.ID))
+	r.setQueryOptions(q)
+	r.params.Set("task", task)
+	r.params.Set("type", logType)
+	r.params.Set("origin", origin)
+	r.params.Set("offset", strconv.FormatInt(offset, 10))
+	r.params.Set("follow", strconv.FormatBool(follow))
+	return a.streamFrames(nodeClient, r, cancel)
+}
+
+// Stream is used to stream the content of a file of an allocation, from the
+// offset relative to the origin, either "start" or "end" of the file. Data
+// appended to the file is streamed as it is written, until the file is
+// deleted or the cancel channel is closed. Frames are sent on the returned
+// channel, which is closed once the stream ends. Errors are sent on the error
+// channel.
+func (a *AllocFS) Stream(alloc *Allocation, path, origin string, offset int64,
+	cancel <-chan struct{}, q *QueryOptions) (<-chan *StreamFrame, <-chan error) {
+	nodeClient, err := a.client.getNodeClientOrSelf(alloc.NodeID, q)
+	if err != nil {
+		errCh := make(chan error, 1)
+		errCh <- err
+		return nil, errCh
 	}
-	c := http.Client{}
-	resp, err := c.Do(req)
+
+	r := nodeClient.newRequest("GET", fmt.Sprintf("/v1/client/fs/stream/%s", alloc.ID))
+	r.setQueryOptions(q)
+	r.params.Set("path", path)
+	r.params.Set("origin", origin)
+	r.params.Set("offset", strconv.FormatInt(offset, 10))
+	return a.streamFrames(nodeClient, r, cancel)
+}
+
+// streamFrames sends the request and decodes the frames of the response
+func (a *AllocFS) streamFrames(nodeClient *Client, r *request, cancel <-chan struct{}) (<-chan *StreamFrame, <-chan error) {
+	errCh := make(chan error, 1)
+	_, resp, err := requireOK(nodeClient.doRequest(r))
 	if err != nil {
-		return nil, nil, err
+		errCh <- err
+		return nil, errCh
 	}
-	return resp.Body, nil, nil
+
+	// Close the stream once cancelled
+	doneCh := make(chan struct{})
+	go func() {
+		select {
+		case <-cancel:
+		case <-doneCh:
+		}
+		resp.Body.Close()
+	}()
+
+	frames := make(chan *StreamFrame, 10)
+	go func() {
+		defer close(frames)
+		defer close(doneCh)
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var frame StreamFrame
+			if err := dec.Decode(&frame); err != nil {
+				select {
+				case <-cancel:
+				default:
+					if err != io.EOF {
+						errCh <- err
+					}
+				}
+				return
+			}
+
+			select {
+			case frames <- &frame:
+			case <-cancel:
+				return
+			}
+		}
+	}()
+	return frames, errCh
 }
 
 func (a *AllocFS) getErrorMsg(resp *http.Response) error {
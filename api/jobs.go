@@ -26,14 +26,49 @@ func (c *Client) Jobs() *Jobs {
 // Register is used to register a new job. It returns the ID
 // of the evaluation, along with any errors encountered.
 func (j *Jobs) Register(job *Job, q *WriteOptions) (string, *WriteMeta, error) {
-	var resp registerJobResponse
+	resp, wm, err := j.RegisterWithResponse(job, q)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.EvalID, wm, nil
+}
+
+// RegisterWithResponse is used to register a new job and returns the full
+// response, including any warnings raised while registering it.
+func (j *Jobs) RegisterWithResponse(job *Job, q *WriteOptions) (*JobRegisterResponse, *WriteMeta, error) {
+	var resp JobRegisterResponse
 
 	req := &registerJobRequest{job}
 	wm, err := j.client.write("/v1/jobs", req, &resp, q)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
-	return resp.EvalID, wm, nil
+	return &resp, wm, nil
+}
+
+// RegisterMultiregion is used to register a multi-region job. The servers
+// roll the job out to its regions in the background, and its progress can be
+// followed with MultiregionStatus.
+func (j *Jobs) RegisterMultiregion(job *Job, q *WriteOptions) (*WriteMeta, error) {
+	var resp JobRegisterResponse
+
+	req := &registerJobRequest{job}
+	wm, err := j.client.write("/v1/jobs", req, &resp, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// MultiregionStatus is used to read the rollout of a multi-region job
+// submitted to the region of the query.
+func (j *Jobs) MultiregionStatus(jobID string, q *QueryOptions) (*MultiregionRollout, *QueryMeta, error) {
+	var resp MultiregionRollout
+	qm, err := j.client.query("/v1/job/"+jobID+"/multiregion", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
 }
 
 // List is used to list all of the existing jobs.
@@ -86,6 +121,16 @@ func (j *Jobs) Evaluations(jobID string, q *QueryOptions) ([]*Evaluation, *Query
 	return resp, qm, nil
 }
 
+// Summary is used to retrieve the summary of the allocations of a job
+func (j *Jobs) Summary(jobID string, q *QueryOptions) (*JobSummary, *QueryMeta, error) {
+	var resp JobSummary
+	qm, err := j.client.query("/v1/job/"+jobID+"/summary", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // Deregister is used to remove an existing job.
 func (j *Jobs) Deregister(jobID string, q *WriteOptions) (string, *WriteMeta, error) {
 	var resp deregisterJobResponse
@@ -98,7 +143,7 @@ func (j *Jobs) Deregister(jobID string, q *WriteOptions) (string, *WriteMeta, er
 
 // ForceEvaluate is used to force-evaluate an existing job.
 func (j *Jobs) ForceEvaluate(jobID string, q *WriteOptions) (string, *WriteMeta, error) {
-	var resp registerJobResponse
+	var resp JobRegisterResponse
 	wm, err := j.client.write("/v1/job/"+jobID+"/evaluate", nil, &resp, q)
 	if err != nil {
 		return "", nil, err
@@ -133,6 +178,56 @@ type PeriodicConfig struct {
 	Spec            string
 	SpecType        string
 	ProhibitOverlap bool
+	TimeZone        string
+	Catchup         string
+	CatchupLimit    int
+}
+
+// Multiregion is for serializing the multi-region config for a job.
+type Multiregion struct {
+	Strategy *MultiregionStrategy
+	Regions  []*MultiregionRegion
+}
+
+// MultiregionStrategy is for serializing the rollout strategy of a
+// multi-region job.
+type MultiregionStrategy struct {
+	MaxParallel int
+}
+
+// MultiregionRegion is for serializing the overrides of a single region of a
+// multi-region job.
+type MultiregionRegion struct {
+	Name        string
+	Count       int
+	Datacenters []string
+	Meta        map[string]string
+}
+
+// MultiregionResult is the outcome of registering a multi-region job in a
+// single region.
+type MultiregionResult struct {
+	Region         string
+	Status         string
+	EvalID         string
+	JobModifyIndex uint64
+	Warnings       string
+	Error          string
+}
+
+// MultiregionRollout is the progress of the rollout of a multi-region job.
+type MultiregionRollout struct {
+	JobID       string
+	Job         *Job
+	Status      string
+	Regions     []*MultiregionResult
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Terminal returns whether the rollout has finished.
+func (r *MultiregionRollout) Terminal() bool {
+	return r.Status == "complete" || r.Status == "failed"
 }
 
 // Job is used to serialize a job.
@@ -148,6 +243,8 @@ type Job struct {
 	TaskGroups        []*TaskGroup
 	Update            *UpdateStrategy
 	Periodic          *PeriodicConfig
+	Multiregion       *Multiregion
+	GCThreshold       time.Duration
 	Meta              map[string]string
 	Status            string
 	StatusDescription string
@@ -169,6 +266,33 @@ type JobListStub struct {
 	ModifyIndex       uint64
 }
 
+// JobSummary summarizes the allocations of a job by task group, and the
+// jobs it launched if it is periodic.
+type JobSummary struct {
+	JobID       string
+	Summary     map[string]TaskGroupSummary
+	Children    *JobChildrenSummary
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// TaskGroupSummary counts the allocations of a task group by state.
+type TaskGroupSummary struct {
+	Queued   int
+	Starting int
+	Running  int
+	Complete int
+	Failed   int
+	Lost     int
+}
+
+// JobChildrenSummary counts the jobs launched by a job by status.
+type JobChildrenSummary struct {
+	Pending int
+	Running int
+	Dead    int
+}
+
 // JobIDSort is used to sort jobs by their job ID's.
 type JobIDSort []*JobListStub
 
@@ -247,9 +371,13 @@ type registerJobRequest struct {
 	Job *Job
 }
 
-// registerJobResponse is used to deserialize a job response
-type registerJobResponse struct {
+// JobRegisterResponse is used to respond to a job registration
+type JobRegisterResponse struct {
 	EvalID string
+
+	// Warnings contains any warnings raised by the servers' admission
+	// controllers while registering the job.
+	Warnings string
 }
 
 // deregisterJobResponse is used to decode a deregister response
@@ -50,6 +50,54 @@ func TestJobs_Register(t *testing.T) {
 	}
 }
 
+func TestJobs_RegisterMultiregion(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Register a multi-region job that targets the local region only
+	job := testJob()
+	job.Multiregion = &Multiregion{
+		Regions: []*MultiregionRegion{
+			&MultiregionRegion{Name: "global", Count: 2},
+		},
+	}
+	wm, err := jobs.RegisterMultiregion(job, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if wm == nil {
+		t.Fatalf("missing write meta")
+	}
+
+	// Wait for the rollout to finish
+	var rollout *MultiregionRollout
+	testutil.WaitForResult(func() (bool, error) {
+		rollout, _, err = jobs.MultiregionStatus(job.ID, nil)
+		if err != nil {
+			return false, err
+		}
+		return rollout.Terminal(), nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+	if len(rollout.Regions) != 1 {
+		t.Fatalf("expected 1 result, got: %#v", rollout.Regions)
+	}
+	if r := rollout.Regions[0]; r.Region != "global" || r.Status != "complete" || r.EvalID == "" {
+		t.Fatalf("bad: %#v", r)
+	}
+
+	// Check the regional job was registered with the override
+	out, _, err := jobs.Info(job.ID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out.Region != "global" || out.TaskGroups[0].Count != 2 {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
 func TestJobs_Info(t *testing.T) {
 	c, s := makeClient(t, nil, nil)
 	defer s.Stop()
@@ -83,6 +131,42 @@ func TestJobs_Info(t *testing.T) {
 	}
 }
 
+func TestJobs_Summary(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Trying to retrieve a job summary before the job exists
+	// returns an error
+	_, _, err := jobs.Summary("job1", nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+
+	// Register the job
+	job := testJob()
+	_, wm, err := jobs.Register(job, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// Query the job summary and ensure it exists
+	result, qm, err := jobs.Summary("job1", nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+
+	// Check that the result is what we expect
+	if result.JobID != job.ID {
+		t.Fatalf("expect: %s, got: %#v", job.ID, result)
+	}
+	if _, ok := result.Summary["group1"]; !ok {
+		t.Fatalf("missing task group summary: %#v", result.Summary)
+	}
+}
+
 func TestJobs_PrefixList(t *testing.T) {
 	c, s := makeClient(t, nil, nil)
 	defer s.Stop()
@@ -0,0 +1,28 @@
+package api
+
+// Keyring is used to manage the root keys the variables are encrypted with.
+type Keyring struct {
+	client *Client
+}
+
+// Keyring returns a handle on the keyring endpoints.
+func (c *Client) Keyring() *Keyring {
+	return &Keyring{client: c}
+}
+
+// Rotate creates a new root key used to encrypt the variables written from
+// now on, and returns its ID. Variables written with the previous keys can
+// still be read.
+func (k *Keyring) Rotate(q *WriteOptions) (string, *WriteMeta, error) {
+	var resp keyringRotateResponse
+	wm, err := k.client.write("/v1/keyring/rotate", nil, &resp, q)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.KeyID, wm, nil
+}
+
+// keyringRotateResponse is used to decode a rotate response
+type keyringRotateResponse struct {
+	KeyID string
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"testing"
+)
+
+func TestKeyring_Rotate(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	k := c.Keyring()
+
+	first, wm, err := k.Rotate(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+	if first == "" {
+		t.Fatalf("missing key ID")
+	}
+
+	second, _, err := k.Rotate(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if second == "" || second == first {
+		t.Fatalf("bad: %q", second)
+	}
+}
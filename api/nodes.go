@@ -3,6 +3,14 @@ package api
 import (
 	"sort"
 	"strconv"
+	"time"
+)
+
+const (
+	// NodeSchedulingEligible and NodeSchedulingIneligible are the scheduling
+	// eligibilities of a node
+	NodeSchedulingEligible   = "eligible"
+	NodeSchedulingIneligible = "ineligible"
 )
 
 // Nodes is used to query node-related API endpoints
@@ -40,7 +48,8 @@ func (n *Nodes) Info(nodeID string, q *QueryOptions) (*Node, *QueryMeta, error)
 	return &resp, qm, nil
 }
 
-// ToggleDrain is used to toggle drain mode on/off for a given node.
+// ToggleDrain is used to toggle drain mode on/off for a given node. Turning
+// it off makes the node eligible for scheduling again.
 func (n *Nodes) ToggleDrain(nodeID string, drain bool, q *WriteOptions) (*WriteMeta, error) {
 	drainArg := strconv.FormatBool(drain)
 	wm, err := n.client.write("/v1/node/"+nodeID+"/drain?enable="+drainArg, nil, nil, q)
@@ -50,6 +59,41 @@ func (n *Nodes) ToggleDrain(nodeID string, drain bool, q *WriteOptions) (*WriteM
 	return wm, nil
 }
 
+// UpdateDrain is used to drain a node with the given drain spec, or to stop
+// draining it if the spec is nil. markEligible makes a node whose drain is
+// stopped eligible for scheduling again.
+func (n *Nodes) UpdateDrain(nodeID string, spec *DrainSpec, markEligible bool, q *WriteOptions) (*WriteMeta, error) {
+	req := &NodeUpdateDrainRequest{
+		NodeID:       nodeID,
+		MarkEligible: markEligible,
+	}
+	if spec != nil {
+		req.DrainStrategy = &DrainStrategy{DrainSpec: *spec}
+	}
+	wm, err := n.client.write("/v1/node/"+nodeID+"/drain", req, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// ToggleEligibility is used to mark a node eligible or ineligible for
+// scheduling.
+func (n *Nodes) ToggleEligibility(nodeID string, eligible bool, q *WriteOptions) (*WriteMeta, error) {
+	req := &NodeUpdateEligibilityRequest{
+		NodeID:      nodeID,
+		Eligibility: NodeSchedulingIneligible,
+	}
+	if eligible {
+		req.Eligibility = NodeSchedulingEligible
+	}
+	wm, err := n.client.write("/v1/node/"+nodeID+"/eligibility", req, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
 // Allocations is used to return the allocations associated with a node.
 func (n *Nodes) Allocations(nodeID string, q *QueryOptions) ([]*Allocation, *QueryMeta, error) {
 	var resp []*Allocation
@@ -71,37 +115,139 @@ func (n *Nodes) ForceEvaluate(nodeID string, q *WriteOptions) (string, *WriteMet
 	return resp.EvalID, wm, nil
 }
 
+// Stats is used to read the latest sample of the resource usage of the host
+// of a node. The request is sent to the node, or through the servers if the
+// node is not reachable.
+func (n *Nodes) Stats(nodeID string, q *QueryOptions) (*HostStats, error) {
+	nodeClient, err := n.client.getNodeClientOrSelf(nodeID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp HostStats
+	if _, err := nodeClient.query("/v1/client/stats?node_id="+nodeID, &resp, q); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// HostStats is a sample of the resource usage of the host of a node.
+type HostStats struct {
+	Memory    *HostMemoryStats
+	CPU       []*HostCPUStats
+	DiskStats []*HostDiskStats
+	Uptime    uint64
+	Timestamp int64
+}
+
+// HostMemoryStats holds the memory usage of a host, in bytes.
+type HostMemoryStats struct {
+	Total     uint64
+	Available uint64
+	Used      uint64
+	Free      uint64
+}
+
+// HostCPUStats holds the utilization of a core of a host, in percents of its
+// time.
+type HostCPUStats struct {
+	CPU    string
+	User   float64
+	System float64
+	Idle   float64
+	Total  float64
+}
+
+// HostDiskStats holds the usage of a mount hosting allocation directories,
+// in bytes.
+type HostDiskStats struct {
+	Device            string
+	Mountpoint        string
+	Size              uint64
+	Used              uint64
+	Available         uint64
+	UsedPercent       float64
+	InodesUsedPercent float64
+}
+
 // Node is used to deserialize a node entry.
 type Node struct {
-	ID                string
-	Datacenter        string
-	Name              string
-	HTTPAddr          string
-	Attributes        map[string]string
-	Resources         *Resources
-	Reserved          *Resources
-	Links             map[string]string
-	Meta              map[string]string
-	NodeClass         string
-	Drain             bool
-	Status            string
-	StatusDescription string
-	CreateIndex       uint64
-	ModifyIndex       uint64
+	ID                    string
+	Datacenter            string
+	Name                  string
+	HTTPAddr              string
+	Attributes            map[string]string
+	Resources             *Resources
+	Reserved              *Resources
+	Links                 map[string]string
+	Meta                  map[string]string
+	NodeClass             string
+	HostVolumes           map[string]*HostVolumeInfo
+	Drain                 bool
+	DrainStrategy         *DrainStrategy
+	SchedulingEligibility string
+	Status                string
+	StatusDescription     string
+	StatusUpdatedAt       int64
+	CreateIndex           uint64
+	ModifyIndex           uint64
+}
+
+// HostVolumeInfo is a host directory a node exposes to task groups as a named
+// volume
+type HostVolumeInfo struct {
+	Name     string
+	Path     string
+	ReadOnly bool
 }
 
 // NodeListStub is a subset of information returned during
 // node list operations.
 type NodeListStub struct {
-	ID                string
-	Datacenter        string
-	Name              string
-	NodeClass         string
-	Drain             bool
-	Status            string
-	StatusDescription string
-	CreateIndex       uint64
-	ModifyIndex       uint64
+	ID                    string
+	Datacenter            string
+	Name                  string
+	NodeClass             string
+	Drain                 bool
+	SchedulingEligibility string
+	Status                string
+	StatusDescription     string
+	CreateIndex           uint64
+	ModifyIndex           uint64
+}
+
+// DrainSpec describes how a node is drained
+type DrainSpec struct {
+	// Deadline is the duration after which the remaining allocations of the
+	// node are migrated regardless of their migrate strategy. No deadline is
+	// set if it is zero and the allocations are migrated at once if it is
+	// negative.
+	Deadline time.Duration
+
+	// IgnoreSystemJobs leaves the allocations of system jobs on the node
+	IgnoreSystemJobs bool
+}
+
+// DrainStrategy is the drain spec of a draining node along with the time
+// the drain started and the time its deadline is reached
+type DrainStrategy struct {
+	DrainSpec
+	ForceDeadline time.Time
+	StartedAt     time.Time
+}
+
+// NodeUpdateDrainRequest is used to update the drain strategy of a node
+type NodeUpdateDrainRequest struct {
+	NodeID        string
+	DrainStrategy *DrainStrategy
+	MarkEligible  bool
+}
+
+// NodeUpdateEligibilityRequest is used to update the scheduling eligibility
+// of a node
+type NodeUpdateEligibilityRequest struct {
+	NodeID      string
+	Eligibility string
 }
 
 // NodeIndexSort reverse sorts nodes by CreateIndex
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/testutil"
 )
@@ -189,6 +190,75 @@ func TestNodes_ToggleDrain(t *testing.T) {
 	}
 }
 
+func TestNodes_UpdateDrain(t *testing.T) {
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	nodes := c.Nodes()
+
+	// Wait for node registration and get the ID
+	var nodeID string
+	testutil.WaitForResult(func() (bool, error) {
+		out, _, err := nodes.List(nil)
+		if err != nil {
+			return false, err
+		}
+		if n := len(out); n != 1 {
+			return false, fmt.Errorf("expected 1 node, got: %d", n)
+		}
+		nodeID = out[0].ID
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+
+	// Drain the node with a deadline
+	spec := &DrainSpec{Deadline: time.Hour}
+	wm, err := nodes.UpdateDrain(nodeID, spec, false, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	out, _, err := nodes.Info(nodeID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !out.Drain || out.DrainStrategy == nil || out.DrainStrategy.Deadline != time.Hour {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out.SchedulingEligibility != NodeSchedulingIneligible {
+		t.Fatalf("bad eligibility: %q", out.SchedulingEligibility)
+	}
+
+	// Stop the drain and keep the node ineligible
+	if _, err := nodes.UpdateDrain(nodeID, nil, false, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	out, _, err = nodes.Info(nodeID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out.Drain || out.SchedulingEligibility != NodeSchedulingIneligible {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Mark the node eligible again
+	wm, err = nodes.ToggleEligibility(nodeID, true, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+	out, _, err = nodes.Info(nodeID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out.SchedulingEligibility != NodeSchedulingEligible {
+		t.Fatalf("bad eligibility: %q", out.SchedulingEligibility)
+	}
+}
+
 func TestNodes_Allocations(t *testing.T) {
 	c, s := makeClient(t, nil, nil)
 	defer s.Stop()
@@ -0,0 +1,56 @@
+package api
+
+// Services is used to query the services registered with the Nomad servers.
+type Services struct {
+	client *Client
+}
+
+// Services returns a handle on the service registration endpoints.
+func (c *Client) Services() *Services {
+	return &Services{client: c}
+}
+
+// List is used to list the names of the registered services along with the
+// tags of their instances. The Prefix of the query options restricts the
+// listing to the service names starting with it.
+func (s *Services) List(q *QueryOptions) ([]*ServiceRegistrationListStub, *QueryMeta, error) {
+	var resp []*ServiceRegistrationListStub
+	qm, err := s.client.query("/v1/services", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Get is used to return the registered instances of a service.
+func (s *Services) Get(name string, q *QueryOptions) ([]*ServiceRegistration, *QueryMeta, error) {
+	var resp []*ServiceRegistration
+	qm, err := s.client.query("/v1/service/"+name, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// ServiceRegistration is an instance of a service registered with the Nomad
+// servers.
+type ServiceRegistration struct {
+	ID          string
+	ServiceName string
+	JobID       string
+	AllocID     string
+	NodeID      string
+	TaskName    string
+	Tags        []string
+	Address     string
+	Port        int
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ServiceRegistrationListStub is used to return the name of a service and
+// the tags of its instances in the service list.
+type ServiceRegistrationListStub struct {
+	ServiceName string
+	Tags        []string
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"testing"
+)
+
+func TestServices_List(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	services := c.Services()
+
+	// Listing services works when there are none
+	list, qm, err := services.List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if n := len(list); n != 0 {
+		t.Fatalf("expected 0 services, got: %d", n)
+	}
+
+	// Unknown services have no instances
+	instances, qm, err := services.Get("frontend", nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if n := len(instances); n != 0 {
+		t.Fatalf("expected 0 instances, got: %d", n)
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+// System is used to query the system-related endpoints.
+type System struct {
+	client *Client
+}
+
+// System returns a handle on the system endpoints.
+func (c *Client) System() *System {
+	return &System{client: c}
+}
+
+// GarbageCollect triggers an immediate garbage collection of terminal jobs,
+// evaluations, allocations and nodes.
+func (s *System) GarbageCollect() error {
+	var req struct{}
+	_, err := s.client.write("/v1/system/gc", &req, nil, nil)
+	return err
+}
@@ -14,6 +14,30 @@ type RestartPolicy struct {
 	Mode             string
 }
 
+// MigrateStrategy defines how the allocations of a task group are migrated
+// off draining nodes
+type MigrateStrategy struct {
+	MaxParallel     int
+	MinHealthyTime  time.Duration
+	HealthyDeadline time.Duration
+}
+
+// VolumeRequest is a volume requested by a task group, which its tasks may
+// mount
+type VolumeRequest struct {
+	Name     string
+	Type     string
+	Source   string
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+// VolumeMount mounts a volume of the task group in a task
+type VolumeMount struct {
+	Volume      string
+	Destination string
+	ReadOnly    bool `mapstructure:"read_only"`
+}
+
 // The ServiceCheck data model represents the consul health check that
 // Nomad registers for a Task
 type ServiceCheck struct {
@@ -33,17 +57,21 @@ type Service struct {
 	Name      string
 	Tags      []string
 	PortLabel string `mapstructure:"port"`
+	Provider  string
 	Checks    []ServiceCheck
 }
 
 // TaskGroup is the unit of scheduling.
 type TaskGroup struct {
-	Name          string
-	Count         int
-	Constraints   []*Constraint
-	Tasks         []*Task
-	RestartPolicy *RestartPolicy
-	Meta          map[string]string
+	Name                string
+	Count               int
+	Constraints         []*Constraint
+	Tasks               []*Task
+	RestartPolicy       *RestartPolicy
+	Migrate             *MigrateStrategy
+	Meta                map[string]string
+	MaxClientDisconnect time.Duration
+	Volumes             map[string]*VolumeRequest
 }
 
 // NewTaskGroup creates a new TaskGroup.
@@ -69,6 +97,15 @@ func (g *TaskGroup) SetMeta(key, val string) *TaskGroup {
 	return g
 }
 
+// AddVolume is used to request a volume for the tasks of a task group.
+func (g *TaskGroup) AddVolume(v *VolumeRequest) *TaskGroup {
+	if g.Volumes == nil {
+		g.Volumes = make(map[string]*VolumeRequest)
+	}
+	g.Volumes[v.Name] = v
+	return g
+}
+
 // AddTask is used to add a new task to a task group.
 func (g *TaskGroup) AddTask(t *Task) *TaskGroup {
 	g.Tasks = append(g.Tasks, t)
@@ -77,15 +114,32 @@ func (g *TaskGroup) AddTask(t *Task) *TaskGroup {
 
 // Task is a single process in a task group.
 type Task struct {
-	Name        string
-	Driver      string
-	Config      map[string]interface{}
-	Constraints []*Constraint
-	Env         map[string]string
-	Services    []Service
-	Resources   *Resources
-	Meta        map[string]string
-	KillTimeout time.Duration
+	Name         string
+	Driver       string
+	Config       map[string]interface{}
+	Constraints  []*Constraint
+	Env          map[string]string
+	Services     []Service
+	Resources    *Resources
+	Meta         map[string]string
+	KillTimeout  time.Duration
+	Variables    []*TaskVariable
+	LogConfig    *LogConfig
+	VolumeMounts []*VolumeMount
+}
+
+// LogConfig configures the rotation of the stdout and stderr logs of a task.
+type LogConfig struct {
+	MaxFiles      int
+	MaxFileSizeMB int
+}
+
+// TaskVariable references a variable rendered for the task into its
+// environment or a file in the task directory.
+type TaskVariable struct {
+	Path        string
+	Env         bool
+	Destination string
 }
 
 // NewTask creates and initializes a new Task.
@@ -121,6 +175,18 @@ func (t *Task) Require(r *Resources) *Task {
 	return t
 }
 
+// SetLogConfig is used to configure the rotation of the logs of a task.
+func (t *Task) SetLogConfig(l *LogConfig) *Task {
+	t.LogConfig = l
+	return t
+}
+
+// AddVolumeMount is used to mount a volume of the task group in the task.
+func (t *Task) AddVolumeMount(m *VolumeMount) *Task {
+	t.VolumeMounts = append(t.VolumeMounts, m)
+	return t
+}
+
 // Constraint adds a new constraints to a single task.
 func (t *Task) Constrain(c *Constraint) *Task {
 	t.Constraints = append(t.Constraints, c)
@@ -139,16 +205,23 @@ const (
 	TaskStarted       = "Started"
 	TaskTerminated    = "Terminated"
 	TaskKilled        = "Killed"
+	TaskRestartSignal = "Restart Signaled"
+	TaskSignaling     = "Signaling"
+	TaskDiskExceeded  = "Disk Exceeded"
 )
 
 // TaskEvent is an event that effects the state of a task and contains meta-data
 // appropriate to the events type.
 type TaskEvent struct {
-	Type        string
-	Time        int64
-	DriverError string
-	ExitCode    int
-	Signal      int
-	Message     string
-	KillError   string
+	Type          string
+	Time          int64
+	DriverError   string
+	ExitCode      int
+	Signal        int
+	Message       string
+	KillError     string
+	RestartReason string
+	TaskSignal    string
+	DiskLimit     int64
+	DiskSize      int64
 }
@@ -102,6 +102,22 @@ func TestTaskGroup_AddTask(t *testing.T) {
 	}
 }
 
+func TestTaskGroup_AddVolume(t *testing.T) {
+	grp := NewTaskGroup("grp1", 1)
+
+	// Request a volume for the task group
+	volume := &VolumeRequest{Name: "data", Type: "host", Source: "mysql"}
+	out := grp.AddVolume(volume)
+	if grp.Volumes["data"] != volume {
+		t.Fatalf("expected volume to be added: %#v", grp.Volumes)
+	}
+
+	// Check that we returned the group
+	if out != grp {
+		t.Fatalf("expect: %#v, got: %#v", grp, out)
+	}
+}
+
 func TestTask_NewTask(t *testing.T) {
 	task := NewTask("task1", "exec")
 	expect := &Task{
@@ -217,3 +233,19 @@ func TestTask_Constrain(t *testing.T) {
 		t.Fatalf("expect: %#v, got: %#v", expect, task.Constraints)
 	}
 }
+
+func TestTask_AddVolumeMount(t *testing.T) {
+	task := NewTask("task1", "exec")
+
+	// Mount a volume in the task
+	mount := &VolumeMount{Volume: "data", Destination: "/data"}
+	out := task.AddVolumeMount(mount)
+	if !reflect.DeepEqual(task.VolumeMounts, []*VolumeMount{mount}) {
+		t.Fatalf("expected volume mount to be added: %#v", task.VolumeMounts)
+	}
+
+	// Check that we returned the task
+	if out != task {
+		t.Fatalf("expect: %#v, got: %#v", task, out)
+	}
+}
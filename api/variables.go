@@ -0,0 +1,78 @@
+package api
+
+import (
+	"sort"
+)
+
+// Variables is used to access the encrypted variables store.
+type Variables struct {
+	client *Client
+}
+
+// Variables returns a handle on the variables endpoints.
+func (c *Client) Variables() *Variables {
+	return &Variables{client: c}
+}
+
+// Read is used to read the variable at the given path.
+func (v *Variables) Read(path string, q *QueryOptions) (*Variable, *QueryMeta, error) {
+	var resp Variable
+	qm, err := v.client.query("/v1/var/"+path, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Put is used to create or replace the variable at its path.
+func (v *Variables) Put(variable *Variable, q *WriteOptions) (*WriteMeta, error) {
+	return v.client.write("/v1/var/"+variable.Path, variable, nil, q)
+}
+
+// Delete is used to delete the variable at the given path.
+func (v *Variables) Delete(path string, q *WriteOptions) (*WriteMeta, error) {
+	return v.client.delete("/v1/var/"+path, nil, q)
+}
+
+// List is used to list the variables, without their items. The Prefix of
+// the query options restricts the listing to the paths starting with it.
+func (v *Variables) List(q *QueryOptions) ([]*VariableListStub, *QueryMeta, error) {
+	var resp []*VariableListStub
+	qm, err := v.client.query("/v1/vars", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(VariablePathSort(resp))
+	return resp, qm, nil
+}
+
+// Variable is a set of secret items stored at a path.
+type Variable struct {
+	Path        string
+	Items       map[string]string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// VariableListStub is used to return a subset of variable information
+// in the variable list.
+type VariableListStub struct {
+	Path        string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// VariablePathSort is used to sort variables by their path.
+type VariablePathSort []*VariableListStub
+
+func (v VariablePathSort) Len() int {
+	return len(v)
+}
+
+func (v VariablePathSort) Less(i, j int) bool {
+	return v[i].Path < v[j].Path
+}
+
+func (v VariablePathSort) Swap(i, j int) {
+	v[i], v[j] = v[j], v[i]
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVariables_CRUD(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	v := c.Variables()
+
+	// Listing variables works when there are none
+	list, _, err := v.List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n := len(list); n != 0 {
+		t.Fatalf("expected 0 variables, got: %d", n)
+	}
+
+	// Create a variable
+	variable := &Variable{
+		Path:  "app/db",
+		Items: map[string]string{"password": "hunter2"},
+	}
+	wm, err := v.Put(variable, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// Read it back
+	out, qm, err := v.Read("app/db", nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if !reflect.DeepEqual(out.Items, variable.Items) {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// List by prefix
+	list, _, err = v.List(&QueryOptions{Prefix: "app"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(list) != 1 || list[0].Path != "app/db" {
+		t.Fatalf("bad: %#v", list)
+	}
+
+	// Delete it
+	if _, err := v.Delete("app/db", nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, _, err := v.Read("app/db", nil); err == nil {
+		t.Fatalf("expected error reading deleted variable")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/driver"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -20,6 +21,10 @@ const (
 	// allocSyncRetryIntv is the interval on which we retry updating
 	// the status of the allocation
 	allocSyncRetryIntv = 15 * time.Second
+
+	// diskWatchInterval is the interval at which the disk usage of the alloc
+	// dir is checked against the disk allocated to the allocation
+	diskWatchInterval = 30 * time.Second
 )
 
 // taskStatus is used to track the status of a task
@@ -37,6 +42,9 @@ type AllocRunner struct {
 	updater       AllocStateUpdater
 	logger        *log.Logger
 	consulService *ConsulService
+	nomadServices *NomadServices
+	variables     VariableFetcher
+	node          NodeFetcher
 
 	alloc *structs.Allocation
 
@@ -68,13 +76,17 @@ type allocRunnerState struct {
 
 // NewAllocRunner is used to create a new allocation context
 func NewAllocRunner(logger *log.Logger, config *config.Config, updater AllocStateUpdater,
-	alloc *structs.Allocation, consulService *ConsulService) *AllocRunner {
+	alloc *structs.Allocation, consulService *ConsulService, nomadServices *NomadServices,
+	variables VariableFetcher, node NodeFetcher) *AllocRunner {
 	ar := &AllocRunner{
 		config:        config,
 		updater:       updater,
 		logger:        logger,
 		alloc:         alloc,
 		consulService: consulService,
+		nomadServices: nomadServices,
+		variables:     variables,
+		node:          node,
 		dirtyCh:       make(chan struct{}, 1),
 		tasks:         make(map[string]*TaskRunner),
 		restored:      make(map[string]struct{}),
@@ -113,7 +125,7 @@ func (r *AllocRunner) RestoreState() error {
 		restartTracker := newRestartTracker(r.RestartPolicy)
 		tr := NewTaskRunner(r.logger, r.config, r.setTaskState, r.ctx,
 			r.alloc, task, r.alloc.TaskStates[task.Name], restartTracker,
-			r.consulService)
+			r.consulService, r.nomadServices, r.variables, r.node)
 		r.tasks[name] = tr
 
 		// Skip tasks in terminal states.
@@ -136,6 +148,11 @@ func (r *AllocRunner) RestoreState() error {
 // is snapshotted. If fullSync is marked as true, we snapshot
 // all the Task Runners associated with the Alloc
 func (r *AllocRunner) SaveState() error {
+	// The state of destroyed runners is removed
+	if r.IsDestroyed() {
+		return nil
+	}
+
 	if err := r.saveAllocRunnerState(); err != nil {
 		return err
 	}
@@ -229,9 +246,10 @@ func (r *AllocRunner) syncStatus() error {
 			pending = true
 		case structs.TaskStateDead:
 			last := len(state.Events) - 1
-			if state.Events[last].Type == structs.TaskDriverFailure {
+			switch state.Events[last].Type {
+			case structs.TaskDriverFailure, structs.TaskDiskExceeded:
 				failed = true
-			} else {
+			default:
 				dead = true
 			}
 		}
@@ -270,6 +288,15 @@ func (r *AllocRunner) setStatus(status, desc string) {
 	}
 }
 
+// ResyncStatus is used to send the allocation status to the servers again,
+// even if it has not changed.
+func (r *AllocRunner) ResyncStatus() {
+	select {
+	case r.dirtyCh <- struct{}{}:
+	default:
+	}
+}
+
 // setTaskState is used to set the status of a task
 func (r *AllocRunner) setTaskState(taskName string) {
 	select {
@@ -325,12 +352,15 @@ func (r *AllocRunner) Run() {
 		restartTracker := newRestartTracker(r.RestartPolicy)
 		tr := NewTaskRunner(r.logger, r.config, r.setTaskState, r.ctx,
 			r.alloc, task, r.alloc.TaskStates[task.Name], restartTracker,
-			r.consulService)
+			r.consulService, r.nomadServices, r.variables, r.node)
 		r.tasks[task.Name] = tr
 		go tr.Run()
 	}
 	r.taskLock.Unlock()
 
+	// Kill the tasks if they use more disk than allocated
+	go r.watchDisk(tg)
+
 OUTER:
 	// Wait for updates
 	for {
@@ -399,6 +429,56 @@ OUTER:
 	r.logger.Printf("[DEBUG] client: terminating runner for alloc '%s'", r.alloc.ID)
 }
 
+// watchDisk periodically checks the disk usage of the alloc dir and kills the
+// tasks of the allocation once it exceeds the disk allocated to the task
+// group, until the runner is destroyed
+func (r *AllocRunner) watchDisk(tg *structs.TaskGroup) {
+	var limit int64
+	for _, task := range tg.Tasks {
+		if resources := r.alloc.TaskResources[task.Name]; resources != nil {
+			limit += int64(resources.DiskMB) * 1024 * 1024
+		}
+	}
+	if limit == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(diskWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if r.checkDisk(limit) {
+				return
+			}
+		case <-r.destroyCh:
+			return
+		}
+	}
+}
+
+// checkDisk kills the tasks of the allocation if the alloc dir uses more disk
+// space than the limit in bytes, and returns whether they were killed
+func (r *AllocRunner) checkDisk(limit int64) bool {
+	size, err := r.ctx.AllocDir.Size()
+	if err != nil {
+		r.logger.Printf("[WARN] client: failed to measure disk usage of alloc '%s': %v", r.alloc.ID, err)
+		return false
+	}
+	if size <= limit {
+		return false
+	}
+
+	r.logger.Printf("[ERR] client: killing alloc '%s': disk usage (%d bytes) over allocated disk (%d bytes)",
+		r.alloc.ID, size, limit)
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+	for _, tr := range r.tasks {
+		tr.Kill(structs.NewTaskEvent(structs.TaskDiskExceeded).SetDiskLimit(limit).SetDiskSize(size))
+	}
+	return true
+}
+
 // Update is used to update the allocation of the context
 func (r *AllocRunner) Update(update *structs.Allocation) {
 	select {
@@ -408,6 +488,89 @@ func (r *AllocRunner) Update(update *structs.Allocation) {
 	}
 }
 
+// RestartTask restarts a task of the allocation, or all of its tasks if no
+// task is given
+func (r *AllocRunner) RestartTask(taskName, reason string) error {
+	runners, err := r.taskRunners(taskName)
+	if err != nil {
+		return err
+	}
+
+	var mErr multierror.Error
+	for _, tr := range runners {
+		if err := tr.Restart(reason); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// SignalTask sends a signal to a task of the allocation, or to all of its
+// tasks if no task is given
+func (r *AllocRunner) SignalTask(taskName, name string, s os.Signal) error {
+	runners, err := r.taskRunners(taskName)
+	if err != nil {
+		return err
+	}
+
+	var mErr multierror.Error
+	for _, tr := range runners {
+		if err := tr.Signal(name, s); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ExecTask runs a command inside a task of the allocation and returns its
+// exit code. The task may be omitted if the allocation has a single task.
+func (r *AllocRunner) ExecTask(taskName string, opts *cstructs.ExecOptions) (int, error) {
+	runners, err := r.taskRunners(taskName)
+	if err != nil {
+		return 0, err
+	}
+	if len(runners) != 1 {
+		return 0, fmt.Errorf("alloc '%s' has multiple tasks, a task must be given", r.alloc.ID)
+	}
+	return runners[0].Exec(opts)
+}
+
+// TaskResourceUsage returns the samples of the resource usage of a task of
+// the allocation, or of all of its tasks if no task is given, by task name
+func (r *AllocRunner) TaskResourceUsage(taskName string) (map[string][]*cstructs.TaskResourceUsage, error) {
+	runners, err := r.taskRunners(taskName)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string][]*cstructs.TaskResourceUsage, len(runners))
+	for _, tr := range runners {
+		usage[tr.task.Name] = tr.ResourceUsage()
+	}
+	return usage, nil
+}
+
+// taskRunners returns the runner of the given task, or all the task runners
+// if no task is given
+func (r *AllocRunner) taskRunners(taskName string) ([]*TaskRunner, error) {
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+
+	if taskName != "" {
+		tr, ok := r.tasks[taskName]
+		if !ok {
+			return nil, fmt.Errorf("unknown task %q in alloc '%s'", taskName, r.alloc.ID)
+		}
+		return []*TaskRunner{tr}, nil
+	}
+
+	runners := make([]*TaskRunner, 0, len(r.tasks))
+	for _, tr := range r.tasks {
+		runners = append(runners, tr)
+	}
+	return runners, nil
+}
+
 // Destroy is used to indicate that the allocation context should be destroyed
 func (r *AllocRunner) Destroy() {
 	r.destroyLock.Lock()
@@ -420,6 +583,13 @@ func (r *AllocRunner) Destroy() {
 	close(r.destroyCh)
 }
 
+// IsDestroyed returns whether the runner was destroyed
+func (r *AllocRunner) IsDestroyed() bool {
+	r.destroyLock.Lock()
+	defer r.destroyLock.Unlock()
+	return r.destroy
+}
+
 // WaitCh returns a channel to wait for termination
 func (r *AllocRunner) WaitCh() <-chan struct{} {
 	return r.waitCh
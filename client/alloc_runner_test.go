@@ -1,7 +1,9 @@
 package client
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -36,7 +38,7 @@ func testAllocRunner(restarts bool) (*MockAllocStateUpdater, *AllocRunner) {
 		*alloc.Job.LookupTaskGroup(alloc.TaskGroup).RestartPolicy = structs.RestartPolicy{Attempts: 0, RestartOnSuccess: false}
 	}
 
-	ar := NewAllocRunner(logger, conf, upd.Update, alloc, consulClient)
+	ar := NewAllocRunner(logger, conf, upd.Update, alloc, consulClient, nil, nil, conf.Node.Copy)
 	return upd, ar
 }
 
@@ -148,7 +150,7 @@ func TestAllocRunner_SaveRestoreState(t *testing.T) {
 	// Create a new alloc runner
 	consulClient, err := NewConsulService(&consulServiceConfig{ar.logger, "127.0.0.1:8500", "", "", false, false, &structs.Node{}})
 	ar2 := NewAllocRunner(ar.logger, ar.config, upd.Update,
-		&structs.Allocation{ID: ar.alloc.ID}, consulClient)
+		&structs.Allocation{ID: ar.alloc.ID}, consulClient, nil, nil, ar.config.Node.Copy)
 	err = ar2.RestoreState()
 	if err != nil {
 		t.Fatalf("err: %v", err)
@@ -173,3 +175,51 @@ func TestAllocRunner_SaveRestoreState(t *testing.T) {
 		t.Fatalf("took too long to terminate")
 	}
 }
+
+func TestAllocRunner_DiskExceeded(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	upd, ar := testAllocRunner(false)
+
+	// Ensure task takes some time
+	task := ar.alloc.Job.TaskGroups[0].Tasks[0]
+	task.Config["command"] = "/bin/sleep"
+	task.Config["args"] = []string{"10"}
+	go ar.Run()
+	defer ar.Destroy()
+
+	testutil.WaitForResult(func() (bool, error) {
+		state, ok := ar.alloc.TaskStates[task.Name]
+		return ok && state.State == structs.TaskStateRunning, nil
+	}, func(err error) {
+		t.Fatalf("task never started: %v", err)
+	})
+
+	// The tasks are not killed under the limit
+	if ar.checkDisk(1024 * 1024 * 1024) {
+		t.Fatalf("tasks killed under the disk limit")
+	}
+
+	// Exceeding the limit kills the tasks and fails the allocation
+	if err := ioutil.WriteFile(filepath.Join(ar.ctx.AllocDir.SharedDir, "foo"), make([]byte, 1024), 0666); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !ar.checkDisk(1) {
+		t.Fatalf("tasks not killed over the disk limit")
+	}
+
+	testutil.WaitForResult(func() (bool, error) {
+		if upd.Count == 0 {
+			return false, nil
+		}
+		last := upd.Allocs[upd.Count-1]
+		return last.ClientStatus == structs.AllocClientStatusFailed, nil
+	}, func(err error) {
+		t.Fatalf("err: %v %#v", err, ar.alloc.TaskStates)
+	})
+
+	state := ar.alloc.TaskStates[task.Name]
+	event := state.Events[len(state.Events)-1]
+	if event.Type != structs.TaskDiskExceeded || event.DiskLimit != 1 || event.DiskSize == 0 {
+		t.Fatalf("unexpected last event: %#v", event)
+	}
+}
@@ -6,17 +6,30 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
+const (
+	// changePollInterval is the interval at which changes are notified when
+	// files can not be watched
+	changePollInterval = 250 * time.Millisecond
+)
+
 var (
 	// The name of the directory that is shared across tasks in a task group.
 	SharedAllocName = "alloc"
 
+	// The name of the shared directory the logs of the tasks are written to.
+	LogDirName = "logs"
+
 	// The set of directories that exist inside eache shared alloc directory.
-	SharedAllocDirs = []string{"logs", "tmp", "data"}
+	SharedAllocDirs = []string{LogDirName, "tmp", "data"}
 
 	// The name of the directory that exists inside each task directory
 	// regardless of driver.
@@ -35,6 +48,15 @@ type AllocDir struct {
 	// TaskDirs is a mapping of task names to their non-shared directory.
 	TaskDirs map[string]string
 
+	// ProjectID is the ID of the filesystem project of the alloc dir, whose
+	// quota tracks the disk usage of the alloc dir. It is zero if the
+	// filesystem does not support project quotas.
+	ProjectID uint32
+
+	// EmbeddedDirs maps the directories embedded in the task directories,
+	// such as their chroot, to the host directories they were copied from.
+	EmbeddedDirs map[string]string
+
 	// A list of locations the shared alloc has been mounted to.
 	mounted []string
 }
@@ -53,6 +75,7 @@ type AllocDirFS interface {
 	List(path string) ([]*AllocFileInfo, error)
 	Stat(path string) (*AllocFileInfo, error)
 	ReadAt(path string, offset int64, limit int64) (io.ReadCloser, error)
+	ChangeEvents(path string, stopCh <-chan struct{}) (<-chan struct{}, error)
 }
 
 func NewAllocDir(allocDir string) *AllocDir {
@@ -70,9 +93,50 @@ func (d *AllocDir) Destroy() error {
 		}
 	}
 
+	// Unmount anything else mounted in the alloc dir, such as the host
+	// volumes of tasks that were not cleaned up, so that removing it never
+	// reaches into them
+	if err := d.unmountAll(); err != nil {
+		return err
+	}
+
 	return os.RemoveAll(d.AllocDir)
 }
 
+// unmountAll unmounts the filesystems mounted under the alloc dir, the
+// deepest first. It fails if any of them is still mounted afterwards.
+func (d *AllocDir) unmountAll() error {
+	mounts, err := mountPoints(d.AllocDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("Failed to list the mounts of the alloc dir: %v", err)
+	}
+	if len(mounts) == 0 {
+		return nil
+	}
+
+	// Mount points sort after their parents
+	sort.Sort(sort.Reverse(sort.StringSlice(mounts)))
+	var mErr multierror.Error
+	for _, m := range mounts {
+		if err := unmount(m); err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Failed to unmount %v: %v", m, err))
+		}
+	}
+
+	remaining, err := mountPoints(d.AllocDir)
+	if err != nil {
+		return fmt.Errorf("Failed to list the mounts of the alloc dir: %v", err)
+	}
+	if len(remaining) != 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Refusing to remove the alloc dir with mounts: %v", remaining))
+		return mErr.ErrorOrNil()
+	}
+	return nil
+}
+
 // Given a list of a task build the correct alloc structure.
 func (d *AllocDir) Build(tasks []*structs.Task) error {
 	// Make the alloc directory, owned by the nomad process.
@@ -80,6 +144,11 @@ func (d *AllocDir) Build(tasks []*structs.Task) error {
 		return fmt.Errorf("Failed to make the alloc directory %v: %v", d.AllocDir, err)
 	}
 
+	// Track the disk usage of the alloc dir with a project quota if the
+	// filesystem supports them. The directories created below inherit the
+	// project.
+	d.ProjectID = d.setupProjectQuota()
+
 	// Make the shared directory and make it availabe to all user/groups.
 	if err := os.Mkdir(d.SharedDir, 0777); err != nil {
 		return err
@@ -164,6 +233,10 @@ func (d *AllocDir) Embed(task string, entries map[string]string) error {
 		if err := os.MkdirAll(destDir, s.Mode().Perm()); err != nil {
 			return fmt.Errorf("Couldn't create destination directory %v: %v", destDir, err)
 		}
+		if d.EmbeddedDirs == nil {
+			d.EmbeddedDirs = make(map[string]string)
+		}
+		d.EmbeddedDirs[destDir] = source
 
 		// Enumerate the files in source.
 		dirEntries, err := ioutil.ReadDir(source)
@@ -237,6 +310,120 @@ func (d *AllocDir) MountSharedDir(task string) error {
 	return nil
 }
 
+// Size returns the disk space used by the alloc dir in bytes. It is read from
+// the project quota of the alloc dir if the filesystem supports them, and
+// computed by walking the alloc dir otherwise.
+func (d *AllocDir) Size() (int64, error) {
+	if d.ProjectID != 0 {
+		if size, err := projectQuotaUsage(d.AllocDir, d.ProjectID); err == nil {
+			return size, nil
+		}
+	}
+	return d.walkSize()
+}
+
+// walkSize sums the disk space used by the files of the alloc dir. The shared
+// dir mounted in the task dirs is counted once, as are files with several
+// hard links. The host files embedded in the task dirs are not counted, unless
+// the task modified them, nor are the filesystems mounted in the alloc dir,
+// such as host volumes.
+func (d *AllocDir) walkSize() (int64, error) {
+	mounts, err := mountPoints(d.AllocDir)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to list the mounts of the alloc dir: %v", err)
+	}
+
+	skip := make(map[string]struct{}, len(d.TaskDirs)+len(d.EmbeddedDirs)+len(mounts))
+	for _, taskDir := range d.TaskDirs {
+		skip[filepath.Join(taskDir, SharedAllocName)] = struct{}{}
+	}
+	for dir := range d.EmbeddedDirs {
+		skip[dir] = struct{}{}
+	}
+	for _, m := range mounts {
+		skip[m] = struct{}{}
+	}
+
+	info, err := os.Lstat(d.AllocDir)
+	if err != nil {
+		return 0, err
+	}
+	w := &sizeWalker{skip: skip, seen: make(map[fileID]struct{})}
+	w.root, w.rootKnown = fileIdentity(info)
+	if err := w.walk(d.AllocDir, ""); err != nil {
+		return 0, err
+	}
+	for dir, host := range d.EmbeddedDirs {
+		if err := w.walk(dir, host); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+	}
+	return w.size, nil
+}
+
+// sizeWalker sums the disk space used by the files of directory trees,
+// counting each file once. Directories on another device than the alloc dir
+// are not walked.
+type sizeWalker struct {
+	skip map[string]struct{}
+	seen map[fileID]struct{}
+	size int64
+
+	root      fileID
+	rootKnown bool
+}
+
+// walk adds the disk space used by the files under the root. If the root was
+// embedded from a host directory, the files that are the same as the host
+// files they were copied from are not counted.
+func (w *sizeWalker) walk(root, host string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Files may be removed by the tasks during the walk
+			if os.IsNotExist(err) && path != root {
+				return nil
+			}
+			return err
+		}
+		if _, ok := w.skip[path]; ok && path != root {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if id, ok := fileIdentity(info); ok && w.rootKnown && id.dev != w.root.dev {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if host != "" && embeddedFile(info, filepath.Join(host, strings.TrimPrefix(path, root))) {
+			return nil
+		}
+		if id, ok := fileIdentity(info); ok {
+			if _, ok := w.seen[id]; ok {
+				return nil
+			}
+			w.seen[id] = struct{}{}
+		}
+		w.size += fileDiskUsage(info)
+		return nil
+	})
+}
+
+// embeddedFile returns whether a file of an embedded directory is still the
+// host file it was hard linked or copied from
+func embeddedFile(info os.FileInfo, hostPath string) bool {
+	hostInfo, err := os.Lstat(hostPath)
+	if err != nil {
+		return false
+	}
+	if os.SameFile(info, hostInfo) {
+		return true
+	}
+	return info.Mode().IsRegular() && hostInfo.Mode().IsRegular() && info.Size() == hostInfo.Size()
+}
+
 // List returns the list of files at a path relative to the alloc dir
 func (d *AllocDir) List(path string) ([]*AllocFileInfo, error) {
 	p := filepath.Join(d.AllocDir, path)
@@ -282,9 +469,79 @@ func (d *AllocDir) ReadAt(path string, offset int64, limit int64) (io.ReadCloser
 	if err != nil {
 		return nil, err
 	}
+	if _, err := f.Seek(offset, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("can't seek to offset %d: %v", offset, err)
+	}
 	return &ReadCloserWrapper{Reader: io.LimitReader(f, limit), Closer: f}, nil
 }
 
+// ChangeEvents returns a channel notified when the file or directory at the
+// path relative to the alloc dir changes, until the stop channel is closed.
+// The changes of a file are watched through its directory, so that its
+// creation and deletion are notified too. If the file can not be watched,
+// the channel is notified periodically instead.
+func (d *AllocDir) ChangeEvents(path string, stopCh <-chan struct{}) (<-chan struct{}, error) {
+	p := filepath.Join(d.AllocDir, path)
+	info, err := os.Stat(p)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	dir, name := p, ""
+	if info == nil || !info.IsDir() {
+		dir, name = filepath.Split(p)
+	}
+
+	changeCh := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changeCh <- struct{}{}:
+		default:
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = watcher.Add(dir); err != nil {
+			watcher.Close()
+		}
+	}
+	if err != nil {
+		// Fall back to polling
+		go func() {
+			ticker := time.NewTicker(changePollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					notify()
+				case <-stopCh:
+					return
+				}
+			}
+		}()
+		return changeCh, nil
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event := <-watcher.Events:
+				if name == "" || filepath.Base(event.Name) == name {
+					notify()
+				}
+			case <-watcher.Errors:
+				// Events may have been missed
+				notify()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return changeCh, nil
+}
+
 // ReadCloserWrapper wraps a LimitReader so that a file is closed once it has been
 // read
 type ReadCloserWrapper struct {
@@ -1,6 +1,7 @@
 package allocdir
 
 import (
+	"errors"
 	"syscall"
 )
 
@@ -13,3 +14,21 @@ func (d *AllocDir) mountSharedDir(dir string) error {
 func (d *AllocDir) unmountSharedDir(dir string) error {
 	return syscall.Unlink(dir)
 }
+
+// Mounts are not tracked on Darwin.
+func mountPoints(path string) ([]string, error) {
+	return nil, nil
+}
+
+func unmount(path string) error {
+	return nil
+}
+
+// Project quotas are not supported on Darwin.
+func (d *AllocDir) setupProjectQuota() uint32 {
+	return 0
+}
+
+func projectQuotaUsage(path string, id uint32) (int64, error) {
+	return 0, errors.New("Project quotas on Darwin not supported.")
+}
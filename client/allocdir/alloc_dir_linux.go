@@ -1,8 +1,15 @@
 package allocdir
 
 import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
+	"unsafe"
 )
 
 // Bind mounts the shared directory into the task directory. Must be root to
@@ -18,3 +25,197 @@ func (d *AllocDir) mountSharedDir(taskDir string) error {
 func (d *AllocDir) unmountSharedDir(dir string) error {
 	return syscall.Unmount(dir, 0)
 }
+
+// mountPoints returns the mount points under the path.
+func mountPoints(path string) ([]string, error) {
+	// The mount points are listed with the symlinks of their path resolved
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		mp := unescapeMountPoint(fields[1])
+		if strings.HasPrefix(mp, resolved+"/") {
+			mounts = append(mounts, path+strings.TrimPrefix(mp, resolved))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+// unescapeMountPoint decodes the octal escapes of the spaces, tabs,
+// newlines and backslashes of a mount point in /proc/self/mounts.
+func unescapeMountPoint(mp string) string {
+	if !strings.Contains(mp, "\\") {
+		return mp
+	}
+	var b []byte
+	for i := 0; i < len(mp); i++ {
+		if mp[i] == '\\' && i+3 < len(mp) {
+			if c, err := strconv.ParseUint(mp[i+1:i+4], 8, 8); err == nil {
+				b = append(b, byte(c))
+				i += 3
+				continue
+			}
+		}
+		b = append(b, mp[i])
+	}
+	return string(b)
+}
+
+// unmount detaches the filesystem mounted at the path. It is detached lazily
+// so that a busy mount does not remain in the alloc dir.
+func unmount(path string) error {
+	return syscall.Unmount(path, syscall.MNT_DETACH)
+}
+
+const (
+	// The ioctls reading and setting the extended attributes of a file, which
+	// hold its project ID.
+	fsIocFsGetXattr = 0x801c581f
+	fsIocFsSetXattr = 0x401c5820
+
+	// fsXflagProjInherit makes the files created in a directory inherit its
+	// project ID.
+	fsXflagProjInherit = 0x200
+
+	// The quotactl command reading the quota of a project.
+	qGetQuota = 0x800007
+	prjQuota  = 2
+)
+
+// fsXattr is the struct fsxattr of the extended attributes ioctls.
+type fsXattr struct {
+	Xflags     uint32
+	Extsize    uint32
+	Nextents   uint32
+	Projid     uint32
+	Cowextsize uint32
+	Pad        [8]byte
+}
+
+// ifDqblk is the struct if_dqblk of the quotactl syscall.
+type ifDqblk struct {
+	Bhardlimit uint64
+	Bsoftlimit uint64
+	Curspace   uint64
+	Ihardlimit uint64
+	Isoftlimit uint64
+	Curinodes  uint64
+	Btime      uint64
+	Itime      uint64
+	Valid      uint32
+}
+
+// setupProjectQuota assigns the alloc dir to a project derived from its path,
+// inherited by the files created in it, so that its disk usage is tracked by
+// the project quotas of the filesystem. It returns the ID of the project, or
+// zero if the filesystem does not support project quotas or the client is not
+// allowed to set them.
+func (d *AllocDir) setupProjectQuota() uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(d.AllocDir))
+	id := h.Sum32()
+	if id == 0 {
+		id = 1
+	}
+
+	f, err := os.Open(d.AllocDir)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	var attr fsXattr
+	if err := ioctl(f.Fd(), fsIocFsGetXattr, unsafe.Pointer(&attr)); err != nil {
+		return 0
+	}
+	attr.Projid = id
+	attr.Xflags |= fsXflagProjInherit
+	if err := ioctl(f.Fd(), fsIocFsSetXattr, unsafe.Pointer(&attr)); err != nil {
+		return 0
+	}
+
+	// Only use the project if the quotas of the filesystem can be read
+	if _, err := projectQuotaUsage(d.AllocDir, id); err != nil {
+		return 0
+	}
+	return id
+}
+
+// projectQuotaUsage returns the disk space used by a project on the
+// filesystem of a path in bytes.
+func projectQuotaUsage(path string, id uint32) (int64, error) {
+	device, err := mountDevice(path)
+	if err != nil {
+		return 0, err
+	}
+	devicePtr, err := syscall.BytePtrFromString(device)
+	if err != nil {
+		return 0, err
+	}
+
+	var quota ifDqblk
+	cmd := uint32(qGetQuota<<8 | prjQuota)
+	_, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, uintptr(cmd),
+		uintptr(unsafe.Pointer(devicePtr)), uintptr(id), uintptr(unsafe.Pointer(&quota)), 0, 0)
+	if errno != 0 {
+		return 0, fmt.Errorf("Failed to read quota of project %d on %v: %v", id, device, errno)
+	}
+	return int64(quota.Curspace), nil
+}
+
+// mountDevice returns the device of the filesystem mounted on the longest
+// mount point containing the path.
+func mountDevice(path string) (string, error) {
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var device, mountPoint string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		mp := fields[1]
+		if mp != "/" && path != mp && !strings.HasPrefix(path, mp+"/") {
+			continue
+		}
+		if len(mp) >= len(mountPoint) {
+			device, mountPoint = fields[0], mp
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if device == "" {
+		return "", fmt.Errorf("No mount found for %v", path)
+	}
+	return device, nil
+}
+
+func ioctl(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package allocdir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/testutil"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestAllocDir_HostVolumeMount(t *testing.T) {
+	testutil.MountCompatible(t)
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	defer d.Destroy()
+	if err := d.Build([]*structs.Task{t1}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	// Bind mount a host volume in the task dir, as the executor does
+	host, err := ioutil.TempDir("", "AllocDirHost")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(host)
+	hostFile := filepath.Join(host, "foo")
+	if err := ioutil.WriteFile(hostFile, make([]byte, 1024*1024), 0666); err != nil {
+		t.Fatalf("Couldn't write file: %v", err)
+	}
+	target := filepath.Join(d.TaskDirs[t1.Name], "data")
+	if err := os.Mkdir(target, 0777); err != nil {
+		t.Fatalf("Couldn't create mount point: %v", err)
+	}
+	if err := syscall.Mount(host, target, "", syscall.MS_BIND, ""); err != nil {
+		t.Fatalf("Couldn't mount host volume: %v", err)
+	}
+
+	// The files of the host volume are not counted
+	size, err := d.Size()
+	if err != nil {
+		t.Fatalf("Size() failed: %v", err)
+	}
+	if size > 64*1024 {
+		t.Fatalf("Size() returned %d; host volume counted", size)
+	}
+
+	// Destroying the alloc dir unmounts the host volume rather than
+	// removing its files
+	if err := d.Destroy(); err != nil {
+		t.Fatalf("Destroy() failed: %v", err)
+	}
+	if _, err := os.Stat(hostFile); err != nil {
+		t.Fatalf("host volume file removed: %v", err)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("alloc dir not removed: %v", err)
+	}
+}
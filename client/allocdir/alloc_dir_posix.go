@@ -20,6 +20,31 @@ func (d *AllocDir) linkOrCopy(src, dst string, perm os.FileMode) error {
 	return fileCopy(src, dst, perm)
 }
 
+// fileID identifies a file by its device and inode
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// fileIdentity returns the device and inode of a file, shared by all its hard
+// links.
+func fileIdentity(info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
+
+// fileDiskUsage returns the disk space allocated to a file.
+func fileDiskUsage(info os.FileInfo) int64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size()
+	}
+	return int64(stat.Blocks) * 512
+}
+
 func (d *AllocDir) dropDirPermissions(path string) error {
 	// Can't do anything if not root.
 	if syscall.Geteuid() != 0 {
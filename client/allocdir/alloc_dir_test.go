@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/client/testutil"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -196,3 +197,115 @@ func TestAllocDir_MountSharedAlloc(t *testing.T) {
 		}
 	}
 }
+
+func TestAllocDir_ChangeEvents(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	defer d.Destroy()
+	if err := d.Build([]*structs.Task{t1}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	changeCh, err := d.ChangeEvents(filepath.Join(SharedAllocName, "foo"), stopCh)
+	if err != nil {
+		t.Fatalf("ChangeEvents() failed: %v", err)
+	}
+
+	// Creating the watched file is notified
+	if err := ioutil.WriteFile(filepath.Join(d.SharedDir, "foo"), []byte("foo"), 0666); err != nil {
+		t.Fatalf("Couldn't write file to shared directory: %v", err)
+	}
+	select {
+	case <-changeCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("change not notified")
+	}
+}
+
+func TestAllocDir_Size(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	defer d.Destroy()
+	if err := d.Build([]*structs.Task{t1}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	// Write files to the shared dir and the task dir
+	data := make([]byte, 1024*1024)
+	for _, dir := range []string{d.SharedDir, d.TaskDirs[t1.Name]} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "foo"), data, 0666); err != nil {
+			t.Fatalf("Couldn't write file: %v", err)
+		}
+	}
+
+	// Host files embedded in the task dir are not counted
+	host, err := ioutil.TempDir("", "AllocDirHost")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(host)
+	if err := ioutil.WriteFile(filepath.Join(host, "bar"), data, 0666); err != nil {
+		t.Fatalf("Couldn't write file: %v", err)
+	}
+	if err := d.Embed(t1.Name, map[string]string{host: "host"}); err != nil {
+		t.Fatalf("Embed() failed: %v", err)
+	}
+
+	// Files with several hard links are counted once
+	taskFile := filepath.Join(d.TaskDirs[t1.Name], "foo")
+	if err := os.Link(taskFile, filepath.Join(d.TaskDirs[t1.Name], "foo2")); err != nil {
+		t.Skipf("Couldn't hard link file: %v", err)
+	}
+
+	exp := 2 * int64(len(data))
+	checkSize := func() {
+		size, err := d.Size()
+		if err != nil {
+			t.Fatalf("Size() failed: %v", err)
+		}
+		if size < exp || size > exp+64*1024 {
+			t.Fatalf("Size() returned %d; want about %d", size, exp)
+		}
+	}
+	checkSize()
+
+	// Hard links to host files that were not embedded are counted
+	other := filepath.Join(host, "other")
+	if err := ioutil.WriteFile(other, data, 0666); err != nil {
+		t.Fatalf("Couldn't write file: %v", err)
+	}
+	if err := os.Link(other, filepath.Join(d.TaskDirs[t1.Name], "other")); err != nil {
+		t.Fatalf("Couldn't hard link file: %v", err)
+	}
+	exp += int64(len(data))
+	checkSize()
+
+	// Files written or modified in embedded dirs are counted
+	embedded := filepath.Join(d.TaskDirs[t1.Name], "host")
+	if err := ioutil.WriteFile(filepath.Join(embedded, "new"), data, 0666); err != nil {
+		t.Fatalf("Couldn't write file: %v", err)
+	}
+	exp += int64(len(data))
+	checkSize()
+
+	if err := os.Remove(filepath.Join(embedded, "bar")); err != nil {
+		t.Fatalf("Couldn't remove file: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(embedded, "bar"), append(data, data...), 0666); err != nil {
+		t.Fatalf("Couldn't write file: %v", err)
+	}
+	exp += 2 * int64(len(data))
+	checkSize()
+}
@@ -23,3 +23,37 @@ func (d *AllocDir) dropDirPermissions(path string) error {
 func (d *AllocDir) unmountSharedDir(dir string) error {
 	return nil
 }
+
+// Mounts are not tracked on Windows.
+func mountPoints(path string) ([]string, error) {
+	return nil, nil
+}
+
+func unmount(path string) error {
+	return nil
+}
+
+// The windows version does not identify files, so that hard links are
+// counted separately.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func fileIdentity(info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// The windows version counts the size of files.
+func fileDiskUsage(info os.FileInfo) int64 {
+	return info.Size()
+}
+
+// Project quotas are not supported on Windows.
+func (d *AllocDir) setupProjectQuota() uint32 {
+	return 0
+}
+
+func projectQuotaUsage(path string, id uint32) (int64, error) {
+	return 0, errors.New("Project quotas on Windows not supported.")
+}
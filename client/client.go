@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net"
+	"net/rpc"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -15,7 +16,9 @@ import (
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/driver"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
+	"github.com/hashicorp/nomad/client/stats"
 	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -57,8 +60,12 @@ const (
 // DefaultConfig returns the default configuration
 func DefaultConfig() *config.Config {
 	return &config.Config{
-		LogOutput: os.Stderr,
-		Region:    "global",
+		LogOutput:             os.Stderr,
+		Region:                "global",
+		GCInterval:            1 * time.Minute,
+		GCDiskUsageThreshold:  80,
+		GCInodeUsageThreshold: 70,
+		GCMaxAllocs:           50,
 	}
 }
 
@@ -72,6 +79,7 @@ type Client struct {
 	logger *log.Logger
 
 	consulService *ConsulService
+	nomadServices *NomadServices
 
 	lastServer     net.Addr
 	lastRPCTime    time.Time
@@ -82,13 +90,34 @@ type Client struct {
 
 	connPool *nomad.ConnPool
 
+	// rpcServer serves the RPCs the servers forward to the node over its
+	// node connection
+	rpcServer *rpc.Server
+
 	lastHeartbeat time.Time
 	heartbeatTTL  time.Duration
+	heartbeatLock sync.RWMutex
+
+	// dynamicMeta is the node meta set at runtime, a nil value removing
+	// the key from the meta of the client config
+	dynamicMeta map[string]*string
+	metaLock    sync.Mutex
+
+	// configLock guards the node of the config, which is updated by the
+	// fingerprinters and the node meta updates
+	configLock sync.RWMutex
 
 	// allocs is the current set of allocations
 	allocs    map[string]*AllocRunner
 	allocLock sync.RWMutex
 
+	// gc garbage collects the alloc dirs of terminal allocations
+	gc *allocGarbageCollector
+
+	// hostStats is the latest sample of the resource usage of the host
+	hostStats     *stats.HostStats
+	hostStatsLock sync.RWMutex
+
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
@@ -108,6 +137,7 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		allocs:     make(map[string]*AllocRunner),
 		shutdownCh: make(chan struct{}),
 	}
+	c.gc = newAllocGarbageCollector(cfg, logger)
 
 	// Setup the Consul Service
 	if err := c.setupConsulService(); err != nil {
@@ -134,6 +164,9 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		return nil, fmt.Errorf("driver setup failed: %v", err)
 	}
 
+	// Setup the registration of services with the servers
+	c.nomadServices = NewNomadServices(c.logger, c, c.config.Region, c.Node())
+
 	// Set up the known servers list
 	c.SetServers(c.config.Servers)
 
@@ -142,11 +175,28 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to restore state: %v", err)
 	}
 
+	// Setup the RPC endpoints of the node
+	if err := c.setupRPC(); err != nil {
+		return nil, fmt.Errorf("failed to setup RPC endpoints: %v", err)
+	}
+
 	// Start the client!
 	go c.run()
 
+	// Keep a node connection to the servers
+	go c.keepNodeConn()
+
+	// Start garbage collecting terminal allocations
+	go c.periodicGC()
+
+	// Start collecting the resource usage of the host
+	go c.collectHostStats()
+
 	// Start the consul service
 	go c.consulService.SyncWithConsul()
+
+	// Start the service registration with the servers
+	go c.nomadServices.SyncWithServers()
 	return c, nil
 }
 
@@ -237,6 +287,7 @@ func (c *Client) Shutdown() error {
 
 	// Stop the consul service
 	c.consulService.ShutDown()
+	c.nomadServices.ShutDown()
 
 	c.shutdown = true
 	close(c.shutdownCh)
@@ -336,27 +387,40 @@ func (c *Client) Stats() map[string]map[string]string {
 	c.allocLock.RLock()
 	numAllocs := len(c.allocs)
 	c.allocLock.RUnlock()
+	lastHeartbeat, heartbeatTTL := c.heartbeatState()
 
 	stats := map[string]map[string]string{
 		"client": map[string]string{
 			"node_id":         c.Node().ID,
 			"known_servers":   toString(uint64(len(c.Servers()))),
 			"num_allocations": toString(uint64(numAllocs)),
-			"last_heartbeat":  fmt.Sprintf("%v", time.Since(c.lastHeartbeat)),
-			"heartbeat_ttl":   fmt.Sprintf("%v", c.heartbeatTTL),
+			"last_heartbeat":  fmt.Sprintf("%v", time.Since(lastHeartbeat)),
+			"heartbeat_ttl":   fmt.Sprintf("%v", heartbeatTTL),
 		},
 		"runtime": nomad.RuntimeStats(),
 	}
 	return stats
 }
 
-// Node returns the locally registered node
+// Node returns a copy of the locally registered node
 func (c *Client) Node() *structs.Node {
-	return c.config.Node
+	c.configLock.RLock()
+	defer c.configLock.RUnlock()
+	return c.config.Node.Copy()
+}
+
+// HasAllocation returns whether an allocation runs on the client
+func (c *Client) HasAllocation(allocID string) bool {
+	c.allocLock.RLock()
+	defer c.allocLock.RUnlock()
+	_, ok := c.allocs[allocID]
+	return ok
 }
 
 // GetAllocFS returns the AllocFS interface for the alloc dir of an allocation
 func (c *Client) GetAllocFS(allocID string) (allocdir.AllocDirFS, error) {
+	c.allocLock.RLock()
+	defer c.allocLock.RUnlock()
 	ar, ok := c.allocs[allocID]
 	if !ok {
 		return nil, fmt.Errorf("alloc not found")
@@ -364,6 +428,61 @@ func (c *Client) GetAllocFS(allocID string) (allocdir.AllocDirFS, error) {
 	return ar.ctx.AllocDir, nil
 }
 
+// RestartAllocation restarts a task of an allocation, or all of its tasks if
+// no task is given. The restart does not count against the restart policy.
+func (c *Client) RestartAllocation(allocID, taskName string) error {
+	ar, err := c.getAllocRunner(allocID)
+	if err != nil {
+		return err
+	}
+	return ar.RestartTask(taskName, "User requested restart")
+}
+
+// SignalAllocation sends a signal to a task of an allocation, or to all of
+// its tasks if no task is given. It defaults to SIGKILL.
+func (c *Client) SignalAllocation(allocID, taskName, signal string) error {
+	name, s, err := parseSignal(signal)
+	if err != nil {
+		return err
+	}
+	ar, err := c.getAllocRunner(allocID)
+	if err != nil {
+		return err
+	}
+	return ar.SignalTask(taskName, name, s)
+}
+
+// ExecAllocation runs a command inside a task of an allocation and returns
+// its exit code. The task may be omitted if the allocation has a single task.
+func (c *Client) ExecAllocation(allocID, taskName string, opts *cstructs.ExecOptions) (int, error) {
+	ar, err := c.getAllocRunner(allocID)
+	if err != nil {
+		return 0, err
+	}
+	return ar.ExecTask(taskName, opts)
+}
+
+// AllocResourceUsage returns the samples of the resource usage of a task of
+// an allocation, or of all of its tasks if no task is given, by task name
+func (c *Client) AllocResourceUsage(allocID, taskName string) (map[string][]*cstructs.TaskResourceUsage, error) {
+	ar, err := c.getAllocRunner(allocID)
+	if err != nil {
+		return nil, err
+	}
+	return ar.TaskResourceUsage(taskName)
+}
+
+// getAllocRunner returns the runner of an allocation
+func (c *Client) getAllocRunner(allocID string) (*AllocRunner, error) {
+	c.allocLock.RLock()
+	defer c.allocLock.RUnlock()
+	ar, ok := c.allocs[allocID]
+	if !ok {
+		return nil, fmt.Errorf("unknown allocation '%s'", allocID)
+	}
+	return ar, nil
+}
+
 // restoreState is used to restore our state from the data dir
 func (c *Client) restoreState() error {
 	if c.config.DevMode {
@@ -383,7 +502,7 @@ func (c *Client) restoreState() error {
 	for _, entry := range list {
 		id := entry.Name()
 		alloc := &structs.Allocation{ID: id}
-		ar := NewAllocRunner(c.logger, c.config, c.updateAllocStatus, alloc, c.consulService)
+		ar := NewAllocRunner(c.logger, c.config, c.updateAllocStatus, alloc, c.consulService, c.nomadServices, c.readVariable, c.Node)
 		c.allocs[id] = ar
 		if err := ar.RestoreState(); err != nil {
 			c.logger.Printf("[ERR] client: failed to restore state for alloc %s: %v", id, err)
@@ -414,15 +533,30 @@ func (c *Client) saveState() error {
 	return mErr.ErrorOrNil()
 }
 
-// nodeID restores a persistent unique ID or generates a new one
-func (c *Client) nodeID() (string, error) {
+// nodeID restores the persistent unique ID and secret ID of the node or
+// generates new ones
+func (c *Client) nodeID() (id, secret string, err error) {
 	// Do not persist in dev mode
 	if c.config.DevMode {
-		return structs.GenerateUUID(), nil
+		return structs.GenerateUUID(), structs.GenerateUUID(), nil
+	}
+
+	id, err = c.persistentID("client-id")
+	if err != nil {
+		return "", "", err
 	}
+	secret, err = c.persistentID("secret-id")
+	if err != nil {
+		return "", "", err
+	}
+	return id, secret, nil
+}
 
+// persistentID restores a unique ID from a file of the state dir, or
+// generates and persists a new one
+func (c *Client) persistentID(file string) (string, error) {
 	// Attempt to read existing ID
-	path := filepath.Join(c.config.StateDir, "client-id")
+	path := filepath.Join(c.config.StateDir, file)
 	buf, err := ioutil.ReadFile(path)
 	if err != nil && !os.IsNotExist(err) {
 		return "", err
@@ -437,7 +571,7 @@ func (c *Client) nodeID() (string, error) {
 	id := structs.GenerateUUID()
 
 	// Persist the ID
-	if err := ioutil.WriteFile(path, []byte(id), 0700); err != nil {
+	if err := ioutil.WriteFile(path, []byte(id), 0600); err != nil {
 		return "", err
 	}
 	return id, nil
@@ -452,7 +586,7 @@ func (c *Client) setupNode() error {
 	}
 	// Generate an iD for the node
 	var err error
-	node.ID, err = c.nodeID()
+	node.ID, node.SecretID, err = c.nodeID()
 	if err != nil {
 		return fmt.Errorf("node ID setup failed: %v", err)
 	}
@@ -465,6 +599,9 @@ func (c *Client) setupNode() error {
 	if node.Meta == nil {
 		node.Meta = make(map[string]string)
 	}
+	if err := c.restoreNodeMeta(); err != nil {
+		return fmt.Errorf("node meta restore failed: %v", err)
+	}
 	if node.Resources == nil {
 		node.Resources = &structs.Resources{}
 	}
@@ -498,7 +635,9 @@ func (c *Client) fingerprint() error {
 		if err != nil {
 			return err
 		}
+		c.configLock.Lock()
 		applies, err := f.Fingerprint(c.config, c.config.Node)
+		c.configLock.Unlock()
 		if err != nil {
 			return err
 		}
@@ -526,7 +665,10 @@ func (c *Client) fingerprintPeriodic(name string, f fingerprint.Fingerprint, d t
 	for {
 		select {
 		case <-time.After(d):
-			if _, err := f.Fingerprint(c.config, c.config.Node); err != nil {
+			c.configLock.Lock()
+			_, err := f.Fingerprint(c.config, c.config.Node)
+			c.configLock.Unlock()
+			if err != nil {
 				c.logger.Printf("[DEBUG] client: periodic fingerprinting for %v failed: %v", name, err)
 			}
 		case <-c.shutdownCh:
@@ -543,7 +685,7 @@ func (c *Client) setupDrivers() error {
 
 	var avail []string
 	var skipped []string
-	driverCtx := driver.NewDriverContext("", c.config, c.config.Node, c.logger, nil)
+	driverCtx := driver.NewDriverContext("", c.config, c.Node(), c.logger, nil, nil)
 	for name := range driver.BuiltinDrivers {
 		// Skip fingerprinting drivers that are not in the whitelist if it is
 		// enabled.
@@ -556,7 +698,9 @@ func (c *Client) setupDrivers() error {
 		if err != nil {
 			return err
 		}
+		c.configLock.Lock()
 		applies, err := d.Fingerprint(c.config, c.config.Node)
+		c.configLock.Unlock()
 		if err != nil {
 			return err
 		}
@@ -629,7 +773,8 @@ func (c *Client) run() {
 			if err := c.updateNodeStatus(); err != nil {
 				heartbeat = time.After(c.retryIntv(registerRetryIntv))
 			} else {
-				heartbeat = time.After(c.heartbeatTTL)
+				_, ttl := c.heartbeatState()
+				heartbeat = time.After(ttl)
 			}
 
 		case <-c.shutdownCh:
@@ -638,6 +783,28 @@ func (c *Client) run() {
 	}
 }
 
+// setHeartbeat records a successful heartbeat and the TTL before the next one
+func (c *Client) setHeartbeat(ttl time.Duration) {
+	c.heartbeatLock.Lock()
+	defer c.heartbeatLock.Unlock()
+	c.lastHeartbeat = time.Now()
+	c.heartbeatTTL = ttl
+}
+
+// heartbeatState returns the time of the last heartbeat and the TTL before
+// the next one
+func (c *Client) heartbeatState() (time.Time, time.Duration) {
+	c.heartbeatLock.RLock()
+	defer c.heartbeatLock.RUnlock()
+	return c.lastHeartbeat, c.heartbeatTTL
+}
+
+// registered returns whether the node has been registered with the servers
+func (c *Client) registered() bool {
+	last, _ := c.heartbeatState()
+	return !last.IsZero()
+}
+
 // registerNode is used to register the node or update the registration
 func (c *Client) registerNode() error {
 	node := c.Node()
@@ -657,8 +824,7 @@ func (c *Client) registerNode() error {
 	if len(resp.EvalIDs) != 0 {
 		c.logger.Printf("[DEBUG] client: %d evaluations triggered by node registration", len(resp.EvalIDs))
 	}
-	c.lastHeartbeat = time.Now()
-	c.heartbeatTTL = resp.HeartbeatTTL
+	c.setHeartbeat(resp.HeartbeatTTL)
 	return nil
 }
 
@@ -681,12 +847,26 @@ func (c *Client) updateNodeStatus() error {
 	}
 	if resp.Index != 0 {
 		c.logger.Printf("[DEBUG] client: state updated to %s", req.Status)
+
+		// The node may have been marked down while it was disconnected, in
+		// which case the servers no longer know the status of our allocations
+		// and have removed our services.
+		c.resyncAllocs()
+		c.nomadServices.Resync()
 	}
-	c.lastHeartbeat = time.Now()
-	c.heartbeatTTL = resp.HeartbeatTTL
+	c.setHeartbeat(resp.HeartbeatTTL)
 	return nil
 }
 
+// resyncAllocs is used to send the status of all allocations to the servers
+func (c *Client) resyncAllocs() {
+	c.allocLock.RLock()
+	defer c.allocLock.RUnlock()
+	for _, ar := range c.allocs {
+		ar.ResyncStatus()
+	}
+}
+
 // updateAllocStatus is used to update the status of an allocation
 func (c *Client) updateAllocStatus(alloc *structs.Allocation) error {
 	args := structs.AllocUpdateRequest{
@@ -826,8 +1006,28 @@ func (c *Client) updateAlloc(exist, update *structs.Allocation) error {
 func (c *Client) addAlloc(alloc *structs.Allocation) error {
 	c.allocLock.Lock()
 	defer c.allocLock.Unlock()
-	ar := NewAllocRunner(c.logger, c.config, c.updateAllocStatus, alloc, c.consulService)
+	ar := NewAllocRunner(c.logger, c.config, c.updateAllocStatus, alloc, c.consulService, c.nomadServices, c.readVariable, c.Node)
 	c.allocs[alloc.ID] = ar
 	go ar.Run()
 	return nil
 }
+
+// readVariable is used by the task runners to read a variable from the
+// servers
+func (c *Client) readVariable(path string) (*structs.Variable, error) {
+	req := structs.VariableSpecificRequest{
+		Path: path,
+		QueryOptions: structs.QueryOptions{
+			Region:     c.config.Region,
+			AllowStale: true,
+		},
+	}
+	var resp structs.SingleVariableResponse
+	if err := c.RPC("Variables.Read", &req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to read variable %q: %v", path, err)
+	}
+	if resp.Variable == nil {
+		return nil, fmt.Errorf("variable %q not found", path)
+	}
+	return resp.Variable, nil
+}
@@ -57,6 +57,24 @@ type Config struct {
 	// Node provides the base node
 	Node *structs.Node
 
+	// GCInterval is the interval at which the alloc dirs of terminal
+	// allocations are garbage collected when over the limits below
+	GCInterval time.Duration
+
+	// GCDiskUsageThreshold and GCInodeUsageThreshold are the percentages of
+	// the disk space and inodes of the partition of the alloc dir over which
+	// terminal allocations are garbage collected
+	GCDiskUsageThreshold  float64
+	GCInodeUsageThreshold float64
+
+	// GCMaxAllocs is the number of allocations over which terminal
+	// allocations are garbage collected
+	GCMaxAllocs int
+
+	// HostVolumes are the host directories exposed to task groups as named
+	// volumes
+	HostVolumes map[string]*structs.ClientHostVolumeConfig
+
 	// Options provides arbitrary key-value configuration for nomad internals,
 	// like fingerprinters and drivers. The format is:
 	//
@@ -150,6 +150,9 @@ func (c *ConsulService) Register(task *structs.Task, alloc *structs.Allocation)
 	c.trackedTasks[fmt.Sprintf("%s-%s", alloc.ID, task.Name)] = tt
 	c.trackedTskLock.Unlock()
 	for _, service := range task.Services {
+		if service.IsNomadProvided() {
+			continue
+		}
 		c.logger.Printf("[INFO] consul: registering service %s with consul.", service.Name)
 		if err := c.registerService(service, task, alloc); err != nil {
 			mErr.Errors = append(mErr.Errors, err)
@@ -168,7 +171,7 @@ func (c *ConsulService) Deregister(task *structs.Task, alloc *structs.Allocation
 	c.trackedTskLock.Unlock()
 	for _, service := range task.Services {
 		serviceID := alloc.Services[service.Name]
-		if serviceID == "" {
+		if serviceID == "" || service.IsNomadProvided() {
 			continue
 		}
 		c.logger.Printf("[INFO] consul: deregistering service %v with consul", service.Name)
@@ -224,6 +227,9 @@ func (c *ConsulService) performSync() {
 	// Add services and checks which Consul doesn't know about
 	for _, trackedTask := range c.trackedTasks {
 		for _, service := range trackedTask.task.Services {
+			if service.IsNomadProvided() {
+				continue
+			}
 			serviceID := trackedTask.alloc.Services[service.Name]
 
 			// Add new services which Consul agent isn't aware of
@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	docker "github.com/fsouza/go-dockerclient"
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/driver/logging"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -71,6 +74,7 @@ type dockerPID struct {
 	ImageID     string
 	ContainerID string
 	KillTimeout time.Duration
+	LogConfig   *structs.LogConfig `json:",omitempty"`
 }
 
 type DockerHandle struct {
@@ -81,6 +85,8 @@ type DockerHandle struct {
 	imageID          string
 	containerID      string
 	killTimeout      time.Duration
+	logConfig        *structs.LogConfig
+	logsDoneCh       chan struct{}
 	waitCh           chan *cstructs.WaitResult
 	doneCh           chan struct{}
 }
@@ -163,12 +169,23 @@ func (d *DockerDriver) containerBinds(alloc *allocdir.AllocDir, task *structs.Ta
 		return nil, fmt.Errorf("Failed to find task local directory: %v", task.Name)
 	}
 
-	return []string{
+	binds := []string{
 		// "z" and "Z" option is to allocate directory with SELinux label.
 		fmt.Sprintf("%s:/%s:rw,z", shared, allocdir.SharedAllocName),
 		// capital "Z" will label with Multi-Category Security (MCS) labels
 		fmt.Sprintf("%s:/%s:rw,Z", local, allocdir.TaskLocal),
-	}, nil
+	}
+
+	// Host volumes are not relabeled as they may be shared with the host
+	// and other tasks.
+	for _, m := range d.volumeMounts {
+		mode := "rw"
+		if m.ReadOnly {
+			mode = "ro"
+		}
+		binds = append(binds, fmt.Sprintf("%s:%s:%s", m.HostPath, m.TaskPath, mode))
+	}
+	return binds, nil
 }
 
 // createContainer initializes a struct needed to call docker.client.CreateContainer()
@@ -537,9 +554,15 @@ func (d *DockerDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle
 		imageID:          dockerImage.ID,
 		containerID:      container.ID,
 		killTimeout:      d.DriverContext.KillTimeout(task),
+		logConfig:        task.LogRotation(),
+		logsDoneCh:       make(chan struct{}),
 		doneCh:           make(chan struct{}),
 		waitCh:           make(chan *cstructs.WaitResult, 1),
 	}
+	if err := h.collectLogs(ctx.AllocDir, d.taskName, 0); err != nil {
+		h.Kill()
+		return nil, err
+	}
 	go h.run()
 	return h, nil
 }
@@ -591,9 +614,16 @@ func (d *DockerDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, er
 		imageID:          pid.ImageID,
 		containerID:      pid.ContainerID,
 		killTimeout:      pid.KillTimeout,
+		logConfig:        pid.LogConfig,
+		logsDoneCh:       make(chan struct{}),
 		doneCh:           make(chan struct{}),
 		waitCh:           make(chan *cstructs.WaitResult, 1),
 	}
+
+	// The output written while the client was down was not collected
+	if err := h.collectLogs(ctx.AllocDir, d.taskName, time.Now().Unix()); err != nil {
+		return nil, err
+	}
 	go h.run()
 	return h, nil
 }
@@ -604,6 +634,7 @@ func (h *DockerHandle) ID() string {
 		ImageID:     h.imageID,
 		ContainerID: h.containerID,
 		KillTimeout: h.killTimeout,
+		LogConfig:   h.logConfig,
 	}
 	data, err := json.Marshal(pid)
 	if err != nil {
@@ -677,6 +708,170 @@ func (h *DockerHandle) Kill() error {
 	return nil
 }
 
+// Signal is used to send a signal to the container
+func (h *DockerHandle) Signal(s os.Signal) error {
+	sig, ok := s.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("Failed to determine the number of signal %v", s)
+	}
+	return h.client.KillContainer(docker.KillContainerOptions{
+		ID:     h.containerID,
+		Signal: docker.Signal(sig),
+	})
+}
+
+// Exec runs a command inside the container using the exec API of Docker
+func (h *DockerHandle) Exec(opts *cstructs.ExecOptions) (int, error) {
+	if len(opts.Command) == 0 {
+		return 0, fmt.Errorf("missing command to exec")
+	}
+
+	exec, err := h.client.CreateExec(docker.CreateExecOptions{
+		Container:    h.containerID,
+		Cmd:          opts.Command,
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          opts.Tty,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to create exec for container %s: %v", h.containerID, err)
+	}
+
+	// Resize the terminal of the exec as the size of the terminal of the
+	// user changes
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	if opts.Tty {
+		go func() {
+			for {
+				select {
+				case size, ok := <-opts.ResizeCh:
+					if !ok {
+						return
+					}
+					if err := h.client.ResizeExecTTY(exec.ID, int(size.Height), int(size.Width)); err != nil {
+						h.logger.Printf("[DEBUG] driver.docker: failed to resize exec %s: %v", exec.ID, err)
+					}
+				case <-doneCh:
+					return
+				}
+			}
+		}()
+	}
+
+	err = h.client.StartExec(exec.ID, docker.StartExecOptions{
+		Tty:          opts.Tty,
+		RawTerminal:  opts.Tty,
+		InputStream:  opts.Stdin,
+		OutputStream: opts.Stdout,
+		ErrorStream:  opts.Stderr,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to start exec %s: %v", exec.ID, err)
+	}
+
+	inspect, err := h.client.InspectExec(exec.ID)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to inspect exec %s: %v", exec.ID, err)
+	}
+	return inspect.ExitCode, nil
+}
+
+var (
+	// dockerMeasuredMemStats and dockerMeasuredCpuStats are the stats
+	// measured by the stats API of Docker
+	dockerMeasuredMemStats = []string{"RSS", "Cache", "Swap", "Max Usage"}
+	dockerMeasuredCpuStats = []string{"System Mode", "User Mode", "Percent", "Total Ticks",
+		"Throttled Periods", "Throttled Time"}
+)
+
+// Stats reads the resource usage of the container from the stats API of
+// Docker
+func (h *DockerHandle) Stats() (*cstructs.ResourceUsage, error) {
+	statsCh := make(chan *docker.Stats)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- h.client.Stats(docker.StatsOptions{
+			ID:     h.containerID,
+			Stats:  statsCh,
+			Stream: false,
+		})
+	}()
+
+	// The channel is closed once the stats are sent
+	var stats *docker.Stats
+	for s := range statsCh {
+		stats = s
+	}
+	if err := <-errCh; err != nil {
+		return nil, fmt.Errorf("Failed to get stats of container %s: %v", h.containerID, err)
+	}
+	if stats == nil {
+		return nil, fmt.Errorf("No stats returned for container %s", h.containerID)
+	}
+
+	mem := stats.MemoryStats
+	cpu := stats.CPUStats
+	return &cstructs.ResourceUsage{
+		MemoryStats: &cstructs.MemoryStats{
+			RSS:      mem.Stats.Rss,
+			Cache:    mem.Stats.Cache,
+			Swap:     mem.Stats.Swap,
+			MaxUsage: mem.MaxUsage,
+			Measured: dockerMeasuredMemStats,
+		},
+		CpuStats: &cstructs.CpuStats{
+			SystemMode:       float64(cpu.CPUUsage.UsageInKernelmode) / float64(time.Second),
+			UserMode:         float64(cpu.CPUUsage.UsageInUsermode) / float64(time.Second),
+			ThrottledPeriods: cpu.ThrottlingData.ThrottledPeriods,
+			ThrottledTime:    cpu.ThrottlingData.ThrottledTime,
+			Measured:         dockerMeasuredCpuStats,
+		},
+	}, nil
+}
+
+// collectLogs writes the output of the container written since the given
+// unix time to rotated files in the shared logs directory of the allocation,
+// until the container stops.
+func (h *DockerHandle) collectLogs(alloc *allocdir.AllocDir, taskName string, since int64) error {
+	logConfig := h.logConfig
+	if logConfig == nil {
+		logConfig = structs.DefaultLogConfig()
+	}
+	logDir := filepath.Join(alloc.SharedDir, allocdir.LogDirName)
+	fileSize := int64(logConfig.MaxFileSizeMB) * 1024 * 1024
+
+	stdout, err := logging.NewRotatedWriter(logDir, fmt.Sprintf("%s.stdout", taskName), logConfig.MaxFiles, fileSize, h.logger)
+	if err != nil {
+		return fmt.Errorf("Failed to create stdout log rotator: %v", err)
+	}
+	stderr, err := logging.NewRotatedWriter(logDir, fmt.Sprintf("%s.stderr", taskName), logConfig.MaxFiles, fileSize, h.logger)
+	if err != nil {
+		stdout.Close()
+		return fmt.Errorf("Failed to create stderr log rotator: %v", err)
+	}
+
+	go func() {
+		defer close(h.logsDoneCh)
+		err := h.client.Logs(docker.LogsOptions{
+			Container:    h.containerID,
+			OutputStream: stdout,
+			ErrorStream:  stderr,
+			Follow:       true,
+			Stdout:       true,
+			Stderr:       true,
+			Since:        since,
+		})
+		if err != nil {
+			h.logger.Printf("[ERR] driver.docker: failed to collect logs of container %s: %v", h.containerID, err)
+		}
+		stdout.Close()
+		stderr.Close()
+	}()
+	return nil
+}
+
 func (h *DockerHandle) run() {
 	// Wait for it...
 	exitCode, err := h.client.WaitContainer(h.containerID)
@@ -684,6 +879,9 @@ func (h *DockerHandle) run() {
 		h.logger.Printf("[ERR] driver.docker: failed to wait for %s; container already terminated", h.containerID)
 	}
 
+	// Wait for the last output of the container to be written
+	<-h.logsDoneCh
+
 	if exitCode != 0 {
 		err = fmt.Errorf("Docker container exited with non-zero exit code: %d", exitCode)
 	}
@@ -1,8 +1,10 @@
 package driver
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"sync"
 	"time"
@@ -16,6 +18,10 @@ import (
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 )
 
+// ErrExecNotSupported is returned by driver handles which can not run
+// commands inside their tasks
+var ErrExecNotSupported = errors.New("exec is not supported by the driver of the task")
+
 // BuiltinDrivers contains the built in registered drivers
 // which are available for allocation handling
 var BuiltinDrivers = map[string]Factory{
@@ -67,6 +73,10 @@ type DriverContext struct {
 	logger   *log.Logger
 	node     *structs.Node
 	taskEnv  *env.TaskEnvironment
+
+	// volumeMounts are the volume mounts of the task resolved to the host
+	// volumes of the client
+	volumeMounts []*cstructs.VolumeMount
 }
 
 // NewDriverContext initializes a new DriverContext with the specified fields.
@@ -74,13 +84,14 @@ type DriverContext struct {
 // private to the driver. If we want to change this later we can gorename all of
 // the fields in DriverContext.
 func NewDriverContext(taskName string, config *config.Config, node *structs.Node,
-	logger *log.Logger, taskEnv *env.TaskEnvironment) *DriverContext {
+	logger *log.Logger, taskEnv *env.TaskEnvironment, volumeMounts []*cstructs.VolumeMount) *DriverContext {
 	return &DriverContext{
-		taskName: taskName,
-		config:   config,
-		node:     node,
-		logger:   logger,
-		taskEnv:  taskEnv,
+		taskName:     taskName,
+		config:       config,
+		node:         node,
+		logger:       logger,
+		taskEnv:      taskEnv,
+		volumeMounts: volumeMounts,
 	}
 }
 
@@ -110,6 +121,17 @@ type DriverHandle interface {
 
 	// Kill is used to stop the task
 	Kill() error
+
+	// Signal is used to send a signal to the task
+	Signal(s os.Signal) error
+
+	// Exec is used to run a command inside the task and returns its exit
+	// code. Drivers that can not run commands inside their tasks return
+	// ErrExecNotSupported.
+	Exec(opts *cstructs.ExecOptions) (int, error)
+
+	// Stats returns the current resource usage of the task
+	Stats() (*cstructs.ResourceUsage, error)
 }
 
 // ExecContext is shared between drivers within an allocation
@@ -128,6 +150,33 @@ func NewExecContext(alloc *allocdir.AllocDir, allocID string) *ExecContext {
 	return &ExecContext{AllocDir: alloc, AllocID: allocID}
 }
 
+// GetVolumeMounts resolves the volume mounts of the task to the host volumes
+// of the client using the volumes requested by its task group.
+func GetVolumeMounts(config *config.Config, tg *structs.TaskGroup, task *structs.Task) ([]*cstructs.VolumeMount, error) {
+	var mounts []*cstructs.VolumeMount
+	for _, m := range task.VolumeMounts {
+		req, ok := tg.Volumes[m.Volume]
+		if !ok {
+			return nil, fmt.Errorf("task %q mounts unknown volume %q", task.Name, m.Volume)
+		}
+		if req.Type != structs.VolumeTypeHost {
+			return nil, fmt.Errorf("volume %q has unsupported type %q", req.Name, req.Type)
+		}
+
+		host, ok := config.HostVolumes[req.Source]
+		if !ok {
+			return nil, fmt.Errorf("host volume %q of volume %q is not available", req.Source, req.Name)
+		}
+
+		mounts = append(mounts, &cstructs.VolumeMount{
+			HostPath: host.Path,
+			TaskPath: m.Destination,
+			ReadOnly: m.ReadOnly || req.ReadOnly || host.ReadOnly,
+		})
+	}
+	return mounts, nil
+}
+
 // GetTaskEnv converts the alloc dir, the node and task configuration into a
 // TaskEnvironment.
 func GetTaskEnv(alloc *allocdir.AllocDir, node *structs.Node, task *structs.Task) (*env.TaskEnvironment, error) {
@@ -14,6 +14,8 @@ import (
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/helper/testtask"
 	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 )
 
 var basicResources = &structs.Resources{
@@ -60,7 +62,7 @@ func testDriverContexts(task *structs.Task) (*DriverContext, *ExecContext) {
 		return nil, nil
 	}
 
-	driverCtx := NewDriverContext(task.Name, cfg, cfg.Node, testLogger(), taskEnv)
+	driverCtx := NewDriverContext(task.Name, cfg, cfg.Node, testLogger(), taskEnv, nil)
 	return driverCtx, execCtx
 }
 
@@ -132,6 +134,49 @@ func TestDriver_GetTaskEnv(t *testing.T) {
 	}
 }
 
+func TestDriver_GetVolumeMounts(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		HostVolumes: map[string]*structs.ClientHostVolumeConfig{
+			"mysql": &structs.ClientHostVolumeConfig{Name: "mysql", Path: "/srv/mysql"},
+			"certs": &structs.ClientHostVolumeConfig{Name: "certs", Path: "/etc/ssl/certs", ReadOnly: true},
+		},
+	}
+	tg := &structs.TaskGroup{
+		Volumes: map[string]*structs.VolumeRequest{
+			"data":  &structs.VolumeRequest{Name: "data", Type: structs.VolumeTypeHost, Source: "mysql"},
+			"certs": &structs.VolumeRequest{Name: "certs", Type: structs.VolumeTypeHost, Source: "certs"},
+		},
+	}
+	task := &structs.Task{
+		Name: "db",
+		VolumeMounts: []*structs.VolumeMount{
+			&structs.VolumeMount{Volume: "data", Destination: "/var/lib/mysql"},
+			&structs.VolumeMount{Volume: "data", Destination: "/backup", ReadOnly: true},
+			&structs.VolumeMount{Volume: "certs", Destination: "/certs"},
+		},
+	}
+
+	mounts, err := GetVolumeMounts(cfg, tg, task)
+	if err != nil {
+		t.Fatalf("GetVolumeMounts() failed: %v", err)
+	}
+	exp := []*cstructs.VolumeMount{
+		&cstructs.VolumeMount{HostPath: "/srv/mysql", TaskPath: "/var/lib/mysql"},
+		&cstructs.VolumeMount{HostPath: "/srv/mysql", TaskPath: "/backup", ReadOnly: true},
+		&cstructs.VolumeMount{HostPath: "/etc/ssl/certs", TaskPath: "/certs", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(mounts, exp) {
+		t.Fatalf("GetVolumeMounts() returned %#v; want %#v", mounts, exp)
+	}
+
+	// The host volume must be available on the client
+	delete(cfg.HostVolumes, "mysql")
+	if _, err := GetVolumeMounts(cfg, tg, task); err == nil {
+		t.Fatalf("expected error for missing host volume")
+	}
+}
+
 func TestMapMergeStrInt(t *testing.T) {
 	t.Parallel()
 	a := map[string]int{
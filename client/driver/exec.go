@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"runtime"
 	"syscall"
@@ -93,7 +94,8 @@ func (d *ExecDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 	}
 
 	// Setup the command
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, task.LogRotation()).
+		SetVolumeMounts(d.volumeMounts)
 	cmd := executor.Command(execCtx, command, driverConfig.Args...)
 	if err := cmd.Limit(task.Resources); err != nil {
 		return nil, fmt.Errorf("failed to constrain resources: %s", err)
@@ -134,7 +136,7 @@ func (d *ExecDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, erro
 	}
 
 	// Find the process
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, nil)
 	cmd, err := executor.OpenId(execCtx, id.ExecutorId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", id.ExecutorId, err)
@@ -185,6 +187,18 @@ func (h *execHandle) Kill() error {
 	}
 }
 
+func (h *execHandle) Signal(s os.Signal) error {
+	return h.cmd.Signal(s)
+}
+
+func (h *execHandle) Exec(opts *cstructs.ExecOptions) (int, error) {
+	return h.cmd.Exec(opts)
+}
+
+func (h *execHandle) Stats() (*cstructs.ResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *execHandle) run() {
 	res := h.cmd.Wait()
 	close(h.doneCh)
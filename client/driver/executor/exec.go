@@ -22,10 +22,12 @@ package executor
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 
 	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/driver/spawn"
 	"github.com/hashicorp/nomad/nomad/structs"
 
 	"github.com/hashicorp/nomad/client/driver/env"
@@ -71,6 +73,16 @@ type Executor interface {
 	// implementations must provide this.
 	ForceStop() error
 
+	// Signal sends the signal to the user process.
+	Signal(os.Signal) error
+
+	// Exec runs a command alongside the user process, with the same
+	// isolation, and returns its exit code once it exits.
+	Exec(*cstructs.ExecOptions) (int, error)
+
+	// Stats returns the current resource usage of the user process.
+	Stats() (*cstructs.ResourceUsage, error)
+
 	// Command provides access the underlying Cmd struct in case the Executor
 	// interface doesn't expose the functionality you need.
 	Command() *exec.Cmd
@@ -81,12 +93,46 @@ type Executor interface {
 // each time we do it. Used in conjection with Factory, above.
 type ExecutorContext struct {
 	taskEnv *env.TaskEnvironment
+
+	// logConfig configures the rotation of the logs of the process. It is
+	// only used when starting the process and defaults to
+	// structs.DefaultLogConfig.
+	logConfig *structs.LogConfig
+
+	// volumeMounts are the host volumes mounted in the task directory. They
+	// are only supported by executors that isolate the process in a chroot.
+	volumeMounts []*cstructs.VolumeMount
 }
 
 // NewExecutorContext initializes a new DriverContext with the specified fields.
-func NewExecutorContext(taskEnv *env.TaskEnvironment) *ExecutorContext {
+func NewExecutorContext(taskEnv *env.TaskEnvironment, logConfig *structs.LogConfig) *ExecutorContext {
 	return &ExecutorContext{
-		taskEnv: taskEnv,
+		taskEnv:   taskEnv,
+		logConfig: logConfig,
+	}
+}
+
+// SetVolumeMounts sets the host volumes to mount in the task directory.
+func (ctx *ExecutorContext) SetVolumeMounts(mounts []*cstructs.VolumeMount) *ExecutorContext {
+	ctx.volumeMounts = mounts
+	return ctx
+}
+
+// taskLogs returns the redirection of the logs of a task to rotated files in
+// the shared logs directory of the allocation
+func (ctx *ExecutorContext) taskLogs(allocDir, taskName string) *spawn.Logs {
+	logConfig := ctx.logConfig
+	if logConfig == nil {
+		logConfig = structs.DefaultLogConfig()
+	}
+
+	logDir := filepath.Join(allocDir, allocdir.SharedAllocName, allocdir.LogDirName)
+	return &spawn.Logs{
+		Stdout:      filepath.Join(logDir, fmt.Sprintf("%v.stdout", taskName)),
+		Stderr:      filepath.Join(logDir, fmt.Sprintf("%v.stderr", taskName)),
+		Stdin:       os.DevNull,
+		MaxFiles:    logConfig.MaxFiles,
+		MaxFileSize: int64(logConfig.MaxFileSizeMB) * 1024 * 1024,
 	}
 }
 
@@ -44,6 +44,9 @@ func (e *BasicExecutor) ConfigureTaskDir(taskName string, alloc *allocdir.AllocD
 	if !ok {
 		return fmt.Errorf("Couldn't find task directory for task %v", taskName)
 	}
+	if len(e.volumeMounts) != 0 {
+		return fmt.Errorf("volume mounts are not supported by the executor")
+	}
 	e.cmd.Dir = taskDir
 
 	e.taskDir = taskDir
@@ -62,11 +65,7 @@ func (e *BasicExecutor) Start() error {
 	spawnState := filepath.Join(e.allocDir, fmt.Sprintf("%s_%s", e.taskName, "exit_status"))
 	e.spawn = spawn.NewSpawner(spawnState)
 	e.spawn.SetCommand(&e.cmd)
-	e.spawn.SetLogs(&spawn.Logs{
-		Stdout: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stdout", e.taskName)),
-		Stderr: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stderr", e.taskName)),
-		Stdin:  os.DevNull,
-	})
+	e.spawn.SetLogs(e.taskLogs(e.allocDir, e.taskName))
 
 	return e.spawn.Spawn(nil)
 }
@@ -80,6 +79,9 @@ func (e *BasicExecutor) Open(id string) error {
 
 	// Setup the executor.
 	e.spawn = &spawn
+	if spawn.UserCmd != nil {
+		e.taskDir = spawn.UserCmd.Dir
+	}
 	return e.spawn.Valid()
 }
 
@@ -114,6 +116,36 @@ func (e *BasicExecutor) Shutdown() error {
 	return proc.Signal(os.Interrupt)
 }
 
+func (e *BasicExecutor) Signal(s os.Signal) error {
+	proc, err := os.FindProcess(e.spawn.UserPid)
+	if err != nil {
+		return fmt.Errorf("Failed to find user processes %v: %v", e.spawn.UserPid, err)
+	}
+
+	return proc.Signal(s)
+}
+
+// Exec runs the command in the task directory, with the environment of the
+// task.
+func (e *BasicExecutor) Exec(opts *cstructs.ExecOptions) (int, error) {
+	if len(opts.Command) == 0 {
+		return 0, fmt.Errorf("missing command to exec")
+	}
+
+	cmd := exec.Command(opts.Command[0], opts.Command[1:]...)
+	cmd.Dir = e.taskDir
+	cmd.Env = e.taskEnv.Build().EnvList()
+	return runExec(cmd, opts, nil)
+}
+
+// Stats samples the resource usage of the user process and of its children.
+func (e *BasicExecutor) Stats() (*cstructs.ResourceUsage, error) {
+	if e.spawn == nil {
+		return nil, fmt.Errorf("BasicExecutor not started")
+	}
+	return processTreeStats(e.spawn.UserPid)
+}
+
 func (e *BasicExecutor) ForceStop() error {
 	proc, err := os.FindProcess(e.spawn.UserPid)
 	if err != nil {
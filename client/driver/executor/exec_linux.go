@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/opencontainers/runc/libcontainer/cgroups"
@@ -62,6 +63,10 @@ type LinuxExecutor struct {
 	taskDir  string
 	allocDir string
 
+	// mounts are the paths in the task directory the host volumes are bind
+	// mounted at.
+	mounts []string
+
 	// Spawn process.
 	spawn *spawn.Spawner
 }
@@ -84,6 +89,7 @@ type ExecLinuxID struct {
 	Groups  *cgroupConfig.Cgroup
 	Spawn   *spawn.Spawner
 	TaskDir string
+	Mounts  []string
 }
 
 func (e *LinuxExecutor) Open(id string) error {
@@ -98,6 +104,7 @@ func (e *LinuxExecutor) Open(id string) error {
 	e.groups = execID.Groups
 	e.spawn = execID.Spawn
 	e.taskDir = execID.TaskDir
+	e.mounts = execID.Mounts
 	return e.spawn.Valid()
 }
 
@@ -111,6 +118,7 @@ func (e *LinuxExecutor) ID() (string, error) {
 		Groups:  e.groups,
 		Spawn:   e.spawn,
 		TaskDir: e.taskDir,
+		Mounts:  e.mounts,
 	}
 
 	var buffer bytes.Buffer
@@ -124,7 +132,7 @@ func (e *LinuxExecutor) ID() (string, error) {
 
 // runAs takes a user id as a string and looks up the user, and sets the command
 // to execute as that user.
-func (e *LinuxExecutor) runAs(userid string) error {
+func (e *LinuxExecutor) runAs(cmd *exec.Cmd, userid string) error {
 	u, err := user.Lookup(userid)
 	if err != nil {
 		return fmt.Errorf("Failed to identify user %v: %v", userid, err)
@@ -141,14 +149,14 @@ func (e *LinuxExecutor) runAs(userid string) error {
 	}
 
 	// Set the command to run as that user and group.
-	if e.cmd.SysProcAttr == nil {
-		e.cmd.SysProcAttr = &syscall.SysProcAttr{}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
 	}
-	if e.cmd.SysProcAttr.Credential == nil {
-		e.cmd.SysProcAttr.Credential = &syscall.Credential{}
+	if cmd.SysProcAttr.Credential == nil {
+		cmd.SysProcAttr.Credential = &syscall.Credential{}
 	}
-	e.cmd.SysProcAttr.Credential.Uid = uint32(uid)
-	e.cmd.SysProcAttr.Credential.Gid = uint32(gid)
+	cmd.SysProcAttr.Credential.Uid = uint32(uid)
+	cmd.SysProcAttr.Credential.Gid = uint32(gid)
 
 	return nil
 }
@@ -156,7 +164,7 @@ func (e *LinuxExecutor) runAs(userid string) error {
 func (e *LinuxExecutor) Start() error {
 	// Run as "nobody" user so we don't leak root privilege to the spawned
 	// process.
-	if err := e.runAs("nobody"); err != nil {
+	if err := e.runAs(&e.cmd, "nobody"); err != nil {
 		return err
 	}
 
@@ -170,11 +178,7 @@ func (e *LinuxExecutor) Start() error {
 	e.spawn = spawn.NewSpawner(spawnState)
 	e.spawn.SetCommand(&e.cmd)
 	e.spawn.SetChroot(e.taskDir)
-	e.spawn.SetLogs(&spawn.Logs{
-		Stdout: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stdout", e.taskName)),
-		Stderr: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stderr", e.taskName)),
-		Stdin:  os.DevNull,
-	})
+	e.spawn.SetLogs(e.taskLogs(e.allocDir, e.taskName))
 
 	enterCgroup := func(pid int) error {
 		// Join the spawn-daemon to the cgroup.
@@ -223,6 +227,113 @@ func (e *LinuxExecutor) Shutdown() error {
 	return proc.Signal(os.Interrupt)
 }
 
+func (e *LinuxExecutor) Signal(s os.Signal) error {
+	proc, err := os.FindProcess(e.spawn.UserPid)
+	if err != nil {
+		return fmt.Errorf("Failed to find user processes %v: %v", e.spawn.UserPid, err)
+	}
+
+	return proc.Signal(s)
+}
+
+// Stats reads the resource usage of the task from the accounting of its
+// cgroups.
+func (e *LinuxExecutor) Stats() (*cstructs.ResourceUsage, error) {
+	if e.groups == nil {
+		return nil, errors.New("Can't read stats: cgroup configuration empty")
+	}
+
+	stats, err := e.getCgroupManager(e.groups).GetStats()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get stats of the cgroup %v: %v", e.groups.Name, err)
+	}
+
+	mem := stats.MemoryStats
+	cpu := stats.CpuStats
+	return &cstructs.ResourceUsage{
+		MemoryStats: &cstructs.MemoryStats{
+			RSS:      mem.Stats["rss"],
+			Cache:    mem.Cache,
+			Swap:     mem.Stats["swap"],
+			MaxUsage: mem.Usage.MaxUsage,
+			Measured: cgroupMeasuredMemStats,
+		},
+		CpuStats: &cstructs.CpuStats{
+			SystemMode:       float64(cpu.CpuUsage.UsageInKernelmode) / float64(time.Second),
+			UserMode:         float64(cpu.CpuUsage.UsageInUsermode) / float64(time.Second),
+			ThrottledPeriods: cpu.ThrottlingData.ThrottledPeriods,
+			ThrottledTime:    cpu.ThrottlingData.ThrottledTime,
+			Measured:         cgroupMeasuredCpuStats,
+		},
+	}, nil
+}
+
+// Exec runs the command in the chroot and the cgroups of the task, as the
+// same user as the user process.
+func (e *LinuxExecutor) Exec(opts *cstructs.ExecOptions) (int, error) {
+	if len(opts.Command) == 0 {
+		return 0, fmt.Errorf("missing command to exec")
+	}
+
+	env := e.taskEnv.EnvList()
+	path, err := lookPathChroot(e.taskDir, opts.Command[0], env)
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := &exec.Cmd{
+		Path: path,
+		Args: opts.Command,
+		Env:  env,
+		Dir:  "/",
+		SysProcAttr: &syscall.SysProcAttr{
+			Chroot: e.taskDir,
+		},
+	}
+	if err := e.runAs(cmd, "nobody"); err != nil {
+		return 0, err
+	}
+
+	enterCgroup := func(pid int) error {
+		if e.groups == nil {
+			return nil
+		}
+
+		manager := e.getCgroupManager(e.groups)
+		if err := manager.Apply(pid); err != nil {
+			return fmt.Errorf("Failed to join exec command to the cgroup (%+v): %v", e.groups, err)
+		}
+		return nil
+	}
+
+	return runExec(cmd, opts, enterCgroup)
+}
+
+// lookPathChroot searches for an executable in the PATH of the environment,
+// relative to the root of the chroot. It returns the path of the executable
+// inside the chroot.
+func lookPathChroot(root, file string, env []string) (string, error) {
+	if strings.Contains(file, "/") {
+		return file, nil
+	}
+
+	path := "/usr/local/bin:/usr/bin:/bin"
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "PATH=") {
+			path = strings.TrimPrefix(kv, "PATH=")
+		}
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		candidate := filepath.Join("/", dir, file)
+		fi, err := os.Stat(filepath.Join(root, candidate))
+		if err == nil && fi.Mode().IsRegular() && fi.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("executable %q not found in the task directory", file)
+}
+
 // ForceStop immediately exits the user process and cleans up both the task
 // directory and the cgroups.
 func (e *LinuxExecutor) ForceStop() error {
@@ -284,11 +395,108 @@ func (e *LinuxExecutor) ConfigureTaskDir(taskName string, alloc *allocdir.AllocD
 		}
 	}
 
+	if err := e.mountVolumes(); err != nil {
+		// Don't leave host volumes mounted in a task directory that may be
+		// removed.
+		if uerr := e.unmountVolumes(); uerr != nil {
+			err = multierror.Append(err, uerr)
+		}
+		return err
+	}
+
 	// Set the tasks AllocDir environment variable.
 	e.taskEnv.SetAllocDir(filepath.Join("/", allocdir.SharedAllocName)).SetTaskLocalDir(filepath.Join("/", allocdir.TaskLocal)).Build()
 	return nil
 }
 
+// mountVolumes bind mounts the host volumes at their destination in the task
+// directory, remounting them read-only if requested.
+func (e *LinuxExecutor) mountVolumes() error {
+	for _, m := range e.volumeMounts {
+		target, err := volumeTarget(e.taskDir, m.TaskPath)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, 0777); err != nil {
+			return fmt.Errorf("Mkdir(%v) failed: %v", target, err)
+		}
+
+		if err := syscall.Mount(m.HostPath, target, "", syscall.MS_BIND, ""); err != nil {
+			return fmt.Errorf("Couldn't mount %v to %v: %v", m.HostPath, target, err)
+		}
+		e.mounts = append(e.mounts, target)
+
+		if m.ReadOnly {
+			flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+			if err := syscall.Mount("", target, "", flags, ""); err != nil {
+				return fmt.Errorf("Couldn't remount %v read-only: %v", target, err)
+			}
+		}
+	}
+	return nil
+}
+
+// volumeTarget returns the path a volume mounted at taskPath is bind mounted
+// on. Symlinks already in the task directory are resolved so that the mount
+// can never land outside of it.
+func volumeTarget(taskDir, taskPath string) (string, error) {
+	root, err := filepath.EvalSymlinks(taskDir)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't resolve task directory %v: %v", taskDir, err)
+	}
+
+	target := filepath.Join(root, taskPath)
+	if !inTaskDir(root, target) {
+		return "", fmt.Errorf("Volume mount destination %v escapes the task directory", taskPath)
+	}
+
+	// Resolve the deepest part of the target that exists; the remainder is
+	// created by the caller.
+	existing := target
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't resolve %v: %v", existing, err)
+	}
+	rest, err := filepath.Rel(existing, target)
+	if err != nil {
+		return "", err
+	}
+	target = filepath.Join(resolved, rest)
+	if !inTaskDir(root, target) {
+		return "", fmt.Errorf("Volume mount destination %v escapes the task directory", taskPath)
+	}
+	return target, nil
+}
+
+// inTaskDir returns whether path is strictly below the task directory root.
+func inTaskDir(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// unmountVolumes unmounts the host volumes mounted in the task directory. The
+// mount points are left in place as removing them must never reach into a
+// host volume.
+func (e *LinuxExecutor) unmountVolumes() error {
+	errs := new(multierror.Error)
+	for _, target := range e.mounts {
+		if err := syscall.Unmount(target, 0); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("Failed to unmount volume (%v): %v", target, err))
+		}
+	}
+	e.mounts = nil
+	return errs.ErrorOrNil()
+}
+
 // pathExists is a helper function to check if the path exists.
 func (e *LinuxExecutor) pathExists(path string) bool {
 	if _, err := os.Stat(path); err != nil {
@@ -306,8 +514,12 @@ func (e *LinuxExecutor) cleanTaskDir() error {
 	e.l.Lock()
 	defer e.l.Unlock()
 
-	// Unmount dev.
 	errs := new(multierror.Error)
+	if err := e.unmountVolumes(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	// Unmount dev.
 	dev := filepath.Join(e.taskDir, "dev")
 	if e.pathExists(dev) {
 		if err := syscall.Unmount(dev, 0); err != nil {
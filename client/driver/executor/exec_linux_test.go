@@ -1,6 +1,10 @@
 package executor
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	ctestutil "github.com/hashicorp/nomad/client/testutil"
@@ -16,3 +20,59 @@ func TestExecutorLinux(t *testing.T) {
 	t.Parallel()
 	testExecutor(t, NewLinuxExecutor, ctestutil.ExecCompatible)
 }
+
+func TestExecutorLinux_VolumeTarget(t *testing.T) {
+	taskDir, err := ioutil.TempDir("", "VolumeTarget")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(taskDir)
+	root, err := filepath.EvalSymlinks(taskDir)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A symlink placed in the task directory by the task points outside
+	outside, err := ioutil.TempDir("", "VolumeTargetOutside")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outside)
+	if err := os.Symlink(outside, filepath.Join(taskDir, "link")); err != nil {
+		t.Fatalf("Couldn't create symlink: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(taskDir, "local"), 0777); err != nil {
+		t.Fatalf("Couldn't create dir: %v", err)
+	}
+	if err := os.Symlink("local", filepath.Join(taskDir, "inner")); err != nil {
+		t.Fatalf("Couldn't create symlink: %v", err)
+	}
+
+	cases := []struct {
+		path   string
+		target string
+	}{
+		{"/srv/data", filepath.Join(root, "srv/data")},
+		{"/inner/data", filepath.Join(root, "local/data")},
+		{"/../../../../../etc", ""},
+		{"/srv/../..", ""},
+		{"/", ""},
+		{"/link", ""},
+		{"/link/data", ""},
+	}
+	for _, c := range cases {
+		target, err := volumeTarget(taskDir, c.path)
+		if c.target == "" {
+			if err == nil || !strings.Contains(err.Error(), "escapes") {
+				t.Fatalf("volumeTarget(%q) = %q, %v; want escape error", c.path, target, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("volumeTarget(%q) failed: %v", c.path, err)
+		}
+		if target != c.target {
+			t.Fatalf("volumeTarget(%q) = %q; want %q", c.path, target, c.target)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"syscall"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// runExec starts the command of an exec session with the streams of the
+// options, and a pseudo terminal if requested. The call-back is invoked with
+// the pid of the command once it is started and the command is killed if it
+// returns an error. The command and the processes it started are killed if
+// the session is cancelled. It returns the exit code of the command.
+func runExec(cmd *exec.Cmd, opts *cstructs.ExecOptions, cb func(pid int) error) (int, error) {
+	if len(opts.Command) == 0 {
+		return 0, fmt.Errorf("missing command to exec")
+	}
+
+	if opts.Tty {
+		return runExecTty(cmd, opts, cb)
+	}
+
+	// Copy stdin ourselves as the command would otherwise not be waited on
+	// until stdin is closed
+	var stdin io.WriteCloser
+	if opts.Stdin != nil {
+		var err error
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			return 0, err
+		}
+	}
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	setExecProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start command: %v", err)
+	}
+	if stdin != nil {
+		go func() {
+			io.Copy(stdin, opts.Stdin)
+			stdin.Close()
+		}()
+	}
+
+	return waitExec(cmd, opts.CancelCh, cb)
+}
+
+// waitExec invokes the call-back with the pid of the started command and waits
+// for it to exit, killing it if cancelCh is closed first. A command killed by
+// a signal exits with 128 plus the number of the signal, as in shells.
+func waitExec(cmd *exec.Cmd, cancelCh <-chan struct{}, cb func(pid int) error) (int, error) {
+	if cb != nil {
+		if err := cb(cmd.Process.Pid); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return 0, err
+		}
+	}
+
+	exitCh := make(chan struct{})
+	defer close(exitCh)
+	go func() {
+		select {
+		case <-cancelCh:
+			killExec(cmd)
+		case <-exitCh:
+		}
+	}()
+
+	err := cmd.Wait()
+	if err == nil {
+		return 0, nil
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			if status.Signaled() {
+				return 128 + int(status.Signal()), nil
+			}
+			return status.ExitStatus(), nil
+		}
+	}
+	return 0, err
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+func TestRunExec_Cancel(t *testing.T) {
+	for _, tty := range []bool{false, true} {
+		// The command starts a process that would outlive it
+		cmd := exec.Command("/bin/sh", "-c", "sleep 60 & echo $!; wait")
+		var stdout bytes.Buffer
+		cancelCh := make(chan struct{})
+		opts := &cstructs.ExecOptions{
+			Command:  cmd.Args,
+			Tty:      tty,
+			Stdout:   &stdout,
+			Stderr:   &stdout,
+			CancelCh: cancelCh,
+		}
+
+		type result struct {
+			code int
+			err  error
+		}
+		resultCh := make(chan result, 1)
+		go func() {
+			code, err := runExec(cmd, opts, nil)
+			resultCh <- result{code, err}
+		}()
+
+		// The user goes away in the middle of the session
+		time.Sleep(500 * time.Millisecond)
+		close(cancelCh)
+
+		select {
+		case r := <-resultCh:
+			if r.err != nil {
+				t.Fatalf("tty %v: err: %v", tty, r.err)
+			}
+			if r.code != 128+int(syscall.SIGKILL) {
+				t.Fatalf("tty %v: exit code %d; want %d", tty, r.code, 128+int(syscall.SIGKILL))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("tty %v: command not killed", tty)
+		}
+
+		// The process started by the command is killed too
+		var pid int
+		if _, err := fmt.Sscan(stdout.String(), &pid); err != nil {
+			t.Fatalf("tty %v: bad output %q: %v", tty, stdout.String(), err)
+		}
+		if processRunning(pid) {
+			t.Fatalf("tty %v: process %d still running", tty, pid)
+		}
+	}
+}
+
+// processRunning returns whether the process exists and is not a zombie
+func processRunning(pid int) bool {
+	status, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return false
+	}
+	return !strings.Contains(string(status), "State:\tZ")
+}
@@ -0,0 +1,84 @@
+// +build !windows
+
+package executor
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"syscall"
+
+	"github.com/kr/pty"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// runExecTty starts the command of an exec session attached to a pseudo
+// terminal. The terminal is resized as the size of the terminal of the user
+// changes, and closed if the session is cancelled.
+func runExecTty(cmd *exec.Cmd, opts *cstructs.ExecOptions, cb func(pid int) error) (int, error) {
+	tty, err := pty.Start(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start command: %v", err)
+	}
+	defer tty.Close()
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		resizeCh := opts.ResizeCh
+		for {
+			select {
+			case size, ok := <-resizeCh:
+				if !ok {
+					resizeCh = nil
+					continue
+				}
+				pty.Setsize(tty, &pty.Winsize{Rows: size.Height, Cols: size.Width})
+			case <-opts.CancelCh:
+				// Stop forwarding the output as processes that left the
+				// session may keep the terminal open
+				tty.Close()
+				return
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+
+	if opts.Stdin != nil {
+		go io.Copy(tty, opts.Stdin)
+	}
+
+	// Reading the terminal fails once the command and all of its children
+	// have exited, which ensures all the output is forwarded
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	outputCh := make(chan struct{})
+	go func() {
+		io.Copy(stdout, tty)
+		close(outputCh)
+	}()
+
+	code, err := waitExec(cmd, opts.CancelCh, cb)
+	<-outputCh
+	return code, err
+}
+
+// setExecProcessGroup starts the command in a process group of its own, so
+// that the processes it starts can be killed with it. Commands attached to a
+// pseudo terminal already lead a session of their own.
+func setExecProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killExec kills the process group of the command of an exec session.
+func killExec(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
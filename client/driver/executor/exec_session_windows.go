@@ -0,0 +1,21 @@
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// runExecTty is not supported as there are no pseudo terminals on Windows.
+func runExecTty(cmd *exec.Cmd, opts *cstructs.ExecOptions, cb func(pid int) error) (int, error) {
+	return 0, fmt.Errorf("tty is not supported on windows")
+}
+
+// setExecProcessGroup does nothing as process groups are not used on Windows.
+func setExecProcessGroup(cmd *exec.Cmd) {}
+
+// killExec kills the command of an exec session.
+func killExec(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"github.com/shirou/gopsutil/process"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+var (
+	// processMeasuredMemStats and processMeasuredCpuStats are the stats
+	// measured by sampling a process tree
+	processMeasuredMemStats = []string{"RSS", "Swap"}
+	processMeasuredCpuStats = []string{"System Mode", "User Mode", "Percent", "Total Ticks"}
+
+	// cgroupMeasuredMemStats and cgroupMeasuredCpuStats are the stats
+	// measured by the cgroup of a process
+	cgroupMeasuredMemStats = []string{"RSS", "Cache", "Swap", "Max Usage"}
+	cgroupMeasuredCpuStats = []string{"System Mode", "User Mode", "Percent", "Total Ticks",
+		"Throttled Periods", "Throttled Time"}
+)
+
+// processTreeStats returns the resource usage of a process and of all its
+// descendants. Processes exiting while they are sampled are ignored.
+func processTreeStats(pid int) (*cstructs.ResourceUsage, error) {
+	root, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil, err
+	}
+
+	mem := &cstructs.MemoryStats{Measured: processMeasuredMemStats}
+	cpu := &cstructs.CpuStats{Measured: processMeasuredCpuStats}
+	procs := []*process.Process{root}
+	for len(procs) != 0 {
+		p := procs[0]
+		procs = procs[1:]
+
+		info, err := p.MemoryInfo()
+		if err != nil {
+			if p == root {
+				return nil, err
+			}
+			continue
+		}
+		mem.RSS += info.RSS
+		mem.Swap += info.Swap
+
+		if times, err := p.CPUTimes(); err == nil {
+			cpu.SystemMode += times.System
+			cpu.UserMode += times.User
+		}
+		if children, err := p.Children(); err == nil {
+			procs = append(procs, children...)
+		}
+	}
+
+	return &cstructs.ResourceUsage{MemoryStats: mem, CpuStats: cpu}, nil
+}
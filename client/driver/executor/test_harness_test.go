@@ -1,15 +1,18 @@
 package executor
 
 import (
+	"bytes"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/driver/env"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/helper/testtask"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -71,6 +74,7 @@ func testExecutor(t *testing.T, buildExecutor func(*ExecutorContext) Executor, c
 	Executor_Start_Kill(t, command)
 	Executor_Open(t, command, buildExecutor)
 	Executor_Open_Invalid(t, command, buildExecutor)
+	Executor_Exec(t, command)
 }
 
 type buildExecCommand func(name string, args ...string) Executor
@@ -284,3 +288,47 @@ func Executor_Open_Invalid(t *testing.T, command buildExecCommand, newExecutor f
 		log.Panicf("Open(%v) should have failed", id)
 	}
 }
+
+func Executor_Exec(t *testing.T, command buildExecCommand) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	task, alloc := mockAllocDir(t)
+	defer alloc.Destroy()
+
+	e := command(testtask.Path(), "sleep", "10s")
+
+	if err := e.Limit(constraint); err != nil {
+		log.Panicf("Limit() failed: %v", err)
+	}
+
+	if err := e.ConfigureTaskDir(task, alloc); err != nil {
+		log.Panicf("ConfigureTaskDir(%v, %v) failed: %v", task, alloc, err)
+	}
+
+	if err := e.Start(); err != nil {
+		log.Panicf("Start() failed: %v", err)
+	}
+	defer e.ForceStop()
+
+	var stdout, stderr bytes.Buffer
+	opts := &cstructs.ExecOptions{
+		Command: []string{"/bin/sh", "-c", "echo hello; echo world >&2; exit 3"},
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	}
+	code, err := e.Exec(opts)
+	if err != nil {
+		log.Panicf("Exec() failed: %v", err)
+	}
+	if code != 3 {
+		log.Panicf("Exec() returned exit code %d; want 3", code)
+	}
+	if out := stdout.String(); out != "hello\n" {
+		log.Panicf("Exec() stdout incorrect: want %q; got %q", "hello\n", out)
+	}
+	if out := stderr.String(); out != "world\n" {
+		log.Panicf("Exec() stderr incorrect: want %q; got %q", "world\n", out)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
@@ -140,7 +141,8 @@ func (d *JavaDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 
 	// Setup the command
 	// Assumes Java is in the $PATH, but could probably be detected
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, task.LogRotation()).
+		SetVolumeMounts(d.volumeMounts)
 	cmd := executor.Command(execCtx, "java", args...)
 
 	// Populate environment variables
@@ -183,7 +185,7 @@ func (d *JavaDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, erro
 	}
 
 	// Find the process
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, nil)
 	cmd, err := executor.OpenId(execCtx, id.ExecutorId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", id.ExecutorId, err)
@@ -235,6 +237,18 @@ func (h *javaHandle) Kill() error {
 	}
 }
 
+func (h *javaHandle) Signal(s os.Signal) error {
+	return h.cmd.Signal(s)
+}
+
+func (h *javaHandle) Exec(opts *cstructs.ExecOptions) (int, error) {
+	return h.cmd.Exec(opts)
+}
+
+func (h *javaHandle) Stats() (*cstructs.ResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *javaHandle) run() {
 	res := h.cmd.Wait()
 	close(h.doneCh)
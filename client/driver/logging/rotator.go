@@ -1,4 +1,6 @@
-package driver
+// Package logging is used to write the logs of tasks to a bounded set of
+// rotated files.
+package logging
 
 import (
 	"fmt"
@@ -90,6 +92,9 @@ func (l *LogRotator) Start(r io.Reader) error {
 			continue
 		}
 
+		// Removing the files rotated out now that a new file is in use
+		l.PurgeOldFiles()
+
 		// Reading from the reader and writing into the current log file as long
 		// as it has capacity or the reader closes
 		for {
@@ -121,7 +126,6 @@ func (l *LogRotator) Start(r io.Reader) error {
 		}
 		l.logFileIdx = l.logFileIdx + 1
 	}
-	return nil
 }
 
 // PurgeOldFiles removes older files and keeps only the last N files rotated for
@@ -133,9 +137,10 @@ func (l *LogRotator) PurgeOldFiles() {
 		return
 	}
 	// Inserting all the rotated files in a slice
+	prefix := fmt.Sprintf("%s.", l.fileName)
 	for _, f := range files {
-		if strings.HasPrefix(f.Name(), l.fileName) {
-			fileIdx := strings.TrimPrefix(f.Name(), fmt.Sprintf("%s.", l.fileName))
+		if strings.HasPrefix(f.Name(), prefix) {
+			fileIdx := strings.TrimPrefix(f.Name(), prefix)
 			n, err := strconv.Atoi(fileIdx)
 			if err != nil {
 				continue
@@ -147,9 +152,61 @@ func (l *LogRotator) PurgeOldFiles() {
 	// Sorting the file indexes so that we can purge the older files and keep
 	// only the number of files as configured by the user
 	sort.Sort(sort.IntSlice(fIndexes))
-	toDelete := fIndexes[l.maxFiles-1 : len(fIndexes)-1]
+	if len(fIndexes) <= l.maxFiles {
+		return
+	}
+	toDelete := fIndexes[:len(fIndexes)-l.maxFiles]
 	for _, fIndex := range toDelete {
 		fname := filepath.Join(l.path, fmt.Sprintf("%s.%d", l.fileName, fIndex))
 		os.RemoveAll(fname)
 	}
 }
+
+// RotatedWriter is a writer whose data is written to rotated files by a
+// LogRotator. It can be used as the output of a process.
+type RotatedWriter struct {
+	w      *io.PipeWriter
+	doneCh chan struct{}
+	err    error
+}
+
+// NewRotatedWriter returns a writer rotating the files named after fileName
+// in the path. At most maxFiles files of fileSize bytes are kept.
+func NewRotatedWriter(path string, fileName string, maxFiles int, fileSize int64, logger *log.Logger) (*RotatedWriter, error) {
+	rotator, err := NewLogRotator(path, fileName, maxFiles, fileSize, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+	rw := &RotatedWriter{
+		w:      w,
+		doneCh: make(chan struct{}),
+	}
+	go func() {
+		defer close(rw.doneCh)
+		err := rotator.Start(r)
+		if err == io.EOF {
+			err = nil
+		} else if err != nil {
+			logger.Printf("[ERR] client.logrotator: failed to write %s: %v", fileName, err)
+		}
+
+		// Fail the writes rather than blocking them if the rotator stopped
+		rw.err = err
+		r.CloseWithError(err)
+	}()
+	return rw, nil
+}
+
+func (w *RotatedWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+// Close closes the writer and waits until the data written is flushed to the
+// files.
+func (w *RotatedWriter) Close() error {
+	w.w.Close()
+	<-w.doneCh
+	return w.err
+}
@@ -1,4 +1,4 @@
-package driver
+package logging
 
 import (
 	"io"
@@ -246,3 +246,68 @@ func TestLogRotator_PurgeDirs(t *testing.T) {
 		t.Fatalf("expected number of files: %v, actual: %v", 2, len(files))
 	}
 }
+
+func TestLogRotator_PurgeKeepsNewestFiles(t *testing.T) {
+	path, err := ioutil.TempDir("", pathPrefix)
+	if err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer os.RemoveAll(path)
+
+	l, err := NewLogRotator(path, "redis.stdout", 2, 4, logger)
+	if err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+
+	// Files are purged as they are rotated
+	r, w := io.Pipe()
+	go func() {
+		w.Write([]byte("abcdefghijklmno"))
+		w.Close()
+	}()
+	if err := l.Start(r); err != nil && err != io.EOF {
+		t.Fatalf("failure in logrotator start: %v", err)
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if len(names) != 2 || names[0] != "redis.stdout.2" || names[1] != "redis.stdout.3" {
+		t.Fatalf("bad: %v", names)
+	}
+}
+
+func TestRotatedWriter(t *testing.T) {
+	path, err := ioutil.TempDir("", pathPrefix)
+	if err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer os.RemoveAll(path)
+
+	w, err := NewRotatedWriter(path, "redis.stdout", 10, 4, logger)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := w.Write([]byte("abcdef")); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Closing waits for the data to be written
+	if err := w.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for name, expected := range map[string]string{"redis.stdout.0": "abcd", "redis.stdout.1": "ef"} {
+		data, err := ioutil.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if string(data) != expected {
+			t.Fatalf("%s: got %q; want %q", name, data, expected)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -85,6 +86,9 @@ func (d *QemuDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
 		return nil, err
 	}
+	if len(d.volumeMounts) != 0 {
+		return nil, fmt.Errorf("volume mounts are not supported by the qemu driver")
+	}
 
 	if len(driverConfig.PortMap) > 1 {
 		return nil, fmt.Errorf("Only one port_map block is allowed in the qemu driver config")
@@ -184,7 +188,7 @@ func (d *QemuDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 	}
 
 	// Setup the command
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, task.LogRotation())
 	cmd := executor.Command(execCtx, args[0], args[1:]...)
 	if err := cmd.Limit(task.Resources); err != nil {
 		return nil, fmt.Errorf("failed to constrain resources: %s", err)
@@ -225,7 +229,7 @@ func (d *QemuDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, erro
 	}
 
 	// Find the process
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, nil)
 	cmd, err := executor.OpenId(execCtx, id.ExecutorId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", id.ExecutorId, err)
@@ -278,6 +282,18 @@ func (h *qemuHandle) Kill() error {
 	}
 }
 
+func (h *qemuHandle) Signal(s os.Signal) error {
+	return h.cmd.Signal(s)
+}
+
+func (h *qemuHandle) Exec(opts *cstructs.ExecOptions) (int, error) {
+	return 0, ErrExecNotSupported
+}
+
+func (h *qemuHandle) Stats() (*cstructs.ResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *qemuHandle) run() {
 	res := h.cmd.Wait()
 	close(h.doneCh)
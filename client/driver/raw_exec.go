@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -62,6 +63,9 @@ func (d *RawExecDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandl
 	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
 		return nil, err
 	}
+	if len(d.volumeMounts) != 0 {
+		return nil, fmt.Errorf("volume mounts are not supported by the raw_exec driver")
+	}
 	// Get the tasks local directory.
 	taskName := d.DriverContext.taskName
 	taskDir, ok := ctx.AllocDir.TaskDirs[taskName]
@@ -91,7 +95,7 @@ func (d *RawExecDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandl
 	}
 
 	// Setup the command
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, task.LogRotation())
 	cmd := executor.NewBasicExecutor(execCtx)
 	executor.SetCommand(cmd, command, driverConfig.Args)
 	if err := cmd.Limit(task.Resources); err != nil {
@@ -133,7 +137,7 @@ func (d *RawExecDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, e
 	}
 
 	// Find the process
-	execCtx := executor.NewExecutorContext(d.taskEnv)
+	execCtx := executor.NewExecutorContext(d.taskEnv, nil)
 	cmd := executor.NewBasicExecutor(execCtx)
 	if err := cmd.Open(id.ExecutorId); err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", id.ExecutorId, err)
@@ -184,6 +188,18 @@ func (h *rawExecHandle) Kill() error {
 	}
 }
 
+func (h *rawExecHandle) Signal(s os.Signal) error {
+	return h.cmd.Signal(s)
+}
+
+func (h *rawExecHandle) Exec(opts *cstructs.ExecOptions) (int, error) {
+	return h.cmd.Exec(opts)
+}
+
+func (h *rawExecHandle) Stats() (*cstructs.ResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *rawExecHandle) run() {
 	res := h.cmd.Wait()
 	close(h.doneCh)
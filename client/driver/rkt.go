@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/driver/executor"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -51,9 +52,9 @@ type RktDriverConfig struct {
 	Args      []string `mapstructure:"args"`
 }
 
-// rktHandle is returned from Start/Open as a handle to the PID
+// rktHandle is returned from Start/Open as a handle to the rkt process
 type rktHandle struct {
-	proc        *os.Process
+	cmd         executor.Executor
 	image       string
 	logger      *log.Logger
 	killTimeout time.Duration
@@ -61,10 +62,10 @@ type rktHandle struct {
 	doneCh      chan struct{}
 }
 
-// rktPID is a struct to map the pid running the process to the vm image on
-// disk
-type rktPID struct {
-	Pid         int
+// rktID is a struct to map the executor running the rkt process to the image
+// on disk
+type rktID struct {
+	ExecutorId  string
 	Image       string
 	KillTimeout time.Duration
 }
@@ -119,14 +120,6 @@ func (d *RktDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, e
 		return nil, fmt.Errorf("Missing ACI image for rkt")
 	}
 
-	// Get the tasks local directory.
-	taskName := d.DriverContext.taskName
-	taskDir, ok := ctx.AllocDir.TaskDirs[taskName]
-	if !ok {
-		return nil, fmt.Errorf("Could not find task directory for task: %v", d.DriverContext.taskName)
-	}
-	taskLocal := filepath.Join(taskDir, allocdir.TaskLocal)
-
 	// Build the command.
 	var cmdArgs []string
 
@@ -155,7 +148,17 @@ func (d *RktDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, e
 	}
 
 	// Append the run command.
-	cmdArgs = append(cmdArgs, "run", "--mds-register=false", img)
+	cmdArgs = append(cmdArgs, "run", "--mds-register=false")
+
+	// Add the host volumes to the pod and mount them in the app.
+	var mountArgs []string
+	for i, m := range d.volumeMounts {
+		name := fmt.Sprintf("volume-%d", i)
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--volume=%s,kind=host,source=%s,readOnly=%t", name, m.HostPath, m.ReadOnly))
+		mountArgs = append(mountArgs, fmt.Sprintf("--mount=volume=%s,target=%s", name, m.TaskPath))
+	}
+	cmdArgs = append(cmdArgs, img)
+	cmdArgs = append(cmdArgs, mountArgs...)
 
 	// Mount allc and task dirs
 	local, ok := ctx.AllocDir.TaskDirs[task.Name]
@@ -196,31 +199,24 @@ func (d *RktDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, e
 		}
 	}
 
-	// Create files to capture stdin and out.
-	stdoutFilename := filepath.Join(taskLocal, fmt.Sprintf("%s.stdout", taskName))
-	stderrFilename := filepath.Join(taskLocal, fmt.Sprintf("%s.stderr", taskName))
-
-	stdo, err := os.OpenFile(stdoutFilename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
-	if err != nil {
-		return nil, fmt.Errorf("Error opening file to redirect stdout: %v", err)
+	// Run rkt through an executor so that its output is written to rotated
+	// log files even if the client restarts.
+	execCtx := executor.NewExecutorContext(d.taskEnv, task.LogRotation())
+	cmd := executor.NewBasicExecutor(execCtx)
+	executor.SetCommand(cmd, "rkt", cmdArgs)
+	if err := cmd.Limit(task.Resources); err != nil {
+		return nil, fmt.Errorf("failed to constrain resources: %s", err)
 	}
-
-	stde, err := os.OpenFile(stderrFilename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
-	if err != nil {
-		return nil, fmt.Errorf("Error opening file to redirect stderr: %v", err)
+	if err := cmd.ConfigureTaskDir(d.taskName, ctx.AllocDir); err != nil {
+		return nil, fmt.Errorf("failed to configure task directory: %v", err)
 	}
-
-	cmd := exec.Command("rkt", cmdArgs...)
-	cmd.Stdout = stdo
-	cmd.Stderr = stde
-
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("Error running rkt: %v", err)
 	}
 
-	d.logger.Printf("[DEBUG] driver.rkt: started ACI %q with: %v", img, cmd.Args)
+	d.logger.Printf("[DEBUG] driver.rkt: started ACI %q with: %v", img, cmd.Command().Args)
 	h := &rktHandle{
-		proc:        cmd.Process,
+		cmd:         cmd,
 		image:       img,
 		logger:      d.logger,
 		killTimeout: d.DriverContext.KillTimeout(task),
@@ -233,24 +229,25 @@ func (d *RktDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, e
 
 func (d *RktDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
 	// Parse the handle
-	pidBytes := []byte(strings.TrimPrefix(handleID, "Rkt:"))
-	qpid := &rktPID{}
-	if err := json.Unmarshal(pidBytes, qpid); err != nil {
+	idBytes := []byte(strings.TrimPrefix(handleID, "Rkt:"))
+	id := &rktID{}
+	if err := json.Unmarshal(idBytes, id); err != nil {
 		return nil, fmt.Errorf("failed to parse Rkt handle '%s': %v", handleID, err)
 	}
 
 	// Find the process
-	proc, err := os.FindProcess(qpid.Pid)
-	if proc == nil || err != nil {
-		return nil, fmt.Errorf("failed to find Rkt PID %d: %v", qpid.Pid, err)
+	execCtx := executor.NewExecutorContext(d.taskEnv, nil)
+	cmd := executor.NewBasicExecutor(execCtx)
+	if err := cmd.Open(id.ExecutorId); err != nil {
+		return nil, fmt.Errorf("failed to open ID %v: %v", id.ExecutorId, err)
 	}
 
 	// Return a driver handle
 	h := &rktHandle{
-		proc:        proc,
-		image:       qpid.Image,
+		cmd:         cmd,
+		image:       id.Image,
 		logger:      d.logger,
-		killTimeout: qpid.KillTimeout,
+		killTimeout: id.KillTimeout,
 		doneCh:      make(chan struct{}),
 		waitCh:      make(chan *cstructs.WaitResult, 1),
 	}
@@ -260,15 +257,15 @@ func (d *RktDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error
 }
 
 func (h *rktHandle) ID() string {
-	// Return a handle to the PID
-	pid := &rktPID{
-		Pid:         h.proc.Pid,
+	executorId, _ := h.cmd.ID()
+	id := &rktID{
+		ExecutorId:  executorId,
 		Image:       h.image,
 		KillTimeout: h.killTimeout,
 	}
-	data, err := json.Marshal(pid)
+	data, err := json.Marshal(id)
 	if err != nil {
-		h.logger.Printf("[ERR] driver.rkt: failed to marshal rkt PID to JSON: %s", err)
+		h.logger.Printf("[ERR] driver.rkt: failed to marshal rkt ID to JSON: %s", err)
 	}
 	return fmt.Sprintf("Rkt:%s", string(data))
 }
@@ -285,23 +282,30 @@ func (h *rktHandle) Update(task *structs.Task) error {
 // Kill is used to terminate the task. We send an Interrupt
 // and then provide a 5 second grace period before doing a Kill.
 func (h *rktHandle) Kill() error {
-	h.proc.Signal(os.Interrupt)
+	h.cmd.Shutdown()
 	select {
 	case <-h.doneCh:
 		return nil
 	case <-time.After(h.killTimeout):
-		return h.proc.Kill()
+		return h.cmd.ForceStop()
 	}
 }
 
+func (h *rktHandle) Signal(s os.Signal) error {
+	return h.cmd.Signal(s)
+}
+
+func (h *rktHandle) Exec(opts *cstructs.ExecOptions) (int, error) {
+	return 0, ErrExecNotSupported
+}
+
+func (h *rktHandle) Stats() (*cstructs.ResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *rktHandle) run() {
-	ps, err := h.proc.Wait()
+	res := h.cmd.Wait()
 	close(h.doneCh)
-	code := 0
-	if !ps.Success() {
-		// TODO: Better exit code parsing.
-		code = 1
-	}
-	h.waitCh <- cstructs.NewWaitResult(code, 0, err)
+	h.waitCh <- res
 	close(h.waitCh)
 }
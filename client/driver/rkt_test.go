@@ -1,15 +1,18 @@
 package driver
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/driver/executor"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 
@@ -33,8 +36,17 @@ func TestRktVersionRegex(t *testing.T) {
 }
 
 func TestRktDriver_Handle(t *testing.T) {
+	cmd := executor.NewBasicExecutor(executor.NewExecutorContext(nil, nil))
+	if err := cmd.Open(fmt.Sprintf(`{"SpawnPid":%d}`, os.Getpid())); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	executorId, err := cmd.ID()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
 	h := &rktHandle{
-		proc:        &os.Process{Pid: 123},
+		cmd:         cmd,
 		image:       "foo",
 		killTimeout: 5 * time.Nanosecond,
 		doneCh:      make(chan struct{}),
@@ -42,9 +54,16 @@ func TestRktDriver_Handle(t *testing.T) {
 	}
 
 	actual := h.ID()
-	expected := `Rkt:{"Pid":123,"Image":"foo","KillTimeout":5}`
-	if actual != expected {
-		t.Errorf("Expected `%s`, found `%s`", expected, actual)
+	if !strings.HasPrefix(actual, "Rkt:") {
+		t.Fatalf("bad: %s", actual)
+	}
+	var id rktID
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(actual, "Rkt:")), &id); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := rktID{ExecutorId: executorId, Image: "foo", KillTimeout: 5}
+	if id != expected {
+		t.Errorf("Expected %#v, found %#v", expected, id)
 	}
 }
 
@@ -244,11 +263,7 @@ func TestRktDriver_Start_Wait_Logs(t *testing.T) {
 		t.Fatalf("timeout")
 	}
 
-	taskDir, ok := execCtx.AllocDir.TaskDirs[task.Name]
-	if !ok {
-		t.Fatalf("Could not find task directory for task: %v", task)
-	}
-	stdout := filepath.Join(taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stdout", task.Name))
+	stdout := filepath.Join(execCtx.AllocDir.SharedDir, allocdir.LogDirName, fmt.Sprintf("%v.stdout.0", task.Name))
 	data, err := ioutil.ReadFile(stdout)
 	if err != nil {
 		t.Fatalf("Failed to read tasks stdout: %v", err)
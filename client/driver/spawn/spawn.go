@@ -32,9 +32,14 @@ type Spawner struct {
 }
 
 // Logs is used to define the filepaths the user command's logs should be
-// redirected to. The files do not need to exist.
+// redirected to. The files do not need to exist. If MaxFiles is set, stdout
+// and stderr are written to rotated files named after the paths, suffixed
+// with their index, and at most MaxFiles files of MaxFileSize bytes are kept.
 type Logs struct {
 	Stdin, Stdout, Stderr string
+
+	MaxFiles    int
+	MaxFileSize int64
 }
 
 // NewSpawner takes a path to a state file. This state file can be used to
@@ -168,6 +173,8 @@ func (s *Spawner) spawnConfig() (string, error) {
 		config.StdoutFile = s.Logs.Stdout
 		config.StdinFile = s.Logs.Stdin
 		config.StderrFile = s.Logs.Stderr
+		config.MaxLogFiles = s.Logs.MaxFiles
+		config.MaxLogFileSize = s.Logs.MaxFileSize
 	}
 
 	var buffer bytes.Buffer
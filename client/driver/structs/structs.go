@@ -1,6 +1,18 @@
 package structs
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/stats"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// ErrFileNotExist is returned by the ClientFS RPCs when the file does not
+// exist
+var ErrFileNotExist = errors.New("file does not exist")
 
 // WaitResult stores the result of a Wait operation.
 type WaitResult struct {
@@ -25,3 +37,155 @@ func (r *WaitResult) String() string {
 	return fmt.Sprintf("Wait returned exit code %v, signal %v, and error %v",
 		r.ExitCode, r.Signal, r.Err)
 }
+
+// ExecOptions configures a command run inside a running task.
+type ExecOptions struct {
+	// Command is the command to run and its arguments
+	Command []string
+
+	// Tty allocates a pseudo terminal to the command. Stdout then carries
+	// both the output and errors of the command.
+	Tty bool
+
+	// Stdin, Stdout and Stderr are the standard streams of the command.
+	// Stdin may be nil.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// ResizeCh delivers the size of the terminal of the user when Tty is
+	// set.
+	ResizeCh <-chan TerminalSize
+
+	// CancelCh is closed when the user of the session is gone, for example
+	// when its connection is closed. The command and the processes it
+	// started are then killed.
+	CancelCh <-chan struct{}
+}
+
+// VolumeMount is a volume mount of a task resolved to the path of its host
+// volume on the client.
+type VolumeMount struct {
+	// HostPath is the path of the host volume on the client
+	HostPath string
+
+	// TaskPath is the absolute path the volume is mounted at in the task
+	TaskPath string
+
+	ReadOnly bool
+}
+
+// TerminalSize is the size of a terminal in characters.
+type TerminalSize struct {
+	Height uint16
+	Width  uint16
+}
+
+// MemoryStats holds the memory usage of a task, in bytes.
+type MemoryStats struct {
+	RSS      uint64
+	Cache    uint64
+	Swap     uint64
+	MaxUsage uint64
+
+	// Measured lists the fields that are measured by the driver of the task
+	Measured []string
+}
+
+// CpuStats holds the CPU usage of a task.
+type CpuStats struct {
+	// SystemMode and UserMode are the cumulative CPU times of the task in
+	// kernel and user mode, in seconds
+	SystemMode float64
+	UserMode   float64
+
+	// Percent is the CPU usage of the task since the previous sample, where
+	// 100 is a fully used core, and TotalTicks is the same usage in MHz. They
+	// are computed by the client from consecutive samples.
+	Percent    float64
+	TotalTicks float64
+
+	// ThrottledPeriods is the number of periods in which the task was
+	// throttled and ThrottledTime the total time it was throttled for, in
+	// nanoseconds
+	ThrottledPeriods uint64
+	ThrottledTime    uint64
+
+	// Measured lists the fields that are measured by the driver of the task
+	Measured []string
+}
+
+// ResourceUsage holds the resource usage of a task.
+type ResourceUsage struct {
+	MemoryStats *MemoryStats
+	CpuStats    *CpuStats
+}
+
+// TaskResourceUsage is a sample of the resource usage of a task taken at a
+// point in time, in Unix nanoseconds.
+type TaskResourceUsage struct {
+	ResourceUsage *ResourceUsage
+	Timestamp     int64
+}
+
+// IsErrFileNotExist returns whether the error, which may have been returned
+// by an RPC, is ErrFileNotExist.
+func IsErrFileNotExist(err error) bool {
+	return err != nil && err.Error() == ErrFileNotExist.Error()
+}
+
+// FsReadAtMaxSize is the most data a node returns for a single read of a
+// file. Larger reads are made of several requests.
+const FsReadAtMaxSize = 1024 * 1024
+
+// FsRequest is used to access a file of the alloc dir of an allocation on the
+// node running it. Offset and Limit are only used to read a file.
+type FsRequest struct {
+	AllocID string
+	Path    string
+	Offset  int64
+	Limit   int64
+	structs.QueryOptions
+}
+
+// FsListResponse is used to return the files of a directory.
+type FsListResponse struct {
+	Files []*allocdir.AllocFileInfo
+}
+
+// FsStatResponse is used to return the information of a file.
+type FsStatResponse struct {
+	Info *allocdir.AllocFileInfo
+}
+
+// FsReadAtResponse is used to return the data read from a file.
+type FsReadAtResponse struct {
+	Data []byte
+}
+
+// ClientStatsRequest is used to read the resource usage of the host of a
+// node.
+type ClientStatsRequest struct {
+	NodeID string
+	structs.QueryOptions
+}
+
+// ClientStatsResponse is used to return the resource usage of the host of a
+// node.
+type ClientStatsResponse struct {
+	HostStats *stats.HostStats
+}
+
+// AllocStatsRequest is used to read the resource usage of a task of an
+// allocation, or of all of its tasks if no task is given.
+type AllocStatsRequest struct {
+	AllocID string
+	Task    string
+	structs.QueryOptions
+}
+
+// AllocStatsResponse is used to return the samples of the resource usage of
+// the tasks of an allocation, by task name.
+type AllocStatsResponse struct {
+	Tasks map[string][]*TaskResourceUsage
+}
@@ -21,6 +21,7 @@ var BuiltinFingerprints = []string{
 	"env_aws",
 	"env_gce",
 	"host",
+	"host_volume",
 	"memory",
 	"network",
 	"storage",
@@ -29,15 +30,16 @@ var BuiltinFingerprints = []string{
 // builtinFingerprintMap contains the built in registered fingerprints
 // which are available, corresponding to a key found in BuiltinFingerprints
 var builtinFingerprintMap = map[string]Factory{
-	"arch":    NewArchFingerprint,
-	"consul":  NewConsulFingerprint,
-	"cpu":     NewCPUFingerprint,
-	"env_aws": NewEnvAWSFingerprint,
-	"env_gce": NewEnvGCEFingerprint,
-	"host":    NewHostFingerprint,
-	"memory":  NewMemoryFingerprint,
-	"network": NewNetworkFingerprinter,
-	"storage": NewStorageFingerprint,
+	"arch":        NewArchFingerprint,
+	"consul":      NewConsulFingerprint,
+	"cpu":         NewCPUFingerprint,
+	"env_aws":     NewEnvAWSFingerprint,
+	"env_gce":     NewEnvGCEFingerprint,
+	"host":        NewHostFingerprint,
+	"host_volume": NewHostVolumeFingerprint,
+	"memory":      NewMemoryFingerprint,
+	"network":     NewNetworkFingerprinter,
+	"storage":     NewStorageFingerprint,
 }
 
 // NewFingerprint is used to instantiate and return a new fingerprint
@@ -0,0 +1,52 @@
+package fingerprint
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	client "github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// HostVolumeFingerprint is used to fingerprint the host volumes configured on
+// the client, which task groups can request by name
+type HostVolumeFingerprint struct {
+	StaticFingerprinter
+	logger *log.Logger
+}
+
+// NewHostVolumeFingerprint is used to create a host volume fingerprint
+func NewHostVolumeFingerprint(logger *log.Logger) Fingerprint {
+	f := &HostVolumeFingerprint{logger: logger}
+	return f
+}
+
+func (f *HostVolumeFingerprint) Fingerprint(config *client.Config, node *structs.Node) (bool, error) {
+	if len(config.HostVolumes) == 0 {
+		return false, nil
+	}
+
+	volumes := make(map[string]*structs.ClientHostVolumeConfig, len(config.HostVolumes))
+	for name, volume := range config.HostVolumes {
+		if !filepath.IsAbs(volume.Path) {
+			return false, fmt.Errorf("host volume '%s': path %q must be absolute", name, volume.Path)
+		}
+		info, err := os.Stat(volume.Path)
+		if err != nil {
+			return false, fmt.Errorf("host volume '%s': %v", name, err)
+		}
+		if !info.IsDir() {
+			return false, fmt.Errorf("host volume '%s': path %q is not a directory", name, volume.Path)
+		}
+
+		volumes[name] = &structs.ClientHostVolumeConfig{
+			Name:     name,
+			Path:     filepath.Clean(volume.Path),
+			ReadOnly: volume.ReadOnly,
+		}
+	}
+	node.HostVolumes = volumes
+	return true, nil
+}
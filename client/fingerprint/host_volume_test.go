@@ -0,0 +1,56 @@
+package fingerprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestHostVolumeFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := NewHostVolumeFingerprint(testLogger())
+	node := &structs.Node{
+		Attributes: make(map[string]string),
+	}
+
+	// Clients without host volumes do not apply
+	ok, err := f.Fingerprint(&config.Config{}, node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ok {
+		t.Fatalf("should not apply")
+	}
+
+	conf := &config.Config{
+		HostVolumes: map[string]*structs.ClientHostVolumeConfig{
+			"data": &structs.ClientHostVolumeConfig{Name: "data", Path: dir + "/", ReadOnly: true},
+		},
+	}
+	ok, err = f.Fingerprint(conf, node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !ok {
+		t.Fatalf("should apply")
+	}
+	volume := node.HostVolumes["data"]
+	if volume == nil || volume.Path != dir || !volume.ReadOnly {
+		t.Fatalf("bad host volume: %#v", volume)
+	}
+
+	// Volumes must be existing directories
+	conf.HostVolumes["data"].Path = filepath.Join(dir, "missing")
+	if _, err := f.Fingerprint(conf, node); err == nil {
+		t.Fatalf("expected error for missing host volume path")
+	}
+}
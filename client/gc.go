@@ -0,0 +1,175 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/shirou/gopsutil/disk"
+)
+
+const (
+	// gcDestroyTimeout is how long the garbage collector waits for the
+	// runner of an allocation to stop before removing its alloc dir
+	gcDestroyTimeout = 1 * time.Minute
+)
+
+// allocGarbageCollector destroys the alloc dirs and the state of terminal
+// allocations, so that they do not fill the disk until the servers garbage
+// collect the allocations. The runners of the collected allocations are kept
+// by the client until the servers remove the allocations.
+type allocGarbageCollector struct {
+	config *config.Config
+	logger *log.Logger
+
+	// diskUsage returns the usage of the partition of a path
+	diskUsage func(path string) (*disk.DiskUsageStat, error)
+
+	lock sync.Mutex
+}
+
+// newAllocGarbageCollector returns a garbage collector for the alloc dirs
+// of the client
+func newAllocGarbageCollector(config *config.Config, logger *log.Logger) *allocGarbageCollector {
+	return &allocGarbageCollector{
+		config:    config,
+		logger:    logger,
+		diskUsage: disk.DiskUsage,
+	}
+}
+
+// Collect destroys the terminal allocations of the runners, least recently
+// updated first. Unless forced, allocations are only destroyed while the
+// number of allocations or the usage of the disk of the alloc dir is over its
+// limit. It returns the number of allocations destroyed.
+func (gc *allocGarbageCollector) Collect(runners []*AllocRunner, force bool) int {
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+
+	var live int
+	var terminal []*AllocRunner
+	for _, ar := range runners {
+		if ar.IsDestroyed() {
+			continue
+		}
+		live++
+		if ar.Alloc().TerminalStatus() {
+			terminal = append(terminal, ar)
+		}
+	}
+	sort.Sort(runnersByModifyIndex(terminal))
+
+	collected := 0
+	for _, ar := range terminal {
+		reason := "forced garbage collection"
+		if !force {
+			var err error
+			if reason, err = gc.overLimit(live); err != nil {
+				gc.logger.Printf("[ERR] client: failed to check alloc dir usage: %v", err)
+				break
+			}
+			if reason == "" {
+				break
+			}
+		}
+
+		gc.logger.Printf("[INFO] client: garbage collecting alloc '%s': %s", ar.Alloc().ID, reason)
+		gc.destroy(ar)
+		live--
+		collected++
+	}
+	return collected
+}
+
+// overLimit returns why allocations must be destroyed, or an empty string if
+// the number of allocations and the usage of the disk are within the limits
+func (gc *allocGarbageCollector) overLimit(live int) (string, error) {
+	if live > gc.config.GCMaxAllocs {
+		return fmt.Sprintf("number of allocations (%d) over limit (%d)", live, gc.config.GCMaxAllocs), nil
+	}
+
+	usage, err := gc.diskUsage(gc.config.AllocDir)
+	if err != nil {
+		return "", err
+	}
+	if usage.UsedPercent > gc.config.GCDiskUsageThreshold {
+		return fmt.Sprintf("disk usage (%.1f%%) over threshold (%.1f%%)",
+			usage.UsedPercent, gc.config.GCDiskUsageThreshold), nil
+	}
+	if usage.InodesUsedPercent > gc.config.GCInodeUsageThreshold {
+		return fmt.Sprintf("inode usage (%.1f%%) over threshold (%.1f%%)",
+			usage.InodesUsedPercent, gc.config.GCInodeUsageThreshold), nil
+	}
+	return "", nil
+}
+
+// destroy destroys the runner of an allocation and then its alloc dir and
+// state, which the runner does not destroy if it stopped already
+func (gc *allocGarbageCollector) destroy(ar *AllocRunner) {
+	ar.Destroy()
+	select {
+	case <-ar.WaitCh():
+	case <-time.After(gcDestroyTimeout):
+		gc.logger.Printf("[WARN] client: timed out waiting for the runner of alloc '%s' to stop", ar.Alloc().ID)
+	}
+
+	if ar.ctx != nil {
+		if err := ar.DestroyContext(); err != nil {
+			gc.logger.Printf("[ERR] client: failed to destroy context for alloc '%s': %v", ar.Alloc().ID, err)
+		}
+	}
+	if err := ar.DestroyState(); err != nil {
+		gc.logger.Printf("[ERR] client: failed to destroy state for alloc '%s': %v", ar.Alloc().ID, err)
+	}
+}
+
+// runnersByModifyIndex sorts alloc runners by the modify index of their
+// allocation
+type runnersByModifyIndex []*AllocRunner
+
+func (r runnersByModifyIndex) Len() int      { return len(r) }
+func (r runnersByModifyIndex) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r runnersByModifyIndex) Less(i, j int) bool {
+	return r[i].Alloc().ModifyIndex < r[j].Alloc().ModifyIndex
+}
+
+// GarbageCollect destroys the alloc dirs and the state of all the terminal
+// allocations and returns the number of allocations destroyed
+func (c *Client) GarbageCollect() int {
+	return c.gc.Collect(c.allocRunners(), true)
+}
+
+// periodicGC destroys terminal allocations on an interval while the client
+// is over its limits, until the client is shutdown
+func (c *Client) periodicGC() {
+	if c.config.GCInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.config.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if n := c.gc.Collect(c.allocRunners(), false); n != 0 {
+				c.logger.Printf("[DEBUG] client: garbage collected %d allocs", n)
+			}
+		case <-c.shutdownCh:
+			return
+		}
+	}
+}
+
+// allocRunners returns the runners of the allocations of the client
+func (c *Client) allocRunners() []*AllocRunner {
+	c.allocLock.RLock()
+	defer c.allocLock.RUnlock()
+	runners := make([]*AllocRunner, 0, len(c.allocs))
+	for _, ar := range c.allocs {
+		runners = append(runners, ar)
+	}
+	return runners
+}
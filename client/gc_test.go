@@ -0,0 +1,120 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/driver"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shirou/gopsutil/disk"
+)
+
+// testGCAllocRunner returns a stopped runner of an allocation with an alloc
+// dir and state
+func testGCAllocRunner(t *testing.T, gc *allocGarbageCollector, index uint64, status string) *AllocRunner {
+	alloc := mock.Alloc()
+	alloc.ModifyIndex = index
+	alloc.ClientStatus = status
+
+	ar := NewAllocRunner(testLogger(), gc.config, nil, alloc, nil, nil, nil, nil)
+	allocDir := allocdir.NewAllocDir(filepath.Join(gc.config.AllocDir, alloc.ID))
+	if err := allocDir.Build(nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ar.ctx = driver.NewExecContext(allocDir, alloc.ID)
+	if err := ar.SaveState(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	close(ar.waitCh)
+	return ar
+}
+
+func TestAllocGarbageCollector_Collect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := DefaultConfig()
+	conf.AllocDir = filepath.Join(dir, "alloc")
+	conf.StateDir = filepath.Join(dir, "state")
+	conf.GCMaxAllocs = 2
+	gc := newAllocGarbageCollector(conf, testLogger())
+	usage := &disk.DiskUsageStat{UsedPercent: 10, InodesUsedPercent: 10}
+	gc.diskUsage = func(path string) (*disk.DiskUsageStat, error) {
+		return usage, nil
+	}
+
+	running := testGCAllocRunner(t, gc, 1, structs.AllocClientStatusRunning)
+	newer := testGCAllocRunner(t, gc, 3, structs.AllocClientStatusDead)
+	older := testGCAllocRunner(t, gc, 2, structs.AllocClientStatusFailed)
+	runners := []*AllocRunner{running, newer, older}
+	destroyed := func(ar *AllocRunner) bool {
+		_, err := os.Stat(ar.ctx.AllocDir.AllocDir)
+		_, stateErr := os.Stat(ar.stateFilePath())
+		return ar.IsDestroyed() && os.IsNotExist(err) && os.IsNotExist(stateErr)
+	}
+
+	// The oldest terminal allocation is collected until under the limit on
+	// the number of allocations
+	if n := gc.Collect(runners, false); n != 1 {
+		t.Fatalf("expected 1 collected alloc, got %d", n)
+	}
+	if !destroyed(older) || newer.IsDestroyed() || running.IsDestroyed() {
+		t.Fatalf("expected only the oldest terminal alloc to be collected")
+	}
+
+	// Nothing is collected while under the limits
+	if n := gc.Collect(runners, false); n != 0 {
+		t.Fatalf("expected no collected alloc, got %d", n)
+	}
+
+	// Terminal allocations are collected over the disk usage threshold
+	usage.UsedPercent = 90
+	if n := gc.Collect(runners, false); n != 1 {
+		t.Fatalf("expected 1 collected alloc, got %d", n)
+	}
+	if !destroyed(newer) || running.IsDestroyed() {
+		t.Fatalf("expected only the terminal alloc to be collected")
+	}
+}
+
+func TestAllocGarbageCollector_Collect_Force(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := DefaultConfig()
+	conf.AllocDir = filepath.Join(dir, "alloc")
+	conf.StateDir = filepath.Join(dir, "state")
+	gc := newAllocGarbageCollector(conf, testLogger())
+	gc.diskUsage = func(path string) (*disk.DiskUsageStat, error) {
+		return &disk.DiskUsageStat{}, nil
+	}
+
+	running := testGCAllocRunner(t, gc, 1, structs.AllocClientStatusRunning)
+	dead := testGCAllocRunner(t, gc, 2, structs.AllocClientStatusDead)
+
+	// All the terminal allocations are collected regardless of the limits
+	if n := gc.Collect([]*AllocRunner{running, dead}, true); n != 1 {
+		t.Fatalf("expected 1 collected alloc, got %d", n)
+	}
+	if !dead.IsDestroyed() || running.IsDestroyed() {
+		t.Fatalf("expected only the terminal alloc to be collected")
+	}
+
+	// The state of collected allocations is not saved again
+	if err := dead.SaveState(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := os.Stat(dead.stateFilePath()); !os.IsNotExist(err) {
+		t.Fatalf("expected state to be destroyed: %v", err)
+	}
+}
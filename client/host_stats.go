@@ -0,0 +1,46 @@
+package client
+
+import (
+	"time"
+
+	"github.com/hashicorp/nomad/client/stats"
+)
+
+const (
+	// hostStatsCollectionInterval is the interval at which the resource
+	// usage of the host is sampled
+	hostStatsCollectionInterval = 1 * time.Second
+)
+
+// HostStats returns the latest sample of the resource usage of the host, or
+// nil if none was collected yet
+func (c *Client) HostStats() *stats.HostStats {
+	c.hostStatsLock.RLock()
+	defer c.hostStatsLock.RUnlock()
+	return c.hostStats
+}
+
+// collectHostStats samples the resource usage of the host on an interval
+// until the client is shutdown
+func (c *Client) collectHostStats() {
+	collector := stats.NewHostStatsCollector(c.config.AllocDir)
+	ticker := time.NewTicker(hostStatsCollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		hs, err := collector.Collect()
+		if err != nil {
+			c.logger.Printf("[DEBUG] client: failed to collect host stats: %v", err)
+		} else {
+			c.hostStatsLock.Lock()
+			c.hostStats = hs
+			c.hostStatsLock.Unlock()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-c.shutdownCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// nodeMetaFile is the file of the state dir where the node meta set at
+	// runtime is persisted
+	nodeMetaFile = "client-meta"
+)
+
+// NodeMeta returns a copy of the current meta of the node
+func (c *Client) NodeMeta() map[string]string {
+	c.configLock.RLock()
+	defer c.configLock.RUnlock()
+
+	meta := make(map[string]string, len(c.config.Node.Meta))
+	for k, v := range c.config.Node.Meta {
+		meta[k] = v
+	}
+	return meta
+}
+
+// UpdateNodeMeta applies updates to the meta of the node at runtime. A nil
+// value removes the key, even if it was set by the client config. The
+// updates are persisted in the state dir so that they survive restarts and
+// the node is registered again so that the servers schedule against the new
+// meta. It returns the resulting meta.
+func (c *Client) UpdateNodeMeta(updates map[string]*string) (map[string]string, error) {
+	for k := range updates {
+		if k == "" {
+			return nil, fmt.Errorf("meta keys must not be empty")
+		}
+	}
+
+	c.metaLock.Lock()
+	defer c.metaLock.Unlock()
+
+	// Persist the updates merged with the previous ones
+	dynamic := make(map[string]*string, len(c.dynamicMeta)+len(updates))
+	for k, v := range c.dynamicMeta {
+		dynamic[k] = v
+	}
+	for k, v := range updates {
+		dynamic[k] = v
+	}
+	if !c.config.DevMode {
+		if err := saveNodeMeta(filepath.Join(c.config.StateDir, nodeMetaFile), dynamic); err != nil {
+			return nil, fmt.Errorf("failed to persist node meta: %v", err)
+		}
+	}
+	c.dynamicMeta = dynamic
+
+	c.configLock.Lock()
+	meta := applyNodeMeta(c.config.Node.Meta, updates)
+	c.config.Node.Meta = meta
+	c.configLock.Unlock()
+
+	// Register the node again if it is already registered, otherwise the
+	// new meta is part of its first registration
+	if c.registered() {
+		if err := c.reregisterNode(); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make(map[string]string, len(meta))
+	for k, v := range meta {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// SetConfigMeta replaces the meta of the node set by the client config, when
+// the config is reloaded. The meta set at runtime still applies on top of
+// it. The node is registered again if its meta changed.
+func (c *Client) SetConfigMeta(meta map[string]string) error {
+	c.metaLock.Lock()
+	defer c.metaLock.Unlock()
+
+	updated := applyNodeMeta(meta, c.dynamicMeta)
+	c.configLock.Lock()
+	node := c.config.Node
+	if len(updated) == len(node.Meta) && (len(updated) == 0 || reflect.DeepEqual(updated, node.Meta)) {
+		c.configLock.Unlock()
+		return nil
+	}
+	node.Meta = updated
+	c.configLock.Unlock()
+
+	if c.registered() {
+		return c.reregisterNode()
+	}
+	return nil
+}
+
+// reregisterNode updates the registration of a node that is already ready
+func (c *Client) reregisterNode() error {
+	node := *c.Node()
+	node.Status = structs.NodeStatusReady
+	req := structs.NodeRegisterRequest{
+		Node:         &node,
+		WriteRequest: structs.WriteRequest{Region: c.config.Region},
+	}
+	var resp structs.NodeUpdateResponse
+	if err := c.RPC("Node.Register", &req, &resp); err != nil {
+		return fmt.Errorf("failed to update node registration: %v", err)
+	}
+	if len(resp.EvalIDs) != 0 {
+		c.logger.Printf("[DEBUG] client: %d evaluations triggered by node meta update", len(resp.EvalIDs))
+	}
+	return nil
+}
+
+// restoreNodeMeta applies the node meta persisted in the state dir on top
+// of the meta of the client config
+func (c *Client) restoreNodeMeta() error {
+	if c.config.DevMode {
+		return nil
+	}
+
+	dynamic, err := loadNodeMeta(filepath.Join(c.config.StateDir, nodeMetaFile))
+	if err != nil {
+		return err
+	}
+	c.metaLock.Lock()
+	defer c.metaLock.Unlock()
+	c.dynamicMeta = dynamic
+
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.config.Node.Meta = applyNodeMeta(c.config.Node.Meta, dynamic)
+	return nil
+}
+
+// applyNodeMeta returns a copy of the meta with the updates applied
+func applyNodeMeta(meta map[string]string, updates map[string]*string) map[string]string {
+	out := make(map[string]string, len(meta)+len(updates))
+	for k, v := range meta {
+		out[k] = v
+	}
+	for k, v := range updates {
+		if v == nil {
+			delete(out, k)
+		} else {
+			out[k] = *v
+		}
+	}
+	return out
+}
+
+// loadNodeMeta reads the persisted node meta, returning none if it was never
+// persisted
+func loadNodeMeta(path string) (map[string]*string, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read node meta: %v", err)
+	}
+
+	var meta map[string]*string
+	if err := json.Unmarshal(buf, &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode node meta: %v", err)
+	}
+	return meta, nil
+}
+
+// saveNodeMeta persists the node meta atomically
+func saveNodeMeta(path string, meta map[string]*string) error {
+	buf, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestClient_UpdateNodeMeta(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	newClient := func() *Client {
+		return &Client{
+			config: &config.Config{
+				StateDir: dir,
+				Node: &structs.Node{
+					Meta: map[string]string{"rack": "r1", "tier": "web"},
+				},
+			},
+			logger: log.New(os.Stderr, "", log.LstdFlags),
+		}
+	}
+
+	c := newClient()
+	rack := "r2"
+	out, err := c.UpdateNodeMeta(map[string]*string{"rack": &rack, "tier": nil})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := map[string]string{"rack": "r2"}
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("bad: %v", out)
+	}
+	if !reflect.DeepEqual(c.NodeMeta(), expected) {
+		t.Fatalf("bad: %v", c.NodeMeta())
+	}
+
+	// Empty keys are rejected
+	if _, err := c.UpdateNodeMeta(map[string]*string{"": &rack}); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// The updates are restored on top of the config meta
+	restored := newClient()
+	if err := restored.restoreNodeMeta(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(restored.NodeMeta(), expected) {
+		t.Fatalf("bad: %v", restored.NodeMeta())
+	}
+}
+
+func TestClient_SetConfigMeta(t *testing.T) {
+	c := &Client{
+		config: &config.Config{
+			Node: &structs.Node{
+				Meta: map[string]string{"rack": "r1"},
+			},
+		},
+		dynamicMeta: map[string]*string{"rack": nil},
+		logger:      log.New(os.Stderr, "", log.LstdFlags),
+	}
+
+	// The meta set at runtime applies on top of the reloaded meta
+	if err := c.SetConfigMeta(map[string]string{"rack": "r2", "tier": "web"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := map[string]string{"tier": "web"}
+	if !reflect.DeepEqual(c.NodeMeta(), expected) {
+		t.Fatalf("bad: %v", c.NodeMeta())
+	}
+}
+
+func TestClient_UpdateNodeMeta_Concurrent(t *testing.T) {
+	c := &Client{
+		config: &config.Config{
+			DevMode: true,
+			Node: &structs.Node{
+				Meta: map[string]string{"rack": "r1"},
+			},
+		},
+		logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+
+	// The node is read while its meta is updated, which is caught when the
+	// tests are run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			rack := fmt.Sprintf("r%d", i)
+			if _, err := c.UpdateNodeMeta(map[string]*string{"rack": &rack}); err != nil {
+				t.Errorf("err: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			node := c.Node()
+			for k, v := range node.Meta {
+				node.Meta[k] = v + "-copy"
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Modifying the copies does not affect the node
+	if rack := c.NodeMeta()["rack"]; rack == "" || len(rack) > 2 {
+		t.Fatalf("bad: %v", rack)
+	}
+}
+
+func TestClient_UpdateNodeMeta_Registered(t *testing.T) {
+	s1, _ := testServer(t, nil)
+	defer s1.Shutdown()
+
+	c1 := testClient(t, func(c *config.Config) {
+		c.RPCHandler = s1
+	})
+	defer c1.Shutdown()
+
+	// Wait for the node to be ready
+	testutil.WaitForResult(func() (bool, error) {
+		node, err := s1.State().NodeByID(c1.Node().ID)
+		if err != nil {
+			return false, err
+		}
+		if node == nil || node.Status != structs.NodeStatusReady {
+			return false, fmt.Errorf("node not ready")
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	rack := "r1"
+	if _, err := c1.UpdateNodeMeta(map[string]*string{"rack": &rack}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The servers see the new meta and the node stays ready
+	node, err := s1.State().NodeByID(c1.Node().ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if node.Meta["rack"] != "r1" || node.Status != structs.NodeStatusReady {
+		t.Fatalf("bad: %#v", node)
+	}
+}
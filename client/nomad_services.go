@@ -0,0 +1,233 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// NomadServices is the service which tracks tasks and registers their
+// services that use the nomad provider with the Nomad servers
+type NomadServices struct {
+	rpc        config.RPCHandler
+	region     string
+	node       *structs.Node
+	logger     *log.Logger
+	shutdownCh chan struct{}
+
+	// trackedTasks are the tasks whose services are registered and
+	// registered is the IDs of the services registered for each of them
+	trackedTasks map[string]*trackedTask
+	registered   map[string][]string
+
+	// dirty is set when the servers may not know about all the registered
+	// services, in which case they are registered again on the next sync
+	dirty bool
+	lock  sync.Mutex
+}
+
+// NewNomadServices returns a service registering the services of the tasks
+// through the given RPC handler
+func NewNomadServices(logger *log.Logger, rpc config.RPCHandler, region string, node *structs.Node) *NomadServices {
+	return &NomadServices{
+		rpc:          rpc,
+		region:       region,
+		node:         node,
+		logger:       logger,
+		shutdownCh:   make(chan struct{}),
+		trackedTasks: make(map[string]*trackedTask),
+		registered:   make(map[string][]string),
+	}
+}
+
+// Register starts tracking a task and registers its services with the
+// servers. Services that were registered for a previous version of the task
+// and that are no longer defined are deregistered.
+func (n *NomadServices) Register(task *structs.Task, alloc *structs.Allocation) error {
+	key := fmt.Sprintf("%s-%s", alloc.ID, task.Name)
+	services, err := n.makeRegistrations(task, alloc)
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	var stale []string
+	known := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		known[service.ID] = struct{}{}
+	}
+	for _, id := range n.registered[key] {
+		if _, ok := known[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+
+	if len(services) == 0 {
+		delete(n.trackedTasks, key)
+		delete(n.registered, key)
+	} else {
+		n.trackedTasks[key] = &trackedTask{task: task, alloc: alloc}
+		ids := make([]string, len(services))
+		for i, service := range services {
+			ids[i] = service.ID
+		}
+		n.registered[key] = ids
+	}
+
+	if len(stale) != 0 {
+		if err := n.deregister(stale); err != nil {
+			n.logger.Printf("[ERR] client.services: failed to deregister services of task '%s' for alloc '%s': %v",
+				task.Name, alloc.ID, err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		return nil
+	}
+
+	for _, service := range services {
+		n.logger.Printf("[INFO] client.services: registering service %s", service.ServiceName)
+	}
+	if err := n.register(services); err != nil {
+		n.dirty = true
+		return err
+	}
+	return nil
+}
+
+// Deregister stops tracking a task and deregisters its services from the
+// servers
+func (n *NomadServices) Deregister(task *structs.Task, alloc *structs.Allocation) error {
+	key := fmt.Sprintf("%s-%s", alloc.ID, task.Name)
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	ids := n.registered[key]
+	delete(n.trackedTasks, key)
+	delete(n.registered, key)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	n.logger.Printf("[INFO] client.services: deregistering services of task '%s' for alloc '%s'", task.Name, alloc.ID)
+	return n.deregister(ids)
+}
+
+// Resync registers all the tracked services again on the next sync. It is
+// used when the servers may have removed the services of the node, such as
+// after the node was marked down.
+func (n *NomadServices) Resync() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.dirty = true
+}
+
+func (n *NomadServices) ShutDown() {
+	close(n.shutdownCh)
+}
+
+// SyncWithServers is a long lived function that registers the tracked
+// services again if the servers may not know about them
+func (n *NomadServices) SyncWithServers() {
+	sync := time.After(syncInterval)
+
+	for {
+		select {
+		case <-sync:
+			n.performSync()
+			sync = time.After(syncInterval)
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// performSync registers all the tracked services if a previous registration
+// failed or a resync was requested
+func (n *NomadServices) performSync() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if !n.dirty {
+		return
+	}
+
+	var services []*structs.ServiceRegistration
+	for _, tt := range n.trackedTasks {
+		regs, err := n.makeRegistrations(tt.task, tt.alloc)
+		if err != nil {
+			n.logger.Printf("[DEBUG] client.services: %v", err)
+		}
+		services = append(services, regs...)
+	}
+
+	if len(services) != 0 {
+		if err := n.register(services); err != nil {
+			n.logger.Printf("[DEBUG] client.services: error syncing services: %v", err)
+			return
+		}
+	}
+	n.dirty = false
+}
+
+// makeRegistrations returns the registrations of the services of the task
+// that use the nomad provider. Services whose port can not be found are
+// skipped and reported in the returned error.
+func (n *NomadServices) makeRegistrations(task *structs.Task, alloc *structs.Allocation) ([]*structs.ServiceRegistration, error) {
+	var services []*structs.ServiceRegistration
+	var err error
+	for _, service := range task.Services {
+		if !service.IsNomadProvided() {
+			continue
+		}
+		serviceID := alloc.Services[service.Name]
+		if serviceID == "" {
+			continue
+		}
+
+		host, port := task.FindHostAndPortFor(service.PortLabel)
+		if host == "" || port == 0 {
+			err = fmt.Errorf("the port:%q marked for registration of service: %q couldn't be found", service.PortLabel, service.Name)
+			continue
+		}
+
+		services = append(services, &structs.ServiceRegistration{
+			ID:          serviceID,
+			ServiceName: service.Name,
+			JobID:       alloc.JobID,
+			AllocID:     alloc.ID,
+			NodeID:      n.node.ID,
+			TaskName:    task.Name,
+			Tags:        service.Tags,
+			Address:     host,
+			Port:        port,
+		})
+	}
+	return services, err
+}
+
+// register upserts the services on the servers
+func (n *NomadServices) register(services []*structs.ServiceRegistration) error {
+	req := structs.ServiceRegistrationUpsertRequest{
+		Services:     services,
+		WriteRequest: structs.WriteRequest{Region: n.region},
+	}
+	var resp structs.GenericResponse
+	return n.rpc.RPC("ServiceRegistration.Upsert", &req, &resp)
+}
+
+// deregister removes the services from the servers
+func (n *NomadServices) deregister(ids []string) error {
+	req := structs.ServiceRegistrationDeleteRequest{
+		IDs:          ids,
+		WriteRequest: structs.WriteRequest{Region: n.region},
+	}
+	var resp structs.GenericResponse
+	return n.rpc.RPC("ServiceRegistration.Delete", &req, &resp)
+}
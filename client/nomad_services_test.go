@@ -0,0 +1,128 @@
+package client
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// mockServicesRPC records the service registrations sent to the servers
+type mockServicesRPC struct {
+	registered  map[string]*structs.ServiceRegistration
+	upsertCount int
+	deleteCount int
+}
+
+func (m *mockServicesRPC) RPC(method string, args interface{}, reply interface{}) error {
+	switch method {
+	case "ServiceRegistration.Upsert":
+		m.upsertCount++
+		for _, service := range args.(*structs.ServiceRegistrationUpsertRequest).Services {
+			m.registered[service.ID] = service
+		}
+	case "ServiceRegistration.Delete":
+		m.deleteCount++
+		for _, id := range args.(*structs.ServiceRegistrationDeleteRequest).IDs {
+			delete(m.registered, id)
+		}
+	}
+	return nil
+}
+
+func newNomadServices() (*NomadServices, *mockServicesRPC) {
+	logger := log.New(os.Stdout, "logger: ", log.Lshortfile)
+	rpc := &mockServicesRPC{registered: make(map[string]*structs.ServiceRegistration)}
+	return NewNomadServices(logger, rpc, "global", &structs.Node{ID: "node"}), rpc
+}
+
+func newNomadServicesTask(alloc *structs.Allocation) *structs.Task {
+	task := newTask()
+	task.Services = []*structs.Service{
+		{
+			Name:      "db",
+			Tags:      []string{"primary"},
+			PortLabel: "db",
+			Provider:  structs.ServiceProviderNomad,
+		},
+		{
+			Name:      "cache",
+			PortLabel: "db",
+			Provider:  structs.ServiceProviderNomad,
+		},
+		{
+			Name:      "consul-only",
+			PortLabel: "db",
+		},
+	}
+	alloc.Services = map[string]string{
+		"db":          "nomad-registered-service-1",
+		"cache":       "nomad-registered-service-2",
+		"consul-only": "nomad-registered-service-3",
+	}
+	return task
+}
+
+func TestNomadServices_RegisterDeregister(t *testing.T) {
+	n, rpc := newNomadServices()
+	alloc := mock.Alloc()
+	task := newNomadServicesTask(alloc)
+
+	if err := n.Register(task, alloc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Only the services using the nomad provider are registered
+	if len(rpc.registered) != 2 {
+		t.Fatalf("bad: %#v", rpc.registered)
+	}
+	db := rpc.registered["nomad-registered-service-1"]
+	if db == nil || db.ServiceName != "db" || db.Address != "10.10.0.1" || db.Port != 20413 ||
+		db.AllocID != alloc.ID || db.NodeID != "node" || db.TaskName != task.Name {
+		t.Fatalf("bad: %#v", db)
+	}
+
+	// Removing a service from the task deregisters it
+	update := new(structs.Task)
+	*update = *task
+	update.Services = task.Services[:1]
+	if err := n.Register(update, alloc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(rpc.registered) != 1 || rpc.registered["nomad-registered-service-1"] == nil {
+		t.Fatalf("bad: %#v", rpc.registered)
+	}
+
+	if err := n.Deregister(update, alloc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(rpc.registered) != 0 {
+		t.Fatalf("bad: %#v", rpc.registered)
+	}
+}
+
+func TestNomadServices_Resync(t *testing.T) {
+	n, rpc := newNomadServices()
+	alloc := mock.Alloc()
+	task := newNomadServicesTask(alloc)
+
+	if err := n.Register(task, alloc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Nothing is sent if the servers know about the services
+	n.performSync()
+	if rpc.upsertCount != 1 {
+		t.Fatalf("bad: %d", rpc.upsertCount)
+	}
+
+	// The services are registered again after the node was marked down
+	rpc.registered = make(map[string]*structs.ServiceRegistration)
+	n.Resync()
+	n.performSync()
+	if rpc.upsertCount != 2 || len(rpc.registered) != 2 {
+		t.Fatalf("bad: %d %#v", rpc.upsertCount, rpc.registered)
+	}
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-msgpack/codec"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+	"github.com/hashicorp/nomad/nomad"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/yamux"
+)
+
+const (
+	// nodeConnRetryIntv is minimum interval on which we retry to establish
+	// the node connection. We pick a value between this and 2x this.
+	nodeConnRetryIntv = 5 * time.Second
+
+	// nodeConnDialTimeout is the timeout to dial a server to establish the
+	// node connection
+	nodeConnDialTimeout = 10 * time.Second
+)
+
+// nodeConnHandler is implemented by a server running in the same process
+// as the client, which accepts the node connection without going over the
+// network
+type nodeConnHandler interface {
+	HandleNodeConn(conn net.Conn)
+}
+
+// setupRPC registers the RPC endpoints the servers forward to the node
+func (c *Client) setupRPC() error {
+	c.rpcServer = rpc.NewServer()
+	if err := c.rpcServer.RegisterName("ClientFS", &clientFS{c}); err != nil {
+		return err
+	}
+	if err := c.rpcServer.RegisterName("ClientStats", &clientStats{c}); err != nil {
+		return err
+	}
+	return c.rpcServer.RegisterName("ClientAllocations", &clientAllocations{c})
+}
+
+// keepNodeConn keeps a node connection to a server until the client is
+// shutdown. The servers forward the RPCs targeting the node over it.
+func (c *Client) keepNodeConn() {
+	for {
+		if err := c.serveNodeConn(); err != nil {
+			c.logger.Printf("[DEBUG] client: node connection failed: %v", err)
+		}
+
+		select {
+		case <-time.After(c.retryIntv(nodeConnRetryIntv)):
+		case <-c.shutdownCh:
+			return
+		}
+	}
+}
+
+// serveNodeConn establishes a node connection and serves the RPCs received
+// over it until it is closed
+func (c *Client) serveNodeConn() error {
+	conn, err := c.dialNodeConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Authenticate the node to the server
+	node := c.Node()
+	header := structs.NodeConnRequest{
+		NodeID:   node.ID,
+		SecretID: node.SecretID,
+	}
+	if err := codec.NewEncoder(conn, structs.MsgpackHandle).Encode(&header); err != nil {
+		return fmt.Errorf("failed to send node connection header: %v", err)
+	}
+
+	conf := yamux.DefaultConfig()
+	conf.LogOutput = c.config.LogOutput
+	session, err := yamux.Server(conn, conf)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	// Close the session on shutdown to stop accepting streams
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		select {
+		case <-c.shutdownCh:
+			session.Close()
+		case <-doneCh:
+		}
+	}()
+
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			if session.IsClosed() {
+				return nil
+			}
+			return err
+		}
+		go c.rpcServer.ServeCodec(nomad.NewServerCodec(stream))
+	}
+}
+
+// dialNodeConn opens a node connection to a server, using the local server
+// if there is one
+func (c *Client) dialNodeConn() (net.Conn, error) {
+	if c.config.RPCHandler != nil {
+		handler, ok := c.config.RPCHandler.(nodeConnHandler)
+		if !ok {
+			return nil, fmt.Errorf("local server does not accept node connections")
+		}
+		client, server := net.Pipe()
+		go handler.HandleNodeConn(server)
+		return client, nil
+	}
+
+	addr, err := c.pickServer()
+	if err != nil {
+		return nil, err
+	}
+	return nomad.DialNodeConn(addr, nodeConnDialTimeout)
+}
+
+// clientFS is the ClientFS endpoint of the node, used to access the alloc
+// dirs of the allocations running on it
+type clientFS struct {
+	c *Client
+}
+
+// List is used to list the files of a directory of an alloc dir
+func (f *clientFS) List(args *cstructs.FsRequest, reply *cstructs.FsListResponse) error {
+	fs, err := f.c.GetAllocFS(args.AllocID)
+	if err != nil {
+		return err
+	}
+	files, err := fs.List(args.Path)
+	if err != nil {
+		return fsError(err)
+	}
+	reply.Files = files
+	return nil
+}
+
+// Stat is used to get the information of a file of an alloc dir
+func (f *clientFS) Stat(args *cstructs.FsRequest, reply *cstructs.FsStatResponse) error {
+	fs, err := f.c.GetAllocFS(args.AllocID)
+	if err != nil {
+		return err
+	}
+	info, err := fs.Stat(args.Path)
+	if err != nil {
+		return fsError(err)
+	}
+	reply.Info = info
+	return nil
+}
+
+// ReadAt is used to read a file of an alloc dir. At most FsReadAtMaxSize
+// bytes are returned, so that reads of large files do not have to be held in
+// memory at once.
+func (f *clientFS) ReadAt(args *cstructs.FsRequest, reply *cstructs.FsReadAtResponse) error {
+	fs, err := f.c.GetAllocFS(args.AllocID)
+	if err != nil {
+		return err
+	}
+	limit := args.Limit
+	if limit > cstructs.FsReadAtMaxSize {
+		limit = cstructs.FsReadAtMaxSize
+	}
+	r, err := fs.ReadAt(args.Path, args.Offset, limit)
+	if err != nil {
+		return fsError(err)
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	reply.Data = data
+	return nil
+}
+
+// fsError maps the errors of files that do not exist to ErrFileNotExist, so
+// that they can be recognized once returned by the RPC
+func fsError(err error) error {
+	if os.IsNotExist(err) {
+		return cstructs.ErrFileNotExist
+	}
+	return err
+}
+
+// clientStats is the ClientStats endpoint of the node, used to read the
+// resource usage of its host
+type clientStats struct {
+	c *Client
+}
+
+// Stats is used to read the resource usage of the host
+func (s *clientStats) Stats(args *cstructs.ClientStatsRequest, reply *cstructs.ClientStatsResponse) error {
+	hs := s.c.HostStats()
+	if hs == nil {
+		return fmt.Errorf("host stats not collected yet")
+	}
+	reply.HostStats = hs
+	return nil
+}
+
+// clientAllocations is the ClientAllocations endpoint of the node, used to
+// read the resource usage of the tasks of the allocations running on it
+type clientAllocations struct {
+	c *Client
+}
+
+// Stats is used to read the resource usage of the tasks of an allocation
+func (a *clientAllocations) Stats(args *cstructs.AllocStatsRequest, reply *cstructs.AllocStatsResponse) error {
+	usage, err := a.c.AllocResourceUsage(args.AllocID, args.Task)
+	if err != nil {
+		return err
+	}
+	reply.Tasks = usage
+	return nil
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultSignal is the signal sent to a task when none is given
+const defaultSignal = "SIGKILL"
+
+// parseSignal returns the signal with the given name, with or without the SIG
+// prefix, along with its canonical name. An empty name returns the default
+// signal.
+func parseSignal(name string) (string, os.Signal, error) {
+	if name == "" {
+		name = defaultSignal
+	}
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := signalLookup[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown signal %q", name)
+	}
+	return name, sig, nil
+}
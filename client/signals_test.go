@@ -0,0 +1,34 @@
+package client
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"", "SIGKILL"},
+		{"SIGINT", "SIGINT"},
+		{"int", "SIGINT"},
+		{"sigterm", "SIGTERM"},
+	}
+	for _, c := range cases {
+		name, sig, err := parseSignal(c.name)
+		if err != nil {
+			t.Fatalf("%q: err: %v", c.name, err)
+		}
+		if name != c.expected || sig != signalLookup[c.expected] {
+			t.Fatalf("%q: bad: %s %v", c.name, name, sig)
+		}
+	}
+
+	if _, sig, _ := parseSignal("term"); sig != syscall.SIGTERM {
+		t.Fatalf("bad: %v", sig)
+	}
+	if _, _, err := parseSignal("SIGFOO"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,165 @@
+package stats
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/host"
+	"github.com/shirou/gopsutil/mem"
+)
+
+// HostStats is a sample of the resource usage of the host, taken at a time in
+// Unix nanoseconds
+type HostStats struct {
+	Memory    *MemoryStats
+	CPU       []*CPUStats
+	DiskStats []*DiskStats
+	Uptime    uint64
+	Timestamp int64
+}
+
+// MemoryStats holds the memory usage of the host, in bytes
+type MemoryStats struct {
+	Total     uint64
+	Available uint64
+	Used      uint64
+	Free      uint64
+}
+
+// CPUStats holds the utilization of a core since the previous sample, in
+// percents of its time
+type CPUStats struct {
+	CPU    string
+	User   float64
+	System float64
+	Idle   float64
+	Total  float64
+}
+
+// DiskStats holds the usage of a mount hosting allocation directories, in
+// bytes
+type DiskStats struct {
+	Device            string
+	Mountpoint        string
+	Size              uint64
+	Used              uint64
+	Available         uint64
+	UsedPercent       float64
+	InodesUsedPercent float64
+}
+
+// HostStatsCollector samples the resource usage of the host. The utilization
+// of the cores is computed from their times since the previous sample.
+type HostStatsCollector struct {
+	allocDir     string
+	lastCPUTimes map[string]cpu.CPUTimesStat
+}
+
+// NewHostStatsCollector returns a collector reporting the usage of the mounts
+// the allocation directories are on
+func NewHostStatsCollector(allocDir string) *HostStatsCollector {
+	return &HostStatsCollector{
+		allocDir:     allocDir,
+		lastCPUTimes: make(map[string]cpu.CPUTimesStat),
+	}
+}
+
+// Collect samples the resource usage of the host
+func (h *HostStatsCollector) Collect() (*HostStats, error) {
+	hs := &HostStats{Timestamp: time.Now().UnixNano()}
+
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, err
+	}
+	hs.Memory = &MemoryStats{
+		Total:     vm.Total,
+		Available: vm.Available,
+		Used:      vm.Used,
+		Free:      vm.Free,
+	}
+
+	times, err := cpu.CPUTimes(true)
+	if err != nil {
+		return nil, err
+	}
+	hs.CPU = make([]*CPUStats, 0, len(times))
+	for _, t := range times {
+		hs.CPU = append(hs.CPU, h.cpuStats(t))
+		h.lastCPUTimes[t.CPU] = t
+	}
+
+	partitions, err := disk.DiskPartitions(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range allocDirPartitions(partitions, h.allocDir) {
+		usage, err := disk.DiskUsage(p.Mountpoint)
+		if err != nil {
+			return nil, err
+		}
+		hs.DiskStats = append(hs.DiskStats, &DiskStats{
+			Device:            p.Device,
+			Mountpoint:        p.Mountpoint,
+			Size:              usage.Total,
+			Used:              usage.Used,
+			Available:         usage.Free,
+			UsedPercent:       usage.UsedPercent,
+			InodesUsedPercent: usage.InodesUsedPercent,
+		})
+	}
+
+	if hs.Uptime, err = host.Uptime(); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
+
+// cpuStats returns the utilization of a core since its previous times
+func (h *HostStatsCollector) cpuStats(t cpu.CPUTimesStat) *CPUStats {
+	stats := &CPUStats{CPU: t.CPU}
+	last, ok := h.lastCPUTimes[t.CPU]
+	if !ok {
+		return stats
+	}
+
+	total := t.Total() - last.Total()
+	if total <= 0 {
+		return stats
+	}
+	stats.User = (t.User - last.User) / total * 100
+	stats.System = (t.System - last.System) / total * 100
+	stats.Idle = (t.Idle - last.Idle) / total * 100
+	stats.Total = 100 - stats.Idle
+	return stats
+}
+
+// allocDirPartitions returns the partitions hosting the allocation
+// directories: the one the directory is on and the ones mounted below it
+func allocDirPartitions(partitions []disk.DiskPartitionStat, allocDir string) []disk.DiskPartitionStat {
+	allocDir = filepath.Clean(allocDir)
+	under := func(path, dir string) bool {
+		return path == dir || dir == "/" || strings.HasPrefix(path, dir+string(filepath.Separator))
+	}
+
+	var out []disk.DiskPartitionStat
+	parent := -1
+	for i, p := range partitions {
+		mount := filepath.Clean(p.Mountpoint)
+		switch {
+		case mount != allocDir && under(mount, allocDir):
+			out = append(out, p)
+		case under(allocDir, mount):
+			if parent == -1 || len(mount) > len(filepath.Clean(partitions[parent].Mountpoint)) {
+				parent = i
+			}
+		}
+	}
+	if parent != -1 {
+		out = append([]disk.DiskPartitionStat{partitions[parent]}, out...)
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+)
+
+func TestHostStatsCollector_CPUStats(t *testing.T) {
+	h := NewHostStatsCollector("/var/lib/nomad/alloc")
+
+	// The first sample has no utilization
+	first := cpu.CPUTimesStat{CPU: "cpu0", User: 10, System: 5, Idle: 85}
+	if stats := h.cpuStats(first); stats.Total != 0 {
+		t.Fatalf("bad: %#v", stats)
+	}
+	h.lastCPUTimes[first.CPU] = first
+
+	second := cpu.CPUTimesStat{CPU: "cpu0", User: 40, System: 15, Idle: 145}
+	stats := h.cpuStats(second)
+	expected := &CPUStats{CPU: "cpu0", User: 30, System: 10, Idle: 60, Total: 40}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Fatalf("bad: %#v", stats)
+	}
+}
+
+func TestAllocDirPartitions(t *testing.T) {
+	partitions := []disk.DiskPartitionStat{
+		{Device: "/dev/sda1", Mountpoint: "/"},
+		{Device: "/dev/sda2", Mountpoint: "/var"},
+		{Device: "/dev/sda3", Mountpoint: "/var/lib/nomad/alloc/1234/web/secrets"},
+		{Device: "/dev/sda4", Mountpoint: "/var/lib/nomadic"},
+		{Device: "/dev/sda5", Mountpoint: "/home"},
+	}
+	out := allocDirPartitions(partitions, "/var/lib/nomad/alloc/")
+
+	var devices []string
+	for _, p := range out {
+		devices = append(devices, p.Device)
+	}
+	if !reflect.DeepEqual(devices, []string{"/dev/sda2", "/dev/sda3"}) {
+		t.Fatalf("bad: %v", devices)
+	}
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"fmt"
+)
+
+// RingBuff is a buffer of a fixed capacity which keeps its most recent
+// values. It is not safe for concurrent use.
+type RingBuff struct {
+	head int
+	buff []interface{}
+	full bool
+}
+
+// NewRingBuff returns a buffer keeping up to capacity values
+func NewRingBuff(capacity int) (*RingBuff, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("capacity must be greater than zero: %d", capacity)
+	}
+	return &RingBuff{buff: make([]interface{}, capacity)}, nil
+}
+
+// Enqueue adds a value to the buffer, overwriting the oldest value once the
+// buffer is full
+func (r *RingBuff) Enqueue(value interface{}) {
+	r.buff[r.head] = value
+	r.head++
+	if r.head == len(r.buff) {
+		r.head = 0
+		r.full = true
+	}
+}
+
+// Peek returns the most recent value, or nil if the buffer is empty
+func (r *RingBuff) Peek() interface{} {
+	if !r.full && r.head == 0 {
+		return nil
+	}
+	idx := r.head - 1
+	if idx < 0 {
+		idx = len(r.buff) - 1
+	}
+	return r.buff[idx]
+}
+
+// Values returns the values of the buffer, oldest first
+func (r *RingBuff) Values() []interface{} {
+	if !r.full {
+		out := make([]interface{}, r.head)
+		copy(out, r.buff[:r.head])
+		return out
+	}
+	out := make([]interface{}, 0, len(r.buff))
+	out = append(out, r.buff[r.head:]...)
+	return append(out, r.buff[:r.head]...)
+}
@@ -0,0 +1,42 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRingBuff_Capacity(t *testing.T) {
+	if _, err := NewRingBuff(0); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRingBuff(t *testing.T) {
+	r, err := NewRingBuff(3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if r.Peek() != nil || len(r.Values()) != 0 {
+		t.Fatalf("expected an empty buffer")
+	}
+
+	r.Enqueue(1)
+	r.Enqueue(2)
+	if r.Peek() != 2 {
+		t.Fatalf("bad: %v", r.Peek())
+	}
+	if v := r.Values(); !reflect.DeepEqual(v, []interface{}{1, 2}) {
+		t.Fatalf("bad: %v", v)
+	}
+
+	// The oldest values are overwritten once the buffer is full
+	r.Enqueue(3)
+	r.Enqueue(4)
+	r.Enqueue(5)
+	if r.Peek() != 5 {
+		t.Fatalf("bad: %v", r.Peek())
+	}
+	if v := r.Values(); !reflect.DeepEqual(v, []interface{}{3, 4, 5}) {
+		t.Fatalf("bad: %v", v)
+	}
+}
@@ -3,20 +3,35 @@ package client
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/driver"
+	"github.com/hashicorp/nomad/client/stats"
 	"github.com/hashicorp/nomad/nomad/structs"
 
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 )
 
+const (
+	// statsCollectionInterval is the interval at which the resource usage of
+	// a running task is sampled
+	statsCollectionInterval = 1 * time.Second
+
+	// statsBufferSize is the number of samples of the resource usage of a
+	// task that are kept
+	statsBufferSize = 60
+)
+
 // TaskRunner is used to wrap a task within an allocation and provide the execution context.
 type TaskRunner struct {
 	config         *config.Config
@@ -26,18 +41,32 @@ type TaskRunner struct {
 	alloc          *structs.Allocation
 	restartTracker *RestartTracker
 	consulService  *ConsulService
+	nomadServices  *NomadServices
+	variables      VariableFetcher
+	node           NodeFetcher
 
 	task     *structs.Task
 	state    *structs.TaskState
 	updateCh chan *structs.Task
 	handle   driver.DriverHandle
 
+	restartCh chan *structs.TaskEvent
+	signalCh  chan *taskSignal
+	handleCh  chan chan driver.DriverHandle
+
 	destroy     bool
 	destroyCh   chan struct{}
 	destroyLock sync.Mutex
 	waitCh      chan struct{}
 
+	// killEvent is the event recorded when the task is killed, if it was
+	// killed for another reason than being destroyed
+	killEvent *structs.TaskEvent
+
 	snapshotLock sync.Mutex
+
+	resourceUsage     *stats.RingBuff
+	resourceUsageLock sync.RWMutex
 }
 
 // taskRunnerState is used to snapshot the state of the task runner
@@ -46,14 +75,33 @@ type taskRunnerState struct {
 	HandleID string
 }
 
+// taskSignal is a signal to send to the task along with the event recording
+// it. The result of sending the signal is returned on the result channel.
+type taskSignal struct {
+	s      os.Signal
+	e      *structs.TaskEvent
+	result chan error
+}
+
 // TaskStateUpdater is used to signal that tasks state has changed.
 type TaskStateUpdater func(taskName string)
 
+// VariableFetcher is used to read a variable from the servers.
+type VariableFetcher func(path string) (*structs.Variable, error)
+
+// NodeFetcher is used to get a copy of the node the client runs on, as it
+// is updated concurrently.
+type NodeFetcher func() *structs.Node
+
 // NewTaskRunner is used to create a new task context
 func NewTaskRunner(logger *log.Logger, config *config.Config,
 	updater TaskStateUpdater, ctx *driver.ExecContext,
 	alloc *structs.Allocation, task *structs.Task, state *structs.TaskState,
-	restartTracker *RestartTracker, consulService *ConsulService) *TaskRunner {
+	restartTracker *RestartTracker, consulService *ConsulService,
+	nomadServices *NomadServices, variables VariableFetcher, node NodeFetcher) *TaskRunner {
+
+	// The capacity is constant so the buffer can not fail to be created
+	resourceUsage, _ := stats.NewRingBuff(statsBufferSize)
 
 	tc := &TaskRunner{
 		config:         config,
@@ -61,13 +109,20 @@ func NewTaskRunner(logger *log.Logger, config *config.Config,
 		logger:         logger,
 		restartTracker: restartTracker,
 		consulService:  consulService,
+		nomadServices:  nomadServices,
+		variables:      variables,
+		node:           node,
 		ctx:            ctx,
 		alloc:          alloc,
 		task:           task,
 		state:          state,
 		updateCh:       make(chan *structs.Task, 8),
+		restartCh:      make(chan *structs.TaskEvent),
+		signalCh:       make(chan *taskSignal),
+		handleCh:       make(chan chan driver.DriverHandle),
 		destroyCh:      make(chan struct{}),
 		waitCh:         make(chan struct{}),
+		resourceUsage:  resourceUsage,
 	}
 	return tc
 }
@@ -172,7 +227,16 @@ func (r *TaskRunner) setState(state string, event *structs.TaskEvent) {
 
 // createDriver makes a driver for the task
 func (r *TaskRunner) createDriver() (driver.Driver, error) {
-	taskEnv, err := driver.GetTaskEnv(r.ctx.AllocDir, r.config.Node, r.task)
+	task, err := r.renderVariables()
+	if err != nil {
+		err = fmt.Errorf("failed to render variables for task '%s' in alloc %s: %v",
+			r.task.Name, r.alloc.ID, err)
+		r.logger.Printf("[ERR] client: %s", err)
+		return nil, err
+	}
+
+	node := r.node()
+	taskEnv, err := driver.GetTaskEnv(r.ctx.AllocDir, node, task)
 	if err != nil {
 		err = fmt.Errorf("failed to create driver '%s' for alloc %s: %v",
 			r.task.Driver, r.alloc.ID, err)
@@ -181,7 +245,15 @@ func (r *TaskRunner) createDriver() (driver.Driver, error) {
 
 	}
 
-	driverCtx := driver.NewDriverContext(r.task.Name, r.config, r.config.Node, r.logger, taskEnv)
+	mounts, err := r.volumeMounts(task)
+	if err != nil {
+		err = fmt.Errorf("failed to create driver '%s' for alloc %s: %v",
+			r.task.Driver, r.alloc.ID, err)
+		r.logger.Printf("[ERR] client: %s", err)
+		return nil, err
+	}
+
+	driverCtx := driver.NewDriverContext(r.task.Name, r.config, node, r.logger, taskEnv, mounts)
 	driver, err := driver.NewDriver(r.task.Driver, driverCtx)
 	if err != nil {
 		err = fmt.Errorf("failed to create driver '%s' for alloc %s: %v",
@@ -192,6 +264,108 @@ func (r *TaskRunner) createDriver() (driver.Driver, error) {
 	return driver, err
 }
 
+// volumeMounts resolves the volume mounts of the task to the host volumes of
+// the client.
+func (r *TaskRunner) volumeMounts(task *structs.Task) ([]*cstructs.VolumeMount, error) {
+	if len(task.VolumeMounts) == 0 {
+		return nil, nil
+	}
+
+	tg := r.alloc.Job.LookupTaskGroup(r.alloc.TaskGroup)
+	if tg == nil {
+		return nil, fmt.Errorf("failed to find task group '%s'", r.alloc.TaskGroup)
+	}
+	return driver.GetVolumeMounts(r.config, tg, task)
+}
+
+// renderVariables reads the variables the task references and writes them to
+// their destination files. It returns the task with the items of the variables
+// that are exposed as environment variables merged into its environment.
+// Variables are rendered each time the task is started so restarts pick up
+// updated values.
+func (r *TaskRunner) renderVariables() (*structs.Task, error) {
+	if len(r.task.Variables) == 0 {
+		return r.task, nil
+	}
+	if r.variables == nil {
+		return nil, fmt.Errorf("variables are not available")
+	}
+
+	task := new(structs.Task)
+	*task = *r.task
+	task.Env = make(map[string]string, len(r.task.Env))
+	for k, v := range r.task.Env {
+		task.Env[k] = v
+	}
+
+	for _, ref := range r.task.Variables {
+		variable, err := r.variables(ref.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if ref.Env {
+			for k, v := range variable.Items {
+				task.Env[k] = v
+			}
+		}
+
+		if ref.Destination != "" {
+			if err := r.writeVariable(ref.Destination, variable); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return task, nil
+}
+
+// writeVariable writes the items of the variable as a JSON object to the
+// destination in the task directory.
+func (r *TaskRunner) writeVariable(dest string, variable *structs.Variable) error {
+	taskDir, ok := r.ctx.AllocDir.TaskDirs[r.task.Name]
+	if !ok {
+		return fmt.Errorf("failed to get task directory for task %q", r.task.Name)
+	}
+
+	path := filepath.Join(taskDir, dest)
+	if !strings.HasPrefix(path, filepath.Clean(taskDir)+string(filepath.Separator)) {
+		return fmt.Errorf("destination %q escapes the task directory", dest)
+	}
+
+	data, err := json.Marshal(variable.Items)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return fmt.Errorf("failed to create destination for variable %q: %v", variable.Path, err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write variable %q: %v", variable.Path, err)
+	}
+	return nil
+}
+
+// registerNomadServices registers the services of the task that use the
+// nomad provider with the servers
+func (r *TaskRunner) registerNomadServices() {
+	if r.nomadServices == nil {
+		return
+	}
+	if err := r.nomadServices.Register(r.task, r.alloc); err != nil {
+		r.logger.Printf("[ERR] client: failed to register services of task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, err)
+	}
+}
+
+// deregisterNomadServices removes the services of the task from the servers
+func (r *TaskRunner) deregisterNomadServices() {
+	if r.nomadServices == nil {
+		return
+	}
+	if err := r.nomadServices.Deregister(r.task, r.alloc); err != nil {
+		r.logger.Printf("[ERR] client: failed to deregister services of task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, err)
+	}
+}
+
 // startTask is used to start the task if there is no handle
 func (r *TaskRunner) startTask() error {
 	// Create a driver
@@ -242,9 +416,15 @@ func (r *TaskRunner) run() {
 		var waitRes *cstructs.WaitResult
 		var destroyErr error
 		destroyed := false
+		restarting := false
 
 		// Register the services defined by the task with Consil
 		r.consulService.Register(r.task, r.alloc)
+		r.registerNomadServices()
+
+		// Sample the resource usage of the task while it runs
+		stopCollection := make(chan struct{})
+		go r.collectResourceUsage(r.handle, stopCollection)
 
 	OUTER:
 		// Wait for updates
@@ -258,6 +438,33 @@ func (r *TaskRunner) run() {
 				if err := r.handle.Update(update); err != nil {
 					r.logger.Printf("[ERR] client: failed to update task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, err)
 				}
+				r.registerNomadServices()
+			case event := <-r.restartCh:
+				// Avoid killing the task twice
+				if destroyed || restarting {
+					continue
+				}
+
+				// Kill the task, it is started again once it exits
+				r.logger.Printf("[DEBUG] client: restarting task '%s' for alloc '%s'", r.task.Name, r.alloc.ID)
+				r.setState(structs.TaskStateRunning, event)
+				if err := r.handle.Kill(); err != nil {
+					r.logger.Printf("[ERR] client: failed to kill task '%s' for alloc '%s' to restart it: %v", r.task.Name, r.alloc.ID, err)
+				}
+				restarting = true
+			case ts := <-r.signalCh:
+				if destroyed || restarting {
+					ts.result <- fmt.Errorf("task '%s' is being killed", r.task.Name)
+					continue
+				}
+				r.setState(structs.TaskStateRunning, ts.e)
+				ts.result <- r.handle.Signal(ts.s)
+			case reply := <-r.handleCh:
+				if destroyed || restarting {
+					reply <- nil
+					continue
+				}
+				reply <- r.handle
 			case <-r.destroyCh:
 				// Avoid destroying twice
 				if destroyed {
@@ -273,15 +480,25 @@ func (r *TaskRunner) run() {
 			}
 		}
 
+		close(stopCollection)
+
 		// De-Register the services belonging to the task from consul
 		r.consulService.Deregister(r.task, r.alloc)
+		r.deregisterNomadServices()
 
 		// If the user destroyed the task, we do not attempt to do any restarts.
 		if destroyed {
-			r.setState(structs.TaskStateDead, structs.NewTaskEvent(structs.TaskKilled).SetKillError(destroyErr))
+			r.setState(structs.TaskStateDead, r.destroyEvent(destroyErr))
 			return
 		}
 
+		// Restart the task immediately if the user asked for it, without
+		// counting it against the restart policy.
+		if restarting {
+			forceStart = true
+			continue
+		}
+
 		// Log whether the task was successful or not.
 		if !waitRes.Successful() {
 			r.logger.Printf("[ERR] client: failed to complete task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, waitRes)
@@ -302,11 +519,25 @@ func (r *TaskRunner) run() {
 		r.logger.Printf("[DEBUG] client: Sleeping for %v before restarting Task %v", when, r.task.Name)
 		r.setState(structs.TaskStatePending, waitEvent)
 
-		// Sleep but watch for destroy events.
-		select {
-		case <-time.After(when):
-		case <-r.destroyCh:
+		// Sleep but watch for destroy events and restart requests.
+		timer := time.NewTimer(when)
+	WAIT:
+		for {
+			select {
+			case <-timer.C:
+				break WAIT
+			case event := <-r.restartCh:
+				r.setState(structs.TaskStatePending, event)
+				break WAIT
+			case ts := <-r.signalCh:
+				ts.result <- fmt.Errorf("task '%s' is not running", r.task.Name)
+			case reply := <-r.handleCh:
+				reply <- nil
+			case <-r.destroyCh:
+				break WAIT
+			}
 		}
+		timer.Stop()
 
 		// Destroyed while we were waiting to restart, so abort.
 		r.destroyLock.Lock()
@@ -314,7 +545,7 @@ func (r *TaskRunner) run() {
 		r.destroyLock.Unlock()
 		if destroyed {
 			r.logger.Printf("[DEBUG] client: Not restarting task: %v because it's destroyed by user", r.task.Name)
-			r.setState(structs.TaskStateDead, structs.NewTaskEvent(structs.TaskKilled))
+			r.setState(structs.TaskStateDead, r.destroyEvent(nil))
 			return
 		}
 
@@ -325,6 +556,16 @@ func (r *TaskRunner) run() {
 	return
 }
 
+// destroyEvent returns the event recording that the task was killed
+func (r *TaskRunner) destroyEvent(err error) *structs.TaskEvent {
+	r.destroyLock.Lock()
+	defer r.destroyLock.Unlock()
+	if r.killEvent != nil {
+		return r.killEvent.SetKillError(err)
+	}
+	return structs.NewTaskEvent(structs.TaskKilled).SetKillError(err)
+}
+
 // Helper function for converting a WaitResult into a TaskTerminated event.
 func (r *TaskRunner) waitErrorToEvent(res *cstructs.WaitResult) *structs.TaskEvent {
 	return structs.NewTaskEvent(structs.TaskTerminated).
@@ -343,6 +584,122 @@ func (r *TaskRunner) Update(update *structs.Task) {
 	}
 }
 
+// Restart is used to restart the task. The restart does not count against
+// the restart policy of the task.
+func (r *TaskRunner) Restart(reason string) error {
+	event := structs.NewTaskEvent(structs.TaskRestartSignal).SetRestartReason(reason)
+	select {
+	case r.restartCh <- event:
+		return nil
+	case <-r.waitCh:
+		return fmt.Errorf("task '%s' is not running", r.task.Name)
+	}
+}
+
+// Signal is used to send a signal to the task
+func (r *TaskRunner) Signal(name string, s os.Signal) error {
+	ts := &taskSignal{
+		s:      s,
+		e:      structs.NewTaskEvent(structs.TaskSignaling).SetTaskSignal(name),
+		result: make(chan error, 1),
+	}
+	select {
+	case r.signalCh <- ts:
+	case <-r.waitCh:
+		return fmt.Errorf("task '%s' is not running", r.task.Name)
+	}
+	return <-ts.result
+}
+
+// Exec runs a command inside the task and returns its exit code
+func (r *TaskRunner) Exec(opts *cstructs.ExecOptions) (int, error) {
+	reply := make(chan driver.DriverHandle, 1)
+	select {
+	case r.handleCh <- reply:
+	case <-r.waitCh:
+		return 0, fmt.Errorf("task '%s' is not running", r.task.Name)
+	}
+
+	handle := <-reply
+	if handle == nil {
+		return 0, fmt.Errorf("task '%s' is not running", r.task.Name)
+	}
+
+	r.logger.Printf("[INFO] client: exec %q in task '%s' for alloc '%s'", opts.Command, r.task.Name, r.alloc.ID)
+	return handle.Exec(opts)
+}
+
+// ResourceUsage returns the samples of the resource usage of the task, oldest
+// first
+func (r *TaskRunner) ResourceUsage() []*cstructs.TaskResourceUsage {
+	r.resourceUsageLock.RLock()
+	values := r.resourceUsage.Values()
+	r.resourceUsageLock.RUnlock()
+
+	samples := make([]*cstructs.TaskResourceUsage, len(values))
+	for i, v := range values {
+		samples[i] = v.(*cstructs.TaskResourceUsage)
+	}
+	return samples
+}
+
+// collectResourceUsage samples the resource usage of the task on an interval
+// until the stop channel is closed
+func (r *TaskRunner) collectResourceUsage(handle driver.DriverHandle, stopCh <-chan struct{}) {
+	// The CPU usage is converted to MHz using the frequency of the cores
+	mhz, _ := strconv.ParseFloat(r.node().Attributes["cpu.frequency"], 64)
+
+	ticker := time.NewTicker(statsCollectionInterval)
+	defer ticker.Stop()
+
+	var last *cstructs.TaskResourceUsage
+	for {
+		select {
+		case <-ticker.C:
+			usage, err := handle.Stats()
+			if err != nil {
+				r.logger.Printf("[DEBUG] client: failed to collect resource usage of task '%s' for alloc '%s': %v",
+					r.task.Name, r.alloc.ID, err)
+				continue
+			}
+
+			sample := &cstructs.TaskResourceUsage{
+				ResourceUsage: usage,
+				Timestamp:     time.Now().UnixNano(),
+			}
+			computeCpuUsage(last, sample, mhz)
+			last = sample
+
+			r.resourceUsageLock.Lock()
+			r.resourceUsage.Enqueue(sample)
+			r.resourceUsageLock.Unlock()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// computeCpuUsage sets the CPU usage of a sample from the CPU time consumed
+// since the previous sample, given the frequency of the cores in MHz
+func computeCpuUsage(prev, cur *cstructs.TaskResourceUsage, mhz float64) {
+	if prev == nil || prev.ResourceUsage.CpuStats == nil || cur.ResourceUsage.CpuStats == nil {
+		return
+	}
+	elapsed := time.Duration(cur.Timestamp - prev.Timestamp).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	p, c := prev.ResourceUsage.CpuStats, cur.ResourceUsage.CpuStats
+	used := (c.SystemMode + c.UserMode) - (p.SystemMode + p.UserMode)
+	if used < 0 {
+		// The task restarted its processes
+		return
+	}
+	c.Percent = used / elapsed * 100
+	c.TotalTicks = c.Percent / 100 * mhz
+}
+
 // Destroy is used to indicate that the task context should be destroyed
 func (r *TaskRunner) Destroy() {
 	r.destroyLock.Lock()
@@ -354,3 +711,17 @@ func (r *TaskRunner) Destroy() {
 	r.destroy = true
 	close(r.destroyCh)
 }
+
+// Kill is used to kill the task without restarting it, recording the event as
+// the reason it was killed
+func (r *TaskRunner) Kill(event *structs.TaskEvent) {
+	r.destroyLock.Lock()
+	defer r.destroyLock.Unlock()
+
+	if r.destroy {
+		return
+	}
+	r.killEvent = event
+	r.destroy = true
+	close(r.destroyCh)
+}
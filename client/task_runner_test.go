@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	ctestutil "github.com/hashicorp/nomad/client/testutil"
 )
 
@@ -49,7 +51,7 @@ func testTaskRunner(restarts bool) (*MockTaskStateUpdater, *TaskRunner) {
 	}
 
 	state := alloc.TaskStates[task.Name]
-	tr := NewTaskRunner(logger, conf, upd.Update, ctx, mock.Alloc(), task, state, restartTracker, consulClient)
+	tr := NewTaskRunner(logger, conf, upd.Update, ctx, mock.Alloc(), task, state, restartTracker, consulClient, nil, nil, conf.Node.Copy)
 	return upd, tr
 }
 
@@ -123,6 +125,85 @@ func TestTaskRunner_Destroy(t *testing.T) {
 
 }
 
+func TestTaskRunner_Restart(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	_, tr := testTaskRunner(false)
+	defer tr.ctx.AllocDir.Destroy()
+
+	// Change command to ensure we run for a bit
+	tr.task.Config["command"] = "/bin/sleep"
+	tr.task.Config["args"] = []string{"10"}
+	go tr.Run()
+	defer tr.Destroy()
+
+	// Restart the task once it is running
+	testutil.WaitForResult(func() (bool, error) {
+		if err := tr.Restart("test"); err != nil {
+			return false, err
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// The task is started again even though restarts are disabled
+	testutil.WaitForResult(func() (bool, error) {
+		events := tr.state.Events
+		if len(events) != 3 {
+			return false, fmt.Errorf("should have 3 updates: %#v", events)
+		}
+		if events[1].Type != structs.TaskRestartSignal {
+			return false, fmt.Errorf("Second Event was %v; want %v", events[1].Type, structs.TaskRestartSignal)
+		}
+		if events[2].Type != structs.TaskStarted {
+			return false, fmt.Errorf("Third Event was %v; want %v", events[2].Type, structs.TaskStarted)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestTaskRunner_Signal(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	_, tr := testTaskRunner(false)
+	defer tr.ctx.AllocDir.Destroy()
+
+	// Change command to ensure we run for a bit
+	tr.task.Config["command"] = "/bin/sleep"
+	tr.task.Config["args"] = []string{"10"}
+	go tr.Run()
+	defer tr.Destroy()
+
+	// Kill the task with a signal
+	testutil.WaitForResult(func() (bool, error) {
+		if err := tr.Signal("SIGKILL", os.Kill); err != nil {
+			return false, err
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	select {
+	case <-tr.WaitCh():
+	case <-time.After(time.Duration(testutil.TestMultiplier()*15) * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	if tr.state.Events[1].Type != structs.TaskSignaling {
+		t.Fatalf("Second Event was %v; want %v", tr.state.Events[1].Type, structs.TaskSignaling)
+	}
+	if tr.state.State != structs.TaskStateDead {
+		t.Fatalf("TaskState %v; want %v", tr.state.State, structs.TaskStateDead)
+	}
+
+	// Signaling a dead task fails
+	if err := tr.Signal("SIGKILL", os.Kill); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
 func TestTaskRunner_Update(t *testing.T) {
 	ctestutil.ExecCompatible(t)
 	_, tr := testTaskRunner(false)
@@ -168,7 +249,7 @@ func TestTaskRunner_SaveRestoreState(t *testing.T) {
 	consulClient, _ := NewConsulService(&consulServiceConfig{tr.logger, "127.0.0.1:8500", "", "", false, false, &structs.Node{}})
 	tr2 := NewTaskRunner(tr.logger, tr.config, upd.Update,
 		tr.ctx, tr.alloc, &structs.Task{Name: tr.task.Name}, tr.state, tr.restartTracker,
-		consulClient)
+		consulClient, nil, nil, tr.config.Node.Copy)
 	if err := tr2.RestoreState(); err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -182,3 +263,76 @@ func TestTaskRunner_SaveRestoreState(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	})
 }
+
+func TestTaskRunner_RenderVariables(t *testing.T) {
+	_, tr := testTaskRunner(false)
+	defer tr.ctx.AllocDir.Destroy()
+
+	tr.task.Env = map[string]string{"FOO": "bar"}
+	tr.task.Variables = []*structs.TaskVariable{
+		{Path: "app/db", Env: true},
+		{Path: "app/tls", Destination: "secrets/tls.json"},
+	}
+	tr.variables = func(path string) (*structs.Variable, error) {
+		return &structs.Variable{
+			Path:  path,
+			Items: map[string]string{"SECRET": path},
+		}, nil
+	}
+
+	task, err := tr.renderVariables()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The environment variables are merged into a copy of the task
+	if task.Env["SECRET"] != "app/db" || task.Env["FOO"] != "bar" {
+		t.Fatalf("bad: %#v", task.Env)
+	}
+	if _, ok := tr.task.Env["SECRET"]; ok {
+		t.Fatalf("task should not be modified: %#v", tr.task.Env)
+	}
+
+	// The file variables are written to the task directory
+	path := filepath.Join(tr.ctx.AllocDir.TaskDirs[tr.task.Name], "secrets", "tls.json")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(data) != `{"SECRET":"app/tls"}` {
+		t.Fatalf("bad: %s", data)
+	}
+
+	// Failing to read a variable fails the task
+	tr.variables = func(path string) (*structs.Variable, error) {
+		return nil, fmt.Errorf("variable %q not found", path)
+	}
+	if _, err := tr.renderVariables(); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestComputeCpuUsage(t *testing.T) {
+	sample := func(ts time.Duration, system, user float64) *cstructs.TaskResourceUsage {
+		return &cstructs.TaskResourceUsage{
+			ResourceUsage: &cstructs.ResourceUsage{
+				CpuStats: &cstructs.CpuStats{SystemMode: system, UserMode: user},
+			},
+			Timestamp: int64(ts),
+		}
+	}
+
+	// The first sample has no usage
+	first := sample(0, 1, 2)
+	computeCpuUsage(nil, first, 2000)
+	if first.ResourceUsage.CpuStats.Percent != 0 {
+		t.Fatalf("bad: %#v", first.ResourceUsage.CpuStats)
+	}
+
+	// Half a second of CPU time over two seconds
+	second := sample(2*time.Second, 1.5, 2.5)
+	computeCpuUsage(first, second, 2000)
+	if cpu := second.ResourceUsage.CpuStats; cpu.Percent != 50 || cpu.TotalTicks != 1000 {
+		t.Fatalf("bad: %#v", cpu)
+	}
+}
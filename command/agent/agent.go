@@ -1,12 +1,14 @@
 package agent
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
 	"time"
 
@@ -28,6 +30,14 @@ type Agent struct {
 	server *nomad.Server
 	client *client.Client
 
+	// metrics is the in-memory sink served by the metrics endpoint. It is
+	// nil if telemetry was not set up.
+	metrics *MetricsSink
+
+	// auditor records the state-changing HTTP requests. It is nil if the
+	// audit log is disabled.
+	auditor *Auditor
+
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
@@ -56,6 +66,10 @@ func NewAgent(config *Config, logOutput io.Writer) (*Agent, error) {
 	if a.client == nil && a.server == nil {
 		return nil, fmt.Errorf("must have at least client or server mode enabled")
 	}
+	if err := a.setupAudit(); err != nil {
+		a.Shutdown()
+		return nil, err
+	}
 	return a, nil
 }
 
@@ -146,6 +160,47 @@ func (a *Agent) serverConfig() (*nomad.Config, error) {
 		conf.NodeGCThreshold = dur
 	}
 
+	if kek := a.config.Server.KeyringEncryptionKey; kek != "" {
+		key, err := base64.StdEncoding.DecodeString(kek)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode keyring_encryption_key: %v", err)
+		}
+		conf.KeyringEncryptionKey = key
+	}
+
+	if admission := a.config.Server.Admission; admission != nil {
+		conf.Admission = &nomad.AdmissionConfig{
+			DefaultMeta:     admission.DefaultMeta,
+			RequiredMeta:    admission.RequiredMeta,
+			MaxTaskMemoryMB: admission.MaxTaskMemoryMB,
+			DenyPrivileged:  admission.DenyPrivileged,
+		}
+		for _, hook := range admission.Webhooks {
+			webhook := &nomad.AdmissionWebhook{
+				Name:     hook.Name,
+				Type:     hook.Type,
+				URL:      hook.URL,
+				FailOpen: hook.FailOpen,
+			}
+			switch hook.Type {
+			case nomad.AdmissionWebhookMutator, nomad.AdmissionWebhookValidator:
+			default:
+				return nil, fmt.Errorf("admission webhook %q has invalid type %q", hook.Name, hook.Type)
+			}
+			if hook.URL == "" {
+				return nil, fmt.Errorf("admission webhook %q is missing a url", hook.Name)
+			}
+			if hook.Timeout != "" {
+				dur, err := time.ParseDuration(hook.Timeout)
+				if err != nil {
+					return nil, fmt.Errorf("admission webhook %q has invalid timeout: %v", hook.Name, err)
+				}
+				webhook.Timeout = dur
+			}
+			conf.Admission.Webhooks = append(conf.Admission.Webhooks, webhook)
+		}
+	}
+
 	return conf, nil
 }
 
@@ -215,6 +270,34 @@ func (a *Agent) setupClient() error {
 		}
 		conf.MaxKillTimeout = dur
 	}
+	if a.config.Client.GCInterval != "" {
+		dur, err := time.ParseDuration(a.config.Client.GCInterval)
+		if err != nil {
+			return fmt.Errorf("Error parsing gc interval: %s", err)
+		}
+		conf.GCInterval = dur
+	}
+	if a.config.Client.GCDiskUsageThreshold != 0 {
+		conf.GCDiskUsageThreshold = float64(a.config.Client.GCDiskUsageThreshold)
+	}
+	if a.config.Client.GCInodeUsageThreshold != 0 {
+		conf.GCInodeUsageThreshold = float64(a.config.Client.GCInodeUsageThreshold)
+	}
+	if a.config.Client.GCMaxAllocs != 0 {
+		conf.GCMaxAllocs = a.config.Client.GCMaxAllocs
+	}
+
+	// Later host volumes override the earlier ones of the same name
+	if len(a.config.Client.HostVolumes) != 0 {
+		conf.HostVolumes = make(map[string]*structs.ClientHostVolumeConfig, len(a.config.Client.HostVolumes))
+		for _, volume := range a.config.Client.HostVolumes {
+			conf.HostVolumes[volume.Name] = &structs.ClientHostVolumeConfig{
+				Name:     volume.Name,
+				Path:     volume.Path,
+				ReadOnly: volume.ReadOnly,
+			}
+		}
+	}
 
 	// Setup the node
 	conf.Node = new(structs.Node)
@@ -247,6 +330,29 @@ func (a *Agent) setupClient() error {
 	return nil
 }
 
+// Reload applies the changes of the config that do not require restarting
+// the client: its servers and the meta of its node.
+func (a *Agent) Reload(config *Config) error {
+	if a.client == nil {
+		return nil
+	}
+
+	if !reflect.DeepEqual(a.config.Client.Servers, config.Client.Servers) {
+		a.client.SetServers(config.Client.Servers)
+		a.config.Client.Servers = config.Client.Servers
+		a.logger.Printf("[INFO] agent: reloaded client servers: %v", config.Client.Servers)
+	}
+
+	if !reflect.DeepEqual(a.config.Client.Meta, config.Client.Meta) {
+		if err := a.client.SetConfigMeta(config.Client.Meta); err != nil {
+			return err
+		}
+		a.config.Client.Meta = config.Client.Meta
+		a.logger.Printf("[INFO] agent: reloaded client meta")
+	}
+	return nil
+}
+
 // Leave is used gracefully exit. Clients will inform servers
 // of their departure so that allocations can be rescheduled.
 func (a *Agent) Leave() error {
@@ -263,6 +369,19 @@ func (a *Agent) Leave() error {
 	return nil
 }
 
+// setupAudit is used to set up the audit log of the HTTP API
+func (a *Agent) setupAudit() error {
+	if a.config.Audit == nil || !a.config.Audit.Enabled {
+		return nil
+	}
+	auditor, err := NewAuditor(a.config.Audit, a.config.DataDir, a.logger)
+	if err != nil {
+		return fmt.Errorf("audit setup failed: %v", err)
+	}
+	a.auditor = auditor
+	return nil
+}
+
 // Shutdown is used to terminate the agent.
 func (a *Agent) Shutdown() error {
 	a.shutdownLock.Lock()
@@ -284,6 +403,12 @@ func (a *Agent) Shutdown() error {
 		}
 	}
 
+	if a.auditor != nil {
+		if err := a.auditor.Close(); err != nil {
+			a.logger.Printf("[ERR] agent: failed to close audit log: %v", err)
+		}
+	}
+
 	a.logger.Println("[INFO] agent: shutdown complete")
 	a.shutdown = true
 	close(a.shutdownCh)
@@ -159,6 +159,19 @@ func TestAgent_ServerConfig(t *testing.T) {
 		t.Fatalf("expect 10s, got: %s", threshold)
 	}
 
+	conf.Server.KeyringEncryptionKey = "not base64"
+	if _, err = a.serverConfig(); err == nil || !strings.Contains(err.Error(), "keyring_encryption_key") {
+		t.Fatalf("expected keyring_encryption_key error, got: %#v", err)
+	}
+	conf.Server.KeyringEncryptionKey = "Ez1Nn0t8RMv4TJrg3vz3PQmF84SYRMwOaSmODXKn6X0="
+	out, err = a.serverConfig()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n := len(out.KeyringEncryptionKey); n != 32 {
+		t.Fatalf("expect a 32 byte key, got %d bytes", n)
+	}
+
 	// Defaults to the global bind addr
 	conf.Addresses.RPC = ""
 	conf.Addresses.Serf = ""
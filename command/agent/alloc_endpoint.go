@@ -1,12 +1,23 @@
 package agent
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
+const (
+	// execUpgradeProtocol is the protocol exec connections are upgraded to
+	execUpgradeProtocol = "nomad-exec"
+)
+
 func (s *HTTPServer) AllocsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if req.Method != "GET" {
 		return nil, CodedError(405, ErrInvalidMethod)
@@ -31,6 +42,9 @@ func (s *HTTPServer) AllocsRequest(resp http.ResponseWriter, req *http.Request)
 
 func (s *HTTPServer) AllocSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	allocID := strings.TrimPrefix(req.URL.Path, "/v1/allocation/")
+	if strings.HasSuffix(allocID, "/stop") {
+		return s.allocStop(resp, req, strings.TrimSuffix(allocID, "/stop"))
+	}
 	if req.Method != "GET" {
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
@@ -53,3 +67,231 @@ func (s *HTTPServer) AllocSpecificRequest(resp http.ResponseWriter, req *http.Re
 	}
 	return out.Alloc, nil
 }
+
+func (s *HTTPServer) allocStop(resp http.ResponseWriter, req *http.Request,
+	allocID string) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	args := structs.AllocStopRequest{
+		AllocID: allocID,
+	}
+	s.parseRegion(req, &args.Region)
+
+	var out structs.AllocStopResponse
+	if err := s.agent.RPC("Alloc.Stop", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
+// ClientAllocRequest is used to restart, signal and exec into the tasks of an
+// allocation running on the client, and to read their resource usage
+func (s *HTTPServer) ClientAllocRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/client/allocation/")
+	if strings.HasSuffix(path, "/stats") {
+		if req.Method != "GET" {
+			return nil, CodedError(405, ErrInvalidMethod)
+		}
+		allocID := strings.TrimSuffix(path, "/stats")
+		return s.allocStats(req, allocID, req.URL.Query().Get("task"))
+	}
+
+	client := s.agent.Client()
+	if client == nil {
+		return nil, CodedError(501, ErrInvalidMethod)
+	}
+
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	switch {
+	case strings.HasSuffix(path, "/restart"):
+		allocID := strings.TrimSuffix(path, "/restart")
+		var args AllocRestartRequest
+		if err := decodeBody(req, &args); err != nil && err != io.EOF {
+			return nil, CodedError(400, err.Error())
+		}
+		return nil, client.RestartAllocation(allocID, args.TaskName)
+	case strings.HasSuffix(path, "/signal"):
+		allocID := strings.TrimSuffix(path, "/signal")
+		var args AllocSignalRequest
+		if err := decodeBody(req, &args); err != nil && err != io.EOF {
+			return nil, CodedError(400, err.Error())
+		}
+		return nil, client.SignalAllocation(allocID, args.TaskName, args.Signal)
+	case strings.HasSuffix(path, "/exec"):
+		allocID := strings.TrimSuffix(path, "/exec")
+		return nil, s.allocExec(resp, req, allocID)
+	default:
+		return nil, CodedError(404, ErrInvalidMethod)
+	}
+}
+
+// AllocRestartRequest is the body of a restart request. All the tasks of the
+// allocation are restarted if no task is given.
+type AllocRestartRequest struct {
+	TaskName string
+}
+
+// AllocSignalRequest is the body of a signal request. The signal is sent to
+// all the tasks of the allocation if no task is given, and defaults to
+// SIGKILL.
+type AllocSignalRequest struct {
+	TaskName string
+	Signal   string
+}
+
+// ExecFrame is a frame of an exec session. Once the connection is upgraded,
+// frames are encoded in JSON in both directions. The user sends the input and
+// the size of its terminal, and the agent sends the output of the command
+// until it exits or fails.
+type ExecFrame struct {
+	Stdin       []byte                 `json:",omitempty"`
+	StdinClosed bool                   `json:",omitempty"`
+	TtySize     *cstructs.TerminalSize `json:",omitempty"`
+	Stdout      []byte                 `json:",omitempty"`
+	Stderr      []byte                 `json:",omitempty"`
+	Exited      bool                   `json:",omitempty"`
+	ExitCode    int                    `json:",omitempty"`
+	Error       string                 `json:",omitempty"`
+}
+
+// allocExec runs a command inside a task of an allocation. The task, the
+// command as a JSON array and whether to allocate a tty are given as query
+// parameters. The connection is then upgraded and carries ExecFrames.
+func (s *HTTPServer) allocExec(resp http.ResponseWriter, req *http.Request, allocID string) error {
+	query := req.URL.Query()
+	var command []string
+	if err := json.Unmarshal([]byte(query.Get("command")), &command); err != nil || len(command) == 0 {
+		return CodedError(400, "command must be a non-empty JSON array")
+	}
+	tty := false
+	if v := query.Get("tty"); v != "" {
+		var err error
+		if tty, err = strconv.ParseBool(v); err != nil {
+			return CodedError(400, fmt.Sprintf("invalid tty: %v", err))
+		}
+	}
+
+	hijacker, ok := resp.(http.Hijacker)
+	if !ok {
+		return CodedError(500, "connection can not be upgraded")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// From now on errors are reported to the user in a frame
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Upgrade: " + execUpgradeProtocol + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		s.logger.Printf("[ERR] http: failed to upgrade exec connection: %v", err)
+		return nil
+	}
+
+	var sendLock sync.Mutex
+	enc := json.NewEncoder(rw)
+	send := func(frame *ExecFrame) error {
+		sendLock.Lock()
+		defer sendLock.Unlock()
+		if err := enc.Encode(frame); err != nil {
+			return err
+		}
+		return rw.Flush()
+	}
+
+	// Forward the input of the user until the connection is closed, which
+	// cancels the session as the user is gone
+	stdinR, stdinW := io.Pipe()
+	defer stdinR.Close()
+	resizeCh := make(chan cstructs.TerminalSize)
+	cancelCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		dec := json.NewDecoder(rw)
+		for {
+			var frame ExecFrame
+			if err := dec.Decode(&frame); err != nil {
+				stdinW.CloseWithError(err)
+				close(cancelCh)
+				return
+			}
+			if len(frame.Stdin) != 0 {
+				if _, err := stdinW.Write(frame.Stdin); err != nil {
+					return
+				}
+			}
+			if frame.StdinClosed {
+				stdinW.Close()
+			}
+			if frame.TtySize != nil {
+				select {
+				case resizeCh <- *frame.TtySize:
+				case <-doneCh:
+					return
+				}
+			}
+		}
+	}()
+
+	opts := &cstructs.ExecOptions{
+		Command:  command,
+		Tty:      tty,
+		Stdin:    stdinR,
+		Stdout:   &execFrameWriter{send: send},
+		Stderr:   &execFrameWriter{send: send, stderr: true},
+		ResizeCh: resizeCh,
+		CancelCh: cancelCh,
+	}
+	code, err := s.agent.Client().ExecAllocation(allocID, query.Get("task"), opts)
+
+	result := &ExecFrame{Exited: true, ExitCode: code}
+	if err != nil {
+		result = &ExecFrame{Error: err.Error()}
+	}
+	if err := send(result); err != nil {
+		s.logger.Printf("[ERR] http: failed to send result of exec: %v", err)
+	}
+	return nil
+}
+
+// execFrameWriter sends what is written to it as the output of an exec
+// session
+type execFrameWriter struct {
+	send   func(*ExecFrame) error
+	stderr bool
+}
+
+func (w *execFrameWriter) Write(p []byte) (int, error) {
+	frame := &ExecFrame{Stdout: p}
+	if w.stderr {
+		frame = &ExecFrame{Stderr: p}
+	}
+	if err := w.send(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// allocStats returns the resource usage of the tasks of an allocation. It is
+// read through the servers if the allocation does not run on the local
+// client.
+func (s *HTTPServer) allocStats(req *http.Request, allocID, task string) (map[string][]*cstructs.TaskResourceUsage, error) {
+	if client := s.agent.Client(); client != nil && client.HasAllocation(allocID) {
+		return client.AllocResourceUsage(allocID, task)
+	}
+
+	args := cstructs.AllocStatsRequest{AllocID: allocID, Task: task}
+	s.parseRegion(req, &args.Region)
+	var out cstructs.AllocStatsResponse
+	if err := s.agent.RPC("ClientAllocations.Stats", &args, &out); err != nil {
+		return nil, err
+	}
+	return out.Tasks, nil
+}
@@ -1,8 +1,14 @@
 package agent
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/nomad/nomad/mock"
@@ -146,3 +152,120 @@ func TestHTTP_AllocQuery(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTP_AllocStop(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		alloc := mock.Alloc()
+		if err := state.UpsertJob(999, alloc.Job); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		err := state.UpsertAllocs(1000,
+			[]*structs.Allocation{alloc})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Make the HTTP request
+		req, err := http.NewRequest("PUT", "/v1/allocation/"+alloc.ID+"/stop", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.AllocSpecificRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Check for the index
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		// Check the response
+		out := obj.(structs.AllocStopResponse)
+		if out.EvalID == "" {
+			t.Fatalf("bad: %#v", out)
+		}
+	})
+}
+
+func TestHTTP_AllocExec(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		// The command is required
+		req, err := http.NewRequest("PUT", "/v1/client/allocation/foo/exec", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+		_, err = s.Server.ClientAllocRequest(respW, req)
+		if err == nil || !strings.Contains(err.Error(), "command") {
+			t.Fatalf("expected command error, got: %v", err)
+		}
+
+		// Upgrade the connection to exec into an unknown allocation
+		addr := fmt.Sprintf("127.0.0.1:%d", s.Agent.config.Ports.HTTP)
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		defer conn.Close()
+
+		params := url.Values{}
+		params.Set("command", `["/bin/sh"]`)
+		req, err = http.NewRequest("PUT", "http://"+addr+"/v1/client/allocation/foo/exec?"+params.Encode(), nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", execUpgradeProtocol)
+		if err := req.Write(conn); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		r := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(r, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("bad status: %d", resp.StatusCode)
+		}
+
+		// The failure is reported in a frame
+		var frame ExecFrame
+		if err := json.NewDecoder(r).Decode(&frame); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !strings.Contains(frame.Error, "unknown allocation") {
+			t.Fatalf("bad: %#v", frame)
+		}
+	})
+}
+
+func TestHTTP_AllocStats(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		// Only reads are allowed
+		req, err := http.NewRequest("PUT", "/v1/client/allocation/foo/stats", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+		if _, err := s.Server.ClientAllocRequest(respW, req); err == nil {
+			t.Fatalf("expected error")
+		}
+
+		// The allocation must be known by the client
+		req, err = http.NewRequest("GET", "/v1/client/allocation/foo/stats", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		_, err = s.Server.ClientAllocRequest(respW, req)
+		if err == nil || !strings.Contains(err.Error(), "unknown allocation") {
+			t.Fatalf("expected unknown allocation error, got: %v", err)
+		}
+	})
+}
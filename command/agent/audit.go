@@ -0,0 +1,437 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// defaultAuditRotateBytes is the size after which the audit file is
+	// rotated if not configured
+	defaultAuditRotateBytes = 100 * 1024 * 1024
+
+	// defaultAuditRotateMaxFiles is the number of rotated audit files kept
+	// if not configured
+	defaultAuditRotateMaxFiles = 10
+
+	// auditRedacted replaces the value of the redacted body fields
+	auditRedacted = "[redacted]"
+)
+
+// AuditEntry is a single line of the audit log
+type AuditEntry struct {
+	Timestamp  time.Time
+	RequestID  string
+	RemoteAddr string
+	Method     string
+	Path       string
+	JobID      string `json:",omitempty"`
+	NodeID     string `json:",omitempty"`
+	Status     int
+	BodyHash   string      `json:",omitempty"`
+	Body       interface{} `json:",omitempty"`
+}
+
+// Auditor writes an audit entry for every state-changing request made to
+// the HTTP API
+type Auditor struct {
+	config *AuditConfig
+	sink   *auditFileSink
+	logger *log.Logger
+
+	redact map[string]struct{}
+	hash   map[string]struct{}
+}
+
+// NewAuditor returns an auditor writing to the file configured in the audit
+// config, which defaults to a file in the data directory.
+func NewAuditor(config *AuditConfig, dataDir string, logger *log.Logger) (*Auditor, error) {
+	path := config.Path
+	if path == "" {
+		if dataDir == "" {
+			return nil, fmt.Errorf("audit log requires a path or a data directory")
+		}
+		path = filepath.Join(dataDir, "audit", "audit.log")
+	}
+
+	rotateBytes := config.RotateBytes
+	if rotateBytes <= 0 {
+		rotateBytes = defaultAuditRotateBytes
+	}
+	maxFiles := config.RotateMaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultAuditRotateMaxFiles
+	}
+
+	sink, err := newAuditFileSink(path, rotateBytes, maxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Auditor{
+		config: config,
+		sink:   sink,
+		logger: logger,
+		redact: make(map[string]struct{}, len(config.RedactFields)),
+		hash:   make(map[string]struct{}, len(config.HashFields)),
+	}
+	for _, field := range config.RedactFields {
+		a.redact[strings.ToLower(field)] = struct{}{}
+	}
+	for _, field := range config.HashFields {
+		a.hash[strings.ToLower(field)] = struct{}{}
+	}
+	return a, nil
+}
+
+// Audited returns whether the request is recorded in the audit log. Only
+// requests that can change the state of the cluster are.
+func (a *Auditor) Audited(req *http.Request) bool {
+	if req.Method == "GET" || req.Method == "HEAD" || req.Method == "OPTIONS" {
+		return false
+	}
+	for _, filter := range a.config.Filters {
+		if filter.matches(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns whether the request matches both the paths and the
+// methods of the filter
+func (f *AuditFilter) matches(req *http.Request) bool {
+	return matchesAny(f.Paths, req.URL.Path, true) && matchesAny(f.Methods, req.Method, false)
+}
+
+func matchesAny(patterns []string, value string, prefix bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if prefix && strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(value, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if strings.EqualFold(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Track starts auditing the request. It returns the response writer the
+// handler must use and a function writing the audit entry, which must be
+// called once the response is written.
+func (a *Auditor) Track(resp http.ResponseWriter, req *http.Request) (http.ResponseWriter, func()) {
+	entry := &AuditEntry{
+		Timestamp:  time.Now().UTC(),
+		RequestID:  structs.GenerateUUID(),
+		RemoteAddr: req.RemoteAddr,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+	}
+	resp.Header().Set("X-Nomad-Request-ID", entry.RequestID)
+
+	// Read the body so it can be recorded and give the handler a copy
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			a.logger.Printf("[ERR] http.audit: failed to read body of request %s: %v", entry.RequestID, err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	a.describe(entry, body)
+
+	recorder := &auditResponseWriter{ResponseWriter: resp}
+	finish := func() {
+		entry.Status = recorder.status
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+		if err := a.write(entry); err != nil {
+			a.logger.Printf("[ERR] http.audit: failed to write entry for request %s: %v", entry.RequestID, err)
+		}
+	}
+	return recorder, finish
+}
+
+// describe sets the job and node IDs touched by the request and the
+// recorded body on the entry
+func (a *Auditor) describe(entry *AuditEntry, body []byte) {
+	switch {
+	case strings.HasPrefix(entry.Path, "/v1/job/"):
+		entry.JobID = firstPathSegment(strings.TrimPrefix(entry.Path, "/v1/job/"))
+	case strings.HasPrefix(entry.Path, "/v1/node/"):
+		entry.NodeID = firstPathSegment(strings.TrimPrefix(entry.Path, "/v1/node/"))
+	}
+
+	if len(body) == 0 {
+		return
+	}
+	sum := sha256.Sum256(body)
+	entry.BodyHash = "sha256:" + hex.EncodeToString(sum[:])
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return
+	}
+
+	// Job registrations carry the job ID in the body
+	if entry.JobID == "" {
+		if obj, ok := decoded.(map[string]interface{}); ok {
+			if job, ok := obj["Job"].(map[string]interface{}); ok {
+				if id, ok := job["ID"].(string); ok {
+					entry.JobID = id
+				}
+			}
+		}
+	}
+
+	if a.config.LogBody {
+		entry.Body = a.sanitize(decoded)
+	}
+}
+
+func firstPathSegment(path string) string {
+	if i := strings.Index(path, "/"); i != -1 {
+		return path[:i]
+	}
+	return path
+}
+
+// sanitize replaces the values of the redacted and hashed fields of the
+// decoded body
+func (a *Auditor) sanitize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			name := strings.ToLower(key)
+			if _, ok := a.redact[name]; ok {
+				out[key] = auditRedacted
+			} else if _, ok := a.hash[name]; ok {
+				out[key] = hashValue(field)
+			} else {
+				out[key] = a.sanitize(field)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = a.sanitize(elem)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// hashValue returns the SHA256 hash of the JSON encoding of the value, so
+// that changes to it can be followed without recording it
+func hashValue(value interface{}) string {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return auditRedacted
+	}
+	sum := sha256.Sum256(buf)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// write appends the entry to the audit file as a JSON line
+func (a *Auditor) write(entry *AuditEntry) error {
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return a.sink.Write(append(buf, '\n'))
+}
+
+// Close closes the audit file
+func (a *Auditor) Close() error {
+	return a.sink.Close()
+}
+
+// auditResponseWriter records the status code of the response
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Hijack records the upgrade of the connection, which is used to stream
+// exec sessions
+func (w *auditResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response can not be hijacked")
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// Flush sends the buffered response to the client, which is used to stream
+// logs and events
+func (w *auditResponseWriter) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		return
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	flusher.Flush()
+}
+
+// CloseNotify returns a channel notified when the client goes away. If the
+// wrapped writer does not support it, the channel is never notified.
+func (w *auditResponseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+// auditFileSink appends to a file that is rotated once it reaches a size.
+// Rotated files are suffixed with their generation, the most recent being
+// ".1", and only a bounded number of them are kept.
+type auditFileSink struct {
+	path        string
+	rotateBytes int64
+	maxFiles    int
+
+	f      *os.File
+	size   int64
+	closed bool
+	l      sync.Mutex
+}
+
+func newAuditFileSink(path string, rotateBytes int64, maxFiles int) (*auditFileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %v", err)
+	}
+	s := &auditFileSink{
+		path:        path,
+		rotateBytes: rotateBytes,
+		maxFiles:    maxFiles,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open opens the audit file for appending
+func (s *auditFileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat audit file: %v", err)
+	}
+	s.f = f
+	s.size = info.Size()
+	return nil
+}
+
+// Write writes a line to the audit file, rotating it first if the line
+// would make it exceed the rotation size
+func (s *auditFileSink) Write(line []byte) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("audit file is closed")
+	}
+
+	// Reopen the file if a previous rotation failed to
+	if s.f == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if s.size > 0 && s.size+int64(len(line)) > s.rotateBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.f.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotate shifts the rotated files by one generation, dropping the oldest,
+// and starts a new audit file
+func (s *auditFileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	s.f = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxFiles))
+	for i := s.maxFiles - 1; i > 0; i-- {
+		old := fmt.Sprintf("%s.%d", s.path, i)
+		if _, err := os.Stat(old); err == nil {
+			if err := os.Rename(old, fmt.Sprintf("%s.%d", s.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// Close closes the audit file
+func (s *auditFileSink) Close() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.closed || s.f == nil {
+		s.closed = true
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	s.closed = true
+	return err
+}
@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testAuditor(t *testing.T, config *AuditConfig) (*Auditor, string) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	a, err := NewAuditor(config, dir, logger)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("err: %v", err)
+	}
+	return a, dir
+}
+
+func readAuditEntries(t *testing.T, path string) []*AuditEntry {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+
+	var entries []*AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+func TestAuditor_Track(t *testing.T) {
+	a, dir := testAuditor(t, &AuditConfig{
+		Enabled:      true,
+		LogBody:      true,
+		RedactFields: []string{"Env"},
+		HashFields:   []string{"meta"},
+	})
+	defer os.RemoveAll(dir)
+	defer a.Close()
+
+	body := `{"Job":{"ID":"web","Meta":{"owner":"ops"},"Tasks":[{"Env":{"TOKEN":"secret"}}]}}`
+	req, err := http.NewRequest("PUT", "/v1/jobs", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req.RemoteAddr = "10.0.0.1:1234"
+	respW := httptest.NewRecorder()
+
+	if !a.Audited(req) {
+		t.Fatalf("expected request to be audited")
+	}
+	resp, finish := a.Track(respW, req)
+
+	// The handler still sees the whole body
+	read, err := ioutil.ReadAll(req.Body)
+	if err != nil || string(read) != body {
+		t.Fatalf("bad: %q %v", read, err)
+	}
+	resp.WriteHeader(400)
+	finish()
+
+	entries := readAuditEntries(t, filepath.Join(dir, "audit", "audit.log"))
+	if len(entries) != 1 {
+		t.Fatalf("bad: %#v", entries)
+	}
+	entry := entries[0]
+	if entry.Method != "PUT" || entry.Path != "/v1/jobs" || entry.JobID != "web" ||
+		entry.Status != 400 || entry.RemoteAddr != "10.0.0.1:1234" || entry.BodyHash == "" {
+		t.Fatalf("bad: %#v", entry)
+	}
+	if entry.RequestID == "" || respW.Header().Get("X-Nomad-Request-ID") != entry.RequestID {
+		t.Fatalf("bad: %#v", entry)
+	}
+
+	recorded, err := json.Marshal(entry.Body)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if strings.Contains(string(recorded), "secret") || strings.Contains(string(recorded), "ops") {
+		t.Fatalf("sensitive fields recorded: %s", recorded)
+	}
+	if !strings.Contains(string(recorded), auditRedacted) || !strings.Contains(string(recorded), `"Meta":"sha256:`) {
+		t.Fatalf("bad: %s", recorded)
+	}
+}
+
+func TestAuditor_Audited(t *testing.T) {
+	a, dir := testAuditor(t, &AuditConfig{
+		Enabled: true,
+		Filters: []*AuditFilter{
+			{Paths: []string{"/v1/system/*"}},
+			{Paths: []string{"/v1/evaluation/"}, Methods: []string{"post"}},
+		},
+	})
+	defer os.RemoveAll(dir)
+	defer a.Close()
+
+	cases := []struct {
+		method  string
+		path    string
+		audited bool
+	}{
+		{"GET", "/v1/jobs", false},
+		{"PUT", "/v1/jobs", true},
+		{"DELETE", "/v1/job/web", true},
+		{"PUT", "/v1/system/gc", false},
+		{"POST", "/v1/evaluation/", false},
+		{"PUT", "/v1/evaluation/", true},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(c.method, c.path, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if a.Audited(req) != c.audited {
+			t.Fatalf("%s %s: expected audited %v", c.method, c.path, c.audited)
+		}
+	}
+}
+
+func TestAuditor_NodeID(t *testing.T) {
+	a, dir := testAuditor(t, &AuditConfig{Enabled: true})
+	defer os.RemoveAll(dir)
+	defer a.Close()
+
+	req, err := http.NewRequest("PUT", "/v1/node/1234/drain?enable=true", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, finish := a.Track(httptest.NewRecorder(), req)
+	finish()
+
+	entries := readAuditEntries(t, filepath.Join(dir, "audit", "audit.log"))
+	if len(entries) != 1 || entries[0].NodeID != "1234" || entries[0].Status != 200 {
+		t.Fatalf("bad: %#v", entries[0])
+	}
+}
+
+// closeNotifyRecorder is a response recorder supporting close notifications
+type closeNotifyRecorder struct {
+	*httptest.ResponseRecorder
+	closeCh chan bool
+}
+
+func (r *closeNotifyRecorder) CloseNotify() <-chan bool {
+	return r.closeCh
+}
+
+func TestAuditResponseWriter_Flush(t *testing.T) {
+	respW := httptest.NewRecorder()
+	w := &auditResponseWriter{ResponseWriter: respW}
+
+	var _ http.Flusher = w
+	w.Flush()
+	if !respW.Flushed || w.status != http.StatusOK {
+		t.Fatalf("bad: %v %d", respW.Flushed, w.status)
+	}
+}
+
+func TestAuditResponseWriter_CloseNotify(t *testing.T) {
+	respW := &closeNotifyRecorder{
+		ResponseRecorder: httptest.NewRecorder(),
+		closeCh:          make(chan bool, 1),
+	}
+	w := &auditResponseWriter{ResponseWriter: respW}
+
+	var _ http.CloseNotifier = w
+	respW.closeCh <- true
+	select {
+	case <-w.CloseNotify():
+	default:
+		t.Fatalf("close not notified")
+	}
+
+	// Writers without close notifications are never notified
+	w = &auditResponseWriter{ResponseWriter: httptest.NewRecorder()}
+	select {
+	case <-w.CloseNotify():
+		t.Fatalf("unexpected close notification")
+	default:
+	}
+}
+
+func TestAuditFileSink_Rotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	s, err := newAuditFileSink(path, 10, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer s.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if err := s.Write([]byte(line)); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for file, content := range expected {
+		buf, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if string(buf) != content {
+			t.Fatalf("%s: expected %q, got %q", file, content, buf)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected oldest file to be removed: %v", err)
+	}
+}
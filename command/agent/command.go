@@ -44,6 +44,15 @@ type Command struct {
 	logFilter      *logutils.LevelFilter
 	logOutput      io.Writer
 	retryJoinErrCh chan struct{}
+	metrics        *MetricsSink
+
+	// inmemSink holds the metrics dumped on SIGUSR1 and metricSinks the
+	// sinks forwarding the metrics to external servers. metricsSink is the
+	// sink of the global metrics, forwarding to all of them, whose sinks are
+	// replaced when the telemetry config is reloaded.
+	inmemSink   *metrics.InmemSink
+	metricSinks []metricSink
+	metricsSink *reloadableSink
 
 	scadaProvider *scada.Provider
 	scadaHttp     *HTTPServer
@@ -286,6 +295,7 @@ func (c *Command) setupAgent(config *Config, logOutput io.Writer) error {
 		c.Ui.Error(fmt.Sprintf("Error starting agent: %s", err))
 		return err
 	}
+	agent.metrics = c.metrics
 	c.agent = agent
 
 	// Enable the SCADA integration
@@ -515,7 +525,9 @@ WAIT:
 	}
 }
 
-// handleReload is invoked when we should reload our configs, e.g. SIGHUP
+// handleReload is invoked when we should reload our configs, e.g. SIGHUP.
+// The changes that can be applied while the agent runs are applied, and
+// the ones requiring a restart are logged.
 func (c *Command) handleReload(config *Config) *Config {
 	c.Ui.Output("Reloading configuration...")
 	newConf := c.readConfig()
@@ -523,6 +535,7 @@ func (c *Command) handleReload(config *Config) *Config {
 		c.Ui.Error(fmt.Sprintf("Failed to reload configs"))
 		return config
 	}
+	logger := c.agent.logger
 
 	// Change the log level
 	minLevel := logutils.LogLevel(strings.ToUpper(newConf.LogLevel))
@@ -536,17 +549,92 @@ func (c *Command) handleReload(config *Config) *Config {
 		// Keep the current log level
 		newConf.LogLevel = config.LogLevel
 	}
+
+	// Replace the telemetry sinks
+	if !reflect.DeepEqual(config.Telemetry, newConf.Telemetry) {
+		if err := c.setupTelementry(newConf); err != nil {
+			logger.Printf("[ERR] agent: failed to reload telemetry: %v", err)
+			newConf.Telemetry = config.Telemetry
+		} else {
+			logger.Printf("[INFO] agent: reloaded telemetry")
+		}
+	}
+
+	// Update the servers and the meta of the client
+	if err := c.agent.Reload(newConf); err != nil {
+		logger.Printf("[ERR] agent: failed to reload client: %v", err)
+	}
+
+	if changed := reloadRestartRequired(config, newConf); len(changed) != 0 {
+		logger.Printf("[WARN] agent: changes of %s require a restart of the agent to be applied",
+			strings.Join(changed, ", "))
+	}
 	return newConf
 }
 
-// setupTelementry is used ot setup the telemetry sub-systems
+// reloadRestartRequired returns the settings changed between the configs
+// that can not be applied while the agent runs
+func reloadRestartRequired(old, new *Config) []string {
+	// The servers and the meta of the client are reloaded
+	oldClient, newClient := *old.Client, *new.Client
+	oldClient.Servers, newClient.Servers = nil, nil
+	oldClient.Meta, newClient.Meta = nil, nil
+
+	// The sinks and the hostname of the telemetry are reloaded, but not the
+	// global metrics nor the sink of the metrics endpoint
+	var oldTelemetry, newTelemetry Telemetry
+	if old.Telemetry != nil {
+		oldTelemetry = *old.Telemetry
+	}
+	if new.Telemetry != nil {
+		newTelemetry = *new.Telemetry
+	}
+
+	settings := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"region", old.Region, new.Region},
+		{"datacenter", old.Datacenter, new.Datacenter},
+		{"name", old.NodeName, new.NodeName},
+		{"data_dir", old.DataDir, new.DataDir},
+		{"bind_addr", old.BindAddr, new.BindAddr},
+		{"enable_debug", old.EnableDebug, new.EnableDebug},
+		{"ports", old.Ports, new.Ports},
+		{"addresses", old.Addresses, new.Addresses},
+		{"advertise", old.AdvertiseAddrs, new.AdvertiseAddrs},
+		{"enable_syslog", old.EnableSyslog, new.EnableSyslog},
+		{"syslog_facility", old.SyslogFacility, new.SyslogFacility},
+		{"disable_update_check", old.DisableUpdateCheck, new.DisableUpdateCheck},
+		{"atlas", old.Atlas, new.Atlas},
+		{"audit", old.Audit, new.Audit},
+		{"server", old.Server, new.Server},
+		{"client", oldClient, newClient},
+		{"telemetry.disable_runtime_metrics", oldTelemetry.DisableRuntimeMetrics, newTelemetry.DisableRuntimeMetrics},
+		{"telemetry.disable_metrics_endpoint", oldTelemetry.DisableMetricsEndpoint, newTelemetry.DisableMetricsEndpoint},
+	}
+
+	var changed []string
+	for _, s := range settings {
+		if !reflect.DeepEqual(s.old, s.new) {
+			changed = append(changed, s.name)
+		}
+	}
+	return changed
+}
+
+// setupTelementry is used ot setup the telemetry sub-systems. The global
+// metrics are only created the first time; when the config is reloaded only
+// the sinks forwarding the metrics to external servers are replaced.
 func (c *Command) setupTelementry(config *Config) error {
 	/* Setup telemetry
 	Aggregate on 10 second intervals for 1 minute. Expose the
 	metrics over stderr when there is a SIGUSR1 received.
 	*/
-	inm := metrics.NewInmemSink(10*time.Second, time.Minute)
-	metrics.DefaultInmemSignal(inm)
+	if c.inmemSink == nil {
+		c.inmemSink = metrics.NewInmemSink(10*time.Second, time.Minute)
+		metrics.DefaultInmemSignal(c.inmemSink)
+	}
 
 	var telConfig *Telemetry
 	if config.Telemetry == nil {
@@ -555,39 +643,73 @@ func (c *Command) setupTelementry(config *Config) error {
 		telConfig = config.Telemetry
 	}
 
-	metricsConf := metrics.DefaultConfig("nomad")
-	metricsConf.EnableHostname = !telConfig.DisableHostname
+	// Keep the totals of the metrics since startup for the metrics endpoint
+	if c.metrics == nil && !telConfig.DisableMetricsEndpoint {
+		c.metrics = NewMetricsSink()
+	}
+	var fanout metrics.FanoutSink
+	var sinks []metricSink
 
 	// Configure the statsite sink
-	var fanout metrics.FanoutSink
 	if telConfig.StatsiteAddr != "" {
 		sink, err := metrics.NewStatsiteSink(telConfig.StatsiteAddr)
 		if err != nil {
 			return err
 		}
 		fanout = append(fanout, sink)
+		sinks = append(sinks, sink)
 	}
 
 	// Configure the statsd sink
 	if telConfig.StatsdAddr != "" {
 		sink, err := metrics.NewStatsdSink(telConfig.StatsdAddr)
 		if err != nil {
+			for _, s := range sinks {
+				s.Shutdown()
+			}
 			return err
 		}
 		fanout = append(fanout, sink)
+		sinks = append(sinks, sink)
+	}
+	fanout = append(fanout, c.inmemSink)
+
+	// The hostname prefixes the keys of the gauges forwarded to the sinks.
+	// It is applied by the reloadable sink so that it can be reloaded.
+	metricsConf := metrics.DefaultConfig("nomad")
+	metricsConf.EnableHostname = false
+	metricsConf.EnableRuntimeMetrics = !telConfig.DisableRuntimeMetrics
+	var hostname string
+	if !telConfig.DisableHostname {
+		hostname = metricsConf.HostName
+	}
+
+	// Replace the sinks of the running metrics. The replaced sinks are only
+	// stopped once nothing writes to them anymore.
+	if c.metricsSink != nil {
+		c.metricsSink.Swap(fanout, hostname)
+		for _, s := range c.metricSinks {
+			s.Shutdown()
+		}
+		c.metricSinks = sinks
+		return nil
 	}
 
 	// Initialize the global sink
-	if len(fanout) > 0 {
-		fanout = append(fanout, inm)
-		metrics.NewGlobal(metricsConf, fanout)
-	} else {
-		metricsConf.EnableHostname = false
-		metrics.NewGlobal(metricsConf, inm)
+	c.metricsSink = newReloadableSink(fanout, hostname, c.metrics)
+	if _, err := metrics.NewGlobal(metricsConf, c.metricsSink); err != nil {
+		return err
 	}
+	c.metricSinks = sinks
 	return nil
 }
 
+// metricSink is a sink forwarding the metrics to an external server
+type metricSink interface {
+	metrics.MetricSink
+	Shutdown()
+}
+
 // setupSCADA is used to start a new SCADA provider and listener,
 // replacing any existing listeners.
 func (c *Command) setupSCADA(config *Config) error {
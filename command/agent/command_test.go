@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/armon/go-metrics"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/mitchellh/cli"
 )
@@ -126,3 +128,73 @@ func TestRetryJoin(t *testing.T) {
 		t.Fatalf(err.Error())
 	})
 }
+
+func TestReloadRestartRequired(t *testing.T) {
+	old := DefaultConfig()
+	old.Client.Servers = []string{"127.0.0.1:4647"}
+
+	// The log level, servers, meta and telemetry are reloaded
+	reloaded := DefaultConfig()
+	reloaded.LogLevel = "DEBUG"
+	reloaded.Client.Servers = []string{"127.0.0.2:4647"}
+	reloaded.Client.Meta = map[string]string{"rack": "r1"}
+	reloaded.Telemetry = &Telemetry{StatsdAddr: "127.0.0.1:8125"}
+	if changed := reloadRestartRequired(old, reloaded); len(changed) != 0 {
+		t.Fatalf("bad: %v", changed)
+	}
+
+	// Other changes require a restart
+	restart := DefaultConfig()
+	restart.Region = "other"
+	restart.Ports.HTTP = 5000
+	restart.Client.NodeClass = "large"
+	restart.Server.NumSchedulers = 2
+	restart.Telemetry = &Telemetry{DisableRuntimeMetrics: true, DisableMetricsEndpoint: true}
+	changed := reloadRestartRequired(old, restart)
+	expected := []string{"region", "ports", "server", "client",
+		"telemetry.disable_runtime_metrics", "telemetry.disable_metrics_endpoint"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("bad: %v", changed)
+	}
+}
+
+func TestCommand_SetupTelemetry_Reload(t *testing.T) {
+	c := &Command{}
+	config := DefaultConfig()
+	config.Telemetry = &Telemetry{StatsdAddr: "127.0.0.1:8125"}
+	if err := c.setupTelementry(config); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	sink := c.metricsSink
+	if len(c.metricSinks) != 1 {
+		t.Fatalf("bad: %#v", c.metricSinks)
+	}
+
+	// Reloading replaces the sinks but keeps the global metrics
+	config.Telemetry = &Telemetry{}
+	if err := c.setupTelementry(config); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if c.metricsSink != sink {
+		t.Fatalf("global metrics sink replaced")
+	}
+	if len(c.metricSinks) != 0 {
+		t.Fatalf("bad: %#v", c.metricSinks)
+	}
+
+	// Nothing is written to the stopped statsd sink
+	metrics.IncrCounter([]string{"nomad", "test"}, 1)
+	metrics.SetGauge([]string{"nomad", "test"}, 1)
+}
+
+func TestCommand_SetupTelemetry_DisableMetricsEndpoint(t *testing.T) {
+	c := &Command{}
+	config := DefaultConfig()
+	config.Telemetry = &Telemetry{DisableMetricsEndpoint: true}
+	if err := c.setupTelementry(config); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if c.metrics != nil {
+		t.Fatalf("metrics endpoint sink created")
+	}
+}
@@ -83,6 +83,9 @@ type Config struct {
 	// AtlasConfig is used to configure Atlas
 	Atlas *AtlasConfig `hcl:"atlas"`
 
+	// Audit is used to configure the audit log of the HTTP API
+	Audit *AuditConfig `hcl:"audit"`
+
 	// NomadConfig is used to override the default config.
 	// This is largly used for testing purposes.
 	NomadConfig *nomad.Config `hcl:"-" json:"-"`
@@ -159,6 +162,38 @@ type ClientConfig struct {
 
 	// MaxKillTimeout allows capping the user-specifiable KillTimeout.
 	MaxKillTimeout string `hcl:"max_kill_timeout"`
+
+	// GCInterval is the interval at which the alloc dirs of terminal
+	// allocations are garbage collected when over the limits below
+	GCInterval string `hcl:"gc_interval"`
+
+	// GCDiskUsageThreshold and GCInodeUsageThreshold are the percentages of
+	// the disk space and inodes of the partition of the alloc dir over which
+	// terminal allocations are garbage collected
+	GCDiskUsageThreshold  int `hcl:"gc_disk_usage_threshold"`
+	GCInodeUsageThreshold int `hcl:"gc_inode_usage_threshold"`
+
+	// GCMaxAllocs is the number of allocations over which terminal
+	// allocations are garbage collected
+	GCMaxAllocs int `hcl:"gc_max_allocs"`
+
+	// HostVolumes are the host directories exposed to task groups as named
+	// volumes
+	HostVolumes []*HostVolumeConfig `hcl:"host_volume"`
+}
+
+// HostVolumeConfig is a host directory exposed to task groups as a named
+// volume
+type HostVolumeConfig struct {
+	// Name identifies the volume in the task groups and is taken from the
+	// block's label.
+	Name string `hcl:",key"`
+
+	// Path is the absolute path of the directory on the host.
+	Path string `hcl:"path"`
+
+	// ReadOnly only allows the volume to be mounted read only.
+	ReadOnly bool `hcl:"read_only"`
 }
 
 // ServerConfig is configuration specific to the server mode
@@ -190,6 +225,10 @@ type ServerConfig struct {
 	// NodeGCThreshold contros how "old" a node must be to be collected by GC.
 	NodeGCThreshold string `hcl:"node_gc_threshold"`
 
+	// KeyringEncryptionKey is the base64 encoded 32 byte key used to wrap the
+	// root keys that encrypt variables. It must be the same on all servers.
+	KeyringEncryptionKey string `hcl:"keyring_encryption_key"`
+
 	// StartJoin is a list of addresses to attempt to join when the
 	// agent starts. If Serf is unable to communicate with any of these
 	// addresses, then the agent will error and exit.
@@ -214,6 +253,158 @@ type ServerConfig struct {
 	// the cluster until an explicit join is received. If this is set to
 	// true, we ignore the leave, and rejoin the cluster on start.
 	RejoinAfterLeave bool `hcl:"rejoin_after_leave"`
+
+	// Admission configures the admission controllers that are run on jobs
+	// before they are registered.
+	Admission *AdmissionConfig `hcl:"admission"`
+}
+
+// AdmissionConfig is the configuration of the job admission controllers
+type AdmissionConfig struct {
+	// DefaultMeta is merged into the meta of registered jobs that do not
+	// set the keys.
+	DefaultMeta map[string]string `hcl:"default_meta"`
+
+	// RequiredMeta is the set of meta keys a job must set.
+	RequiredMeta []string `hcl:"required_meta"`
+
+	// MaxTaskMemoryMB is the most memory a single task may request.
+	MaxTaskMemoryMB int `hcl:"max_task_memory_mb"`
+
+	// DenyPrivileged rejects jobs that run privileged Docker containers.
+	DenyPrivileged bool `hcl:"deny_privileged"`
+
+	// Webhooks are external admission controllers called over HTTP, in the
+	// order they are defined.
+	Webhooks []*AdmissionWebhookConfig `hcl:"webhook"`
+}
+
+// AdmissionWebhookConfig configures an external admission controller
+type AdmissionWebhookConfig struct {
+	// Name identifies the webhook in errors and warnings and is taken from
+	// the block's label.
+	Name string `hcl:",key"`
+
+	// Type is either "mutator" or "validator".
+	Type string `hcl:"type"`
+
+	// URL is the address the job is POSTed to.
+	URL string `hcl:"url"`
+
+	// Timeout bounds the time spent waiting on the webhook.
+	Timeout string `hcl:"timeout"`
+
+	// FailOpen admits the job with a warning if the webhook can not be
+	// reached, instead of rejecting it.
+	FailOpen bool `hcl:"fail_open"`
+}
+
+// Merge is used to merge two admission configs together
+func (a *AdmissionConfig) Merge(b *AdmissionConfig) *AdmissionConfig {
+	result := *a
+
+	if b.MaxTaskMemoryMB != 0 {
+		result.MaxTaskMemoryMB = b.MaxTaskMemoryMB
+	}
+	if b.DenyPrivileged {
+		result.DenyPrivileged = true
+	}
+
+	// Add the default meta
+	if a.DefaultMeta != nil || b.DefaultMeta != nil {
+		result.DefaultMeta = make(map[string]string, len(a.DefaultMeta)+len(b.DefaultMeta))
+		for k, v := range a.DefaultMeta {
+			result.DefaultMeta[k] = v
+		}
+		for k, v := range b.DefaultMeta {
+			result.DefaultMeta[k] = v
+		}
+	}
+
+	// Add the required meta and the webhooks
+	result.RequiredMeta = make([]string, 0, len(a.RequiredMeta)+len(b.RequiredMeta))
+	result.RequiredMeta = append(result.RequiredMeta, a.RequiredMeta...)
+	result.RequiredMeta = append(result.RequiredMeta, b.RequiredMeta...)
+
+	result.Webhooks = make([]*AdmissionWebhookConfig, 0, len(a.Webhooks)+len(b.Webhooks))
+	result.Webhooks = append(result.Webhooks, a.Webhooks...)
+	result.Webhooks = append(result.Webhooks, b.Webhooks...)
+
+	return &result
+}
+
+// AuditConfig is the configuration of the audit log, which records every
+// state-changing request made to the HTTP API.
+type AuditConfig struct {
+	// Enabled turns on the audit log
+	Enabled bool `hcl:"enabled"`
+
+	// Path is the file the audit entries are written to. It defaults to
+	// audit/audit.log in the data directory.
+	Path string `hcl:"path"`
+
+	// RotateBytes is the size after which the audit file is rotated and
+	// RotateMaxFiles the number of rotated files kept.
+	RotateBytes    int64 `hcl:"rotate_bytes"`
+	RotateMaxFiles int   `hcl:"rotate_max_files"`
+
+	// LogBody records the request body in the audit entries. The fields
+	// named in RedactFields are replaced and the ones in HashFields are
+	// replaced by a SHA256 hash of their value. Field names are matched at
+	// any depth and case insensitively.
+	LogBody      bool     `hcl:"log_body"`
+	RedactFields []string `hcl:"redact_fields"`
+	HashFields   []string `hcl:"hash_fields"`
+
+	// Filters exclude the requests they match from the audit log
+	Filters []*AuditFilter `hcl:"filter"`
+}
+
+// AuditFilter matches the requests to exclude from the audit log. A request
+// matches if both its path and method match. An empty list matches any value
+// and paths ending with a "*" match by prefix.
+type AuditFilter struct {
+	// Name identifies the filter and is taken from the block's label.
+	Name string `hcl:",key"`
+
+	Paths   []string `hcl:"paths"`
+	Methods []string `hcl:"methods"`
+}
+
+// Merge is used to merge two audit configs together
+func (a *AuditConfig) Merge(b *AuditConfig) *AuditConfig {
+	result := *a
+
+	if b.Enabled {
+		result.Enabled = true
+	}
+	if b.Path != "" {
+		result.Path = b.Path
+	}
+	if b.RotateBytes != 0 {
+		result.RotateBytes = b.RotateBytes
+	}
+	if b.RotateMaxFiles != 0 {
+		result.RotateMaxFiles = b.RotateMaxFiles
+	}
+	if b.LogBody {
+		result.LogBody = true
+	}
+
+	// Add the fields and the filters
+	result.RedactFields = make([]string, 0, len(a.RedactFields)+len(b.RedactFields))
+	result.RedactFields = append(result.RedactFields, a.RedactFields...)
+	result.RedactFields = append(result.RedactFields, b.RedactFields...)
+
+	result.HashFields = make([]string, 0, len(a.HashFields)+len(b.HashFields))
+	result.HashFields = append(result.HashFields, a.HashFields...)
+	result.HashFields = append(result.HashFields, b.HashFields...)
+
+	result.Filters = make([]*AuditFilter, 0, len(a.Filters)+len(b.Filters))
+	result.Filters = append(result.Filters, a.Filters...)
+	result.Filters = append(result.Filters, b.Filters...)
+
+	return &result
 }
 
 // Telemetry is the telemetry configuration for the server
@@ -221,6 +412,14 @@ type Telemetry struct {
 	StatsiteAddr    string `hcl:"statsite_address"`
 	StatsdAddr      string `hcl:"statsd_address"`
 	DisableHostname bool   `hcl:"disable_hostname"`
+
+	// DisableRuntimeMetrics disables the collection of the Go runtime
+	// metrics such as the number of goroutines and the GC pauses.
+	DisableRuntimeMetrics bool `hcl:"disable_runtime_metrics"`
+
+	// DisableMetricsEndpoint disables the in-memory sink keeping the totals
+	// of the metrics and the /v1/metrics endpoint serving them.
+	DisableMetricsEndpoint bool `hcl:"disable_metrics_endpoint"`
 }
 
 // Ports is used to encapsulate the various ports we bind to for network
@@ -285,9 +484,13 @@ func DefaultConfig() *Config {
 		AdvertiseAddrs: &AdvertiseAddrs{},
 		Atlas:          &AtlasConfig{},
 		Client: &ClientConfig{
-			Enabled:        false,
-			NetworkSpeed:   100,
-			MaxKillTimeout: "30s",
+			Enabled:               false,
+			NetworkSpeed:          100,
+			MaxKillTimeout:        "30s",
+			GCInterval:            "1m",
+			GCDiskUsageThreshold:  80,
+			GCInodeUsageThreshold: 70,
+			GCMaxAllocs:           50,
 		},
 		Server: &ServerConfig{
 			Enabled:          false,
@@ -377,6 +580,14 @@ func (c *Config) Merge(b *Config) *Config {
 		result.Telemetry = result.Telemetry.Merge(b.Telemetry)
 	}
 
+	// Apply the audit config
+	if result.Audit == nil && b.Audit != nil {
+		audit := *b.Audit
+		result.Audit = &audit
+	} else if b.Audit != nil {
+		result.Audit = result.Audit.Merge(b.Audit)
+	}
+
 	// Apply the client config
 	if result.Client == nil && b.Client != nil {
 		client := *b.Client
@@ -453,6 +664,9 @@ func (a *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 	if b.NodeGCThreshold != "" {
 		result.NodeGCThreshold = b.NodeGCThreshold
 	}
+	if b.KeyringEncryptionKey != "" {
+		result.KeyringEncryptionKey = b.KeyringEncryptionKey
+	}
 	if b.RetryMaxAttempts != 0 {
 		result.RetryMaxAttempts = b.RetryMaxAttempts
 	}
@@ -464,6 +678,14 @@ func (a *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 		result.RejoinAfterLeave = true
 	}
 
+	// Apply the admission config
+	if result.Admission == nil && b.Admission != nil {
+		admission := *b.Admission
+		result.Admission = &admission
+	} else if b.Admission != nil {
+		result.Admission = result.Admission.Merge(b.Admission)
+	}
+
 	// Add the schedulers
 	result.EnabledSchedulers = append(result.EnabledSchedulers, b.EnabledSchedulers...)
 
@@ -505,10 +727,26 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.MaxKillTimeout != "" {
 		result.MaxKillTimeout = b.MaxKillTimeout
 	}
+	if b.GCInterval != "" {
+		result.GCInterval = b.GCInterval
+	}
+	if b.GCDiskUsageThreshold != 0 {
+		result.GCDiskUsageThreshold = b.GCDiskUsageThreshold
+	}
+	if b.GCInodeUsageThreshold != 0 {
+		result.GCInodeUsageThreshold = b.GCInodeUsageThreshold
+	}
+	if b.GCMaxAllocs != 0 {
+		result.GCMaxAllocs = b.GCMaxAllocs
+	}
 
-	// Add the servers
+	// Add the servers and the host volumes
 	result.Servers = append(result.Servers, b.Servers...)
 
+	result.HostVolumes = make([]*HostVolumeConfig, 0, len(a.HostVolumes)+len(b.HostVolumes))
+	result.HostVolumes = append(result.HostVolumes, a.HostVolumes...)
+	result.HostVolumes = append(result.HostVolumes, b.HostVolumes...)
+
 	// Add the options map values
 	if result.Options == nil {
 		result.Options = make(map[string]string)
@@ -541,6 +779,12 @@ func (a *Telemetry) Merge(b *Telemetry) *Telemetry {
 	if b.DisableHostname {
 		result.DisableHostname = true
 	}
+	if b.DisableRuntimeMetrics {
+		result.DisableRuntimeMetrics = true
+	}
+	if b.DisableMetricsEndpoint {
+		result.DisableMetricsEndpoint = true
+	}
 	return &result
 }
 
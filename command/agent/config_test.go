@@ -39,8 +39,12 @@ func TestConfig_Merge(t *testing.T) {
 			Options: map[string]string{
 				"foo": "bar",
 			},
-			NetworkSpeed:   100,
-			MaxKillTimeout: "20s",
+			NetworkSpeed:          100,
+			MaxKillTimeout:        "20s",
+			GCInterval:            "1m",
+			GCDiskUsageThreshold:  80,
+			GCInodeUsageThreshold: 70,
+			GCMaxAllocs:           50,
 		},
 		Server: &ServerConfig{
 			Enabled:         false,
@@ -87,9 +91,11 @@ func TestConfig_Merge(t *testing.T) {
 		DisableAnonymousSignature: true,
 		BindAddr:                  "127.0.0.2",
 		Telemetry: &Telemetry{
-			StatsiteAddr:    "127.0.0.2:8125",
-			StatsdAddr:      "127.0.0.2:8125",
-			DisableHostname: true,
+			StatsiteAddr:           "127.0.0.2:8125",
+			StatsdAddr:             "127.0.0.2:8125",
+			DisableHostname:        true,
+			DisableRuntimeMetrics:  true,
+			DisableMetricsEndpoint: true,
 		},
 		Client: &ClientConfig{
 			Enabled:   true,
@@ -104,22 +110,45 @@ func TestConfig_Merge(t *testing.T) {
 				"foo": "bar",
 				"baz": "zip",
 			},
-			NetworkSpeed:   105,
-			MaxKillTimeout: "50s",
+			NetworkSpeed:          105,
+			MaxKillTimeout:        "50s",
+			GCInterval:            "5m",
+			GCDiskUsageThreshold:  90,
+			GCInodeUsageThreshold: 85,
+			GCMaxAllocs:           20,
+			HostVolumes: []*HostVolumeConfig{
+				&HostVolumeConfig{
+					Name: "mysql",
+					Path: "/srv/mysql",
+				},
+				&HostVolumeConfig{
+					Name:     "certs",
+					Path:     "/etc/ssl/certs",
+					ReadOnly: true,
+				},
+			},
 		},
 		Server: &ServerConfig{
-			Enabled:           true,
-			BootstrapExpect:   2,
-			DataDir:           "/tmp/data2",
-			ProtocolVersion:   2,
-			NumSchedulers:     2,
-			EnabledSchedulers: []string{structs.JobTypeBatch},
-			NodeGCThreshold:   "12h",
-			RejoinAfterLeave:  true,
-			StartJoin:         []string{"1.1.1.1"},
-			RetryJoin:         []string{"1.1.1.1"},
-			RetryInterval:     "10s",
-			retryInterval:     time.Second * 10,
+			Enabled:              true,
+			BootstrapExpect:      2,
+			DataDir:              "/tmp/data2",
+			ProtocolVersion:      2,
+			NumSchedulers:        2,
+			EnabledSchedulers:    []string{structs.JobTypeBatch},
+			NodeGCThreshold:      "12h",
+			KeyringEncryptionKey: "Ez1Nn0t8RMv4TJrg3vz3PQmF84SYRMwOaSmODXKn6X0=",
+			RejoinAfterLeave:     true,
+			StartJoin:            []string{"1.1.1.1"},
+			RetryJoin:            []string{"1.1.1.1"},
+			RetryInterval:        "10s",
+			retryInterval:        time.Second * 10,
+			Admission: &AdmissionConfig{
+				DefaultMeta:     map[string]string{"team": "infra"},
+				RequiredMeta:    []string{"owner"},
+				MaxTaskMemoryMB: 16384,
+				DenyPrivileged:  true,
+				Webhooks:        []*AdmissionWebhookConfig{},
+			},
 		},
 		Ports: &Ports{
 			HTTP: 20000,
@@ -420,26 +449,77 @@ func TestConfig_LoadConfigString(t *testing.T) {
 				"foo": "bar",
 				"baz": "zip",
 			},
-			NetworkSpeed: 100,
+			NetworkSpeed:          100,
+			GCInterval:            "5m",
+			GCDiskUsageThreshold:  90,
+			GCInodeUsageThreshold: 85,
+			GCMaxAllocs:           20,
+			HostVolumes: []*HostVolumeConfig{
+				&HostVolumeConfig{
+					Name: "mysql",
+					Path: "/srv/mysql",
+				},
+				&HostVolumeConfig{
+					Name:     "certs",
+					Path:     "/etc/ssl/certs",
+					ReadOnly: true,
+				},
+			},
 		},
 		Server: &ServerConfig{
-			Enabled:           true,
-			BootstrapExpect:   5,
-			DataDir:           "/tmp/data",
-			ProtocolVersion:   3,
-			NumSchedulers:     2,
-			EnabledSchedulers: []string{"test"},
-			NodeGCThreshold:   "12h",
-			RetryJoin:         []string{"1.1.1.1", "2.2.2.2"},
-			StartJoin:         []string{"1.1.1.1", "2.2.2.2"},
-			RetryInterval:     "15s",
-			RejoinAfterLeave:  true,
-			RetryMaxAttempts:  3,
+			Enabled:              true,
+			BootstrapExpect:      5,
+			DataDir:              "/tmp/data",
+			ProtocolVersion:      3,
+			NumSchedulers:        2,
+			EnabledSchedulers:    []string{"test"},
+			NodeGCThreshold:      "12h",
+			KeyringEncryptionKey: "Ez1Nn0t8RMv4TJrg3vz3PQmF84SYRMwOaSmODXKn6X0=",
+			RetryJoin:            []string{"1.1.1.1", "2.2.2.2"},
+			StartJoin:            []string{"1.1.1.1", "2.2.2.2"},
+			RetryInterval:        "15s",
+			RejoinAfterLeave:     true,
+			RetryMaxAttempts:     3,
+			Admission: &AdmissionConfig{
+				DefaultMeta: map[string]string{
+					"team": "infra",
+				},
+				RequiredMeta:    []string{"owner"},
+				MaxTaskMemoryMB: 16384,
+				DenyPrivileged:  true,
+				Webhooks: []*AdmissionWebhookConfig{
+					{
+						Name:     "audit",
+						Type:     "validator",
+						URL:      "http://127.0.0.1:8080/validate",
+						Timeout:  "2s",
+						FailOpen: true,
+					},
+				},
+			},
+		},
+		Audit: &AuditConfig{
+			Enabled:        true,
+			Path:           "/var/log/nomad/audit.log",
+			RotateBytes:    1048576,
+			RotateMaxFiles: 5,
+			LogBody:        true,
+			RedactFields:   []string{"Env"},
+			HashFields:     []string{"Meta"},
+			Filters: []*AuditFilter{
+				{
+					Name:    "system",
+					Paths:   []string{"/v1/system/*"},
+					Methods: []string{"PUT"},
+				},
+			},
 		},
 		Telemetry: &Telemetry{
-			StatsiteAddr:    "127.0.0.1:1234",
-			StatsdAddr:      "127.0.0.1:2345",
-			DisableHostname: true,
+			StatsiteAddr:           "127.0.0.1:1234",
+			StatsdAddr:             "127.0.0.1:2345",
+			DisableHostname:        true,
+			DisableRuntimeMetrics:  true,
+			DisableMetricsEndpoint: true,
 		},
 		LeaveOnInt:                true,
 		LeaveOnTerm:               true,
@@ -502,6 +582,17 @@ client {
 		baz = "zip"
 	}
 	network_speed = 100
+	gc_interval = "5m"
+	gc_disk_usage_threshold = 90
+	gc_inode_usage_threshold = 85
+	gc_max_allocs = 20
+	host_volume "mysql" {
+		path = "/srv/mysql"
+	}
+	host_volume "certs" {
+		path = "/etc/ssl/certs"
+		read_only = true
+	}
 }
 server {
 	enabled = true
@@ -511,16 +602,46 @@ server {
 	num_schedulers = 2
 	enabled_schedulers = ["test"]
 	node_gc_threshold = "12h"
+	keyring_encryption_key = "Ez1Nn0t8RMv4TJrg3vz3PQmF84SYRMwOaSmODXKn6X0="
 	retry_join = [ "1.1.1.1", "2.2.2.2" ]
 	start_join = [ "1.1.1.1", "2.2.2.2" ]
 	retry_max = 3
 	retry_interval = "15s"
 	rejoin_after_leave = true
+	admission {
+		required_meta = ["owner"]
+		max_task_memory_mb = 16384
+		deny_privileged = true
+		default_meta {
+			team = "infra"
+		}
+		webhook "audit" {
+			type = "validator"
+			url = "http://127.0.0.1:8080/validate"
+			timeout = "2s"
+			fail_open = true
+		}
+	}
+}
+audit {
+	enabled = true
+	path = "/var/log/nomad/audit.log"
+	rotate_bytes = 1048576
+	rotate_max_files = 5
+	log_body = true
+	redact_fields = ["Env"]
+	hash_fields = ["Meta"]
+	filter "system" {
+		paths = ["/v1/system/*"]
+		methods = ["PUT"]
+	}
 }
 telemetry {
 	statsite_address = "127.0.0.1:1234"
 	statsd_address = "127.0.0.1:2345"
 	disable_hostname = true
+	disable_runtime_metrics = true
+	disable_metrics_endpoint = true
 }
 leave_on_interrupt = true
 leave_on_terminate = true
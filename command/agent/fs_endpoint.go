@@ -4,13 +4,21 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/hashicorp/nomad/client/allocdir"
 )
 
 var (
 	allocIDNotPresentErr  = fmt.Errorf("must provide a valid alloc id")
 	fileNameNotPresentErr = fmt.Errorf("must provide a file name")
+	taskNotPresentErr     = fmt.Errorf("must provide a task name")
+	logTypeNotPresentErr  = fmt.Errorf("must provide a log type (stdout or stderr)")
+	invalidOriginErr      = fmt.Errorf("origin must be start or end")
 )
 
 func (s *HTTPServer) DirectoryListRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
@@ -22,7 +30,7 @@ func (s *HTTPServer) DirectoryListRequest(resp http.ResponseWriter, req *http.Re
 	if path = req.URL.Query().Get("path"); path == "" {
 		path = "/"
 	}
-	fs, err := s.agent.client.GetAllocFS(allocID)
+	fs, err := s.allocFS(req, allocID)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +45,7 @@ func (s *HTTPServer) FileStatRequest(resp http.ResponseWriter, req *http.Request
 	if path = req.URL.Query().Get("path"); path == "" {
 		return nil, fileNameNotPresentErr
 	}
-	fs, err := s.agent.client.GetAllocFS(allocID)
+	fs, err := s.allocFS(req, allocID)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +72,7 @@ func (s *HTTPServer) FileReadAtRequest(resp http.ResponseWriter, req *http.Reque
 	if limit, err = strconv.ParseInt(q.Get("limit"), 10, 64); err != nil {
 		return nil, fmt.Errorf("error parsing limit: %v", err)
 	}
-	fs, err := s.agent.client.GetAllocFS(allocID)
+	fs, err := s.allocFS(req, allocID)
 	if err != nil {
 		return nil, err
 	}
@@ -75,3 +83,178 @@ func (s *HTTPServer) FileReadAtRequest(resp http.ResponseWriter, req *http.Reque
 	io.Copy(resp, r)
 	return nil, nil
 }
+
+// FileStreamRequest streams a file as StreamFrames, from the offset relative
+// to the origin, either the start or the end of the file. Data appended to
+// the file is streamed as it is written, until the file is deleted or the
+// connection is closed.
+func (s *HTTPServer) FileStreamRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var allocID, path, origin string
+	var offset int64
+	var err error
+
+	q := req.URL.Query()
+
+	if allocID = strings.TrimPrefix(req.URL.Path, "/v1/client/fs/stream/"); allocID == "" {
+		return nil, allocIDNotPresentErr
+	}
+	if path = q.Get("path"); path == "" {
+		return nil, fileNameNotPresentErr
+	}
+	if origin = q.Get("origin"); origin == "" {
+		origin = "start"
+	} else if origin != "start" && origin != "end" {
+		return nil, invalidOriginErr
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.ParseInt(v, 10, 64); err != nil || offset < 0 {
+			return nil, fmt.Errorf("error parsing offset: %q", v)
+		}
+	}
+
+	fs, err := s.allocFS(req, allocID)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, CodedError(404, fmt.Sprintf("file %q not found", path))
+		}
+		return nil, err
+	}
+	if info.IsDir {
+		return nil, CodedError(400, fmt.Sprintf("%q is a directory", path))
+	}
+
+	list := func() ([]*streamFile, error) {
+		info, err := fs.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []*streamFile{{Path: path, Size: info.Size}}, nil
+	}
+	files := []*streamFile{{Path: path, Size: info.Size}}
+
+	resp.Header().Set("Content-Type", "application/json")
+	idx, start := startPosition(files, origin, offset)
+	if err := newFileStreamer(resp, fs, list, path, true).Stream(files, idx, start); err != nil {
+		s.logger.Printf("[ERR] http: failed to stream file %q in alloc %q: %v", path, allocID, err)
+	}
+	return nil, nil
+}
+
+// LogsRequest streams the logs of a task as StreamFrames. The logs are read
+// from the offset relative to the origin, either the start or the end of the
+// logs, across rotated log files. If follow is set, the logs are streamed as
+// they are written.
+func (s *HTTPServer) LogsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var allocID, task, logType, origin string
+	var offset int64
+	var follow bool
+	var err error
+
+	q := req.URL.Query()
+
+	if allocID = strings.TrimPrefix(req.URL.Path, "/v1/client/fs/logs/"); allocID == "" {
+		return nil, allocIDNotPresentErr
+	}
+	if task = q.Get("task"); task == "" {
+		return nil, taskNotPresentErr
+	}
+	if logType = q.Get("type"); logType != "stdout" && logType != "stderr" {
+		return nil, logTypeNotPresentErr
+	}
+	if origin = q.Get("origin"); origin == "" {
+		origin = "start"
+	} else if origin != "start" && origin != "end" {
+		return nil, invalidOriginErr
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.ParseInt(v, 10, 64); err != nil || offset < 0 {
+			return nil, fmt.Errorf("error parsing offset: %q", v)
+		}
+	}
+	if v := q.Get("follow"); v != "" {
+		if follow, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("error parsing follow: %v", err)
+		}
+	}
+
+	fs, err := s.allocFS(req, allocID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := func() ([]*streamFile, error) {
+		return logFiles(fs, task, logType)
+	}
+	files, err := list()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 && !follow {
+		return nil, CodedError(404, fmt.Sprintf("no %s logs for task %q", logType, task))
+	}
+
+	// Watch the directory of the log files
+	watch := filepath.Join(allocdir.SharedAllocName, allocdir.LogDirName)
+	if len(files) != 0 {
+		watch = filepath.Dir(files[0].Path)
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	idx, start := startPosition(files, origin, offset)
+	if err := newFileStreamer(resp, fs, list, watch, follow).Stream(files, idx, start); err != nil {
+		s.logger.Printf("[ERR] http: failed to stream %s logs of task %q in alloc %q: %v", logType, task, allocID, err)
+	}
+	return nil, nil
+}
+
+// logFiles returns the log files of a task, oldest first. Rotated log files
+// are named after the task, the log type and their index in the shared logs
+// directory. Otherwise the task logs to a single file in its local directory.
+func logFiles(fs allocdir.AllocDirFS, task, logType string) ([]*streamFile, error) {
+	logDir := filepath.Join(allocdir.SharedAllocName, allocdir.LogDirName)
+	entries, err := fs.List(logDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("%s.%s.", task, logType)
+	indexes := make(map[int]*streamFile)
+	keys := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir || !strings.HasPrefix(e.Name, prefix) {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimPrefix(e.Name, prefix))
+		if err != nil {
+			continue
+		}
+		indexes[idx] = &streamFile{Path: filepath.Join(logDir, e.Name), Size: e.Size}
+		keys = append(keys, idx)
+	}
+	if len(keys) != 0 {
+		sort.Ints(keys)
+		files := make([]*streamFile, len(keys))
+		for i, idx := range keys {
+			files[i] = indexes[idx]
+		}
+		return files, nil
+	}
+
+	path := filepath.Join(task, allocdir.TaskLocal, fmt.Sprintf("%s.%s", task, logType))
+	info, err := fs.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return []*streamFile{{Path: path, Size: info.Size}}, nil
+}
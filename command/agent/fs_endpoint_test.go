@@ -1,9 +1,15 @@
 package agent
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 )
 
 func TestAllocDirFS_List_MissingParams(t *testing.T) {
@@ -84,3 +90,103 @@ func TestAllocDirFS_ReadAt_MissingParams(t *testing.T) {
 		}
 	})
 }
+
+func TestAllocDirFS_Stream_MissingParams(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		req, err := http.NewRequest("GET", "/v1/client/fs/stream/", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		_, err = s.Server.FileStreamRequest(respW, req)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		req, err = http.NewRequest("GET", "/v1/client/fs/stream/foo", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+
+		_, err = s.Server.FileStreamRequest(respW, req)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		req, err = http.NewRequest("GET", "/v1/client/fs/stream/foo?path=/path/to/file&origin=middle", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+
+		_, err = s.Server.FileStreamRequest(respW, req)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestRemoteFsError(t *testing.T) {
+	// Errors of missing files returned by the RPCs are recognized
+	err := remoteFsError("stat", "foo", errors.New(cstructs.ErrFileNotExist.Error()))
+	if !os.IsNotExist(err) {
+		t.Fatalf("expected not exist error, got: %v", err)
+	}
+
+	other := errors.New("unknown allocation")
+	if err := remoteFsError("stat", "foo", other); err != other {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestRemoteFileReader(t *testing.T) {
+	file := bytes.Repeat([]byte("0123456789"), cstructs.FsReadAtMaxSize/4)
+
+	// Serve the reads as a node would, with at most FsReadAtMaxSize bytes
+	var reads int
+	read := func(offset, limit int64) ([]byte, error) {
+		reads++
+		if limit > cstructs.FsReadAtMaxSize {
+			limit = cstructs.FsReadAtMaxSize
+		}
+		if offset >= int64(len(file)) {
+			return nil, nil
+		}
+		end := offset + limit
+		if end > int64(len(file)) {
+			end = int64(len(file))
+		}
+		return file[offset:end], nil
+	}
+
+	cases := []struct {
+		offset, limit int64
+		reads         int
+	}{
+		{0, int64(len(file)), 3},
+		{10, 100, 1},
+		{0, 2 * int64(len(file)), 4},
+		{int64(len(file)), 100, 1},
+	}
+	for _, c := range cases {
+		reads = 0
+		r := &remoteFileReader{offset: c.offset, limit: c.limit, read: read}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		end := c.offset + c.limit
+		if end > int64(len(file)) {
+			end = int64(len(file))
+		}
+		if !bytes.Equal(data, file[c.offset:end]) {
+			t.Fatalf("read %d bytes at %d: got %d bytes", c.limit, c.offset, len(data))
+		}
+		if reads != c.reads {
+			t.Fatalf("read %d bytes at %d: expected %d reads, got %d", c.limit, c.offset, c.reads, reads)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// remoteAllocFS accesses the alloc dir of an allocation running on another
+// node through the ClientFS RPCs, which the servers forward to the node.
+type remoteAllocFS struct {
+	agent   *Agent
+	allocID string
+	region  string
+}
+
+// allocFS returns the alloc dir of an allocation. It is accessed directly if
+// the allocation runs on the local client, and through the servers
+// otherwise.
+func (s *HTTPServer) allocFS(req *http.Request, allocID string) (allocdir.AllocDirFS, error) {
+	if client := s.agent.Client(); client != nil && client.HasAllocation(allocID) {
+		return client.GetAllocFS(allocID)
+	}
+
+	var region string
+	s.parseRegion(req, &region)
+	return &remoteAllocFS{agent: s.agent, allocID: allocID, region: region}, nil
+}
+
+func (f *remoteAllocFS) request(path string) *cstructs.FsRequest {
+	return &cstructs.FsRequest{
+		AllocID:      f.allocID,
+		Path:         path,
+		QueryOptions: structs.QueryOptions{Region: f.region},
+	}
+}
+
+func (f *remoteAllocFS) List(path string) ([]*allocdir.AllocFileInfo, error) {
+	var resp cstructs.FsListResponse
+	if err := f.agent.RPC("ClientFS.List", f.request(path), &resp); err != nil {
+		return nil, remoteFsError("list", path, err)
+	}
+	return resp.Files, nil
+}
+
+func (f *remoteAllocFS) Stat(path string) (*allocdir.AllocFileInfo, error) {
+	var resp cstructs.FsStatResponse
+	if err := f.agent.RPC("ClientFS.Stat", f.request(path), &resp); err != nil {
+		return nil, remoteFsError("stat", path, err)
+	}
+	return resp.Info, nil
+}
+
+// ReadAt reads the file in chunks, as a node returns at most
+// FsReadAtMaxSize bytes for each read
+func (f *remoteAllocFS) ReadAt(path string, offset int64, limit int64) (io.ReadCloser, error) {
+	r := &remoteFileReader{
+		offset: offset,
+		limit:  limit,
+		read: func(offset, limit int64) ([]byte, error) {
+			return f.readChunk(path, offset, limit)
+		},
+	}
+
+	// Read the first chunk so that errors are returned before any data
+	if err := r.fill(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// readChunk reads at most FsReadAtMaxSize bytes of a remote file
+func (f *remoteAllocFS) readChunk(path string, offset int64, limit int64) ([]byte, error) {
+	args := f.request(path)
+	args.Offset = offset
+	args.Limit = limit
+	if args.Limit > cstructs.FsReadAtMaxSize {
+		args.Limit = cstructs.FsReadAtMaxSize
+	}
+	var resp cstructs.FsReadAtResponse
+	if err := f.agent.RPC("ClientFS.ReadAt", args, &resp); err != nil {
+		return nil, remoteFsError("read", path, err)
+	}
+	return resp.Data, nil
+}
+
+// ChangeEvents does not watch remote files, so that their streams poll them
+func (f *remoteAllocFS) ChangeEvents(path string, stopCh <-chan struct{}) (<-chan struct{}, error) {
+	return nil, nil
+}
+
+// remoteFileReader reads a range of a remote file, one chunk at a time
+type remoteFileReader struct {
+	offset int64
+	limit  int64
+	buf    []byte
+	eof    bool
+
+	// read reads a chunk of the file, up to the limit
+	read func(offset, limit int64) ([]byte, error)
+}
+
+func (r *remoteFileReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *remoteFileReader) Close() error {
+	return nil
+}
+
+// fill reads the next chunk of the range. The end is reached once the limit
+// is read or the file has no more data.
+func (r *remoteFileReader) fill() error {
+	if r.limit <= 0 {
+		r.eof = true
+		return nil
+	}
+	data, err := r.read(r.offset, r.limit)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		r.eof = true
+	}
+	r.buf = data
+	r.offset += int64(len(data))
+	r.limit -= int64(len(data))
+	return nil
+}
+
+// remoteFsError maps the errors of remote files that do not exist to errors
+// recognized by os.IsNotExist
+func remoteFsError(op, path string, err error) error {
+	if cstructs.IsErrFileNotExist(err) {
+		return &os.PathError{Op: op, Path: path, Err: os.ErrNotExist}
+	}
+	return err
+}
@@ -0,0 +1,268 @@
+package agent
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+)
+
+const (
+	// streamFrameSize is the maximum number of bytes of a file sent in a
+	// single frame
+	streamFrameSize = 64 * 1024
+
+	// streamPollInterval is the interval at which followed files are checked
+	// for new data when their changes can not be watched
+	streamPollInterval = 250 * time.Millisecond
+
+	// streamHeartbeatInterval is the interval at which heartbeats are sent
+	// while waiting for new data, so that closed connections are detected
+	streamHeartbeatInterval = 10 * time.Second
+
+	// streamEventFileTruncated and streamEventFileDeleted are the events of
+	// frames sent when a streamed file is truncated or deleted
+	streamEventFileTruncated = "file truncated"
+	streamEventFileDeleted   = "file deleted"
+)
+
+// StreamFrame is a chunk of a streamed file. Frames are encoded in JSON one
+// after the other. Frames with neither data nor event are heartbeats; one is
+// sent as soon as the end of the files is reached when following them.
+type StreamFrame struct {
+	// Offset is the offset in the file after the data of the frame
+	Offset int64
+
+	// Data is the content of the file
+	Data []byte `json:",omitempty"`
+
+	// File is the path of the file, relative to the allocation directory
+	File string `json:",omitempty"`
+
+	// FileEvent is set when the file changed in a way that is not an append
+	FileEvent string `json:",omitempty"`
+}
+
+// IsHeartbeat returns whether the frame is a heartbeat
+func (f *StreamFrame) IsHeartbeat() bool {
+	return len(f.Data) == 0 && f.FileEvent == ""
+}
+
+// streamFile is a file of a stream and its size when it was listed
+type streamFile struct {
+	Path string
+	Size int64
+}
+
+// streamFileLister returns the files to stream, oldest first. Files are
+// expected to only be appended to, and only the last one may still grow.
+type streamFileLister func() ([]*streamFile, error)
+
+// fileStreamer streams a sequence of files of an allocation directory as
+// frames.
+type fileStreamer struct {
+	fs     allocdir.AllocDirFS
+	list   streamFileLister
+	watch  string
+	follow bool
+
+	enc     *json.Encoder
+	flusher http.Flusher
+	closeCh <-chan bool
+	changes <-chan struct{}
+}
+
+// newFileStreamer returns a streamer writing frames to the response. If
+// follow is set, new data is streamed as it is written until the connection
+// is closed. The changes of the file or directory at the watch path trigger
+// reading the files again; if it is empty the files are polled.
+func newFileStreamer(resp http.ResponseWriter, fs allocdir.AllocDirFS, list streamFileLister, watch string, follow bool) *fileStreamer {
+	s := &fileStreamer{
+		fs:     fs,
+		list:   list,
+		watch:  watch,
+		follow: follow,
+		enc:    json.NewEncoder(resp),
+	}
+	s.flusher, _ = resp.(http.Flusher)
+	if notifier, ok := resp.(http.CloseNotifier); ok {
+		s.closeCh = notifier.CloseNotify()
+	}
+	return s
+}
+
+// startPosition returns the file and the offset in it where a stream starts.
+// The offset is relative to the start of the first file or, if origin is
+// "end", to the end of the last file, and may span several files.
+func startPosition(files []*streamFile, origin string, offset int64) (int, int64) {
+	if len(files) == 0 {
+		return 0, 0
+	}
+
+	if origin == "end" {
+		idx := len(files) - 1
+		for idx > 0 && offset > files[idx].Size {
+			offset -= files[idx].Size
+			idx--
+		}
+		if offset > files[idx].Size {
+			return idx, 0
+		}
+		return idx, files[idx].Size - offset
+	}
+
+	idx := 0
+	for idx < len(files)-1 && offset >= files[idx].Size {
+		offset -= files[idx].Size
+		idx++
+	}
+	if offset > files[idx].Size {
+		return idx, files[idx].Size
+	}
+	return idx, offset
+}
+
+// Stream streams the files from the given position. Files appearing once the
+// end of the last file is reached are streamed too, which allows following
+// files across rotations.
+func (s *fileStreamer) Stream(files []*streamFile, idx int, offset int64) error {
+	if s.follow && s.watch != "" {
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		changes, err := s.fs.ChangeEvents(s.watch, stopCh)
+		if err != nil {
+			return err
+		}
+		s.changes = changes
+	}
+
+	// Wait for the first file to appear
+	for len(files) == 0 {
+		if !s.follow {
+			return nil
+		}
+		if !s.wait() {
+			return nil
+		}
+		var err error
+		if files, err = s.list(); err != nil {
+			return err
+		}
+		idx, offset = 0, 0
+	}
+
+	current := files[idx].Path
+	caughtUp := false
+	lastSend := time.Now()
+	for {
+		data, err := s.read(current, offset)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(data) != 0 {
+			offset += int64(len(data))
+			if err := s.send(&StreamFrame{File: current, Offset: offset, Data: data}); err != nil {
+				return err
+			}
+			lastSend = time.Now()
+			continue
+		}
+
+		// The end of the current file is reached, check whether it was
+		// rotated, truncated or deleted
+		listed, err := s.list()
+		if err != nil {
+			return err
+		}
+		pos := -1
+		for i, f := range listed {
+			if f.Path == current {
+				pos = i
+				break
+			}
+		}
+
+		switch {
+		case pos == -1:
+			if err := s.send(&StreamFrame{File: current, Offset: offset, FileEvent: streamEventFileDeleted}); err != nil {
+				return err
+			}
+			if len(listed) == 0 {
+				return nil
+			}
+			current, offset = listed[0].Path, 0
+			continue
+		case listed[pos].Size < offset:
+			offset = 0
+			if err := s.send(&StreamFrame{File: current, Offset: offset, FileEvent: streamEventFileTruncated}); err != nil {
+				return err
+			}
+			continue
+		case listed[pos].Size > offset:
+			// Data was written since the read
+			continue
+		case pos < len(listed)-1:
+			current, offset = listed[pos+1].Path, 0
+			continue
+		}
+
+		// The end of the last file is reached
+		if !s.follow {
+			return nil
+		}
+		if !caughtUp || time.Since(lastSend) > streamHeartbeatInterval {
+			if err := s.send(&StreamFrame{File: current, Offset: offset}); err != nil {
+				return err
+			}
+			caughtUp = true
+			lastSend = time.Now()
+		}
+		if !s.wait() {
+			return nil
+		}
+	}
+}
+
+// read returns the data of the file after the offset, up to the size of a
+// frame
+func (s *fileStreamer) read(path string, offset int64) ([]byte, error) {
+	r, err := s.fs.ReadAt(path, offset, streamFrameSize)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// send writes a frame and flushes it to the connection
+func (s *fileStreamer) send(frame *StreamFrame) error {
+	if err := s.enc.Encode(frame); err != nil {
+		return err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// wait waits for the files to change, or before polling them again if their
+// changes are not watched. It returns false if the connection was closed
+// meanwhile.
+func (s *fileStreamer) wait() bool {
+	timeout := streamPollInterval
+	if s.changes != nil {
+		timeout = streamHeartbeatInterval
+	}
+
+	select {
+	case <-s.closeCh:
+		return false
+	case <-s.changes:
+		return true
+	case <-time.After(timeout):
+		return true
+	}
+}
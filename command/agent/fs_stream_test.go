@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// testLogAllocDir returns an allocation directory with a "web" task
+func testLogAllocDir(t *testing.T) *allocdir.AllocDir {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	d := allocdir.NewAllocDir(dir)
+	if err := d.Build([]*structs.Task{{Name: "web"}}); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("err: %v", err)
+	}
+	return d
+}
+
+func writeLogFile(t *testing.T, d *allocdir.AllocDir, name, content string) {
+	path := filepath.Join(d.SharedDir, "logs", name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestStartPosition(t *testing.T) {
+	files := []*streamFile{
+		{Path: "a", Size: 10},
+		{Path: "b", Size: 5},
+	}
+	cases := []struct {
+		origin string
+		offset int64
+		idx    int
+		start  int64
+	}{
+		{"start", 0, 0, 0},
+		{"start", 4, 0, 4},
+		{"start", 10, 1, 0},
+		{"start", 12, 1, 2},
+		{"start", 100, 1, 5},
+		{"end", 0, 1, 5},
+		{"end", 5, 1, 0},
+		{"end", 6, 0, 9},
+		{"end", 100, 0, 0},
+	}
+	for _, c := range cases {
+		idx, start := startPosition(files, c.origin, c.offset)
+		if idx != c.idx || start != c.start {
+			t.Fatalf("%s %d: got (%d, %d); want (%d, %d)", c.origin, c.offset, idx, start, c.idx, c.start)
+		}
+	}
+}
+
+func TestLogFiles(t *testing.T) {
+	d := testLogAllocDir(t)
+	defer d.Destroy()
+
+	// Without rotated files the logs of the task are read in its directory
+	local := filepath.Join(d.TaskDirs["web"], allocdir.TaskLocal, "web.stdout")
+	if err := ioutil.WriteFile(local, []byte("hello"), 0666); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	files, err := logFiles(d, "web", "stdout")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := []*streamFile{{Path: filepath.Join("web", "local", "web.stdout"), Size: 5}}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("bad: %#v", files)
+	}
+
+	// Rotated files are sorted by index
+	writeLogFile(t, d, "web.stdout.10", "c")
+	writeLogFile(t, d, "web.stdout.2", "bb")
+	writeLogFile(t, d, "web.stderr.0", "nope")
+	writeLogFile(t, d, "web.stdout.foo", "nope")
+	files, err = logFiles(d, "web", "stdout")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected = []*streamFile{
+		{Path: filepath.Join("alloc", "logs", "web.stdout.2"), Size: 2},
+		{Path: filepath.Join("alloc", "logs", "web.stdout.10"), Size: 1},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("bad: %#v", files)
+	}
+}
+
+func TestFileStreamer_Stream(t *testing.T) {
+	d := testLogAllocDir(t)
+	defer d.Destroy()
+	writeLogFile(t, d, "web.stdout.0", "hello ")
+	writeLogFile(t, d, "web.stdout.1", "world")
+
+	list := func() ([]*streamFile, error) {
+		return logFiles(d, "web", "stdout")
+	}
+	files, err := list()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The data spans the rotated files
+	respW := httptest.NewRecorder()
+	idx, start := startPosition(files, "start", 2)
+	if err := newFileStreamer(respW, d, list, "", false).Stream(files, idx, start); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var data bytes.Buffer
+	dec := json.NewDecoder(respW.Body)
+	for {
+		var frame StreamFrame
+		if err := dec.Decode(&frame); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		data.Write(frame.Data)
+	}
+	if data.String() != "llo world" {
+		t.Fatalf("bad: %q", data.String())
+	}
+}
+
+func TestFileStreamer_Follow(t *testing.T) {
+	d := testLogAllocDir(t)
+	defer d.Destroy()
+	writeLogFile(t, d, "web.stdout.0", "hello")
+
+	list := func() ([]*streamFile, error) {
+		return logFiles(d, "web", "stdout")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		files, err := list()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		newFileStreamer(resp, d, list, filepath.Join("alloc", "logs"), true).Stream(files, 0, 0)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	next := func() *StreamFrame {
+		var frame StreamFrame
+		if err := dec.Decode(&frame); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return &frame
+	}
+
+	// The existing data is followed by a heartbeat
+	if frame := next(); string(frame.Data) != "hello" || frame.Offset != 5 {
+		t.Fatalf("bad: %#v", frame)
+	}
+	if frame := next(); !frame.IsHeartbeat() {
+		t.Fatalf("expected heartbeat: %#v", frame)
+	}
+
+	// Data written to a new log file is streamed
+	writeLogFile(t, d, "web.stdout.1", "world")
+	frame := next()
+	if string(frame.Data) != "world" || frame.File != filepath.Join("alloc", "logs", "web.stdout.1") {
+		t.Fatalf("bad: %#v", frame)
+	}
+}
+
+func TestFileStreamer_FileEvents(t *testing.T) {
+	d := testLogAllocDir(t)
+	defer d.Destroy()
+	writeLogFile(t, d, "web.stdout.0", "hello")
+
+	path := filepath.Join("alloc", "logs", "web.stdout.0")
+	list := func() ([]*streamFile, error) {
+		info, err := d.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []*streamFile{{Path: path, Size: info.Size}}, nil
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		files, err := list()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		newFileStreamer(resp, d, list, path, true).Stream(files, 0, 0)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	next := func() *StreamFrame {
+		var frame StreamFrame
+		if err := dec.Decode(&frame); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return &frame
+	}
+	if frame := next(); string(frame.Data) != "hello" {
+		t.Fatalf("bad: %#v", frame)
+	}
+	if frame := next(); !frame.IsHeartbeat() {
+		t.Fatalf("expected heartbeat: %#v", frame)
+	}
+
+	// Truncating the file streams it from its start again
+	if err := ioutil.WriteFile(filepath.Join(d.AllocDir, path), []byte("hi"), 0666); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if frame := next(); frame.FileEvent != streamEventFileTruncated || frame.Offset != 0 {
+		t.Fatalf("bad: %#v", frame)
+	}
+	if frame := next(); string(frame.Data) != "hi" {
+		t.Fatalf("bad: %#v", frame)
+	}
+
+	// Deleting the file ends the stream
+	if err := os.Remove(filepath.Join(d.AllocDir, path)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if frame := next(); frame.FileEvent != streamEventFileDeleted {
+		t.Fatalf("bad: %#v", frame)
+	}
+	var frame StreamFrame
+	if err := dec.Decode(&frame); err != io.EOF {
+		t.Fatalf("expected end of stream: %v %#v", err, frame)
+	}
+}
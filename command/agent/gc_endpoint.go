@@ -0,0 +1,24 @@
+package agent
+
+import (
+	"net/http"
+)
+
+// ClientGCRequest is used to garbage collect the alloc dirs and the state of
+// all the terminal allocations of the client
+func (s *HTTPServer) ClientGCRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	client := s.agent.Client()
+	if client == nil {
+		return nil, CodedError(501, ErrInvalidMethod)
+	}
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	return &ClientGCResponse{Collected: client.GarbageCollect()}, nil
+}
+
+// ClientGCResponse is the response of a garbage collection of the client
+type ClientGCResponse struct {
+	// Collected is the number of allocations garbage collected
+	Collected int
+}
@@ -106,6 +106,12 @@ func (s *HTTPServer) registerHandlers(enableDebug bool) {
 	s.mux.HandleFunc("/v1/client/fs/ls/", s.wrap(s.DirectoryListRequest))
 	s.mux.HandleFunc("/v1/client/fs/stat/", s.wrap(s.FileStatRequest))
 	s.mux.HandleFunc("/v1/client/fs/readat/", s.wrap(s.FileReadAtRequest))
+	s.mux.HandleFunc("/v1/client/fs/stream/", s.wrap(s.FileStreamRequest))
+	s.mux.HandleFunc("/v1/client/fs/logs/", s.wrap(s.LogsRequest))
+	s.mux.HandleFunc("/v1/client/metadata", s.wrap(s.NodeMetaRequest))
+	s.mux.HandleFunc("/v1/client/stats", s.wrap(s.ClientStatsRequest))
+	s.mux.HandleFunc("/v1/client/gc", s.wrap(s.ClientGCRequest))
+	s.mux.HandleFunc("/v1/client/allocation/", s.wrap(s.ClientAllocRequest))
 
 	s.mux.HandleFunc("/v1/agent/self", s.wrap(s.AgentSelfRequest))
 	s.mux.HandleFunc("/v1/agent/join", s.wrap(s.AgentJoinRequest))
@@ -118,6 +124,17 @@ func (s *HTTPServer) registerHandlers(enableDebug bool) {
 	s.mux.HandleFunc("/v1/status/leader", s.wrap(s.StatusLeaderRequest))
 	s.mux.HandleFunc("/v1/status/peers", s.wrap(s.StatusPeersRequest))
 
+	s.mux.HandleFunc("/v1/system/gc", s.wrap(s.GarbageCollectRequest))
+
+	s.mux.HandleFunc("/v1/vars", s.wrap(s.VariablesRequest))
+	s.mux.HandleFunc("/v1/var/", s.wrap(s.VariableSpecificRequest))
+	s.mux.HandleFunc("/v1/keyring/rotate", s.wrap(s.KeyringRotateRequest))
+
+	s.mux.HandleFunc("/v1/services", s.wrap(s.ServicesRequest))
+	s.mux.HandleFunc("/v1/service/", s.wrap(s.ServiceSpecificRequest))
+
+	s.mux.HandleFunc("/v1/metrics", s.wrap(s.MetricsRequest))
+
 	if enableDebug {
 		s.mux.HandleFunc("/debug/pprof/", pprof.Index)
 		s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
@@ -159,6 +176,14 @@ func (s *HTTPServer) wrap(handler func(resp http.ResponseWriter, req *http.Reque
 		defer func() {
 			s.logger.Printf("[DEBUG] http: Request %v (%v)", reqURL, time.Now().Sub(start))
 		}()
+
+		// Record state-changing requests in the audit log
+		if auditor := s.agent.auditor; auditor != nil && auditor.Audited(req) {
+			var finish func()
+			resp, finish = auditor.Track(resp, req)
+			defer finish()
+		}
+
 		obj, err := handler(resp, req)
 
 		// Check for an error
@@ -48,6 +48,12 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/evaluations"):
 		jobName := strings.TrimSuffix(path, "/evaluations")
 		return s.jobEvaluations(resp, req, jobName)
+	case strings.HasSuffix(path, "/summary"):
+		jobName := strings.TrimSuffix(path, "/summary")
+		return s.jobSummaryRequest(resp, req, jobName)
+	case strings.HasSuffix(path, "/multiregion"):
+		jobName := strings.TrimSuffix(path, "/multiregion")
+		return s.jobMultiregionRequest(resp, req, jobName)
 	case strings.HasSuffix(path, "/periodic/force"):
 		jobName := strings.TrimSuffix(path, "/periodic/force")
 		return s.periodicForceRequest(resp, req, jobName)
@@ -176,6 +182,30 @@ func (s *HTTPServer) jobQuery(resp http.ResponseWriter, req *http.Request,
 	return out.Job, nil
 }
 
+func (s *HTTPServer) jobSummaryRequest(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	args := structs.JobSpecificRequest{
+		JobID: jobName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.JobSummaryResponse
+	if err := s.agent.RPC("Job.Summary", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.JobSummary == nil {
+		return nil, CodedError(404, "job summary not found")
+	}
+	return out.JobSummary, nil
+}
+
 func (s *HTTPServer) jobUpdate(resp http.ResponseWriter, req *http.Request,
 	jobName string) (interface{}, error) {
 	var args structs.JobRegisterRequest
@@ -212,3 +242,27 @@ func (s *HTTPServer) jobDelete(resp http.ResponseWriter, req *http.Request,
 	setIndex(resp, out.Index)
 	return out, nil
 }
+
+func (s *HTTPServer) jobMultiregionRequest(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	args := structs.JobSpecificRequest{
+		JobID: jobName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.MultiregionRolloutResponse
+	if err := s.agent.RPC("Job.MultiregionStatus", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Rollout == nil {
+		return nil, CodedError(404, "multi-region rollout not found")
+	}
+	return out.Rollout, nil
+}
@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestHTTP_KeyringRotate(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		// Make the HTTP request
+		req, err := http.NewRequest("PUT", "/v1/keyring/rotate", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.KeyringRotateRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Check for the index
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		// The new key is the active one
+		out := obj.(structs.KeyringRotateResponse)
+		active, err := s.Agent.Server().State().ActiveRootKeyMeta()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if active == nil || active.KeyID != out.KeyID {
+			t.Fatalf("bad: %#v", active)
+		}
+	})
+}
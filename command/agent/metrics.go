@@ -0,0 +1,300 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+)
+
+// MetricsSink is an in-memory metrics sink that keeps the last value of each
+// gauge and the running totals of each counter and sample since the agent
+// started. It backs the metrics endpoint.
+type MetricsSink struct {
+	gauges   map[string]float32
+	counters map[string]*MetricsAggregate
+	samples  map[string]*MetricsAggregate
+	l        sync.RWMutex
+}
+
+// MetricsAggregate is the running summary of a counter or a sample
+type MetricsAggregate struct {
+	Count int
+	Sum   float64
+	SumSq float64
+	Min   float64
+	Max   float64
+}
+
+// ingest adds a value to the aggregate
+func (a *MetricsAggregate) ingest(v float64) {
+	a.Count++
+	a.Sum += v
+	a.SumSq += v * v
+	if v < a.Min || a.Count == 1 {
+		a.Min = v
+	}
+	if v > a.Max || a.Count == 1 {
+		a.Max = v
+	}
+}
+
+// Mean returns the mean of the ingested values
+func (a *MetricsAggregate) Mean() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Count)
+}
+
+// Stddev returns the standard deviation of the ingested values
+func (a *MetricsAggregate) Stddev() float64 {
+	if a.Count < 2 {
+		return 0
+	}
+	num := float64(a.Count)*a.SumSq - a.Sum*a.Sum
+	if num < 0 {
+		return 0
+	}
+	return math.Sqrt(num / float64(a.Count*(a.Count-1)))
+}
+
+// NewMetricsSink returns an empty metrics sink
+func NewMetricsSink() *MetricsSink {
+	return &MetricsSink{
+		gauges:   make(map[string]float32),
+		counters: make(map[string]*MetricsAggregate),
+		samples:  make(map[string]*MetricsAggregate),
+	}
+}
+
+func (m *MetricsSink) SetGauge(key []string, val float32) {
+	k := strings.Join(key, ".")
+	m.l.Lock()
+	m.gauges[k] = val
+	m.l.Unlock()
+}
+
+// EmitKey values are kept as gauges as only their last value is meaningful
+func (m *MetricsSink) EmitKey(key []string, val float32) {
+	m.SetGauge(key, val)
+}
+
+func (m *MetricsSink) IncrCounter(key []string, val float32) {
+	m.ingest(m.counters, key, val)
+}
+
+func (m *MetricsSink) AddSample(key []string, val float32) {
+	m.ingest(m.samples, key, val)
+}
+
+func (m *MetricsSink) ingest(into map[string]*MetricsAggregate, key []string, val float32) {
+	k := strings.Join(key, ".")
+	m.l.Lock()
+	defer m.l.Unlock()
+	agg, ok := into[k]
+	if !ok {
+		agg = new(MetricsAggregate)
+		into[k] = agg
+	}
+	agg.ingest(float64(val))
+}
+
+// reloadableSink forwards the metrics to a set of sinks that can be replaced
+// while metrics are emitted. The global metrics are created once with it as
+// their sink, so that reloading the telemetry config does not start another
+// collector of the runtime metrics.
+type reloadableSink struct {
+	sinks metrics.FanoutSink
+
+	// hostname, if set, is inserted after the service name in the key of the
+	// gauges forwarded to the sinks, as done by the global metrics.
+	hostname string
+
+	// endpoint, if set, keeps the metrics served by the metrics endpoint.
+	// Their names are never prefixed with the hostname.
+	endpoint *MetricsSink
+
+	l sync.RWMutex
+}
+
+// newReloadableSink returns a sink forwarding the metrics to the given sinks
+// and to the metrics endpoint sink, which may be nil
+func newReloadableSink(sinks metrics.FanoutSink, hostname string, endpoint *MetricsSink) *reloadableSink {
+	return &reloadableSink{sinks: sinks, hostname: hostname, endpoint: endpoint}
+}
+
+// Swap replaces the sinks the metrics are forwarded to and the hostname
+// their gauges are prefixed with. Once it returns, no metric is being
+// written to the previous sinks so they can be shut down.
+func (r *reloadableSink) Swap(sinks metrics.FanoutSink, hostname string) {
+	r.l.Lock()
+	r.sinks = sinks
+	r.hostname = hostname
+	r.l.Unlock()
+}
+
+func (r *reloadableSink) SetGauge(key []string, val float32) {
+	if r.endpoint != nil {
+		r.endpoint.SetGauge(key, val)
+	}
+	r.l.RLock()
+	r.sinks.SetGauge(r.gaugeKey(key), val)
+	r.l.RUnlock()
+}
+
+func (r *reloadableSink) EmitKey(key []string, val float32) {
+	if r.endpoint != nil {
+		r.endpoint.EmitKey(key, val)
+	}
+	r.l.RLock()
+	r.sinks.EmitKey(key, val)
+	r.l.RUnlock()
+}
+
+func (r *reloadableSink) IncrCounter(key []string, val float32) {
+	if r.endpoint != nil {
+		r.endpoint.IncrCounter(key, val)
+	}
+	r.l.RLock()
+	r.sinks.IncrCounter(key, val)
+	r.l.RUnlock()
+}
+
+func (r *reloadableSink) AddSample(key []string, val float32) {
+	if r.endpoint != nil {
+		r.endpoint.AddSample(key, val)
+	}
+	r.l.RLock()
+	r.sinks.AddSample(key, val)
+	r.l.RUnlock()
+}
+
+// gaugeKey returns the key of a gauge prefixed with the hostname. The first
+// part of the key is the service name. The read lock must be held.
+func (r *reloadableSink) gaugeKey(key []string) []string {
+	if r.hostname == "" || len(key) == 0 {
+		return key
+	}
+	out := make([]string, 0, len(key)+1)
+	out = append(out, key[0], r.hostname)
+	return append(out, key[1:]...)
+}
+
+// MetricsSummary is the JSON representation of the metrics
+type MetricsSummary struct {
+	Timestamp string
+	Gauges    []GaugeValue
+	Counters  []SampledValue
+	Samples   []SampledValue
+}
+
+// GaugeValue is the last value of a gauge
+type GaugeValue struct {
+	Name  string
+	Value float32
+}
+
+// SampledValue is the summary of a counter or a sample
+type SampledValue struct {
+	Name   string
+	Count  int
+	Sum    float64
+	Min    float64
+	Max    float64
+	Mean   float64
+	Stddev float64
+}
+
+// Summary returns the current metrics sorted by name
+func (m *MetricsSink) Summary() *MetricsSummary {
+	m.l.RLock()
+	defer m.l.RUnlock()
+
+	out := &MetricsSummary{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Gauges:    make([]GaugeValue, 0, len(m.gauges)),
+		Counters:  sampledValues(m.counters),
+		Samples:   sampledValues(m.samples),
+	}
+	for _, name := range sortedKeys(m.gauges) {
+		out.Gauges = append(out.Gauges, GaugeValue{Name: name, Value: m.gauges[name]})
+	}
+	return out
+}
+
+func sampledValues(in map[string]*MetricsAggregate) []SampledValue {
+	names := make([]string, 0, len(in))
+	for name := range in {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]SampledValue, 0, len(in))
+	for _, name := range names {
+		agg := in[name]
+		out = append(out, SampledValue{
+			Name:   name,
+			Count:  agg.Count,
+			Sum:    agg.Sum,
+			Min:    agg.Min,
+			Max:    agg.Max,
+			Mean:   agg.Mean(),
+			Stddev: agg.Stddev(),
+		})
+	}
+	return out
+}
+
+func sortedKeys(in map[string]float32) []string {
+	names := make([]string, 0, len(in))
+	for name := range in {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition
+// format. Gauges are exported as gauges, counters as counters of their total
+// and samples as summaries of their count and sum.
+func (m *MetricsSink) WritePrometheus(w io.Writer) error {
+	summary := m.Summary()
+	for _, g := range summary.Gauges {
+		name := prometheusName(g.Name)
+		if _, err := fmt.Fprintf(w, "# TYPE %s gauge\n%s %v\n", name, name, g.Value); err != nil {
+			return err
+		}
+	}
+	for _, c := range summary.Counters {
+		name := prometheusName(c.Name)
+		if _, err := fmt.Fprintf(w, "# TYPE %s counter\n%s %v\n", name, name, c.Sum); err != nil {
+			return err
+		}
+	}
+	for _, s := range summary.Samples {
+		name := prometheusName(s.Name)
+		if _, err := fmt.Fprintf(w, "# TYPE %s summary\n%s_sum %v\n%s_count %d\n",
+			name, name, s.Sum, name, s.Count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prometheusName converts a metric key to a valid Prometheus metric name
+func prometheusName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func (s *HTTPServer) MetricsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	sink := s.agent.metrics
+	if sink == nil {
+		return nil, CodedError(404, "Metrics are not enabled")
+	}
+
+	switch format := req.URL.Query().Get("format"); format {
+	case "":
+		return sink.Summary(), nil
+	case "prometheus":
+		resp.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := sink.WritePrometheus(resp); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	default:
+		return nil, CodedError(400, fmt.Sprintf("Unsupported metrics format %q", format))
+	}
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTP_Metrics(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		s.Agent.metrics = NewMetricsSink()
+		s.Agent.metrics.SetGauge([]string{"nomad", "broker", "total_ready"}, 1)
+
+		// Make the HTTP request
+		req, err := http.NewRequest("GET", "/v1/metrics", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.MetricsRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		out := obj.(*MetricsSummary)
+		if len(out.Gauges) != 1 || out.Gauges[0].Name != "nomad.broker.total_ready" {
+			t.Fatalf("bad: %#v", out)
+		}
+
+		// Request the Prometheus format
+		req, err = http.NewRequest("GET", "/v1/metrics?format=prometheus", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.MetricsRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if obj != nil {
+			t.Fatalf("bad: %#v", obj)
+		}
+		if !strings.Contains(respW.Body.String(), "nomad_broker_total_ready 1") {
+			t.Fatalf("bad: %s", respW.Body.String())
+		}
+
+		// Unknown formats are rejected
+		req, err = http.NewRequest("GET", "/v1/metrics?format=xml", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.MetricsRequest(httptest.NewRecorder(), req); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/armon/go-metrics"
+)
+
+func TestMetricsSink_Summary(t *testing.T) {
+	m := NewMetricsSink()
+	m.SetGauge([]string{"nomad", "broker", "total_ready"}, 3)
+	m.SetGauge([]string{"nomad", "broker", "total_ready"}, 5)
+	m.IncrCounter([]string{"nomad", "heartbeat", "invalidate"}, 1)
+	m.IncrCounter([]string{"nomad", "heartbeat", "invalidate"}, 1)
+	m.AddSample([]string{"nomad", "plan", "evaluate"}, 2)
+	m.AddSample([]string{"nomad", "plan", "evaluate"}, 4)
+
+	summary := m.Summary()
+	if len(summary.Gauges) != 1 || summary.Gauges[0].Name != "nomad.broker.total_ready" ||
+		summary.Gauges[0].Value != 5 {
+		t.Fatalf("bad: %#v", summary.Gauges)
+	}
+	if len(summary.Counters) != 1 || summary.Counters[0].Count != 2 || summary.Counters[0].Sum != 2 {
+		t.Fatalf("bad: %#v", summary.Counters)
+	}
+	sample := summary.Samples[0]
+	if sample.Name != "nomad.plan.evaluate" || sample.Count != 2 || sample.Min != 2 ||
+		sample.Max != 4 || sample.Mean != 3 {
+		t.Fatalf("bad: %#v", sample)
+	}
+}
+
+func TestMetricsSink_WritePrometheus(t *testing.T) {
+	m := NewMetricsSink()
+	m.SetGauge([]string{"nomad", "runtime", "num_goroutines"}, 12)
+	m.IncrCounter([]string{"nomad", "heartbeat", "invalidate"}, 1)
+	m.AddSample([]string{"nomad", "plan", "submit"}, 1.5)
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out := buf.String()
+	expected := []string{
+		"# TYPE nomad_runtime_num_goroutines gauge\nnomad_runtime_num_goroutines 12\n",
+		"# TYPE nomad_heartbeat_invalidate counter\nnomad_heartbeat_invalidate 1\n",
+		"# TYPE nomad_plan_submit summary\nnomad_plan_submit_sum 1.5\nnomad_plan_submit_count 1\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Fatalf("missing %q in:\n%s", e, out)
+		}
+	}
+}
+
+func TestPrometheusName(t *testing.T) {
+	if name := prometheusName("nomad.runtime.alloc-bytes"); name != "nomad_runtime_alloc_bytes" {
+		t.Fatalf("bad: %q", name)
+	}
+}
+
+func TestReloadableSink_Swap(t *testing.T) {
+	first, second := NewMetricsSink(), NewMetricsSink()
+	r := newReloadableSink(metrics.FanoutSink{first}, "", nil)
+	r.IncrCounter([]string{"nomad", "test"}, 1)
+
+	r.Swap(metrics.FanoutSink{second}, "")
+	r.IncrCounter([]string{"nomad", "test"}, 1)
+	r.SetGauge([]string{"nomad", "gauge"}, 1)
+
+	if s := first.Summary(); len(s.Counters) != 1 || s.Counters[0].Count != 1 || len(s.Gauges) != 0 {
+		t.Fatalf("bad: %#v", s)
+	}
+	if s := second.Summary(); len(s.Counters) != 1 || s.Counters[0].Count != 1 || len(s.Gauges) != 1 {
+		t.Fatalf("bad: %#v", s)
+	}
+}
+
+func TestReloadableSink_Hostname(t *testing.T) {
+	m := NewMetricsSink()
+	endpoint := NewMetricsSink()
+	r := newReloadableSink(metrics.FanoutSink{m}, "host1", endpoint)
+	r.SetGauge([]string{"nomad", "gauge"}, 1)
+	r.IncrCounter([]string{"nomad", "counter"}, 1)
+	r.AddSample([]string{"nomad", "sample"}, 1)
+
+	// Only the gauges forwarded to the sinks are prefixed with the hostname
+	s := m.Summary()
+	if len(s.Gauges) != 1 || s.Gauges[0].Name != "nomad.host1.gauge" {
+		t.Fatalf("bad: %#v", s.Gauges)
+	}
+	if len(s.Counters) != 1 || s.Counters[0].Name != "nomad.counter" {
+		t.Fatalf("bad: %#v", s.Counters)
+	}
+	if len(s.Samples) != 1 || s.Samples[0].Name != "nomad.sample" {
+		t.Fatalf("bad: %#v", s.Samples)
+	}
+
+	// The metrics endpoint never has the hostname in the names
+	s = endpoint.Summary()
+	if len(s.Gauges) != 1 || s.Gauges[0].Name != "nomad.gauge" {
+		t.Fatalf("bad: %#v", s.Gauges)
+	}
+	if len(s.Counters) != 1 || s.Counters[0].Name != "nomad.counter" {
+		t.Fatalf("bad: %#v", s.Counters)
+	}
+}
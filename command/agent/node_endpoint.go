@@ -42,6 +42,9 @@ func (s *HTTPServer) NodeSpecificRequest(resp http.ResponseWriter, req *http.Req
 	case strings.HasSuffix(path, "/drain"):
 		nodeName := strings.TrimSuffix(path, "/drain")
 		return s.nodeToggleDrain(resp, req, nodeName)
+	case strings.HasSuffix(path, "/eligibility"):
+		nodeName := strings.TrimSuffix(path, "/eligibility")
+		return s.nodeToggleEligibility(resp, req, nodeName)
 	default:
 		return s.nodeQuery(resp, req, path)
 	}
@@ -95,24 +98,49 @@ func (s *HTTPServer) nodeToggleDrain(resp http.ResponseWriter, req *http.Request
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
-	// Get the enable value
-	enableRaw := req.URL.Query().Get("enable")
-	if enableRaw == "" {
-		return nil, CodedError(400, "missing enable value")
+	// The enable value toggles a drain without a deadline, disabling it
+	// making the node eligible again as before. Otherwise the drain strategy
+	// is in the body
+	var args structs.NodeUpdateDrainRequest
+	if enableRaw := req.URL.Query().Get("enable"); enableRaw != "" {
+		enable, err := strconv.ParseBool(enableRaw)
+		if err != nil {
+			return nil, CodedError(400, "invalid enable value")
+		}
+		args.Drain = enable
+		args.MarkEligible = !enable
+	} else if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	args.NodeID = nodeID
+	s.parseRegion(req, &args.Region)
+
+	var out structs.NodeDrainUpdateResponse
+	if err := s.agent.RPC("Node.UpdateDrain", &args, &out); err != nil {
+		return nil, err
 	}
-	enable, err := strconv.ParseBool(enableRaw)
-	if err != nil {
-		return nil, CodedError(400, "invalid enable value")
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
+func (s *HTTPServer) nodeToggleEligibility(resp http.ResponseWriter, req *http.Request,
+	nodeID string) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
-	args := structs.NodeUpdateDrainRequest{
-		NodeID: nodeID,
-		Drain:  enable,
+	var args structs.NodeUpdateEligibilityRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if args.Eligibility == "" {
+		return nil, CodedError(400, "missing eligibility value")
 	}
+	args.NodeID = nodeID
 	s.parseRegion(req, &args.Region)
 
-	var out structs.NodeDrainUpdateResponse
-	if err := s.agent.RPC("Node.UpdateDrain", &args, &out); err != nil {
+	var out structs.NodeEligibilityUpdateResponse
+	if err := s.agent.RPC("Node.UpdateEligibility", &args, &out); err != nil {
 		return nil, err
 	}
 	setIndex(resp, out.Index)
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -259,6 +260,122 @@ func TestHTTP_NodeDrain(t *testing.T) {
 		if len(upd.EvalIDs) == 0 {
 			t.Fatalf("bad: %v", upd)
 		}
+
+		// Disabling the drain makes the node eligible again
+		req, err = http.NewRequest("POST", "/v1/node/"+node.ID+"/drain?enable=false", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		if _, err := s.Server.NodeSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		out, err := state.NodeByID(node.ID)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out.Drain || out.SchedulingEligibility != structs.NodeSchedulingEligible {
+			t.Fatalf("bad: %#v", out)
+		}
+	})
+}
+
+func TestHTTP_NodeDrain_Strategy(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		// Create the node
+		node := mock.Node()
+		args := structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		if err := s.Agent.RPC("Node.Register", &args, &resp); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Drain the node with a deadline
+		drain := structs.NodeUpdateDrainRequest{
+			DrainStrategy: &structs.DrainStrategy{
+				DrainSpec: structs.DrainSpec{Deadline: time.Hour},
+			},
+		}
+		req, err := http.NewRequest("POST", "/v1/node/"+node.ID+"/drain", encodeReq(drain))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+		if _, err := s.Server.NodeSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		out, err := s.Agent.server.State().NodeByID(node.ID)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out.DrainStrategy == nil || out.DrainStrategy.Deadline != time.Hour {
+			t.Fatalf("bad: %#v", out.DrainStrategy)
+		}
+
+		// Stop the drain but keep the node ineligible
+		req, err = http.NewRequest("POST", "/v1/node/"+node.ID+"/drain", encodeReq(structs.NodeUpdateDrainRequest{}))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		if _, err := s.Server.NodeSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		out, err = s.Agent.server.State().NodeByID(node.ID)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out.Drain || out.SchedulingEligibility != structs.NodeSchedulingIneligible {
+			t.Fatalf("bad: %#v", out)
+		}
+	})
+}
+
+func TestHTTP_NodeEligibility(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		// Create the node
+		node := mock.Node()
+		args := structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		if err := s.Agent.RPC("Node.Register", &args, &resp); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Make the HTTP request
+		elig := structs.NodeUpdateEligibilityRequest{
+			Eligibility: structs.NodeSchedulingIneligible,
+		}
+		req, err := http.NewRequest("POST", "/v1/node/"+node.ID+"/eligibility", encodeReq(elig))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+		if _, err := s.Server.NodeSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		out, err := s.Agent.server.State().NodeByID(node.ID)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out.SchedulingEligibility != structs.NodeSchedulingIneligible {
+			t.Fatalf("bad: %#v", out)
+		}
 	})
 }
 
@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"net/http"
+)
+
+// NodeMetaRequest is used to read and update the meta of the client node at
+// runtime
+func (s *HTTPServer) NodeMetaRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	client := s.agent.Client()
+	if client == nil {
+		return nil, CodedError(501, ErrInvalidMethod)
+	}
+
+	switch req.Method {
+	case "GET":
+		return client.NodeMeta(), nil
+	case "PUT", "POST":
+		var args NodeMetaApplyRequest
+		if err := decodeBody(req, &args); err != nil {
+			return nil, CodedError(400, err.Error())
+		}
+		if len(args.Meta) == 0 {
+			return nil, CodedError(400, "missing meta updates")
+		}
+		meta, err := client.UpdateNodeMeta(args.Meta)
+		if err != nil {
+			return nil, err
+		}
+		return meta, nil
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+// NodeMetaApplyRequest is the body of a node meta update. A null value
+// removes the key.
+type NodeMetaApplyRequest struct {
+	Meta map[string]*string
+}
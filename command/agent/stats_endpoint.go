@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"net/http"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// ClientStatsRequest is used to read the latest sample of the resource usage
+// of the host of the client, or of the node given by the node_id query param
+// through the servers
+func (s *HTTPServer) ClientStatsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	client := s.agent.Client()
+	nodeID := req.URL.Query().Get("node_id")
+	if client != nil && (nodeID == "" || nodeID == client.Node().ID) {
+		stats := client.HostStats()
+		if stats == nil {
+			return nil, CodedError(503, "host stats are not collected yet")
+		}
+		return stats, nil
+	}
+	if nodeID == "" {
+		return nil, CodedError(400, "must provide a node ID")
+	}
+
+	args := cstructs.ClientStatsRequest{NodeID: nodeID}
+	s.parseRegion(req, &args.Region)
+	var out cstructs.ClientStatsResponse
+	if err := s.agent.RPC("ClientStats.Stats", &args, &out); err != nil {
+		return nil, err
+	}
+	return out.HostStats, nil
+}
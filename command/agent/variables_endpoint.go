@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func (s *HTTPServer) VariablesRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.VariableListRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.VariableListResponse
+	if err := s.agent.RPC("Variables.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Variables == nil {
+		out.Variables = make([]*structs.VariableListStub, 0)
+	}
+	return out.Variables, nil
+}
+
+func (s *HTTPServer) VariableSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/var/")
+	if path == "" {
+		return nil, CodedError(400, "Missing variable path")
+	}
+
+	switch req.Method {
+	case "GET":
+		return s.variableQuery(resp, req, path)
+	case "PUT", "POST":
+		return s.variableUpdate(resp, req, path)
+	case "DELETE":
+		return s.variableDelete(resp, req, path)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) variableQuery(resp http.ResponseWriter, req *http.Request,
+	path string) (interface{}, error) {
+	args := structs.VariableSpecificRequest{
+		Path: path,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.SingleVariableResponse
+	if err := s.agent.RPC("Variables.Read", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Variable == nil {
+		return nil, CodedError(404, "variable not found")
+	}
+	return out.Variable, nil
+}
+
+func (s *HTTPServer) variableUpdate(resp http.ResponseWriter, req *http.Request,
+	path string) (interface{}, error) {
+	var variable structs.Variable
+	if err := decodeBody(req, &variable); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if variable.Path != "" && variable.Path != path {
+		return nil, CodedError(400, "Variable path does not match")
+	}
+	variable.Path = path
+
+	args := structs.VariableUpsertRequest{
+		Variable: &variable,
+	}
+	s.parseRegion(req, &args.Region)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("Variables.Upsert", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
+func (s *HTTPServer) variableDelete(resp http.ResponseWriter, req *http.Request,
+	path string) (interface{}, error) {
+	args := structs.VariableDeleteRequest{
+		Path: path,
+	}
+	s.parseRegion(req, &args.Region)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("Variables.Delete", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestHTTP_VariableCRUD(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		// Create the variable
+		variable := &structs.Variable{
+			Items: map[string]string{"password": "hunter2"},
+		}
+		buf := encodeReq(variable)
+		req, err := http.NewRequest("PUT", "/v1/var/app/db", buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		if _, err := s.Server.VariableSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		// Read it back
+		req, err = http.NewRequest("GET", "/v1/var/app/db", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+
+		obj, err := s.Server.VariableSpecificRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		out := obj.(*structs.Variable)
+		if out.Path != "app/db" || !reflect.DeepEqual(out.Items, variable.Items) {
+			t.Fatalf("bad: %#v", out)
+		}
+
+		// List the variables
+		req, err = http.NewRequest("GET", "/v1/vars?prefix=app", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+
+		obj, err = s.Server.VariablesRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if list := obj.([]*structs.VariableListStub); len(list) != 1 || list[0].Path != "app/db" {
+			t.Fatalf("bad: %#v", list)
+		}
+
+		// Delete it
+		req, err = http.NewRequest("DELETE", "/v1/var/app/db", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+
+		if _, err := s.Server.VariableSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Reading a missing variable is a 404
+		req, err = http.NewRequest("GET", "/v1/var/app/db", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+
+		_, err = s.Server.VariableSpecificRequest(respW, req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 404 {
+			t.Fatalf("expected 404, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+type AllocExecCommand struct {
+	Meta
+}
+
+func (c *AllocExecCommand) Help() string {
+	helpText := `
+Usage: nomad alloc exec [options] <allocation> <command> [<args>...]
+
+  Run a command inside a task of an existing allocation. The command runs with
+  the same isolation as the task, and its standard streams are attached to the
+  terminal. The exit code of the command is returned. The task must be given
+  if the allocation has multiple tasks.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Exec Options:
+
+  -task <task>
+    Name of the task to run the command in.
+
+  -i
+    Forward stdin to the command. Defaults to true.
+
+  -t
+    Allocate a pseudo terminal to the command. Defaults to true if stdin
+    is a terminal.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AllocExecCommand) Synopsis() string {
+	return "Run a command inside a running allocation"
+}
+
+func (c *AllocExecCommand) Run(args []string) int {
+	var verbose, stdinOpt, tty bool
+	var task string
+
+	stdinFd := int(os.Stdin.Fd())
+	flags := c.Meta.FlagSet("alloc exec", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&task, "task", "", "")
+	flags.BoolVar(&stdinOpt, "i", true, "")
+	flags.BoolVar(&tty, "t", terminal.IsTerminal(stdinFd), "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the allocation ID and the command
+	args = flags.Args()
+	if len(args) < 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	allocID, command := args[0], args[1:]
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	alloc, err := getAllocByPrefix(client, allocID, length)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if err := validateAllocTask(alloc, task); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	var stdin io.Reader
+	if stdinOpt {
+		stdin = os.Stdin
+	}
+
+	// Put the terminal in raw mode so that the remote terminal handles the
+	// input, and keep the remote terminal the same size as the local one
+	var resizeCh chan api.TerminalSize
+	if tty && terminal.IsTerminal(stdinFd) {
+		state, err := terminal.MakeRaw(stdinFd)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error setting the terminal in raw mode: %s", err))
+			return 1
+		}
+		defer terminal.Restore(stdinFd, state)
+
+		resizeCh = make(chan api.TerminalSize, 1)
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go watchTerminalSize(stdinFd, resizeCh, stopCh)
+	}
+
+	code, err := client.Allocations().Exec(alloc, task, tty, command,
+		stdin, os.Stdout, os.Stderr, resizeCh, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error executing command: %s", err))
+		return 1
+	}
+	return code
+}
+
+// sendTerminalSize sends the current size of the terminal, if it can be
+// determined
+func sendTerminalSize(fd int, ch chan<- api.TerminalSize, stopCh <-chan struct{}) {
+	width, height, err := terminal.GetSize(fd)
+	if err != nil {
+		return
+	}
+
+	select {
+	case ch <- api.TerminalSize{Height: uint16(height), Width: uint16(width)}:
+	case <-stopCh:
+	}
+}
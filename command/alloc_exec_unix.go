@@ -0,0 +1,29 @@
+// +build !windows
+
+package command
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// watchTerminalSize sends the size of the terminal on the channel initially
+// and whenever it is resized, until the stop channel is closed.
+func watchTerminalSize(fd int, ch chan<- api.TerminalSize, stopCh <-chan struct{}) {
+	winchCh := make(chan os.Signal, 1)
+	signal.Notify(winchCh, syscall.SIGWINCH)
+	defer signal.Stop(winchCh)
+
+	sendTerminalSize(fd, ch, stopCh)
+	for {
+		select {
+		case <-winchCh:
+			sendTerminalSize(fd, ch, stopCh)
+		case <-stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package command
+
+import "github.com/hashicorp/nomad/api"
+
+// watchTerminalSize sends the initial size of the terminal on the channel.
+// Resizes are not detected as there is no SIGWINCH on Windows.
+func watchTerminalSize(fd int, ch chan<- api.TerminalSize, stopCh <-chan struct{}) {
+	sendTerminalSize(fd, ch, stopCh)
+}
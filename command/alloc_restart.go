@@ -0,0 +1,96 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type AllocRestartCommand struct {
+	Meta
+}
+
+func (c *AllocRestartCommand) Help() string {
+	helpText := `
+Usage: nomad alloc restart [options] <allocation> [<task>]
+
+  Restart the tasks of an existing allocation in place. If a task is given,
+  only that task is restarted. Restarts requested with this command do not
+  count against the restart policy of the task group.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Restart Options:
+
+  -task <task>
+    Name of the task to restart. This is an alternative to passing the
+    task as the second argument.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AllocRestartCommand) Synopsis() string {
+	return "Restart a running allocation or task"
+}
+
+func (c *AllocRestartCommand) Run(args []string) int {
+	var verbose bool
+	var task string
+
+	flags := c.Meta.FlagSet("alloc restart", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&task, "task", "", "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the allocation ID and optionally the task
+	args = flags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	allocID := args[0]
+	if len(args) == 2 {
+		if task != "" && task != args[1] {
+			c.Ui.Error("The task must be given either as an argument or with -task")
+			return 1
+		}
+		task = args[1]
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	alloc, err := getAllocByPrefix(client, allocID, length)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if err := validateAllocTask(alloc, task); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if err := client.Allocations().Restart(alloc, task, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error restarting allocation: %s", err))
+		return 1
+	}
+	return 0
+}
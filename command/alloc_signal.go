@@ -0,0 +1,100 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type AllocSignalCommand struct {
+	Meta
+}
+
+func (c *AllocSignalCommand) Help() string {
+	helpText := `
+Usage: nomad alloc signal [options] <allocation> [<task>]
+
+  Send a signal to the tasks of an existing allocation. If a task is given,
+  the signal is only sent to that task.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Signal Options:
+
+  -s <signal>
+    Name of the signal to send, such as SIGHUP or USR1. Defaults to
+    SIGKILL.
+
+  -task <task>
+    Name of the task to signal. This is an alternative to passing the
+    task as the second argument.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AllocSignalCommand) Synopsis() string {
+	return "Signal a running allocation or task"
+}
+
+func (c *AllocSignalCommand) Run(args []string) int {
+	var verbose bool
+	var signal, task string
+
+	flags := c.Meta.FlagSet("alloc signal", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&signal, "s", "SIGKILL", "")
+	flags.StringVar(&task, "task", "", "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the allocation ID and optionally the task
+	args = flags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	allocID := args[0]
+	if len(args) == 2 {
+		if task != "" && task != args[1] {
+			c.Ui.Error("The task must be given either as an argument or with -task")
+			return 1
+		}
+		task = args[1]
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	alloc, err := getAllocByPrefix(client, allocID, length)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if err := validateAllocTask(alloc, task); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if err := client.Allocations().Signal(alloc, task, signal, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error signalling allocation: %s", err))
+		return 1
+	}
+	return 0
+}
@@ -30,6 +30,10 @@ General Options:
   -short
     Display short output. Shows only the most recent task event.
 
+  -stats
+    Display the resource usage of the tasks against their resources. The usage
+    is read from the node running the allocation.
+
   -verbose
     Show full information.
 `
@@ -42,11 +46,12 @@ func (c *AllocStatusCommand) Synopsis() string {
 }
 
 func (c *AllocStatusCommand) Run(args []string) int {
-	var short, verbose bool
+	var short, stats, verbose bool
 
 	flags := c.Meta.FlagSet("alloc-status", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&short, "short", false, "")
+	flags.BoolVar(&stats, "stats", false, "")
 	flags.BoolVar(&verbose, "verbose", false, "")
 
 	if err := flags.Parse(args); err != nil {
@@ -144,6 +149,16 @@ func (c *AllocStatusCommand) Run(args []string) int {
 		c.taskStatus(alloc)
 	}
 
+	// Print the resource usage of each task.
+	if stats {
+		usage, err := client.Allocations().Stats(alloc, "", nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error reading resource usage: %s", err))
+			return 1
+		}
+		c.taskResourceUsage(alloc, usage)
+	}
+
 	// Format the detailed status
 	c.Ui.Output("\n==> Status")
 	dumpAllocStatus(c.Ui, alloc, length)
@@ -194,6 +209,14 @@ func (c *AllocStatusCommand) taskStatus(alloc *api.Allocation) {
 				desc = event.DriverError
 			case api.TaskKilled:
 				desc = event.KillError
+			case api.TaskRestartSignal:
+				desc = event.RestartReason
+			case api.TaskSignaling:
+				desc = fmt.Sprintf("Signal: %s", event.TaskSignal)
+			case api.TaskDiskExceeded:
+				const mb = 1024 * 1024
+				desc = fmt.Sprintf("Disk usage of %d MB over the %d MB allocated",
+					event.DiskSize/mb, event.DiskLimit/mb)
 			case api.TaskTerminated:
 				var parts []string
 				parts = append(parts, fmt.Sprintf("Exit Code: %d", event.ExitCode))
@@ -217,6 +240,35 @@ func (c *AllocStatusCommand) taskStatus(alloc *api.Allocation) {
 	}
 }
 
+// taskResourceUsage prints out the latest resource usage of each task against
+// its resources.
+func (c *AllocStatusCommand) taskResourceUsage(alloc *api.Allocation, usage map[string][]*api.TaskResourceUsage) {
+	resources := make([]string, 0, len(alloc.TaskStates)+1)
+	resources = append(resources, "Task|CPU|Memory|Swap")
+	for task := range c.sortedTaskStateIterator(alloc.TaskStates) {
+		var cpuRequest, memRequest int
+		if r, ok := alloc.TaskResources[task]; ok && r != nil {
+			cpuRequest, memRequest = r.CPU, r.MemoryMB
+		}
+
+		cpu, mem, swap := "-", "-", "-"
+		if samples := usage[task]; len(samples) != 0 {
+			latest := samples[len(samples)-1].ResourceUsage
+			if latest.CpuStats != nil {
+				cpu = fmt.Sprintf("%.0f/%d MHz", latest.CpuStats.TotalTicks, cpuRequest)
+			}
+			if latest.MemoryStats != nil {
+				mem = fmt.Sprintf("%d/%d MB", latest.MemoryStats.RSS/(1024*1024), memRequest)
+				swap = fmt.Sprintf("%d MB", latest.MemoryStats.Swap/(1024*1024))
+			}
+		}
+		resources = append(resources, fmt.Sprintf("%s|%s|%s|%s", task, cpu, mem, swap))
+	}
+
+	c.Ui.Output("\n==> Resource Usage")
+	c.Ui.Output(formatList(resources))
+}
+
 // formatUnixNanoTime is a helper for formating time for output.
 func (c *AllocStatusCommand) formatUnixNanoTime(nano int64) string {
 	t := time.Unix(0, nano)
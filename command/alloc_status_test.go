@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/mitchellh/cli"
 )
 
@@ -63,3 +64,41 @@ func TestAllocStatusCommand_Fails(t *testing.T) {
 	}
 
 }
+
+func TestAllocStatusCommand_TaskResourceUsage(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &AllocStatusCommand{Meta: Meta{Ui: ui}}
+
+	alloc := &api.Allocation{
+		TaskResources: map[string]*api.Resources{
+			"web": {CPU: 500, MemoryMB: 256},
+			"db":  {CPU: 1000, MemoryMB: 512},
+		},
+		TaskStates: map[string]*api.TaskState{
+			"web": {State: "running"},
+			"db":  {State: "pending"},
+		},
+	}
+	usage := map[string][]*api.TaskResourceUsage{
+		"web": {
+			{ResourceUsage: &api.ResourceUsage{
+				CpuStats:    &api.CpuStats{TotalTicks: 100},
+				MemoryStats: &api.MemoryStats{RSS: 8 * 1024 * 1024},
+			}},
+			{ResourceUsage: &api.ResourceUsage{
+				CpuStats:    &api.CpuStats{TotalTicks: 250},
+				MemoryStats: &api.MemoryStats{RSS: 12 * 1024 * 1024, Swap: 1024 * 1024},
+			}},
+		},
+	}
+	cmd.taskResourceUsage(alloc, usage)
+
+	// The latest sample is displayed against the resources of the task
+	out := ui.OutputWriter.String()
+	if !strings.Contains(out, "250/500 MHz") || !strings.Contains(out, "12/256 MB") || !strings.Contains(out, "1 MB") {
+		t.Fatalf("bad: %s", out)
+	}
+	if strings.Contains(out, "100/500 MHz") {
+		t.Fatalf("expected only the latest sample: %s", out)
+	}
+}
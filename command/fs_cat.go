@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+
+	"github.com/hashicorp/nomad/api"
 )
 
 type FSCatCommand struct {
@@ -24,6 +28,17 @@ func (f *FSCatCommand) Help() string {
 
   -verbose
     Show full information.
+
+  -f
+    Follow the file, displaying data as it is appended until interrupted or
+    the file is deleted.
+
+  -origin <start|end>
+    The origin of the offset. Defaults to start.
+
+  -offset <bytes>
+    The number of bytes from the origin to start displaying the file at.
+    Defaults to 0.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -33,16 +48,30 @@ func (f *FSCatCommand) Synopsis() string {
 }
 
 func (f *FSCatCommand) Run(args []string) int {
-	var verbose bool
+	var verbose, follow bool
+	var origin string
+	var offset int64
 	flags := f.Meta.FlagSet("fs-list", FlagSetClient)
 	flags.Usage = func() { f.Ui.Output(f.Help()) }
 	flags.BoolVar(&verbose, "verbose", false, "")
+	flags.BoolVar(&follow, "f", false, "")
+	flags.StringVar(&origin, "origin", "start", "")
+	flags.Int64Var(&offset, "offset", 0, "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
 	args = flags.Args()
 
+	if origin != "start" && origin != "end" {
+		f.Ui.Error("origin must be start or end")
+		return 1
+	}
+	if offset < 0 {
+		f.Ui.Error("offset must not be negative")
+		return 1
+	}
+
 	if len(args) < 1 {
 		f.Ui.Error("allocation id is a required parameter")
 		return 1
@@ -123,10 +152,22 @@ func (f *FSCatCommand) Run(args []string) int {
 		return 1
 	}
 
+	if follow {
+		return f.followFile(client, alloc, path, origin, offset)
+	}
+
 	// Get the contents of the file
-	offset := 0
-	limit := file.Size
-	r, _, err := client.AllocFS().ReadAt(alloc, path, int64(offset), limit, nil)
+	start := offset
+	if origin == "end" {
+		start = file.Size - offset
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > file.Size {
+		start = file.Size
+	}
+	r, _, err := client.AllocFS().ReadAt(alloc, path, start, file.Size-start, nil)
 	if err != nil {
 		f.Ui.Error(fmt.Sprintf("Error reading file: %v", err))
 		return 1
@@ -134,3 +175,35 @@ func (f *FSCatCommand) Run(args []string) int {
 	io.Copy(os.Stdout, r)
 	return 0
 }
+
+// followFile displays the file as data is appended to it, until interrupted
+// or the file is deleted
+func (f *FSCatCommand) followFile(client *api.Client, alloc *api.Allocation, path, origin string, offset int64) int {
+	cancel := make(chan struct{})
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalCh)
+	go func() {
+		<-signalCh
+		close(cancel)
+	}()
+
+	frames, errCh := client.AllocFS().Stream(alloc, path, origin, offset, cancel, nil)
+	for {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				return 0
+			}
+			switch frame.FileEvent {
+			case "":
+				os.Stdout.Write(frame.Data)
+			default:
+				f.Ui.Error(fmt.Sprintf("nomad: %s: %s", path, frame.FileEvent))
+			}
+		case err := <-errCh:
+			f.Ui.Error(fmt.Sprintf("Error streaming file: %v", err))
+			return 1
+		}
+	}
+}
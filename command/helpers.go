@@ -1,8 +1,10 @@
 package command
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/ryanuber/columnize"
 )
 
@@ -37,3 +39,72 @@ func limit(s string, length int) string {
 func formatTime(t time.Time) string {
 	return t.Format(time.RFC822)
 }
+
+// getAllocByPrefix returns the allocation with the given ID or with the given
+// unique ID prefix. If the prefix matches multiple allocations, the error
+// lists them.
+func getAllocByPrefix(client *api.Client, allocID string, length int) (*api.Allocation, error) {
+	alloc, _, err := client.Allocations().Info(allocID, nil)
+	if err == nil {
+		return alloc, nil
+	}
+
+	if len(allocID) == 1 {
+		return nil, fmt.Errorf("Identifier must contain at least two characters.")
+	}
+	if len(allocID)%2 == 1 {
+		// Identifiers must be of even length, so we strip off the last byte
+		// to provide a consistent user experience.
+		allocID = allocID[:len(allocID)-1]
+	}
+
+	allocs, _, err := client.Allocations().PrefixList(allocID)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying allocation: %v", err)
+	}
+	if len(allocs) == 0 {
+		return nil, fmt.Errorf("No allocation(s) with prefix or id %q found", allocID)
+	}
+	if len(allocs) > 1 {
+		// Format the allocs
+		out := make([]string, len(allocs)+1)
+		out[0] = "ID|Eval ID|Job ID|Task Group|Desired Status|Client Status"
+		for i, alloc := range allocs {
+			out[i+1] = fmt.Sprintf("%s|%s|%s|%s|%s|%s",
+				limit(alloc.ID, length),
+				limit(alloc.EvalID, length),
+				alloc.JobID,
+				alloc.TaskGroup,
+				alloc.DesiredStatus,
+				alloc.ClientStatus,
+			)
+		}
+		return nil, fmt.Errorf("Prefix matched multiple allocations\n\n%s", formatList(out))
+	}
+
+	// Prefix lookup matched a single allocation
+	alloc, _, err = client.Allocations().Info(allocs[0].ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying allocation: %s", err)
+	}
+	return alloc, nil
+}
+
+// validateAllocTask checks that the task, if given, belongs to the task group
+// of the allocation
+func validateAllocTask(alloc *api.Allocation, task string) error {
+	if task == "" || alloc.Job == nil {
+		return nil
+	}
+	for _, tg := range alloc.Job.TaskGroups {
+		if tg.Name != alloc.TaskGroup {
+			continue
+		}
+		for _, t := range tg.Tasks {
+			if t.Name == task {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("Could not find task named %q in allocation %q", task, alloc.ID)
+}
@@ -0,0 +1,216 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+const (
+	// bytesPerLine is the estimated number of bytes per line, used to
+	// request enough bytes to display the last lines of the logs
+	bytesPerLine = 120
+
+	// defaultTailLines is the number of lines displayed by -tail
+	defaultTailLines = 10
+)
+
+type LogsCommand struct {
+	Meta
+}
+
+func (l *LogsCommand) Help() string {
+	helpText := `
+Usage: nomad logs [options] <allocation> [<task>]
+
+  Display the logs of a task of an allocation. The task may be omitted if
+  the allocation has a single task. The logs are read across rotated log
+  files.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Logs Options:
+
+  -stderr
+    Display stderr logs instead of stdout.
+
+  -f
+    Follow the logs, displaying them as they are written until interrupted.
+
+  -tail
+    Only display the last lines of the logs. Defaults to the last 10 lines.
+
+  -n <lines>
+    Number of lines to display from the end of the logs, on a best-effort
+    basis. Implies -tail.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (l *LogsCommand) Synopsis() string {
+	return "Streams the logs of a task"
+}
+
+func (l *LogsCommand) Run(args []string) int {
+	var verbose, stderr, follow, tail bool
+	var lines int64
+
+	flags := l.Meta.FlagSet("logs", FlagSetClient)
+	flags.Usage = func() { l.Ui.Output(l.Help()) }
+	flags.BoolVar(&stderr, "stderr", false, "")
+	flags.BoolVar(&follow, "f", false, "")
+	flags.BoolVar(&tail, "tail", false, "")
+	flags.Int64Var(&lines, "n", -1, "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the allocation ID and optionally the task
+	args = flags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		l.Ui.Error(l.Help())
+		return 1
+	}
+	allocID := args[0]
+	var task string
+	if len(args) == 2 {
+		task = args[1]
+	}
+
+	if lines >= 0 {
+		tail = true
+	} else if tail {
+		lines = defaultTailLines
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Get the HTTP client
+	client, err := l.Meta.Client()
+	if err != nil {
+		l.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	alloc, err := getAllocByPrefix(client, allocID, length)
+	if err != nil {
+		l.Ui.Error(err.Error())
+		return 1
+	}
+	if task == "" {
+		if task, err = singleAllocTask(alloc); err != nil {
+			l.Ui.Error(err.Error())
+			return 1
+		}
+	} else if err := validateAllocTask(alloc, task); err != nil {
+		l.Ui.Error(err.Error())
+		return 1
+	}
+
+	logType := "stdout"
+	if stderr {
+		logType = "stderr"
+	}
+	origin, offset := "start", int64(0)
+	if tail {
+		origin, offset = "end", lines*bytesPerLine
+	}
+
+	// Stop following the logs when interrupted
+	cancel := make(chan struct{})
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalCh)
+	go func() {
+		<-signalCh
+		close(cancel)
+	}()
+
+	frames, errCh := client.AllocFS().Logs(alloc, follow, task, logType, origin, offset, cancel, nil)
+	if err := l.outputLogs(frames, errCh, tail, lines); err != nil {
+		l.Ui.Error(fmt.Sprintf("Error reading logs: %s", err))
+		return 1
+	}
+	return 0
+}
+
+// outputLogs writes the logs to stdout as they are received. When tailing,
+// only the last lines of the logs received until the end of the logs is
+// first reached are written.
+func (l *LogsCommand) outputLogs(frames <-chan *api.StreamFrame, errCh <-chan error, tail bool, lines int64) error {
+	var buf bytes.Buffer
+	for {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				if tail {
+					os.Stdout.Write(lastLines(buf.Bytes(), lines))
+				}
+				return nil
+			}
+			if tail {
+				if !frame.IsHeartbeat() {
+					buf.Write(frame.Data)
+					continue
+				}
+				os.Stdout.Write(lastLines(buf.Bytes(), lines))
+				tail = false
+			}
+			os.Stdout.Write(frame.Data)
+		case err := <-errCh:
+			return err
+		}
+	}
+}
+
+// lastLines returns the last lines of the data. A trailing newline does not
+// start a line.
+func lastLines(data []byte, lines int64) []byte {
+	if lines <= 0 {
+		return nil
+	}
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	for i := end - 1; i >= 0; i-- {
+		if data[i] == '\n' {
+			lines--
+			if lines == 0 {
+				return data[i+1:]
+			}
+		}
+	}
+	return data
+}
+
+// singleAllocTask returns the task of an allocation with a single task
+func singleAllocTask(alloc *api.Allocation) (string, error) {
+	tasks := make([]string, 0, len(alloc.TaskStates))
+	for name := range alloc.TaskStates {
+		tasks = append(tasks, name)
+	}
+	if len(tasks) != 1 {
+		sort.Strings(tasks)
+		return "", fmt.Errorf("Allocation %q has multiple tasks, a task must be given: %s",
+			alloc.ID, strings.Join(tasks, ", "))
+	}
+	return tasks[0], nil
+}
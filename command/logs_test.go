@@ -0,0 +1,53 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestLogsCommand_Implements(t *testing.T) {
+	var _ cli.Command = &LogsCommand{}
+}
+
+func TestLogsCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &LogsCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "foobar", "web"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error querying allocation") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
+
+func TestLogsCommand_LastLines(t *testing.T) {
+	cases := []struct {
+		data     string
+		lines    int64
+		expected string
+	}{
+		{"a\nb\nc\n", 2, "b\nc\n"},
+		{"a\nb\nc", 2, "b\nc"},
+		{"a\nb\nc\n", 5, "a\nb\nc\n"},
+		{"a\nb\nc\n", 0, ""},
+		{"", 3, ""},
+	}
+	for _, c := range cases {
+		if out := string(lastLines([]byte(c.data), c.lines)); out != c.expected {
+			t.Fatalf("%q %d: got %q; want %q", c.data, c.lines, out, c.expected)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package command
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
 )
 
 type NodeDrainCommand struct {
@@ -16,6 +19,12 @@ Usage: nomad node-drain [options] <node>
   Toggles node draining on a specified node. It is required
   that either -enable or -disable is specified, but not both.
 
+  A draining node is ineligible for scheduling and its allocations are
+  migrated to other nodes following the migrate strategy of their task
+  group, until the deadline after which all remaining allocations are
+  migrated at once. A node whose drain completes or is disabled stays
+  ineligible for scheduling unless it is explicitly made eligible.
+
 General Options:
 
   ` + generalOptionsUsage() + `
@@ -27,6 +36,26 @@ Node Drain Options:
 
   -enable
     Enable draining for the specified node.
+
+  -deadline <duration>
+    Set the deadline by which all allocations must be moved off the node.
+    Remaining allocations after the deadline are migrated regardless of
+    their migrate strategy. Defaults to 1h.
+
+  -no-deadline
+    No deadline is set and allocations are only migrated following their
+    migrate strategy.
+
+  -force
+    Migrate all allocations off the node at once, ignoring their migrate
+    strategy.
+
+  -ignore-system
+    Leave the allocations of system jobs on the node.
+
+  -keep-ineligible
+    Keep the node ineligible for scheduling when disabling draining. By
+    default the node is made eligible again.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -36,12 +65,18 @@ func (c *NodeDrainCommand) Synopsis() string {
 }
 
 func (c *NodeDrainCommand) Run(args []string) int {
-	var enable, disable bool
+	var enable, disable, noDeadline, force, ignoreSystem, keepIneligible bool
+	var deadline time.Duration
 
 	flags := c.Meta.FlagSet("node-drain", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&enable, "enable", false, "Enable drain mode")
 	flags.BoolVar(&disable, "disable", false, "Disable drain mode")
+	flags.DurationVar(&deadline, "deadline", time.Hour, "Deadline of the drain")
+	flags.BoolVar(&noDeadline, "no-deadline", false, "Drain without a deadline")
+	flags.BoolVar(&force, "force", false, "Migrate all allocations at once")
+	flags.BoolVar(&ignoreSystem, "ignore-system", false, "Leave system allocations")
+	flags.BoolVar(&keepIneligible, "keep-ineligible", false, "Keep the node ineligible")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -53,6 +88,16 @@ func (c *NodeDrainCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Validate the drain options
+	if noDeadline && force {
+		c.Ui.Error("-no-deadline and -force can not be used together")
+		return 1
+	}
+	if deadline <= 0 && !noDeadline && !force {
+		c.Ui.Error("The deadline must be a positive duration")
+		return 1
+	}
+
 	// Check that we got a node ID
 	args = flags.Args()
 	if len(args) != 1 {
@@ -118,8 +163,22 @@ func (c *NodeDrainCommand) Run(args []string) int {
 		}
 	}
 
+	// Build the drain spec, or stop the drain
+	var spec *api.DrainSpec
+	if enable {
+		spec = &api.DrainSpec{
+			Deadline:         deadline,
+			IgnoreSystemJobs: ignoreSystem,
+		}
+		if noDeadline {
+			spec.Deadline = 0
+		} else if force {
+			spec.Deadline = -1
+		}
+	}
+
 	// Toggle node draining
-	if _, err := client.Nodes().ToggleDrain(node.ID, enable, nil); err != nil {
+	if _, err := client.Nodes().UpdateDrain(node.ID, spec, !keepIneligible, nil); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error toggling drain mode: %s", err))
 		return 1
 	}
@@ -0,0 +1,85 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type NodeMetaApplyCommand struct {
+	Meta
+}
+
+func (c *NodeMetaApplyCommand) Help() string {
+	helpText := `
+Usage: nomad node meta apply [options] [<key>=<value>...]
+
+  Update the meta of the client node at runtime, without restarting the
+  agent. This command only works on client nodes. The given keys are set,
+  and the ones passed to -unset are removed, even if they are set in the
+  client configuration. The changes are persisted in the state directory of
+  the client and constraints are evaluated against them immediately.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Node Meta Apply Options:
+
+  -unset <key>[,<key>...]
+    Comma separated list of keys to remove from the meta.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeMetaApplyCommand) Synopsis() string {
+	return "Update the meta of a client node"
+}
+
+func (c *NodeMetaApplyCommand) Run(args []string) int {
+	var unset string
+
+	flags := c.Meta.FlagSet("node meta apply", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&unset, "unset", "", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got at least one update
+	args = flags.Args()
+	if len(args) == 0 && unset == "" {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	updates := make(map[string]*string, len(args))
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			c.Ui.Error(fmt.Sprintf("Invalid meta %q, expected <key>=<value>", arg))
+			return 1
+		}
+		value := parts[1]
+		updates[parts[0]] = &value
+	}
+	if unset != "" {
+		for _, key := range strings.Split(unset, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				updates[key] = nil
+			}
+		}
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	if _, err := client.Agent().ApplyNodeMeta(updates); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error applying node meta: %s", err))
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestNodeMetaApplyCommand_Implements(t *testing.T) {
+	var _ cli.Command = &NodeMetaApplyCommand{}
+}
+
+func TestNodeMetaApplyCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &NodeMetaApplyCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run(nil); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on malformed meta
+	if code := cmd.Run([]string{"novalue"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Invalid meta") {
+		t.Fatalf("expected invalid meta error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "rack=r1"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error applying node meta") {
+		t.Fatalf("expected failed request error, got: %s", out)
+	}
+}
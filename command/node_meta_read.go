@@ -0,0 +1,68 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type NodeMetaReadCommand struct {
+	Meta
+}
+
+func (c *NodeMetaReadCommand) Help() string {
+	helpText := `
+Usage: nomad node meta read [options]
+
+  Read the current meta of the client node, including the changes made at
+  runtime. This command only works on client nodes.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeMetaReadCommand) Synopsis() string {
+	return "Read the meta of a client node"
+}
+
+func (c *NodeMetaReadCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("node meta read", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	if args = flags.Args(); len(args) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	meta, err := client.Agent().NodeMeta()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading node meta: %s", err))
+		return 1
+	}
+
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = fmt.Sprintf("%s|%s", k, meta[k])
+	}
+	c.Ui.Output(formatKV(out))
+	return 0
+}
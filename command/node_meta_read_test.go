@@ -0,0 +1,34 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestNodeMetaReadCommand_Implements(t *testing.T) {
+	var _ cli.Command = &NodeMetaReadCommand{}
+}
+
+func TestNodeMetaReadCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &NodeMetaReadCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error reading node meta") {
+		t.Fatalf("expected failed request error, got: %s", out)
+	}
+}
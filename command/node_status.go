@@ -3,7 +3,12 @@ package command
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/nomad/structs"
 )
 
 type NodeStatusCommand struct {
@@ -32,6 +37,10 @@ Node Status Options:
     Display short output. Used only when a single node is being
     queried, and drops verbose output about node allocations.
 
+  -stats
+    Display the resource usage of the host of the node next to the resources
+    allocated on it. The usage is read from the node.
+
   -verbose
     Display full information.
 `
@@ -43,11 +52,12 @@ func (c *NodeStatusCommand) Synopsis() string {
 }
 
 func (c *NodeStatusCommand) Run(args []string) int {
-	var short, verbose bool
+	var short, stats, verbose bool
 
 	flags := c.Meta.FlagSet("node-status", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&short, "short", false, "")
+	flags.BoolVar(&stats, "stats", false, "")
 	flags.BoolVar(&verbose, "verbose", false, "")
 
 	if err := flags.Parse(args); err != nil {
@@ -90,14 +100,15 @@ func (c *NodeStatusCommand) Run(args []string) int {
 
 		// Format the nodes list
 		out := make([]string, len(nodes)+1)
-		out[0] = "ID|Datacenter|Name|Class|Drain|Status"
+		out[0] = "ID|Datacenter|Name|Class|Drain|Eligibility|Status"
 		for i, node := range nodes {
-			out[i+1] = fmt.Sprintf("%s|%s|%s|%s|%v|%s",
+			out[i+1] = fmt.Sprintf("%s|%s|%s|%s|%v|%s|%s",
 				limit(node.ID, length),
 				node.Datacenter,
 				node.Name,
 				node.NodeClass,
 				node.Drain,
+				node.SchedulingEligibility,
 				node.Status)
 		}
 
@@ -178,19 +189,35 @@ func (c *NodeStatusCommand) Run(args []string) int {
 		fmt.Sprintf("Class|%s", node.NodeClass),
 		fmt.Sprintf("Datacenter|%s", node.Datacenter),
 		fmt.Sprintf("Drain|%v", node.Drain),
+		fmt.Sprintf("Eligibility|%s", node.SchedulingEligibility),
 		fmt.Sprintf("Status|%s", node.Status),
 		fmt.Sprintf("Attributes|%s", strings.Join(attributes, ", ")),
 	}
 
-	var allocs []string
-	if !short {
+	var nodeAllocs []*api.Allocation
+	if !short || stats {
 		// Query the node allocations
-		nodeAllocs, _, err := client.Nodes().Allocations(node.ID, nil)
+		nodeAllocs, _, err = client.Nodes().Allocations(node.ID, nil)
 		if err != nil {
 			c.Ui.Error(fmt.Sprintf("Error querying node allocations: %s", err))
 			return 1
 		}
+	}
 
+	var hostStats *api.HostStats
+	if stats {
+		// Query the resource usage of the host
+		hostStats, err = client.Nodes().Stats(node.ID, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error querying node stats: %s", err))
+			return 1
+		}
+		uptime := time.Duration(hostStats.Uptime) * time.Second
+		basic = append(basic, fmt.Sprintf("Uptime|%s", uptime))
+	}
+
+	var allocs []string
+	if !short {
 		// Format the allocations
 		allocs = make([]string, len(nodeAllocs)+1)
 		allocs[0] = "ID|Eval ID|Job ID|Task Group|Desired Status|Client Status"
@@ -207,9 +234,58 @@ func (c *NodeStatusCommand) Run(args []string) int {
 
 	// Dump the output
 	c.Ui.Output(formatKV(basic))
+	if stats {
+		c.Ui.Output("\n==> Resource Utilization")
+		c.Ui.Output(formatList(nodeResourceUsage(node, nodeAllocs, hostStats)))
+	}
 	if !short {
 		c.Ui.Output("\n==> Allocations")
 		c.Ui.Output(formatList(allocs))
 	}
 	return 0
 }
+
+// nodeResourceUsage returns the rows comparing the resources allocated on a
+// node and the resources used on its host to its total resources
+func nodeResourceUsage(node *api.Node, allocs []*api.Allocation, hostStats *api.HostStats) []string {
+	var allocated api.Resources
+	for _, alloc := range allocs {
+		if alloc.DesiredStatus != structs.AllocDesiredStatusRun || alloc.Resources == nil {
+			continue
+		}
+		if alloc.ClientStatus != structs.AllocClientStatusPending &&
+			alloc.ClientStatus != structs.AllocClientStatusRunning {
+			continue
+		}
+		allocated.CPU += alloc.Resources.CPU
+		allocated.MemoryMB += alloc.Resources.MemoryMB
+		allocated.DiskMB += alloc.Resources.DiskMB
+	}
+
+	// The utilization of the cores is converted to MHz using their frequency
+	mhz, _ := strconv.ParseFloat(node.Attributes["cpu.frequency"], 64)
+	var usedCPU float64
+	for _, cpu := range hostStats.CPU {
+		usedCPU += cpu.Total / 100 * mhz
+	}
+	var usedMemory, usedDisk uint64
+	if hostStats.Memory != nil {
+		usedMemory = hostStats.Memory.Used
+	}
+	for _, disk := range hostStats.DiskStats {
+		usedDisk += disk.Used
+	}
+
+	var total api.Resources
+	if node.Resources != nil {
+		total = *node.Resources
+	}
+
+	const mb = 1024 * 1024
+	return []string{
+		"Resource|Allocated|Used|Total",
+		fmt.Sprintf("CPU|%d MHz|%.0f MHz|%d MHz", allocated.CPU, usedCPU, total.CPU),
+		fmt.Sprintf("Memory|%d MB|%d MB|%d MB", allocated.MemoryMB, usedMemory/mb, total.MemoryMB),
+		fmt.Sprintf("Disk|%d MB|%d MB|%d MB", allocated.DiskMB, usedDisk/mb, total.DiskMB),
+	}
+}
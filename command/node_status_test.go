@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/mitchellh/cli"
 )
@@ -156,3 +157,39 @@ func TestNodeStatusCommand_Fails(t *testing.T) {
 		t.Fatalf("expected too few characters error, got: %s", out)
 	}
 }
+
+func TestNodeResourceUsage(t *testing.T) {
+	node := &api.Node{
+		Attributes: map[string]string{"cpu.frequency": "2000.000000"},
+		Resources:  &api.Resources{CPU: 4000, MemoryMB: 4096, DiskMB: 10000},
+	}
+	allocs := []*api.Allocation{
+		{
+			DesiredStatus: "run",
+			ClientStatus:  "running",
+			Resources:     &api.Resources{CPU: 500, MemoryMB: 256, DiskMB: 300},
+		},
+		{
+			DesiredStatus: "run",
+			ClientStatus:  "dead",
+			Resources:     &api.Resources{CPU: 1000, MemoryMB: 1024, DiskMB: 300},
+		},
+	}
+	hostStats := &api.HostStats{
+		Memory:    &api.HostMemoryStats{Used: 1024 * 1024 * 1024},
+		CPU:       []*api.HostCPUStats{{Total: 50}, {Total: 25}},
+		DiskStats: []*api.HostDiskStats{{Used: 2048 * 1024 * 1024}},
+	}
+
+	// Terminal allocations do not count as allocated
+	out := formatList(nodeResourceUsage(node, allocs, hostStats))
+	for _, expected := range []string{
+		"CPU       500 MHz    1500 MHz  4000 MHz",
+		"Memory    256 MB     1024 MB   4096 MB",
+		"Disk      300 MB     2048 MB   10000 MB",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("expected %q in output:\n%s", expected, out)
+		}
+	}
+}
@@ -36,6 +36,12 @@ Usage: nomad run [options] <file>
   exit code will be 2. Any other errors, including client connection
   issues or internal errors, are indicated by exit code 1.
 
+  Jobs with a multiregion stanza are rolled out to each of their
+  regions by the servers in the background. Instead of entering the
+  monitor, the command waits for the rollout to finish, displays the
+  outcome of each region and returns exit code 1 if any region
+  failed. With -detach, it returns once the rollout has started.
+
 General Options:
 
   ` + generalOptionsUsage() + `
@@ -117,12 +123,24 @@ func (c *RunCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Multi-region jobs are rolled out by the servers, so there is no single
+	// evaluation to monitor.
+	if job.IsMultiregion() {
+		return c.runMultiregion(client, job, apiJob, detach, length)
+	}
+
 	// Submit the job
-	evalID, _, err := client.Jobs().Register(apiJob, nil)
+	resp, _, err := client.Jobs().RegisterWithResponse(apiJob, nil)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error submitting job: %s", err))
 		return 1
 	}
+	evalID := resp.EvalID
+
+	// Output any warnings raised by the servers
+	if resp.Warnings != "" {
+		c.Ui.Warn(fmt.Sprintf("Job Warnings:\n%s\n", resp.Warnings))
+	}
 
 	// Check if we should enter monitor mode
 	if detach || periodic {
@@ -142,6 +160,63 @@ func (c *RunCommand) Run(args []string) int {
 
 }
 
+// runMultiregion submits a multi-region job and, unless detached, waits for
+// its rollout to finish and outputs the outcome of each of its regions.
+func (c *RunCommand) runMultiregion(client *api.Client, job *structs.Job, apiJob *api.Job,
+	detach bool, length int) int {
+	if _, err := client.Jobs().RegisterMultiregion(apiJob, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error submitting job: %s", err))
+		return 1
+	}
+
+	if detach {
+		c.Ui.Output(fmt.Sprintf("Multi-region rollout of job %q started", job.ID))
+		return 0
+	}
+
+	// Wait for the rollout to finish
+	var rollout *api.MultiregionRollout
+	q := &api.QueryOptions{}
+	for {
+		var qm *api.QueryMeta
+		var err error
+		rollout, qm, err = client.Jobs().MultiregionStatus(job.ID, q)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error reading multi-region rollout: %s", err))
+			return 1
+		}
+		if rollout.Terminal() {
+			break
+		}
+		q.WaitIndex = qm.LastIndex
+	}
+
+	code := 0
+	out := make([]string, len(rollout.Regions)+1)
+	out[0] = "Region|Evaluation ID|Status"
+	for i, result := range rollout.Regions {
+		status := result.Status
+		if result.Error != "" {
+			status = fmt.Sprintf("%s: %s", status, result.Error)
+			code = 1
+		}
+		evalID := result.EvalID
+		if len(evalID) > length {
+			evalID = evalID[:length]
+		}
+		out[i+1] = fmt.Sprintf("%s|%s|%s", result.Region, evalID, status)
+	}
+
+	c.Ui.Output(formatList(out))
+
+	for _, result := range rollout.Regions {
+		if result.Warnings != "" {
+			c.Ui.Warn(fmt.Sprintf("\nJob Warnings (%s):\n%s", result.Region, result.Warnings))
+		}
+	}
+	return code
+}
+
 // convertStructJob is used to take a *structs.Job and convert it to an *api.Job.
 // This function is just a hammer and probably needs to be revisited.
 func convertStructJob(in *structs.Job) (*api.Job, error) {
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ServiceInfoCommand struct {
+	Meta
+}
+
+func (c *ServiceInfoCommand) Help() string {
+	helpText := `
+Usage: nomad service info [options] <service>
+
+  Display the instances of a service registered with the Nomad servers and
+  the address they can be reached at.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Service Info Options:
+
+  -verbose
+    Display full allocation and node IDs.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ServiceInfoCommand) Synopsis() string {
+	return "Display the instances of a service"
+}
+
+func (c *ServiceInfoCommand) Run(args []string) int {
+	var verbose bool
+
+	flags := c.Meta.FlagSet("service info", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&verbose, "verbose", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one service
+	if args = flags.Args(); len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name := args[0]
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	instances, _, err := client.Services().Get(name, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying service: %s", err))
+		return 1
+	}
+	if len(instances) == 0 {
+		c.Ui.Error(fmt.Sprintf("No instances of service %q found", name))
+		return 1
+	}
+
+	out := make([]string, len(instances)+1)
+	out[0] = "Job ID|Alloc ID|Node ID|Task|Address|Tags"
+	for i, s := range instances {
+		out[i+1] = fmt.Sprintf("%s|%s|%s|%s|%s:%d|%s",
+			s.JobID,
+			limit(s.AllocID, length),
+			limit(s.NodeID, length),
+			s.TaskName,
+			s.Address,
+			s.Port,
+			strings.Join(s.Tags, ","))
+	}
+	c.Ui.Output(formatList(out))
+	return 0
+}
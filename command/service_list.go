@@ -0,0 +1,76 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+type ServiceListCommand struct {
+	Meta
+}
+
+func (c *ServiceListCommand) Help() string {
+	helpText := `
+Usage: nomad service list [options] [<prefix>]
+
+  List the services registered with the Nomad servers, along with the tags
+  of their instances. If a prefix is given, only the services whose name
+  starts with it are listed. Services registered with Consul are not listed.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ServiceListCommand) Synopsis() string {
+	return "List services registered with Nomad"
+}
+
+func (c *ServiceListCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("service list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got at most one argument
+	args = flags.Args()
+	if len(args) > 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	q := &api.QueryOptions{}
+	if len(args) == 1 {
+		q.Prefix = args[0]
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	services, _, err := client.Services().List(q)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing services: %s", err))
+		return 1
+	}
+
+	// No output if we have no services
+	if len(services) == 0 {
+		return 0
+	}
+
+	out := make([]string, len(services)+1)
+	out[0] = "Service Name|Tags"
+	for i, s := range services {
+		out[i+1] = fmt.Sprintf("%s|%s", s.ServiceName, strings.Join(s.Tags, ","))
+	}
+	c.Ui.Output(formatList(out))
+	return 0
+}
@@ -4,17 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
+
+	"github.com/hashicorp/nomad/client/driver/logging"
 )
 
 type SpawnDaemonCommand struct {
 	Meta
 	config   *DaemonConfig
 	exitFile io.WriteCloser
+
+	// logWriters are the writers rotating the logs of the user command
+	logWriters []*logging.RotatedWriter
 }
 
 func (c *SpawnDaemonCommand) Help() string {
@@ -69,6 +77,11 @@ type DaemonConfig struct {
 	StdinFile  string
 	StderrFile string
 
+	// If set, the stdout and stderr files are the base paths of rotated log
+	// files, of which MaxLogFiles of MaxLogFileSize bytes are kept.
+	MaxLogFiles    int
+	MaxLogFileSize int64
+
 	// An optional path specifying the directory to chroot the process in.
 	Chroot string
 }
@@ -108,6 +121,10 @@ func (c *SpawnDaemonCommand) parseConfig(args []string) (*DaemonConfig, error) {
 // configureLogs creates the log files and redirects the process
 // stdin/stderr/stdout to them. If unsuccessful, an error is returned.
 func (c *SpawnDaemonCommand) configureLogs() error {
+	if c.config.MaxLogFiles > 0 {
+		return c.configureRotatedLogs()
+	}
+
 	if len(c.config.StdoutFile) != 0 {
 		stdo, err := os.OpenFile(c.config.StdoutFile, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
 		if err != nil {
@@ -136,6 +153,55 @@ func (c *SpawnDaemonCommand) configureLogs() error {
 	return nil
 }
 
+// configureRotatedLogs redirects the process stdout/stderr to rotated log
+// files and its stdin to the stdin file. If unsuccessful, an error is
+// returned.
+func (c *SpawnDaemonCommand) configureRotatedLogs() error {
+	logger := log.New(ioutil.Discard, "", 0)
+	rotated := func(path string) (*logging.RotatedWriter, error) {
+		w, err := logging.NewRotatedWriter(filepath.Dir(path), filepath.Base(path),
+			c.config.MaxLogFiles, c.config.MaxLogFileSize, logger)
+		if err != nil {
+			return nil, fmt.Errorf("Error creating log rotator for %s: %v", path, err)
+		}
+		c.logWriters = append(c.logWriters, w)
+		return w, nil
+	}
+
+	if len(c.config.StdoutFile) != 0 {
+		w, err := rotated(c.config.StdoutFile)
+		if err != nil {
+			return err
+		}
+		c.config.Cmd.Stdout = w
+	}
+
+	if len(c.config.StderrFile) != 0 {
+		w, err := rotated(c.config.StderrFile)
+		if err != nil {
+			return err
+		}
+		c.config.Cmd.Stderr = w
+	}
+
+	if len(c.config.StdinFile) != 0 {
+		stdi, err := os.OpenFile(c.config.StdinFile, os.O_CREATE|os.O_RDONLY, 0666)
+		if err != nil {
+			return fmt.Errorf("Error opening file to redirect stdin: %v", err)
+		}
+		c.config.Cmd.Stdin = stdi
+	}
+
+	return nil
+}
+
+// closeLogs waits for the logs of the user command to be written
+func (c *SpawnDaemonCommand) closeLogs() {
+	for _, w := range c.logWriters {
+		w.Close()
+	}
+}
+
 func (c *SpawnDaemonCommand) Run(args []string) int {
 	var err error
 	c.config, err = c.parseConfig(args)
@@ -184,8 +250,10 @@ func (c *SpawnDaemonCommand) Run(args []string) int {
 	// Indicate that the command was started successfully.
 	c.outputStartStatus(nil, 0)
 
-	// Wait and then output the exit status.
-	return c.writeExitStatus(c.config.Cmd.Wait())
+	// Wait and then output the exit status once the logs are written.
+	exit := c.config.Cmd.Wait()
+	c.closeLogs()
+	return c.writeExitStatus(exit)
 }
 
 // outputStartStatus is a helper function that outputs a SpawnStartStatus to
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -31,8 +32,8 @@ Status Options:
 
   -short
     Display short output. Used only when a single job is being
-    queried, and drops verbose information about allocations
-    and evaluations.
+    queried, and drops the summary of the job and verbose
+    information about allocations and evaluations.
 
   -verbose
     Display full information.
@@ -157,8 +158,9 @@ func (c *StatusCommand) Run(args []string) int {
 	}
 
 	if periodic {
-		basic = append(basic, fmt.Sprintf("Next Periodic Launch|%v",
-			sJob.Periodic.Next(time.Now())))
+		location := sJob.Periodic.GetLocation()
+		basic = append(basic, fmt.Sprintf("Next Periodic Launch|%v (%s)",
+			sJob.Periodic.Next(time.Now().In(location)), location))
 	}
 
 	c.Ui.Output(formatKV(basic))
@@ -168,6 +170,12 @@ func (c *StatusCommand) Run(args []string) int {
 		return 0
 	}
 
+	// Print the summary of the allocations and launched jobs
+	if err := c.outputJobSummary(client, job); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
 	// Print periodic job information
 	if periodic {
 		if err := c.outputPeriodicInfo(client, job); err != nil {
@@ -186,6 +194,53 @@ func (c *StatusCommand) Run(args []string) int {
 	return 0
 }
 
+// outputJobSummary prints the allocation counts of each task group of the
+// job and the status counts of the jobs it launched. If the request fails,
+// an error is returned.
+func (c *StatusCommand) outputJobSummary(client *api.Client, job *api.Job) error {
+	summary, _, err := client.Jobs().Summary(job.ID, nil)
+	if err != nil {
+		return fmt.Errorf("Error querying job summary: %s", err)
+	}
+
+	if len(summary.Summary) != 0 {
+		taskGroups := make([]string, 0, len(summary.Summary))
+		for name := range summary.Summary {
+			taskGroups = append(taskGroups, name)
+		}
+		sort.Strings(taskGroups)
+
+		out := make([]string, len(taskGroups)+1)
+		out[0] = "Task Group|Queued|Starting|Running|Failed|Complete|Lost"
+		for i, name := range taskGroups {
+			tg := summary.Summary[name]
+			out[i+1] = fmt.Sprintf("%s|%d|%d|%d|%d|%d|%d",
+				name,
+				tg.Queued,
+				tg.Starting,
+				tg.Running,
+				tg.Failed,
+				tg.Complete,
+				tg.Lost)
+		}
+		c.Ui.Output("\n==> Summary")
+		c.Ui.Output(formatList(out))
+	}
+
+	if summary.Children != nil {
+		out := []string{
+			"Pending|Running|Dead",
+			fmt.Sprintf("%d|%d|%d",
+				summary.Children.Pending,
+				summary.Children.Running,
+				summary.Children.Dead),
+		}
+		c.Ui.Output("\n==> Children Job Summary")
+		c.Ui.Output(formatList(out))
+	}
+	return nil
+}
+
 // outputPeriodicInfo prints information about the passed periodic job. If a
 // request fails, an error is returned.
 func (c *StatusCommand) outputPeriodicInfo(client *api.Client, job *api.Job) error {
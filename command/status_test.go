@@ -60,6 +60,9 @@ func TestStatusCommand_Run(t *testing.T) {
 	if strings.Contains(out, "job1_sfx") || !strings.Contains(out, "job2_sfx") {
 		t.Fatalf("expected only job2_sfx, got: %s", out)
 	}
+	if !strings.Contains(out, "Summary") {
+		t.Fatalf("should dump job summary")
+	}
 	if !strings.Contains(out, "Evaluations") {
 		t.Fatalf("should dump evaluations")
 	}
@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type VarGetCommand struct {
+	Meta
+}
+
+func (c *VarGetCommand) Help() string {
+	helpText := `
+Usage: nomad var get [options] <path>
+
+  Read the variable stored at the given path and display its items.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Get Options:
+
+  -item=<key>
+    Only output the raw value of the given item. This is useful for scripts.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarGetCommand) Synopsis() string {
+	return "Read a variable"
+}
+
+func (c *VarGetCommand) Run(args []string) int {
+	var item string
+
+	flags := c.Meta.FlagSet("var get", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&item, "item", "", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	if args = flags.Args(); len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	path := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	variable, _, err := client.Variables().Read(path, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading variable: %s", err))
+		return 1
+	}
+
+	if item != "" {
+		value, ok := variable.Items[item]
+		if !ok {
+			c.Ui.Error(fmt.Sprintf("Variable %q has no item %q", path, item))
+			return 1
+		}
+		c.Ui.Output(value)
+		return 0
+	}
+
+	keys := make([]string, 0, len(variable.Items))
+	for k := range variable.Items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = fmt.Sprintf("%s|%s", k, variable.Items[k])
+	}
+	c.Ui.Output(formatKV(out))
+	return 0
+}
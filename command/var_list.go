@@ -0,0 +1,76 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+type VarListCommand struct {
+	Meta
+}
+
+func (c *VarListCommand) Help() string {
+	helpText := `
+Usage: nomad var list [options] [<prefix>]
+
+  List the paths of the stored variables. If a prefix is given, only the
+  variables whose path starts with it are listed. The items of the variables
+  are not displayed.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarListCommand) Synopsis() string {
+	return "List variables"
+}
+
+func (c *VarListCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("var list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got at most one argument
+	args = flags.Args()
+	if len(args) > 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	q := &api.QueryOptions{}
+	if len(args) == 1 {
+		q.Prefix = args[0]
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	variables, _, err := client.Variables().List(q)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing variables: %s", err))
+		return 1
+	}
+
+	// No output if we have no variables
+	if len(variables) == 0 {
+		return 0
+	}
+
+	out := make([]string, len(variables)+1)
+	out[0] = "Path|Modify Index"
+	for i, v := range variables {
+		out[i+1] = fmt.Sprintf("%s|%d", v.Path, v.ModifyIndex)
+	}
+	c.Ui.Output(formatList(out))
+	return 0
+}
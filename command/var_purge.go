@@ -0,0 +1,56 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type VarPurgeCommand struct {
+	Meta
+}
+
+func (c *VarPurgeCommand) Help() string {
+	helpText := `
+Usage: nomad var purge [options] <path>
+
+  Permanently delete the variable stored at the given path.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarPurgeCommand) Synopsis() string {
+	return "Delete a variable"
+}
+
+func (c *VarPurgeCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("var purge", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	if args = flags.Args(); len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	path := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	if _, err := client.Variables().Delete(path, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error deleting variable: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Deleted variable %q", path))
+	return 0
+}
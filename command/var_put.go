@@ -0,0 +1,72 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+type VarPutCommand struct {
+	Meta
+}
+
+func (c *VarPutCommand) Help() string {
+	helpText := `
+Usage: nomad var put [options] <path> <key>=<value> [<key>=<value>...]
+
+  Write a variable to the given path. The items replace any existing items of
+  the variable at the path. Variables are encrypted by the servers before they
+  are stored.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarPutCommand) Synopsis() string {
+	return "Create or update a variable"
+}
+
+func (c *VarPutCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("var put", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got a path and at least one item
+	if args = flags.Args(); len(args) < 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	variable := &api.Variable{
+		Path:  args[0],
+		Items: make(map[string]string, len(args)-1),
+	}
+	for _, arg := range args[1:] {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			c.Ui.Error(fmt.Sprintf("Invalid item %q, expected <key>=<value>", arg))
+			return 1
+		}
+		variable.Items[parts[0]] = parts[1]
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	if _, err := client.Variables().Put(variable, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing variable: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Wrote variable %q", variable.Path))
+	return 0
+}
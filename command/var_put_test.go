@@ -0,0 +1,43 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestVarPutCommand_Implements(t *testing.T) {
+	var _ cli.Command = &VarPutCommand{}
+}
+
+func TestVarPutCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &VarPutCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"app/db"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on malformed items
+	if code := cmd.Run([]string{"app/db", "novalue"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Invalid item") {
+		t.Fatalf("expected invalid item error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "app/db", "a=b"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error writing variable") {
+		t.Fatalf("expected failed request error, got: %s", out)
+	}
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type VarRotateKeyCommand struct {
+	Meta
+}
+
+func (c *VarRotateKeyCommand) Help() string {
+	helpText := `
+Usage: nomad var rotate-key [options]
+
+  Create a new root key used to encrypt the variables written from now on.
+  The previous root keys are kept so the variables written with them can
+  still be read.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarRotateKeyCommand) Synopsis() string {
+	return "Rotate the root key variables are encrypted with"
+}
+
+func (c *VarRotateKeyCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("var rotate-key", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	if args = flags.Args(); len(args) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	keyID, _, err := client.Keyring().Rotate(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error rotating root key: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Rotated root key, new key ID %q", keyID))
+	return 0
+}
@@ -24,6 +24,26 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 	}
 
 	return map[string]cli.CommandFactory{
+		"alloc exec": func() (cli.Command, error) {
+			return &command.AllocExecCommand{
+				Meta: meta,
+			}, nil
+		},
+		"alloc restart": func() (cli.Command, error) {
+			return &command.AllocRestartCommand{
+				Meta: meta,
+			}, nil
+		},
+		"alloc signal": func() (cli.Command, error) {
+			return &command.AllocSignalCommand{
+				Meta: meta,
+			}, nil
+		},
+		"alloc stop": func() (cli.Command, error) {
+			return &command.AllocStopCommand{
+				Meta: meta,
+			}, nil
+		},
 		"alloc-status": func() (cli.Command, error) {
 			return &command.AllocStatusCommand{
 				Meta: meta,
@@ -78,12 +98,35 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 			}, nil
 		},
 
+		"logs": func() (cli.Command, error) {
+			return &command.LogsCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"node-drain": func() (cli.Command, error) {
 			return &command.NodeDrainCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"node-eligibility": func() (cli.Command, error) {
+			return &command.NodeEligibilityCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"node meta apply": func() (cli.Command, error) {
+			return &command.NodeMetaApplyCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node meta read": func() (cli.Command, error) {
+			return &command.NodeMetaReadCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"node-status": func() (cli.Command, error) {
 			return &command.NodeStatusCommand{
 				Meta: meta,
@@ -114,6 +157,17 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 			}, nil
 		},
 
+		"service info": func() (cli.Command, error) {
+			return &command.ServiceInfoCommand{
+				Meta: meta,
+			}, nil
+		},
+		"service list": func() (cli.Command, error) {
+			return &command.ServiceListCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"spawn-daemon": func() (cli.Command, error) {
 			return &command.SpawnDaemonCommand{
 				Meta: meta,
@@ -132,6 +186,38 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 			}, nil
 		},
 
+		"system gc": func() (cli.Command, error) {
+			return &command.SystemGCCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"var get": func() (cli.Command, error) {
+			return &command.VarGetCommand{
+				Meta: meta,
+			}, nil
+		},
+		"var list": func() (cli.Command, error) {
+			return &command.VarListCommand{
+				Meta: meta,
+			}, nil
+		},
+		"var purge": func() (cli.Command, error) {
+			return &command.VarPurgeCommand{
+				Meta: meta,
+			}, nil
+		},
+		"var put": func() (cli.Command, error) {
+			return &command.VarPutCommand{
+				Meta: meta,
+			}, nil
+		},
+		"var rotate-key": func() (cli.Command, error) {
+			return &command.VarRotateKeyCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"validate": func() (cli.Command, error) {
 			return &command.ValidateCommand{
 				Meta: meta,
@@ -91,6 +91,7 @@ func parseJob(result *structs.Job, list *ast.ObjectList) error {
 	delete(m, "meta")
 	delete(m, "update")
 	delete(m, "periodic")
+	delete(m, "multiregion")
 
 	// Set the ID and name to the object key
 	result.ID = obj.Keys[0].Token.Value().(string)
@@ -102,7 +103,15 @@ func parseJob(result *structs.Job, list *ast.ObjectList) error {
 	result.Type = "service"
 
 	// Decode the rest
-	if err := mapstructure.WeakDecode(m, result); err != nil {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           result,
+	})
+	if err != nil {
+		return err
+	}
+	if err := dec.Decode(m); err != nil {
 		return err
 	}
 
@@ -135,6 +144,13 @@ func parseJob(result *structs.Job, list *ast.ObjectList) error {
 		}
 	}
 
+	// If we have a multiregion definition, then parse that
+	if o := listVal.Filter("multiregion"); len(o.Items) > 0 {
+		if err := parseMultiregion(&result.Multiregion, o); err != nil {
+			return err
+		}
+	}
+
 	// Parse out meta fields. These are in HCL as a list so we need
 	// to iterate over them and merge them.
 	if metaO := listVal.Filter("meta"); len(metaO.Items) > 0 {
@@ -210,6 +226,8 @@ func parseGroups(result *structs.Job, list *ast.ObjectList) error {
 		delete(m, "meta")
 		delete(m, "task")
 		delete(m, "restart")
+		delete(m, "migrate")
+		delete(m, "volume")
 
 		// Default count to 1 if not specified
 		if _, ok := m["count"]; !ok {
@@ -219,7 +237,15 @@ func parseGroups(result *structs.Job, list *ast.ObjectList) error {
 		// Build the group with the basic decode
 		var g structs.TaskGroup
 		g.Name = n
-		if err := mapstructure.WeakDecode(m, &g); err != nil {
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &g,
+		})
+		if err != nil {
+			return err
+		}
+		if err := dec.Decode(m); err != nil {
 			return err
 		}
 
@@ -237,6 +263,20 @@ func parseGroups(result *structs.Job, list *ast.ObjectList) error {
 			}
 		}
 
+		// Parse migrate strategy
+		if o := listVal.Filter("migrate"); len(o.Items) > 0 {
+			if err := parseMigrate(&g.Migrate, o); err != nil {
+				return err
+			}
+		}
+
+		// Parse the volumes the tasks may mount
+		if o := listVal.Filter("volume"); len(o.Items) > 0 {
+			if err := parseVolumes(&g.Volumes, o); err != nil {
+				return fmt.Errorf("group '%s': %s", n, err)
+			}
+		}
+
 		// Parse out meta fields. These are in HCL as a list so we need
 		// to iterate over them and merge them.
 		if metaO := listVal.Filter("meta"); len(metaO.Items) > 0 {
@@ -296,6 +336,39 @@ func parseRestartPolicy(final **structs.RestartPolicy, list *ast.ObjectList) err
 	return nil
 }
 
+// parseMigrate parses the migrate block of a group. Fields that are not set
+// take their default value.
+func parseMigrate(final **structs.MigrateStrategy, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'migrate' block allowed")
+	}
+
+	// Get our migrate object
+	obj := list.Items[0]
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
+		return err
+	}
+
+	result := structs.DefaultMigrateStrategy()
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           result,
+	})
+	if err != nil {
+		return err
+	}
+	if err := dec.Decode(m); err != nil {
+		return err
+	}
+
+	*final = result
+	return nil
+}
+
 func parseConstraints(result *[]*structs.Constraint, list *ast.ObjectList) error {
 	for _, o := range list.Elem().Items {
 		var m map[string]interface{}
@@ -401,6 +474,9 @@ func parseTasks(jobName string, taskGroupName string, result *[]*structs.Task, l
 		delete(m, "service")
 		delete(m, "meta")
 		delete(m, "resources")
+		delete(m, "variable")
+		delete(m, "logs")
+		delete(m, "volume_mount")
 
 		// Build the task
 		var t structs.Task
@@ -484,12 +560,100 @@ func parseTasks(jobName string, taskGroupName string, result *[]*structs.Task, l
 			t.Resources = &r
 		}
 
+		// Parse the variables the task references
+		if o := listVal.Filter("variable"); len(o.Items) > 0 {
+			if err := parseTaskVariables(&t.Variables, o); err != nil {
+				return fmt.Errorf("task '%s': %s", t.Name, err)
+			}
+		}
+
+		// Parse the volumes of the group mounted in the task
+		if o := listVal.Filter("volume_mount"); len(o.Items) > 0 {
+			if err := parseVolumeMounts(&t.VolumeMounts, o); err != nil {
+				return fmt.Errorf("task '%s': %s", t.Name, err)
+			}
+		}
+
+		// If we have logs, then parse that
+		if o := listVal.Filter("logs"); len(o.Items) > 0 {
+			if len(o.Items) > 1 {
+				return fmt.Errorf("task '%s': only one 'logs' block allowed", t.Name)
+			}
+			var m map[string]interface{}
+			if err := hcl.DecodeObject(&m, o.Items[0].Val); err != nil {
+				return err
+			}
+
+			var log structs.LogConfig
+			if err := mapstructure.WeakDecode(m, &log); err != nil {
+				return fmt.Errorf("task '%s': logs: %s", t.Name, err)
+			}
+			t.LogConfig = &log
+		}
+
 		*result = append(*result, &t)
 	}
 
 	return nil
 }
 
+func parseTaskVariables(result *[]*structs.TaskVariable, list *ast.ObjectList) error {
+	for _, item := range list.Children().Items {
+		path := item.Keys[0].Token.Value().(string)
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		variable := &structs.TaskVariable{Path: path}
+		if err := mapstructure.WeakDecode(m, variable); err != nil {
+			return fmt.Errorf("variable '%s': %s", path, err)
+		}
+		*result = append(*result, variable)
+	}
+	return nil
+}
+
+func parseVolumes(result *map[string]*structs.VolumeRequest, list *ast.ObjectList) error {
+	volumes := make(map[string]*structs.VolumeRequest)
+	for _, item := range list.Children().Items {
+		name := item.Keys[0].Token.Value().(string)
+		if _, ok := volumes[name]; ok {
+			return fmt.Errorf("volume '%s' defined more than once", name)
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		volume := &structs.VolumeRequest{Name: name}
+		if err := mapstructure.WeakDecode(m, volume); err != nil {
+			return fmt.Errorf("volume '%s': %s", name, err)
+		}
+		volumes[name] = volume
+	}
+	*result = volumes
+	return nil
+}
+
+func parseVolumeMounts(result *[]*structs.VolumeMount, list *ast.ObjectList) error {
+	for _, item := range list.Elem().Items {
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		var mount structs.VolumeMount
+		if err := mapstructure.WeakDecode(m, &mount); err != nil {
+			return fmt.Errorf("volume_mount: %s", err)
+		}
+		*result = append(*result, &mount)
+	}
+	return nil
+}
+
 func parseServices(jobName string, taskGroupName string, task *structs.Task, serviceObjs *ast.ObjectList) error {
 	task.Services = make([]*structs.Service, len(serviceObjs.Items))
 	var defaultServiceName bool
@@ -720,3 +884,83 @@ func parsePeriodic(result **structs.PeriodicConfig, list *ast.ObjectList) error
 	*result = &p
 	return nil
 }
+
+func parseMultiregion(result **structs.Multiregion, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'multiregion' block allowed per job")
+	}
+
+	// Value should be an object
+	var listVal *ast.ObjectList
+	if ot, ok := list.Items[0].Val.(*ast.ObjectType); ok {
+		listVal = ot.List
+	} else {
+		return fmt.Errorf("multiregion: should be an object")
+	}
+
+	var mr structs.Multiregion
+
+	// Parse the rollout strategy
+	if o := listVal.Filter("strategy"); len(o.Items) > 0 {
+		o = o.Elem()
+		if len(o.Items) > 1 {
+			return fmt.Errorf("only one 'strategy' block allowed per multiregion")
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Items[0].Val); err != nil {
+			return err
+		}
+
+		var strategy structs.MultiregionStrategy
+		if err := mapstructure.WeakDecode(m, &strategy); err != nil {
+			return err
+		}
+		mr.Strategy = &strategy
+	}
+
+	// Parse the regions, keeping the order they are defined in as it is the
+	// rollout order.
+	for _, item := range listVal.Filter("region").Children().Items {
+		name := item.Keys[0].Token.Value().(string)
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+		delete(m, "meta")
+
+		region := &structs.MultiregionRegion{Name: name}
+		if err := mapstructure.WeakDecode(m, region); err != nil {
+			return fmt.Errorf("region '%s': %s", name, err)
+		}
+
+		// Value should be an object
+		var regionVal *ast.ObjectList
+		if ot, ok := item.Val.(*ast.ObjectType); ok {
+			regionVal = ot.List
+		} else {
+			return fmt.Errorf("region '%s': should be an object", name)
+		}
+
+		// Parse out meta fields. These are in HCL as a list so we need
+		// to iterate over them and merge them.
+		if metaO := regionVal.Filter("meta"); len(metaO.Items) > 0 {
+			for _, o := range metaO.Elem().Items {
+				var m map[string]interface{}
+				if err := hcl.DecodeObject(&m, o.Val); err != nil {
+					return err
+				}
+				if err := mapstructure.WeakDecode(m, &region.Meta); err != nil {
+					return err
+				}
+			}
+		}
+
+		mr.Regions = append(mr.Regions, region)
+	}
+
+	*result = &mr
+	return nil
+}
@@ -63,8 +63,9 @@ func TestParse(t *testing.T) {
 					},
 
 					&structs.TaskGroup{
-						Name:  "binsl",
-						Count: 5,
+						Name:                "binsl",
+						Count:               5,
+						MaxClientDisconnect: 5 * time.Minute,
 						Constraints: []*structs.Constraint{
 							&structs.Constraint{
 								LTarget: "kernel.os",
@@ -240,6 +241,70 @@ func TestParse(t *testing.T) {
 					SpecType:        structs.PeriodicSpecCron,
 					Spec:            "*/5 * * *",
 					ProhibitOverlap: true,
+					TimeZone:        "Europe/Minsk",
+				},
+			},
+			false,
+		},
+
+		{
+			"periodic-catchup.hcl",
+			&structs.Job{
+				ID:       "foo",
+				Name:     "foo",
+				Priority: 50,
+				Region:   "global",
+				Type:     "service",
+				Periodic: &structs.PeriodicConfig{
+					Enabled:      true,
+					SpecType:     structs.PeriodicSpecCron,
+					Spec:         "*/5 * * *",
+					Catchup:      structs.PeriodicCatchupAll,
+					CatchupLimit: 3,
+				},
+			},
+			false,
+		},
+
+		{
+			"gc-threshold.hcl",
+			&structs.Job{
+				ID:          "foo",
+				Name:        "foo",
+				Priority:    50,
+				Region:      "global",
+				Type:        "batch",
+				GCThreshold: 30 * time.Minute,
+			},
+			false,
+		},
+
+		{
+			"multiregion.hcl",
+			&structs.Job{
+				ID:       "foo",
+				Name:     "foo",
+				Priority: 50,
+				Region:   "global",
+				Type:     "service",
+				Multiregion: &structs.Multiregion{
+					Strategy: &structs.MultiregionStrategy{
+						MaxParallel: 1,
+					},
+					Regions: []*structs.MultiregionRegion{
+						&structs.MultiregionRegion{
+							Name:        "east",
+							Count:       2,
+							Datacenters: []string{"east-1"},
+							Meta: map[string]string{
+								"tier": "primary",
+							},
+						},
+						&structs.MultiregionRegion{
+							Name:        "west",
+							Datacenters: []string{"west-1", "west-2"},
+						},
+					},
 				},
 			},
 			false,
@@ -288,6 +353,180 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+
+		{
+			"task-variables.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "bar",
+								Driver: "docker",
+								Variables: []*structs.TaskVariable{
+									&structs.TaskVariable{
+										Path: "app/db",
+										Env:  true,
+									},
+									&structs.TaskVariable{
+										Path:        "app/tls",
+										Destination: "secrets/tls.json",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"task-logs.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "bar",
+								Driver: "docker",
+								LogConfig: &structs.LogConfig{
+									MaxFiles:      5,
+									MaxFileSizeMB: 20,
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"service-provider.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "bar",
+								Driver: "docker",
+								Services: []*structs.Service{
+									{
+										Name:      "frontend",
+										PortLabel: "http",
+										Provider:  structs.ServiceProviderNomad,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"migrate.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Migrate: &structs.MigrateStrategy{
+							MaxParallel:     2,
+							MinHealthyTime:  30 * time.Second,
+							HealthyDeadline: 5 * time.Minute,
+						},
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "bar",
+								Driver: "docker",
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"volumes.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Volumes: map[string]*structs.VolumeRequest{
+							"data": &structs.VolumeRequest{
+								Name:   "data",
+								Type:   "host",
+								Source: "mysql",
+							},
+							"certs": &structs.VolumeRequest{
+								Name:     "certs",
+								Source:   "certs",
+								ReadOnly: true,
+							},
+						},
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "bar",
+								Driver: "docker",
+								VolumeMounts: []*structs.VolumeMount{
+									&structs.VolumeMount{
+										Volume:      "data",
+										Destination: "/var/lib/mysql",
+									},
+									&structs.VolumeMount{
+										Volume:      "certs",
+										Destination: "/etc/ssl/certs",
+										ReadOnly:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
 	}
 
 	for _, tc := range cases {
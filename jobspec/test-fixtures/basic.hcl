@@ -31,6 +31,7 @@ job "binstore-storagelocker" {
 
     group "binsl" {
         count = 5
+        max_client_disconnect = "5m"
         restart {
             attempts = 5
             interval = "10m"
@@ -0,0 +1,4 @@
+job "foo" {
+    type = "batch"
+    gc_threshold = "30m"
+}
@@ -0,0 +1,12 @@
+job "foo" {
+    group "bar" {
+        migrate {
+            max_parallel = 2
+            min_healthy_time = "30s"
+        }
+
+        task "bar" {
+            driver = "docker"
+        }
+    }
+}
@@ -0,0 +1,19 @@
+job "foo" {
+    multiregion {
+        strategy {
+            max_parallel = 1
+        }
+
+        region "east" {
+            count = 2
+            datacenters = ["east-1"]
+            meta {
+                tier = "primary"
+            }
+        }
+
+        region "west" {
+            datacenters = ["west-1", "west-2"]
+        }
+    }
+}
@@ -0,0 +1,7 @@
+job "foo" {
+    periodic {
+        cron = "*/5 * * *"
+        catchup = "all"
+        catchup_limit = 3
+    }
+}
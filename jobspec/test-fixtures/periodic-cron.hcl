@@ -2,5 +2,6 @@ job "foo" {
     periodic {
         cron = "*/5 * * *"
         prohibit_overlap = true
+        time_zone = "Europe/Minsk"
     }
 }
@@ -0,0 +1,11 @@
+job "foo" {
+    task "bar" {
+        driver = "docker"
+
+        service {
+            name = "frontend"
+            port = "http"
+            provider = "nomad"
+        }
+    }
+}
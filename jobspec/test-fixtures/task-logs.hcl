@@ -0,0 +1,10 @@
+job "foo" {
+    task "bar" {
+        driver = "docker"
+
+        logs {
+            max_files = 5
+            max_file_size = 20
+        }
+    }
+}
@@ -0,0 +1,13 @@
+job "foo" {
+    task "bar" {
+        driver = "docker"
+
+        variable "app/db" {
+            env = true
+        }
+
+        variable "app/tls" {
+            destination = "secrets/tls.json"
+        }
+    }
+}
@@ -0,0 +1,28 @@
+job "foo" {
+    group "bar" {
+        volume "data" {
+            type = "host"
+            source = "mysql"
+        }
+
+        volume "certs" {
+            source = "certs"
+            read_only = true
+        }
+
+        task "bar" {
+            driver = "docker"
+
+            volume_mount {
+                volume = "data"
+                destination = "/var/lib/mysql"
+            }
+
+            volume_mount {
+                volume = "certs"
+                destination = "/etc/ssl/certs"
+                read_only = true
+            }
+        }
+    }
+}
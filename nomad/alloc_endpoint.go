@@ -1,6 +1,7 @@
 package nomad
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -110,3 +111,69 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 		}}
 	return a.srv.blockingRPC(&opts)
 }
+
+// Stop is used to stop an allocation. The allocation is marked for migration
+// and an evaluation is created so that the scheduler stops it and places a
+// replacement.
+func (a *Alloc) Stop(args *structs.AllocStopRequest, reply *structs.AllocStopResponse) error {
+	if done, err := a.srv.forward("Alloc.Stop", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "alloc", "stop"}, time.Now())
+
+	if args.AllocID == "" {
+		return fmt.Errorf("missing allocation ID")
+	}
+
+	// Look for the allocation
+	snap, err := a.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	alloc, err := snap.AllocByID(args.AllocID)
+	if err != nil {
+		return err
+	}
+	if alloc == nil {
+		return fmt.Errorf("allocation not found")
+	}
+	if alloc.TerminalStatus() {
+		return fmt.Errorf("allocation is already terminal")
+	}
+
+	job, err := snap.JobByID(alloc.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job not found")
+	}
+
+	// Create the evaluation that stops and replaces the allocation
+	eval := &structs.Evaluation{
+		ID:             structs.GenerateUUID(),
+		Priority:       job.Priority,
+		Type:           job.Type,
+		TriggeredBy:    structs.EvalTriggerAllocStop,
+		JobID:          job.ID,
+		JobModifyIndex: job.JobModifyIndex,
+		Status:         structs.EvalStatusPending,
+	}
+	req := structs.AllocUpdateDesiredTransitionRequest{
+		Allocs: map[string]*structs.DesiredTransition{
+			alloc.ID: &structs.DesiredTransition{Migrate: true},
+		},
+		Evals:        []*structs.Evaluation{eval},
+		WriteRequest: args.WriteRequest,
+	}
+	_, index, err := a.srv.raftApply(structs.AllocUpdateDesiredTransitionRequestType, &req)
+	if err != nil {
+		a.srv.logger.Printf("[ERR] nomad.alloc: stop failed: %v", err)
+		return err
+	}
+
+	// Setup the reply
+	reply.EvalID = eval.ID
+	reply.Index = index
+	return nil
+}
@@ -216,3 +216,62 @@ func TestAllocEndpoint_GetAlloc_Blocking(t *testing.T) {
 		t.Fatalf("bad: %#v", resp.Alloc)
 	}
 }
+
+func TestAllocEndpoint_Stop(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the job and the allocation
+	alloc := mock.Alloc()
+	state := s1.fsm.State()
+	if err := state.UpsertJob(999, alloc.Job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertAllocs(1000, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Stop the allocation
+	req := &structs.AllocStopRequest{
+		AllocID:      alloc.ID,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.AllocStopResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Alloc.Stop", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	// The allocation is marked for migration
+	out, err := state.AllocByID(alloc.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !out.DesiredTransition.ShouldMigrate() {
+		t.Fatalf("bad: %#v", out.DesiredTransition)
+	}
+
+	// The evaluation is created
+	eval, err := state.EvalByID(resp.EvalID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if eval == nil {
+		t.Fatalf("expected eval")
+	}
+	if eval.JobID != alloc.JobID || eval.TriggeredBy != structs.EvalTriggerAllocStop {
+		t.Fatalf("bad: %#v", eval)
+	}
+
+	// Stopping an unknown allocation fails
+	req.AllocID = structs.GenerateUUID()
+	if err := msgpackrpc.CallWithCodec(codec, "Alloc.Stop", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,44 @@
+package nomad
+
+import (
+	"time"
+
+	"github.com/armon/go-metrics"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// ClientFS endpoint is used to access the alloc dirs of allocations. The
+// requests are forwarded to the node running the allocation.
+type ClientFS struct {
+	srv *Server
+}
+
+// List is used to list the files of a directory of an alloc dir
+func (f *ClientFS) List(args *cstructs.FsRequest, reply *cstructs.FsListResponse) error {
+	return f.forward("ClientFS.List", args, reply)
+}
+
+// Stat is used to get the information of a file of an alloc dir
+func (f *ClientFS) Stat(args *cstructs.FsRequest, reply *cstructs.FsStatResponse) error {
+	return f.forward("ClientFS.Stat", args, reply)
+}
+
+// ReadAt is used to read a file of an alloc dir
+func (f *ClientFS) ReadAt(args *cstructs.FsRequest, reply *cstructs.FsReadAtResponse) error {
+	return f.forward("ClientFS.ReadAt", args, reply)
+}
+
+// forward forwards a request to the region of the allocation and then to the
+// node running it
+func (f *ClientFS) forward(method string, args *cstructs.FsRequest, reply interface{}) error {
+	if args.Region != f.srv.config.Region {
+		return f.srv.forwardRegion(args.Region, method, args, reply)
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client_fs", "forward"}, time.Now())
+
+	nodeID, err := f.srv.allocNodeID(args.AllocID)
+	if err != nil {
+		return err
+	}
+	return f.srv.forwardNode(nodeID, method, args, reply)
+}
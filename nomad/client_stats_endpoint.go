@@ -0,0 +1,49 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// ClientStats endpoint is used to read the resource usage of the hosts of the
+// nodes. The requests are forwarded to the node.
+type ClientStats struct {
+	srv *Server
+}
+
+// Stats is used to read the resource usage of the host of a node
+func (c *ClientStats) Stats(args *cstructs.ClientStatsRequest, reply *cstructs.ClientStatsResponse) error {
+	if args.Region != c.srv.config.Region {
+		return c.srv.forwardRegion(args.Region, "ClientStats.Stats", args, reply)
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client_stats", "stats"}, time.Now())
+
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID")
+	}
+	return c.srv.forwardNode(args.NodeID, "ClientStats.Stats", args, reply)
+}
+
+// ClientAllocations endpoint is used to read the resource usage of the tasks
+// of allocations. The requests are forwarded to the node running the
+// allocation.
+type ClientAllocations struct {
+	srv *Server
+}
+
+// Stats is used to read the resource usage of the tasks of an allocation
+func (c *ClientAllocations) Stats(args *cstructs.AllocStatsRequest, reply *cstructs.AllocStatsResponse) error {
+	if args.Region != c.srv.config.Region {
+		return c.srv.forwardRegion(args.Region, "ClientAllocations.Stats", args, reply)
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client_allocations", "stats"}, time.Now())
+
+	nodeID, err := c.srv.allocNodeID(args.AllocID)
+	if err != nil {
+		return err
+	}
+	return c.srv.forwardNode(nodeID, "ClientAllocations.Stats", args, reply)
+}
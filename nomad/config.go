@@ -92,6 +92,12 @@ type Config struct {
 	// RequireTLS ensures that all RPC traffic is protected with TLS
 	RequireTLS bool
 
+	// KeyringEncryptionKey is the key encryption key used to wrap the root
+	// keys of the keyring, both in the local keystore and when they are sent
+	// to other servers. It must be the same on all the servers of a region.
+	// If not set, a key local to the server is generated.
+	KeyringEncryptionKey []byte
+
 	// SerfConfig is the configuration for the serf cluster
 	SerfConfig *serf.Config
 
@@ -176,6 +182,58 @@ type Config struct {
 	// a new leader is elected, since we no longer know the status
 	// of all the heartbeats.
 	FailoverHeartbeatTTL time.Duration
+
+	// Admission configures the admission controllers that are run on jobs
+	// before they are registered.
+	Admission *AdmissionConfig
+}
+
+// AdmissionConfig configures the admission controllers run on job
+// registration. Mutators are run before validators.
+type AdmissionConfig struct {
+	// DefaultMeta is merged into the meta of jobs that do not set the keys.
+	DefaultMeta map[string]string
+
+	// RequiredMeta is the set of meta keys every job must set.
+	RequiredMeta []string
+
+	// MaxTaskMemoryMB is the maximum memory a single task may request. Zero
+	// disables the check.
+	MaxTaskMemoryMB int
+
+	// DenyPrivileged rejects jobs that run privileged docker containers.
+	DenyPrivileged bool
+
+	// Webhooks are external admission controllers called over HTTP, in order.
+	Webhooks []*AdmissionWebhook
+}
+
+const (
+	// AdmissionWebhookMutator is a webhook that may modify the job.
+	AdmissionWebhookMutator = "mutator"
+
+	// AdmissionWebhookValidator is a webhook that may reject the job or
+	// return warnings.
+	AdmissionWebhookValidator = "validator"
+)
+
+// AdmissionWebhook configures an external admission controller.
+type AdmissionWebhook struct {
+	// Name is used to identify the webhook in errors and warnings.
+	Name string
+
+	// Type is either a mutator or a validator.
+	Type string
+
+	// URL is the address the job is POSTed to.
+	URL string
+
+	// Timeout bounds the request to the webhook.
+	Timeout time.Duration
+
+	// FailOpen allows the job to be registered, with a warning, if the
+	// webhook can not be reached. Otherwise the registration fails.
+	FailOpen bool
 }
 
 // CheckVersion is used to check if the ProtocolVersion is valid
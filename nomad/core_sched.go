@@ -35,11 +35,48 @@ func (s *CoreScheduler) Process(eval *structs.Evaluation) error {
 		return s.nodeGC(eval)
 	case structs.CoreJobJobGC:
 		return s.jobGC(eval)
+	case structs.CoreJobForceGC:
+		return s.forceGC(eval)
 	default:
 		return fmt.Errorf("core scheduler cannot handle job '%s'", eval.JobID)
 	}
 }
 
+// forceGC is used to garbage collect all eligible objects, ignoring the
+// configured thresholds.
+func (c *CoreScheduler) forceGC(eval *structs.Evaluation) error {
+	if err := c.jobGC(eval); err != nil {
+		return err
+	}
+	if err := c.evalGC(eval); err != nil {
+		return err
+	}
+
+	// Node GC must occur after the others to ensure the allocations are
+	// cleared.
+	return c.nodeGC(eval)
+}
+
+// getThreshold returns the raft index before which objects are eligible for
+// garbage collection. Forced GC uses the index the evaluation was created at
+// so that all terminal objects are collected; otherwise the FSM time table is
+// used to map the threshold duration to a rough raft index.
+func (c *CoreScheduler) getThreshold(eval *structs.Evaluation, objName string,
+	threshold time.Duration) uint64 {
+	if eval.JobID == structs.CoreJobForceGC {
+		c.srv.logger.Printf("[DEBUG] sched.core: %s GC: forced, scanning before index %d",
+			objName, eval.ModifyIndex)
+		return eval.ModifyIndex
+	}
+
+	tt := c.srv.fsm.TimeTable()
+	cutoff := time.Now().UTC().Add(-1 * threshold)
+	oldThreshold := tt.NearestIndex(cutoff)
+	c.srv.logger.Printf("[DEBUG] sched.core: %s GC: scanning before index %d (%v)",
+		objName, oldThreshold, threshold)
+	return oldThreshold
+}
+
 // jobGC is used to garbage collect eligible jobs.
 func (c *CoreScheduler) jobGC(eval *structs.Evaluation) error {
 	// Get all the jobs eligible for garbage collection.
@@ -48,12 +85,8 @@ func (c *CoreScheduler) jobGC(eval *structs.Evaluation) error {
 		return err
 	}
 
-	// Get the time table to calculate GC cutoffs.
-	tt := c.srv.fsm.TimeTable()
-	cutoff := time.Now().UTC().Add(-1 * c.srv.config.JobGCThreshold)
-	oldThreshold := tt.NearestIndex(cutoff)
-	c.srv.logger.Printf("[DEBUG] sched.core: job GC: scanning before index %d (%v)",
-		oldThreshold, c.srv.config.JobGCThreshold)
+	// Get the default threshold. Jobs may override it with their own.
+	defThreshold := c.getThreshold(eval, "job", c.srv.config.JobGCThreshold)
 
 	// Collect the allocations, evaluations and jobs to GC
 	var gcAlloc, gcEval, gcJob []string
@@ -62,6 +95,12 @@ OUTER:
 	for i := iter.Next(); i != nil; i = iter.Next() {
 		job := i.(*structs.Job)
 
+		oldThreshold := defThreshold
+		if job.GCThreshold != 0 && eval.JobID != structs.CoreJobForceGC {
+			tt := c.srv.fsm.TimeTable()
+			oldThreshold = tt.NearestIndex(time.Now().UTC().Add(-1 * job.GCThreshold))
+		}
+
 		// Ignore new jobs.
 		if job.CreateIndex > oldThreshold {
 			continue
@@ -125,14 +164,8 @@ func (c *CoreScheduler) evalGC(eval *structs.Evaluation) error {
 		return err
 	}
 
-	// Compute the old threshold limit for GC using the FSM
-	// time table.  This is a rough mapping of a time to the
-	// Raft index it belongs to.
-	tt := c.srv.fsm.TimeTable()
-	cutoff := time.Now().UTC().Add(-1 * c.srv.config.EvalGCThreshold)
-	oldThreshold := tt.NearestIndex(cutoff)
-	c.srv.logger.Printf("[DEBUG] sched.core: eval GC: scanning before index %d (%v)",
-		oldThreshold, c.srv.config.EvalGCThreshold)
+	// Compute the old threshold limit for GC
+	oldThreshold := c.getThreshold(eval, "eval", c.srv.config.EvalGCThreshold)
 
 	// Collect the allocations and evaluations to GC
 	var gcAlloc, gcEval []string
@@ -223,14 +256,8 @@ func (c *CoreScheduler) nodeGC(eval *structs.Evaluation) error {
 		return err
 	}
 
-	// Compute the old threshold limit for GC using the FSM
-	// time table.  This is a rough mapping of a time to the
-	// Raft index it belongs to.
-	tt := c.srv.fsm.TimeTable()
-	cutoff := time.Now().UTC().Add(-1 * c.srv.config.NodeGCThreshold)
-	oldThreshold := tt.NearestIndex(cutoff)
-	c.srv.logger.Printf("[DEBUG] sched.core: node GC: scanning before index %d (%v)",
-		oldThreshold, c.srv.config.NodeGCThreshold)
+	// Compute the old threshold limit for GC
+	oldThreshold := c.getThreshold(eval, "node", c.srv.config.NodeGCThreshold)
 
 	// Collect the nodes to GC
 	var gcNode []string
@@ -0,0 +1,329 @@
+package nomad
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// nodeDrainInterval is how often the leader checks the progress of
+	// draining nodes
+	nodeDrainInterval = 5 * time.Second
+)
+
+// nodeDrainer moves the allocations off draining nodes. It marks them for
+// migration in batches bounded by the migrate strategy of their task group,
+// waiting for the replacements of a batch to be healthy before marking the
+// next one, and marks whatever is left once the drain deadline is reached.
+// Batch allocations are left to finish until the deadline and system
+// allocations are migrated last. A node whose allocations are all gone is
+// no longer draining, but stays ineligible for scheduling.
+type nodeDrainer struct {
+	logger *log.Logger
+
+	// lastMigration is the last time allocations of each task group were
+	// marked for migration. Unhealthy replacements only hold back the next
+	// migrations of their group until its healthy deadline since then.
+	lastMigration map[string]time.Time
+}
+
+// drainPlan is the result of a pass of the drainer
+type drainPlan struct {
+	// transitions are the allocations to mark for migration and evals the
+	// evaluations that act on them
+	transitions map[string]*structs.DesiredTransition
+	evals       []*structs.Evaluation
+
+	// migrated are the task groups whose allocations are marked
+	migrated []string
+
+	// done are the nodes whose drain is complete
+	done []string
+}
+
+func newNodeDrainer(logger *log.Logger) *nodeDrainer {
+	return &nodeDrainer{
+		logger:        logger,
+		lastMigration: make(map[string]time.Time),
+	}
+}
+
+// runNodeDrainer is a long lived function run by the leader that drains
+// the nodes with a drain strategy
+func (s *Server) runNodeDrainer(stopCh chan struct{}) {
+	drainer := newNodeDrainer(s.logger)
+	ticker := time.NewTicker(nodeDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := s.drainNodes(drainer, time.Now().UTC()); err != nil {
+				s.logger.Printf("[ERR] nomad.drainer: %v", err)
+			}
+		}
+	}
+}
+
+// drainNodes runs a single pass of the drainer and commits its result
+func (s *Server) drainNodes(drainer *nodeDrainer, now time.Time) error {
+	snap, err := s.fsm.State().Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot state: %v", err)
+	}
+	plan, err := drainer.plan(snap, now)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.transitions) != 0 {
+		req := structs.AllocUpdateDesiredTransitionRequest{
+			Allocs: plan.transitions,
+			Evals:  plan.evals,
+		}
+		if _, _, err := s.raftApply(structs.AllocUpdateDesiredTransitionRequestType, &req); err != nil {
+			return fmt.Errorf("failed to mark allocations for migration: %v", err)
+		}
+		for _, key := range plan.migrated {
+			drainer.lastMigration[key] = now
+		}
+		metrics.IncrCounter([]string{"nomad", "drainer", "migrations"}, float32(len(plan.transitions)))
+	}
+
+	for _, nodeID := range plan.done {
+		req := structs.NodeUpdateDrainRequest{
+			NodeID: nodeID,
+		}
+		if _, _, err := s.raftApply(structs.NodeUpdateDrainRequestType, &req); err != nil {
+			return fmt.Errorf("failed to complete the drain of node %s: %v", nodeID, err)
+		}
+		s.logger.Printf("[INFO] nomad.drainer: node %s drain complete", nodeID)
+	}
+	return nil
+}
+
+// plan computes the allocations to mark for migration and the nodes whose
+// drain is complete
+func (d *nodeDrainer) plan(snap *state.StateSnapshot, now time.Time) (*drainPlan, error) {
+	plan := &drainPlan{
+		transitions: make(map[string]*structs.DesiredTransition),
+	}
+
+	// Find the draining nodes
+	draining := make(map[string]*structs.Node)
+	iter, err := snap.Nodes()
+	if err != nil {
+		return nil, err
+	}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		node := raw.(*structs.Node)
+		if node.DrainStrategy != nil {
+			draining[node.ID] = node
+		}
+	}
+	if len(draining) == 0 {
+		d.lastMigration = make(map[string]time.Time)
+		return plan, nil
+	}
+
+	nodeIDs := make([]string, 0, len(draining))
+	for id := range draining {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Strings(nodeIDs)
+
+	jobs := make(map[string]*structs.Allocation)
+	mark := func(alloc *structs.Allocation) {
+		plan.transitions[alloc.ID] = &structs.DesiredTransition{Migrate: true}
+		if _, ok := jobs[alloc.JobID]; !ok {
+			jobs[alloc.JobID] = alloc
+		}
+	}
+
+	for _, nodeID := range nodeIDs {
+		node := draining[nodeID]
+		strategy := node.DrainStrategy
+		forced := strategy.DeadlineReached(now)
+
+		allocs, err := snap.AllocsByNode(nodeID)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := 0
+		var system []*structs.Allocation
+		groups := make(map[string][]*structs.Allocation)
+		for _, alloc := range allocs {
+			if alloc.TerminalStatus() {
+				continue
+			}
+
+			// System allocations are migrated once the others are gone
+			if alloc.Job.Type == structs.JobTypeSystem {
+				if !strategy.IgnoreSystemJobs {
+					system = append(system, alloc)
+				}
+				continue
+			}
+
+			remaining++
+			switch {
+			case alloc.DesiredTransition.ShouldMigrate():
+			case forced:
+				mark(alloc)
+			case alloc.Job.Type == structs.JobTypeBatch:
+				// Batch allocations are left to finish until the deadline
+			default:
+				key := migrationKey(alloc)
+				groups[key] = append(groups[key], alloc)
+			}
+		}
+
+		// Mark as many allocations of each group as its strategy allows
+		keys := make([]string, 0, len(groups))
+		for key := range groups {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			group := groups[key]
+			slots, err := d.migrationSlots(snap, draining, plan, group[0], now)
+			if err != nil {
+				return nil, err
+			}
+			if slots <= 0 {
+				continue
+			}
+
+			sort.Sort(allocsByID(group))
+			if slots > len(group) {
+				slots = len(group)
+			}
+			for _, alloc := range group[:slots] {
+				mark(alloc)
+			}
+			plan.migrated = append(plan.migrated, key)
+		}
+
+		if remaining != 0 {
+			continue
+		}
+		if len(system) == 0 {
+			plan.done = append(plan.done, nodeID)
+			continue
+		}
+		for _, alloc := range system {
+			if !alloc.DesiredTransition.ShouldMigrate() {
+				mark(alloc)
+			}
+		}
+	}
+
+	// Create an evaluation for each job with marked allocations
+	jobIDs := make([]string, 0, len(jobs))
+	for id := range jobs {
+		jobIDs = append(jobIDs, id)
+	}
+	sort.Strings(jobIDs)
+	for _, id := range jobIDs {
+		alloc := jobs[id]
+		plan.evals = append(plan.evals, &structs.Evaluation{
+			ID:          structs.GenerateUUID(),
+			Priority:    alloc.Job.Priority,
+			Type:        alloc.Job.Type,
+			TriggeredBy: structs.EvalTriggerNodeDrain,
+			JobID:       alloc.JobID,
+			NodeID:      alloc.NodeID,
+			Status:      structs.EvalStatusPending,
+		})
+	}
+	return plan, nil
+}
+
+// migrationSlots returns how many more allocations of the task group of the
+// given allocation may be marked for migration. Allocations already marked
+// and replacements that are not yet healthy take up slots.
+func (d *nodeDrainer) migrationSlots(snap *state.StateSnapshot, draining map[string]*structs.Node,
+	plan *drainPlan, alloc *structs.Allocation, now time.Time) (int, error) {
+	strategy := structs.DefaultMigrateStrategy()
+	if tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup); tg != nil && tg.Migrate != nil {
+		strategy = tg.Migrate
+	}
+
+	// Unhealthy replacements only hold back migrations until the healthy
+	// deadline, which is started on the first pass after a leader election
+	key := migrationKey(alloc)
+	last, ok := d.lastMigration[key]
+	if !ok {
+		last = now
+		d.lastMigration[key] = now
+	}
+	gated := strategy.HealthyDeadline == 0 || now.Sub(last) < strategy.HealthyDeadline
+
+	allocs, err := snap.AllocsByJob(alloc.JobID)
+	if err != nil {
+		return 0, err
+	}
+
+	inFlight := 0
+	for _, other := range allocs {
+		if other.TaskGroup != alloc.TaskGroup || other.TerminalStatus() {
+			continue
+		}
+		if _, ok := draining[other.NodeID]; ok {
+			_, marked := plan.transitions[other.ID]
+			if marked || other.DesiredTransition.ShouldMigrate() {
+				inFlight++
+			}
+			continue
+		}
+		if gated && !allocHealthy(other, strategy.MinHealthyTime, now) {
+			inFlight++
+		}
+	}
+	return strategy.MaxParallel - inFlight, nil
+}
+
+// allocHealthy returns whether all the tasks of the allocation have been
+// running for at least the given time
+func allocHealthy(alloc *structs.Allocation, minHealthyTime time.Duration, now time.Time) bool {
+	if alloc.ClientStatus != structs.AllocClientStatusRunning || len(alloc.TaskStates) == 0 {
+		return false
+	}
+	for _, state := range alloc.TaskStates {
+		if state.State != structs.TaskStateRunning {
+			return false
+		}
+
+		var started int64
+		for _, event := range state.Events {
+			if event.Type == structs.TaskStarted && event.Time > started {
+				started = event.Time
+			}
+		}
+		if started == 0 || now.Sub(time.Unix(0, started)) < minHealthyTime {
+			return false
+		}
+	}
+	return true
+}
+
+// migrationKey identifies the task group of an allocation
+func migrationKey(alloc *structs.Allocation) string {
+	return fmt.Sprintf("%s/%s", alloc.JobID, alloc.TaskGroup)
+}
+
+// allocsByID sorts allocations by ID
+type allocsByID []*structs.Allocation
+
+func (a allocsByID) Len() int           { return len(a) }
+func (a allocsByID) Less(i, j int) bool { return a[i].ID < a[j].ID }
+func (a allocsByID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
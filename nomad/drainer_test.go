@@ -0,0 +1,331 @@
+package nomad
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// drainerAllocs creates running allocations of the job on the node
+func drainerAllocs(job *structs.Job, nodeID string, count int) []*structs.Allocation {
+	allocs := make([]*structs.Allocation, count)
+	for i := range allocs {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = nodeID
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		allocs[i] = alloc
+	}
+	return allocs
+}
+
+// healthyAlloc marks the allocation as running for the given time
+func healthyAlloc(alloc *structs.Allocation, since time.Duration) {
+	alloc.ClientStatus = structs.AllocClientStatusRunning
+	alloc.TaskStates = map[string]*structs.TaskState{
+		"web": &structs.TaskState{
+			State: structs.TaskStateRunning,
+			Events: []*structs.TaskEvent{
+				{Type: structs.TaskStarted, Time: time.Now().Add(-since).UnixNano()},
+			},
+		},
+	}
+}
+
+func drainingNode(deadline time.Duration, ignoreSystem bool) *structs.Node {
+	node := mock.Node()
+	node.Drain = true
+	node.SchedulingEligibility = structs.NodeSchedulingIneligible
+	node.DrainStrategy = &structs.DrainStrategy{
+		DrainSpec: structs.DrainSpec{
+			Deadline:         deadline,
+			IgnoreSystemJobs: ignoreSystem,
+		},
+	}
+	if deadline > 0 {
+		node.DrainStrategy.ForceDeadline = time.Now().Add(deadline)
+	}
+	return node
+}
+
+func TestNodeDrainer_MaxParallel(t *testing.T) {
+	state := testStateStore(t)
+	node := drainingNode(time.Hour, false)
+	if err := state.UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	job := mock.Job()
+	job.TaskGroups[0].Migrate = &structs.MigrateStrategy{
+		MaxParallel:     2,
+		MinHealthyTime:  10 * time.Second,
+		HealthyDeadline: 5 * time.Minute,
+	}
+	if err := state.UpsertJob(1001, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	allocs := drainerAllocs(job, node.ID, 3)
+	if err := state.UpsertAllocs(1002, allocs); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	drainer := newNodeDrainer(log.New(os.Stderr, "", log.LstdFlags))
+	snap, _ := state.Snapshot()
+	plan, err := drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Only max_parallel allocations are marked, with a single eval
+	if len(plan.transitions) != 2 || len(plan.done) != 0 {
+		t.Fatalf("bad: %#v", plan)
+	}
+	if len(plan.evals) != 1 || plan.evals[0].JobID != job.ID ||
+		plan.evals[0].TriggeredBy != structs.EvalTriggerNodeDrain {
+		t.Fatalf("bad: %#v", plan.evals)
+	}
+	if err := state.UpdateAllocsDesiredTransitions(1003, plan.transitions, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Nothing more is marked while the marked allocations are migrating
+	snap, _ = state.Snapshot()
+	plan, err = drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 0 {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	// Stop the marked allocations and place unhealthy replacements
+	var stopped, replacements []*structs.Allocation
+	for _, alloc := range allocs {
+		out, _ := state.AllocByID(alloc.ID)
+		if !out.DesiredTransition.ShouldMigrate() {
+			continue
+		}
+		stop := new(structs.Allocation)
+		*stop = *out
+		stop.DesiredStatus = structs.AllocDesiredStatusStop
+		stopped = append(stopped, stop)
+
+		replacement := drainerAllocs(job, structs.GenerateUUID(), 1)[0]
+		replacement.ClientStatus = structs.AllocClientStatusPending
+		replacements = append(replacements, replacement)
+	}
+	if err := state.UpsertAllocs(1004, append(stopped, replacements...)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	snap, _ = state.Snapshot()
+	plan, err = drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 0 {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	// Once the replacements are healthy the last allocation is marked
+	for _, alloc := range replacements {
+		healthyAlloc(alloc, time.Minute)
+		if err := state.UpdateAllocFromClient(1005, alloc); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	snap, _ = state.Snapshot()
+	plan, err = drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 1 {
+		t.Fatalf("bad: %#v", plan)
+	}
+}
+
+func TestNodeDrainer_HealthyDeadline(t *testing.T) {
+	state := testStateStore(t)
+	node := drainingNode(0, false)
+	if err := state.UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	job := mock.Job()
+	if err := state.UpsertJob(1001, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	allocs := drainerAllocs(job, node.ID, 1)
+
+	// An unhealthy allocation elsewhere holds back the migration
+	other := drainerAllocs(job, structs.GenerateUUID(), 1)
+	other[0].ClientStatus = structs.AllocClientStatusPending
+	if err := state.UpsertAllocs(1002, append(allocs, other...)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	drainer := newNodeDrainer(log.New(os.Stderr, "", log.LstdFlags))
+	now := time.Now()
+	snap, _ := state.Snapshot()
+	plan, err := drainer.plan(snap, now)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 0 {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	// Past the healthy deadline it no longer does
+	plan, err = drainer.plan(snap, now.Add(structs.DefaultMigrateStrategy().HealthyDeadline))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 1 {
+		t.Fatalf("bad: %#v", plan)
+	}
+}
+
+func TestNodeDrainer_Deadline(t *testing.T) {
+	state := testStateStore(t)
+	node := drainingNode(time.Hour, false)
+	if err := state.UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	job := mock.Job()
+	if err := state.UpsertJob(1001, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	batch := mock.Job()
+	batch.Type = structs.JobTypeBatch
+	if err := state.UpsertJob(1002, batch); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	allocs := append(drainerAllocs(job, node.ID, 3), drainerAllocs(batch, node.ID, 2)...)
+	if err := state.UpsertAllocs(1003, allocs); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Before the deadline the batch allocations are left alone
+	drainer := newNodeDrainer(log.New(os.Stderr, "", log.LstdFlags))
+	snap, _ := state.Snapshot()
+	plan, err := drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 1 {
+		t.Fatalf("bad: %#v", plan)
+	}
+	for _, alloc := range allocs[3:] {
+		if _, ok := plan.transitions[alloc.ID]; ok {
+			t.Fatalf("batch alloc marked: %#v", plan)
+		}
+	}
+
+	// At the deadline everything is marked
+	plan, err = drainer.plan(snap, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 5 || len(plan.evals) != 2 {
+		t.Fatalf("bad: %#v", plan)
+	}
+}
+
+func TestNodeDrainer_SystemJobs(t *testing.T) {
+	state := testStateStore(t)
+	node := drainingNode(-1, false)
+	ignoring := drainingNode(-1, true)
+	if err := state.UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertNode(1001, ignoring); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	job := mock.Job()
+	sysJob := mock.SystemJob()
+	if err := state.UpsertJob(1002, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertJob(1003, sysJob); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	service := drainerAllocs(job, node.ID, 1)
+	system := drainerAllocs(sysJob, node.ID, 1)
+	ignored := drainerAllocs(sysJob, ignoring.ID, 1)
+	if err := state.UpsertAllocs(1004, append(append(service, system...), ignored...)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// System allocations are migrated after the others
+	drainer := newNodeDrainer(log.New(os.Stderr, "", log.LstdFlags))
+	snap, _ := state.Snapshot()
+	plan, err := drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 1 || plan.transitions[service[0].ID] == nil {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	// The node ignoring system jobs is done
+	if len(plan.done) != 1 || plan.done[0] != ignoring.ID {
+		t.Fatalf("bad: %#v", plan.done)
+	}
+
+	stop := new(structs.Allocation)
+	*stop = *service[0]
+	stop.DesiredStatus = structs.AllocDesiredStatusStop
+	if err := state.UpsertAllocs(1005, []*structs.Allocation{stop}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	snap, _ = state.Snapshot()
+	plan, err = drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 1 || plan.transitions[system[0].ID] == nil {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	// Once the system allocation is gone the drain is complete
+	stop = new(structs.Allocation)
+	*stop = *system[0]
+	stop.DesiredStatus = structs.AllocDesiredStatusStop
+	if err := state.UpsertAllocs(1006, []*structs.Allocation{stop}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	snap, _ = state.Snapshot()
+	plan, err = drainer.plan(snap, time.Now())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(plan.transitions) != 0 || len(plan.done) != 2 {
+		t.Fatalf("bad: %#v", plan)
+	}
+}
+
+func TestAllocHealthy(t *testing.T) {
+	alloc := mock.Alloc()
+	if allocHealthy(alloc, 0, time.Now()) {
+		t.Fatalf("pending alloc should not be healthy")
+	}
+
+	healthyAlloc(alloc, 5*time.Second)
+	if allocHealthy(alloc, 10*time.Second, time.Now()) {
+		t.Fatalf("alloc running for less than min healthy time should not be healthy")
+	}
+	if !allocHealthy(alloc, time.Second, time.Now()) {
+		t.Fatalf("alloc should be healthy")
+	}
+}
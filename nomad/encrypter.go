@@ -0,0 +1,272 @@
+package nomad
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/watch"
+)
+
+const (
+	// rootKeySize is the size of the AES-256 root keys
+	rootKeySize = 32
+
+	// keyringReplicationInterval is how often the servers check for root
+	// keys missing from their keystore, in addition to when the keyring
+	// changes
+	keyringReplicationInterval = 30 * time.Second
+)
+
+// initializeKeyring is used by the leader to create the first root key of the
+// keyring, if there is no active key yet.
+func (s *Server) initializeKeyring() error {
+	active, err := s.fsm.State().ActiveRootKeyMeta()
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return nil
+	}
+
+	meta, _, err := s.rotateRootKey()
+	if err != nil {
+		return err
+	}
+	s.logger.Printf("[INFO] nomad: initialized keyring with root key %s", meta.KeyID)
+	return nil
+}
+
+// rotateRootKey is used by the leader to create a new active root key. The
+// key material is stored in the local keystore before its metadata is
+// committed via Raft, and the older keys are kept to decrypt the variables
+// written with them.
+func (s *Server) rotateRootKey() (*structs.RootKeyMeta, uint64, error) {
+	material := make([]byte, rootKeySize)
+	if _, err := rand.Read(material); err != nil {
+		return nil, 0, fmt.Errorf("failed to generate root key: %v", err)
+	}
+
+	meta := &structs.RootKeyMeta{
+		KeyID:      structs.GenerateUUID(),
+		Algorithm:  structs.RootKeyAlgorithmAES256GCM,
+		State:      structs.RootKeyStateActive,
+		CreateTime: time.Now().UnixNano(),
+	}
+	if err := s.keystore.Put(meta.KeyID, material); err != nil {
+		return nil, 0, err
+	}
+
+	req := structs.RootKeyUpsertRequest{
+		RootKeyMeta:  meta,
+		WriteRequest: structs.WriteRequest{Region: s.config.Region},
+	}
+	_, index, err := s.raftApply(structs.RootKeyUpsertRequestType, req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create root key: %v", err)
+	}
+	return meta, index, nil
+}
+
+// rootKey returns the material of a root key. Keys missing from the local
+// keystore are fetched from the other servers.
+func (s *Server) rootKey(keyID string) ([]byte, error) {
+	key, err := s.keystore.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		return key, nil
+	}
+	return s.fetchRootKey(keyID)
+}
+
+// fetchRootKey fetches the material of a root key from the other servers of
+// the region and adds it to the local keystore.
+func (s *Server) fetchRootKey(keyID string) ([]byte, error) {
+	local := s.serf.LocalMember().Name
+
+	s.peerLock.RLock()
+	peers := make([]*serverParts, 0, len(s.localPeers))
+	for _, server := range s.localPeers {
+		if server.Name != local {
+			peers = append(peers, server)
+		}
+	}
+	s.peerLock.RUnlock()
+
+	var lastErr error
+	for _, server := range peers {
+		req := structs.KeyringGetRequest{
+			KeyID:        keyID,
+			ServerName:   local,
+			Timestamp:    time.Now().UnixNano(),
+			QueryOptions: structs.QueryOptions{Region: s.config.Region},
+		}
+		req.Signature = s.keystore.sign(req.KeyID, req.ServerName, req.Timestamp)
+
+		var resp structs.KeyringGetResponse
+		if err := s.connPool.RPC(s.config.Region, server.Addr, server.Version, "Keyring.Get", &req, &resp); err != nil {
+			lastErr = fmt.Errorf("failed to fetch root key %s from %s: %v", keyID, server.Name, err)
+			continue
+		}
+		key, err := s.keystore.unwrap(keyID, resp.WrappedKey)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := s.keystore.Put(keyID, key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("root key %s not found", keyID)
+	}
+	return nil, lastErr
+}
+
+// replicateKeyring runs on every server and fetches the material of the root
+// keys missing from the local keystore, so that the keys outlive the server
+// that created them.
+func (s *Server) replicateKeyring() {
+	items := watch.NewItems(watch.Item{Table: "root_keys"})
+	notifyCh := make(chan struct{}, 1)
+	for {
+		// The state store is replaced when a snapshot is restored, so the
+		// watch is set up again on every pass
+		state := s.fsm.State()
+		state.Watch(items, notifyCh)
+
+		if err := s.replicateRootKeys(state); err != nil {
+			s.logger.Printf("[ERR] nomad: failed to replicate keyring: %v", err)
+		}
+
+		select {
+		case <-notifyCh:
+		case <-time.After(keyringReplicationInterval):
+		case <-s.shutdownCh:
+			state.StopWatch(items, notifyCh)
+			return
+		}
+		state.StopWatch(items, notifyCh)
+	}
+}
+
+// replicateRootKeys fetches every root key of the state store that is missing
+// from the local keystore
+func (s *Server) replicateRootKeys(state *state.StateStore) error {
+	iter, err := state.RootKeyMetas()
+	if err != nil {
+		return err
+	}
+
+	var mErr multierror.Error
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		meta := raw.(*structs.RootKeyMeta)
+		if key, err := s.keystore.Get(meta.KeyID); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+			continue
+		} else if key != nil {
+			continue
+		}
+		if _, err := s.fetchRootKey(meta.KeyID); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// encryptVariable encrypts the items of the variable with the active root
+// key. The path is authenticated along with the items so the data can not be
+// moved to another path.
+func (s *Server) encryptVariable(v *structs.Variable) (*structs.VariableEncrypted, error) {
+	meta, err := s.fsm.State().ActiveRootKeyMeta()
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("keyring is not initialized")
+	}
+
+	aead, err := s.rootKeyAEAD(meta.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(v.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	return &structs.VariableEncrypted{
+		Path:  v.Path,
+		KeyID: meta.KeyID,
+		Data:  aead.Seal(nonce, nonce, plaintext, []byte(v.Path)),
+	}, nil
+}
+
+// decryptVariable decrypts the items of a variable with the root key it was
+// encrypted with.
+func (s *Server) decryptVariable(v *structs.VariableEncrypted) (*structs.Variable, error) {
+	meta, err := s.fsm.State().RootKeyMetaByID(v.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("root key %s for variable %q not found", v.KeyID, v.Path)
+	}
+
+	aead, err := s.rootKeyAEAD(meta.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	size := aead.NonceSize()
+	if len(v.Data) < size {
+		return nil, fmt.Errorf("encrypted data of variable %q is too short", v.Path)
+	}
+	plaintext, err := aead.Open(nil, v.Data[:size], v.Data[size:], []byte(v.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt variable %q: %v", v.Path, err)
+	}
+
+	out := &structs.Variable{
+		Path:        v.Path,
+		CreateIndex: v.CreateIndex,
+		ModifyIndex: v.ModifyIndex,
+	}
+	if err := json.Unmarshal(plaintext, &out.Items); err != nil {
+		return nil, fmt.Errorf("failed to decode variable %q: %v", v.Path, err)
+	}
+	return out, nil
+}
+
+// rootKeyAEAD returns the AES-GCM cipher for the root key
+func (s *Server) rootKeyAEAD(keyID string) (cipher.AEAD, error) {
+	key, err := s.rootKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root key %s: %v", keyID, err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,110 @@
+package nomad
+
+import (
+	"crypto/rand"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func testEncrypterServer(t *testing.T) *Server {
+	s := &Server{fsm: testFSM(t), keystore: testKeystore(t, "")}
+	testRootKey(t, s, 1000)
+	return s
+}
+
+// testRootKey adds a new active root key to the keystore and the state store
+// of the server
+func testRootKey(t *testing.T, s *Server, index uint64) *structs.RootKeyMeta {
+	meta := &structs.RootKeyMeta{
+		KeyID:     structs.GenerateUUID(),
+		Algorithm: structs.RootKeyAlgorithmAES256GCM,
+		State:     structs.RootKeyStateActive,
+	}
+	key := make([]byte, rootKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := s.keystore.Put(meta.KeyID, key); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := s.fsm.State().UpsertRootKeyMeta(index, meta); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return meta
+}
+
+func TestEncrypter_RoundTrip(t *testing.T) {
+	s := testEncrypterServer(t)
+
+	variable := &structs.Variable{
+		Path:  "app/db",
+		Items: map[string]string{"password": "hunter2"},
+	}
+	encrypted, err := s.encryptVariable(variable)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if reflect.DeepEqual(encrypted.Data, []byte(`{"password":"hunter2"}`)) {
+		t.Fatalf("variable not encrypted")
+	}
+
+	out, err := s.decryptVariable(encrypted)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, variable.Items) {
+		t.Fatalf("bad: %#v", out.Items)
+	}
+
+	// Moving the data to another path fails to decrypt
+	encrypted.Path = "app/other"
+	if _, err := s.decryptVariable(encrypted); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestEncrypter_Rotation(t *testing.T) {
+	s := testEncrypterServer(t)
+
+	variable := &structs.Variable{
+		Path:  "app/db",
+		Items: map[string]string{"password": "hunter2"},
+	}
+	encrypted, err := s.encryptVariable(variable)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Variables written with an older key can still be read
+	key := testRootKey(t, s, 1001)
+
+	out, err := s.decryptVariable(encrypted)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, variable.Items) {
+		t.Fatalf("bad: %#v", out.Items)
+	}
+
+	// New variables use the active key
+	encrypted, err = s.encryptVariable(variable)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if encrypted.KeyID != key.KeyID {
+		t.Fatalf("bad key: %s", encrypted.KeyID)
+	}
+}
+
+func TestEncrypter_Uninitialized(t *testing.T) {
+	s := &Server{fsm: testFSM(t), keystore: testKeystore(t, "")}
+	variable := &structs.Variable{
+		Path:  "app/db",
+		Items: map[string]string{"password": "hunter2"},
+	}
+	if _, err := s.encryptVariable(variable); err == nil {
+		t.Fatalf("expected error")
+	}
+}
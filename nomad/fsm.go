@@ -33,6 +33,11 @@ const (
 	AllocSnapshot
 	TimeTableSnapshot
 	PeriodicLaunchSnapshot
+	VariableSnapshot
+	RootKeyMetaSnapshot
+	ServiceRegistrationSnapshot
+	JobSummarySnapshot
+	MultiregionRolloutSnapshot
 )
 
 // nomadFSM implements a finite state machine that is used
@@ -130,6 +135,22 @@ func (n *nomadFSM) Apply(log *raft.Log) interface{} {
 		return n.applyAllocUpdate(buf[1:], log.Index)
 	case structs.AllocClientUpdateRequestType:
 		return n.applyAllocClientUpdate(buf[1:], log.Index)
+	case structs.VariableUpsertRequestType:
+		return n.applyUpsertVariable(buf[1:], log.Index)
+	case structs.VariableDeleteRequestType:
+		return n.applyDeleteVariable(buf[1:], log.Index)
+	case structs.RootKeyUpsertRequestType:
+		return n.applyUpsertRootKey(buf[1:], log.Index)
+	case structs.ServiceRegistrationUpsertRequestType:
+		return n.applyUpsertServiceRegistrations(buf[1:], log.Index)
+	case structs.ServiceRegistrationDeleteRequestType:
+		return n.applyDeleteServiceRegistrations(buf[1:], log.Index)
+	case structs.NodeUpdateEligibilityRequestType:
+		return n.applyEligibilityUpdate(buf[1:], log.Index)
+	case structs.AllocUpdateDesiredTransitionRequestType:
+		return n.applyAllocUpdateDesiredTransition(buf[1:], log.Index)
+	case structs.MultiregionRolloutUpsertRequestType:
+		return n.applyUpsertMultiregionRollout(buf[1:], log.Index)
 	default:
 		if ignoreUnknown {
 			n.logger.Printf("[WARN] nomad.fsm: ignoring unknown message type (%d), upgrade to newer version", msgType)
@@ -175,7 +196,7 @@ func (n *nomadFSM) applyStatusUpdate(buf []byte, index uint64) interface{} {
 		panic(fmt.Errorf("failed to decode request: %v", err))
 	}
 
-	if err := n.state.UpdateNodeStatus(index, req.NodeID, req.Status); err != nil {
+	if err := n.state.UpdateNodeStatus(index, req.NodeID, req.Status, req.UpdatedAt); err != nil {
 		n.logger.Printf("[ERR] nomad.fsm: UpdateNodeStatus failed: %v", err)
 		return err
 	}
@@ -189,13 +210,35 @@ func (n *nomadFSM) applyDrainUpdate(buf []byte, index uint64) interface{} {
 		panic(fmt.Errorf("failed to decode request: %v", err))
 	}
 
-	if err := n.state.UpdateNodeDrain(index, req.NodeID, req.Drain); err != nil {
+	// Drain requests without a strategy force the migration of all the
+	// allocations of the node
+	if req.Drain && req.DrainStrategy == nil {
+		req.DrainStrategy = &structs.DrainStrategy{
+			DrainSpec: structs.DrainSpec{Deadline: -1},
+		}
+	}
+
+	if err := n.state.UpdateNodeDrain(index, req.NodeID, req.DrainStrategy, req.MarkEligible); err != nil {
 		n.logger.Printf("[ERR] nomad.fsm: UpdateNodeDrain failed: %v", err)
 		return err
 	}
 	return nil
 }
 
+func (n *nomadFSM) applyEligibilityUpdate(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "node_eligibility_update"}, time.Now())
+	var req structs.NodeUpdateEligibilityRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpdateNodeEligibility(index, req.NodeID, req.Eligibility); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpdateNodeEligibility failed: %v", err)
+		return err
+	}
+	return nil
+}
+
 func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "register_job"}, time.Now())
 	var req structs.JobRegisterRequest
@@ -317,6 +360,29 @@ func (n *nomadFSM) applyUpdateEval(buf []byte, index uint64) interface{} {
 	return nil
 }
 
+func (n *nomadFSM) applyAllocUpdateDesiredTransition(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "alloc_update_desired_transition"}, time.Now())
+	var req structs.AllocUpdateDesiredTransitionRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpdateAllocsDesiredTransitions(index, req.Allocs, req.Evals); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpdateAllocsDesiredTransitions failed: %v", err)
+		return err
+	}
+
+	for _, eval := range req.Evals {
+		if eval.ShouldEnqueue() {
+			if err := n.evalBroker.Enqueue(eval); err != nil {
+				n.logger.Printf("[ERR] nomad.fsm: failed to enqueue evaluation %s: %v", eval.ID, err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (n *nomadFSM) applyDeleteEval(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "delete_eval"}, time.Now())
 	var req structs.EvalDeleteRequest
@@ -362,6 +428,90 @@ func (n *nomadFSM) applyAllocClientUpdate(buf []byte, index uint64) interface{}
 	return nil
 }
 
+func (n *nomadFSM) applyUpsertVariable(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_variable"}, time.Now())
+	var req structs.VariableEncryptedUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertVariable(index, req.Variable); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpsertVariable failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyDeleteVariable(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "delete_variable"}, time.Now())
+	var req structs.VariableDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteVariable(index, req.Path); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: DeleteVariable failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyUpsertMultiregionRollout(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_multiregion_rollout"}, time.Now())
+	var req structs.MultiregionRolloutUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertMultiregionRollout(index, req.Rollout); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpsertMultiregionRollout failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyUpsertRootKey(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_root_key"}, time.Now())
+	var req structs.RootKeyUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertRootKeyMeta(index, req.RootKeyMeta); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpsertRootKeyMeta failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyUpsertServiceRegistrations(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_service_registrations"}, time.Now())
+	var req structs.ServiceRegistrationUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertServiceRegistrations(index, req.Services); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpsertServiceRegistrations failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyDeleteServiceRegistrations(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "delete_service_registrations"}, time.Now())
+	var req structs.ServiceRegistrationDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteServiceRegistrations(index, req.IDs); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: DeleteServiceRegistrations failed: %v", err)
+		return err
+	}
+	return nil
+}
+
 func (n *nomadFSM) Snapshot() (raft.FSMSnapshot, error) {
 	// Create a new snapshot
 	snap, err := n.state.Snapshot()
@@ -403,6 +553,7 @@ func (n *nomadFSM) Restore(old io.ReadCloser) error {
 	}
 
 	// Populate the new state
+	summaries := false
 	msgType := make([]byte, 1)
 	for {
 		// Read the message type
@@ -474,6 +625,52 @@ func (n *nomadFSM) Restore(old io.ReadCloser) error {
 				return err
 			}
 
+		case VariableSnapshot:
+			variable := new(structs.VariableEncrypted)
+			if err := dec.Decode(variable); err != nil {
+				return err
+			}
+			if err := restore.VariableRestore(variable); err != nil {
+				return err
+			}
+
+		case RootKeyMetaSnapshot:
+			key := new(structs.RootKeyMeta)
+			if err := dec.Decode(key); err != nil {
+				return err
+			}
+			if err := restore.RootKeyMetaRestore(key); err != nil {
+				return err
+			}
+
+		case ServiceRegistrationSnapshot:
+			service := new(structs.ServiceRegistration)
+			if err := dec.Decode(service); err != nil {
+				return err
+			}
+			if err := restore.ServiceRegistrationRestore(service); err != nil {
+				return err
+			}
+
+		case JobSummarySnapshot:
+			summary := new(structs.JobSummary)
+			if err := dec.Decode(summary); err != nil {
+				return err
+			}
+			if err := restore.JobSummaryRestore(summary); err != nil {
+				return err
+			}
+			summaries = true
+
+		case MultiregionRolloutSnapshot:
+			rollout := new(structs.MultiregionRollout)
+			if err := dec.Decode(rollout); err != nil {
+				return err
+			}
+			if err := restore.MultiregionRolloutRestore(rollout); err != nil {
+				return err
+			}
+
 		default:
 			return fmt.Errorf("Unrecognized snapshot type: %v", msgType)
 		}
@@ -481,6 +678,17 @@ func (n *nomadFSM) Restore(old io.ReadCloser) error {
 
 	// Commit the state restore
 	restore.Commit()
+
+	// Snapshots taken before job summaries existed do not include them
+	if !summaries {
+		index, err := newState.Index("allocs")
+		if err != nil {
+			return err
+		}
+		if err := newState.ReconcileJobSummaries(index); err != nil {
+			return fmt.Errorf("job summary reconcile failed: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -528,6 +736,26 @@ func (s *nomadSnapshot) Persist(sink raft.SnapshotSink) error {
 		sink.Cancel()
 		return err
 	}
+	if err := s.persistRootKeyMetas(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistVariables(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistServiceRegistrations(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistJobSummaries(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistMultiregionRollouts(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
 	return nil
 }
 
@@ -693,6 +921,142 @@ func (s *nomadSnapshot) persistPeriodicLaunches(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *nomadSnapshot) persistMultiregionRollouts(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the rollouts
+	rollouts, err := s.snap.MultiregionRollouts()
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := rollouts.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		rollout := raw.(*structs.MultiregionRollout)
+
+		// Write out the rollout
+		sink.Write([]byte{byte(MultiregionRolloutSnapshot)})
+		if err := encoder.Encode(rollout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistVariables(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the variables
+	variables, err := s.snap.Variables()
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := variables.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		variable := raw.(*structs.VariableEncrypted)
+
+		// Write out the encrypted variable
+		sink.Write([]byte{byte(VariableSnapshot)})
+		if err := encoder.Encode(variable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistRootKeyMetas(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get the metadata of all the root keys. The key material is kept in the
+	// keystore of each server and never written to the snapshot.
+	keys, err := s.snap.RootKeyMetas()
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := keys.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		key := raw.(*structs.RootKeyMeta)
+
+		// Write out the root key metadata
+		sink.Write([]byte{byte(RootKeyMetaSnapshot)})
+		if err := encoder.Encode(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistServiceRegistrations(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the service registrations
+	services, err := s.snap.ServiceRegistrations()
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := services.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		service := raw.(*structs.ServiceRegistration)
+
+		// Write out the service registration
+		sink.Write([]byte{byte(ServiceRegistrationSnapshot)})
+		if err := encoder.Encode(service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistJobSummaries(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the job summaries
+	summaries, err := s.snap.JobSummaries()
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := summaries.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		summary := raw.(*structs.JobSummary)
+
+		// Write out the job summary
+		sink.Write([]byte{byte(JobSummarySnapshot)})
+		if err := encoder.Encode(summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Release is a no-op, as we just need to GC the pointer
 // to the state store snapshot. There is nothing to explicitly
 // cleanup.
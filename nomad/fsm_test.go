@@ -220,6 +220,85 @@ func TestFSM_UpdateNodeDrain(t *testing.T) {
 	}
 }
 
+func TestFSM_UpdateNodeEligibility(t *testing.T) {
+	fsm := testFSM(t)
+
+	node := mock.Node()
+	req := structs.NodeRegisterRequest{
+		Node: node,
+	}
+	buf, err := structs.Encode(structs.NodeRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	req2 := structs.NodeUpdateEligibilityRequest{
+		NodeID:      node.ID,
+		Eligibility: structs.NodeSchedulingIneligible,
+	}
+	buf, err = structs.Encode(structs.NodeUpdateEligibilityRequestType, req2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp = fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	node, err = fsm.State().NodeByID(req.Node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if node.SchedulingEligibility != structs.NodeSchedulingIneligible {
+		t.Fatalf("bad node: %#v", node)
+	}
+}
+
+func TestFSM_AllocUpdateDesiredTransition(t *testing.T) {
+	fsm := testFSM(t)
+	fsm.evalBroker.SetEnabled(true)
+
+	alloc := mock.Alloc()
+	fsm.State().UpsertAllocs(1, []*structs.Allocation{alloc})
+
+	eval := mock.Eval()
+	eval.JobID = alloc.JobID
+	req := structs.AllocUpdateDesiredTransitionRequest{
+		Allocs: map[string]*structs.DesiredTransition{
+			alloc.ID: &structs.DesiredTransition{Migrate: true},
+		},
+		Evals: []*structs.Evaluation{eval},
+	}
+	buf, err := structs.Encode(structs.AllocUpdateDesiredTransitionRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err := fsm.State().AllocByID(alloc.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !out.DesiredTransition.ShouldMigrate() {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// The evaluation is enqueued
+	if stats := fsm.evalBroker.Stats(); stats.TotalReady != 1 {
+		t.Fatalf("bad: %#v", stats)
+	}
+}
+
 func TestFSM_RegisterJob(t *testing.T) {
 	fsm := testFSM(t)
 
@@ -487,6 +566,144 @@ func TestFSM_UpdateAllocFromClient(t *testing.T) {
 	}
 }
 
+func TestFSM_UpsertDeleteVariable(t *testing.T) {
+	fsm := testFSM(t)
+
+	variable := &structs.VariableEncrypted{
+		Path:  "app/db",
+		KeyID: structs.GenerateUUID(),
+		Data:  []byte("secret"),
+	}
+	req := structs.VariableEncryptedUpsertRequest{
+		Variable: variable,
+	}
+	buf, err := structs.Encode(structs.VariableUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify we are registered
+	out, err := fsm.State().VariableByPath(variable.Path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("not found!")
+	}
+	if out.CreateIndex != 1 {
+		t.Fatalf("bad index: %d", out.CreateIndex)
+	}
+
+	del := structs.VariableDeleteRequest{
+		Path: variable.Path,
+	}
+	buf, err = structs.Encode(structs.VariableDeleteRequestType, del)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp = fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify we are removed
+	out, err = fsm.State().VariableByPath(variable.Path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("variable found!")
+	}
+}
+
+func TestFSM_UpsertDeleteServiceRegistrations(t *testing.T) {
+	fsm := testFSM(t)
+
+	service := mock.ServiceRegistration()
+	req := structs.ServiceRegistrationUpsertRequest{
+		Services: []*structs.ServiceRegistration{service},
+	}
+	buf, err := structs.Encode(structs.ServiceRegistrationUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify we are registered
+	out, err := fsm.State().ServiceRegistrationByID(service.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("not found!")
+	}
+	if out.CreateIndex != 1 {
+		t.Fatalf("bad index: %d", out.CreateIndex)
+	}
+
+	del := structs.ServiceRegistrationDeleteRequest{
+		IDs: []string{service.ID},
+	}
+	buf, err = structs.Encode(structs.ServiceRegistrationDeleteRequestType, del)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp = fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify we are removed
+	out, err = fsm.State().ServiceRegistrationByID(service.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("service found!")
+	}
+}
+
+func TestFSM_UpsertRootKeyMeta(t *testing.T) {
+	fsm := testFSM(t)
+
+	key := &structs.RootKeyMeta{
+		KeyID:     structs.GenerateUUID(),
+		Algorithm: structs.RootKeyAlgorithmAES256GCM,
+		State:     structs.RootKeyStateActive,
+	}
+	req := structs.RootKeyUpsertRequest{
+		RootKeyMeta: key,
+	}
+	buf, err := structs.Encode(structs.RootKeyUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err := fsm.State().ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.KeyID != key.KeyID {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
 func testSnapshotRestore(t *testing.T, fsm *nomadFSM) *nomadFSM {
 	// Snapshot
 	snap, err := fsm.Snapshot()
@@ -556,6 +773,31 @@ func TestFSM_SnapshotRestore_Jobs(t *testing.T) {
 	}
 }
 
+func TestFSM_SnapshotRestore_JobSummaries(t *testing.T) {
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	job1 := mock.Job()
+	state.UpsertJob(1000, job1)
+	alloc := mock.Alloc()
+	alloc.Job = job1
+	alloc.JobID = job1.ID
+	state.UpsertAllocs(1001, []*structs.Allocation{alloc})
+	job2 := mock.Job()
+	state.UpsertJob(1002, job2)
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	for _, job := range []*structs.Job{job1, job2} {
+		expected, _ := state.JobSummaryByID(job.ID)
+		out, _ := state2.JobSummaryByID(job.ID)
+		if !reflect.DeepEqual(expected, out) {
+			t.Fatalf("bad: \n%#v\n%#v", out, expected)
+		}
+	}
+}
+
 func TestFSM_SnapshotRestore_Evals(t *testing.T) {
 	// Add some state
 	fsm := testFSM(t)
@@ -664,3 +906,64 @@ func TestFSM_SnapshotRestore_PeriodicLaunches(t *testing.T) {
 		t.Fatalf("bad: \n%#v\n%#v", out2, job2)
 	}
 }
+
+func TestFSM_SnapshotRestore_MultiregionRollouts(t *testing.T) {
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	job := mock.Job()
+	job.Multiregion = &structs.Multiregion{
+		Regions: []*structs.MultiregionRegion{
+			&structs.MultiregionRegion{Name: "east"},
+		},
+	}
+	rollout := structs.NewMultiregionRollout(job)
+	state.UpsertMultiregionRollout(1000, rollout)
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out, _ := state2.MultiregionRolloutByJobID(job.ID)
+	if out == nil || out.Status != rollout.Status || len(out.Regions) != 1 ||
+		out.Regions[0].Region != "east" || out.Job.ID != job.ID {
+		t.Fatalf("bad: \n%#v\n%#v", out, rollout)
+	}
+}
+
+func TestFSM_SnapshotRestore_Variables(t *testing.T) {
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	key := &structs.RootKeyMeta{KeyID: structs.GenerateUUID(), State: structs.RootKeyStateActive}
+	state.UpsertRootKeyMeta(1000, key)
+	variable := &structs.VariableEncrypted{Path: "app/db", KeyID: key.KeyID, Data: []byte("secret")}
+	state.UpsertVariable(1001, variable)
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	outKey, _ := state2.RootKeyMetaByID(key.KeyID)
+	outVar, _ := state2.VariableByPath(variable.Path)
+	if !reflect.DeepEqual(key, outKey) {
+		t.Fatalf("bad: \n%#v\n%#v", outKey, key)
+	}
+	if !reflect.DeepEqual(variable, outVar) {
+		t.Fatalf("bad: \n%#v\n%#v", outVar, variable)
+	}
+}
+
+func TestFSM_SnapshotRestore_ServiceRegistrations(t *testing.T) {
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	service := mock.ServiceRegistration()
+	state.UpsertServiceRegistrations(1000, []*structs.ServiceRegistration{service})
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out, _ := state2.ServiceRegistrationByID(service.ID)
+	if !reflect.DeepEqual(service, out) {
+		t.Fatalf("bad: \n%#v\n%#v", out, service)
+	}
+}
@@ -14,6 +14,11 @@ import (
 // Job endpoint is used for job interactions
 type Job struct {
 	srv *Server
+
+	// mutators and validators make up the admission chain that is run on
+	// jobs before they are registered.
+	mutators   []jobMutator
+	validators []jobValidator
 }
 
 // Register is used to upsert a job for scheduling
@@ -32,16 +37,20 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		return err
 	}
 
-	// Initialize the job fields (sets defaults and any necessary init work).
-	args.Job.InitFields()
-
-	if err := args.Job.Validate(); err != nil {
-		return err
+	// Multi-region jobs are not registered here but fanned out to each of
+	// their regions.
+	if args.Job.IsMultiregion() {
+		return j.registerMultiregion(args, reply)
 	}
 
-	if args.Job.Type == structs.JobTypeCore {
-		return fmt.Errorf("job type cannot be core")
+	// Run the admission controllers, which set the job defaults and
+	// validate the job.
+	job, warnings, err := j.admissionControllers(args.Job)
+	if err != nil {
+		return err
 	}
+	args.Job = job
+	reply.Warnings = mergeWarnings(warnings)
 
 	// Commit this update via Raft
 	_, index, err := j.srv.raftApply(structs.JobRegisterRequestType, args)
@@ -89,6 +98,29 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	return nil
 }
 
+// registerMultiregion admits a multi-region job and starts its rollout to
+// each of its regions. The rollout is run by the leader in the background and
+// its progress can be read with MultiregionStatus. Each regional copy is
+// registered through the Job.Register endpoint of its region, so it is also
+// admitted by the admission controllers of that region.
+func (j *Job) registerMultiregion(args *structs.JobRegisterRequest, reply *structs.JobRegisterResponse) error {
+	// Admit a copy so the regional jobs are built from the job as
+	// submitted.
+	_, warnings, err := j.admissionControllers(args.Job.Copy())
+	if err != nil {
+		return err
+	}
+	reply.Warnings = mergeWarnings(warnings)
+
+	index, err := j.srv.createMultiregionRollout(args.Job)
+	if err != nil {
+		j.srv.logger.Printf("[ERR] nomad.job: multi-region rollout failed to start: %v", err)
+		return err
+	}
+	reply.Index = index
+	return nil
+}
+
 // checkBlacklist returns an error if the user has set any blacklisted field in
 // the job.
 func (j *Job) checkBlacklist(job *structs.Job) error {
@@ -275,6 +307,96 @@ func (j *Job) GetJob(args *structs.JobSpecificRequest,
 	return j.srv.blockingRPC(&opts)
 }
 
+// Summary retrieves the summary of the allocations of a job
+func (j *Job) Summary(args *structs.JobSpecificRequest,
+	reply *structs.JobSummaryResponse) error {
+	if done, err := j.srv.forward("Job.Summary", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "summary"}, time.Now())
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{JobSummary: args.JobID}),
+		run: func() error {
+
+			// Look for the job summary
+			snap, err := j.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			out, err := snap.JobSummaryByID(args.JobID)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.JobSummary = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the job summary table
+				index, err := snap.Index("job_summary")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
+// MultiregionStatus retrieves the rollout of a multi-region job submitted to
+// this region
+func (j *Job) MultiregionStatus(args *structs.JobSpecificRequest,
+	reply *structs.MultiregionRolloutResponse) error {
+	if done, err := j.srv.forward("Job.MultiregionStatus", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "multiregion_status"}, time.Now())
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{MultiregionRollout: args.JobID}),
+		run: func() error {
+			// Look for the rollout
+			snap, err := j.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			out, err := snap.MultiregionRolloutByJobID(args.JobID)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Rollout = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the rollouts table
+				index, err := snap.Index("multiregion_rollouts")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
 // List is used to list the jobs registered in the system
 func (j *Job) List(args *structs.JobListRequest,
 	reply *structs.JobListResponse) error {
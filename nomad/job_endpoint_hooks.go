@@ -0,0 +1,313 @@
+package nomad
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// defaultAdmissionWebhookTimeout is used for webhooks that do not set a
+	// timeout.
+	defaultAdmissionWebhookTimeout = 5 * time.Second
+)
+
+// jobAdmissionController is a step of the admission chain that is run on a
+// job before it is registered.
+type jobAdmissionController interface {
+	Name() string
+}
+
+// jobMutator is an admission controller that may modify the job.
+type jobMutator interface {
+	jobAdmissionController
+	Mutate(*structs.Job) (out *structs.Job, warnings []error, err error)
+}
+
+// jobValidator is an admission controller that may reject the job or attach
+// warnings to its registration.
+type jobValidator interface {
+	jobAdmissionController
+	Validate(*structs.Job) (warnings []error, err error)
+}
+
+// NewJobEndpoints creates the Job endpoint with the admission chain built
+// from the server's configuration.
+func NewJobEndpoints(s *Server) *Job {
+	j := &Job{
+		srv: s,
+		mutators: []jobMutator{
+			jobCanonicalizer{},
+		},
+		validators: []jobValidator{
+			jobValidate{},
+		},
+	}
+
+	conf := s.config.Admission
+	if conf == nil {
+		return j
+	}
+
+	if len(conf.DefaultMeta) != 0 {
+		j.mutators = append(j.mutators, &jobDefaultMeta{meta: conf.DefaultMeta})
+	}
+	if len(conf.RequiredMeta) != 0 || conf.MaxTaskMemoryMB != 0 || conf.DenyPrivileged {
+		j.validators = append(j.validators, &jobPolicyValidator{conf: conf})
+	}
+	for _, hook := range conf.Webhooks {
+		w := newAdmissionWebhook(hook)
+		switch hook.Type {
+		case AdmissionWebhookMutator:
+			j.mutators = append(j.mutators, w)
+		default:
+			j.validators = append(j.validators, w)
+		}
+	}
+	return j
+}
+
+// admissionControllers runs the mutators and then the validators on the job.
+// It returns the mutated job and any warnings, or an error if the job is
+// rejected.
+func (j *Job) admissionControllers(job *structs.Job) (*structs.Job, []error, error) {
+	var warnings []error
+
+	for _, mutator := range j.mutators {
+		out, w, err := mutator.Mutate(job)
+		warnings = append(warnings, w...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error in job mutator %s: %v", mutator.Name(), err)
+		}
+		job = out
+	}
+
+	var mErr multierror.Error
+	for _, validator := range j.validators {
+		w, err := validator.Validate(job)
+		warnings = append(warnings, w...)
+		if err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	if err := mErr.ErrorOrNil(); err != nil {
+		return nil, nil, err
+	}
+
+	return job, warnings, nil
+}
+
+// mergeWarnings formats the admission warnings for the register response.
+func mergeWarnings(warnings []error) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	out := make([]string, len(warnings))
+	for i, w := range warnings {
+		out[i] = fmt.Sprintf("* %s", w)
+	}
+	return strings.Join(out, "\n")
+}
+
+// jobCanonicalizer sets the defaults of the job fields.
+type jobCanonicalizer struct{}
+
+func (jobCanonicalizer) Name() string {
+	return "canonicalize"
+}
+
+func (jobCanonicalizer) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	job.InitFields()
+	return job, nil, nil
+}
+
+// jobDefaultMeta fills in the meta keys the job does not set.
+type jobDefaultMeta struct {
+	meta map[string]string
+}
+
+func (*jobDefaultMeta) Name() string {
+	return "default-meta"
+}
+
+func (m *jobDefaultMeta) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	if job.Meta == nil {
+		job.Meta = make(map[string]string, len(m.meta))
+	}
+	for k, v := range m.meta {
+		if _, ok := job.Meta[k]; !ok {
+			job.Meta[k] = v
+		}
+	}
+	return job, nil, nil
+}
+
+// jobValidate runs the job's own validation.
+type jobValidate struct{}
+
+func (jobValidate) Name() string {
+	return "validate"
+}
+
+func (jobValidate) Validate(job *structs.Job) ([]error, error) {
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	if job.Type == structs.JobTypeCore {
+		return nil, fmt.Errorf("job type cannot be core")
+	}
+	return nil, nil
+}
+
+// jobPolicyValidator enforces the operator's job policies.
+type jobPolicyValidator struct {
+	conf *AdmissionConfig
+}
+
+func (*jobPolicyValidator) Name() string {
+	return "policy"
+}
+
+func (v *jobPolicyValidator) Validate(job *structs.Job) ([]error, error) {
+	var mErr multierror.Error
+	for _, key := range v.conf.RequiredMeta {
+		if job.Meta[key] == "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("missing required meta key %q", key))
+		}
+	}
+
+	for _, tg := range job.TaskGroups {
+		for _, task := range tg.Tasks {
+			if max := v.conf.MaxTaskMemoryMB; max != 0 && task.Resources != nil && task.Resources.MemoryMB > max {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("task %q in group %q requests %d MB of memory, more than the allowed %d MB",
+					task.Name, tg.Name, task.Resources.MemoryMB, max))
+			}
+
+			if v.conf.DenyPrivileged && task.Driver == "docker" && isTrue(task.Config["privileged"]) {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("task %q in group %q runs a privileged container", task.Name, tg.Name))
+			}
+		}
+	}
+	return nil, mErr.ErrorOrNil()
+}
+
+// isTrue returns whether a driver config value is set to true.
+func isTrue(v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return b == "true" || b == "1"
+	default:
+		return false
+	}
+}
+
+// admissionWebhookRequest is the body sent to admission webhooks
+type admissionWebhookRequest struct {
+	Job *structs.Job
+}
+
+// admissionWebhookResponse is the body returned by admission webhooks
+type admissionWebhookResponse struct {
+	// Job is the modified job. It is only used by mutators and leaves the job
+	// unchanged if omitted.
+	Job *structs.Job
+
+	// Error rejects the job if set.
+	Error string
+
+	// Warnings are returned to the submitter of the job.
+	Warnings []string
+}
+
+// admissionWebhook is an admission controller that calls out to an external
+// HTTP endpoint.
+type admissionWebhook struct {
+	conf   *AdmissionWebhook
+	client *http.Client
+}
+
+func newAdmissionWebhook(conf *AdmissionWebhook) *admissionWebhook {
+	timeout := conf.Timeout
+	if timeout == 0 {
+		timeout = defaultAdmissionWebhookTimeout
+	}
+	return &admissionWebhook{
+		conf:   conf,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *admissionWebhook) Name() string {
+	return w.conf.Name
+}
+
+func (w *admissionWebhook) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	resp, warnings, err := w.call(job)
+	if err != nil || resp == nil {
+		return job, warnings, err
+	}
+	if resp.Job != nil {
+		job = resp.Job
+	}
+	return job, warnings, nil
+}
+
+func (w *admissionWebhook) Validate(job *structs.Job) ([]error, error) {
+	_, warnings, err := w.call(job)
+	return warnings, err
+}
+
+// call sends the job to the webhook. If the webhook can not be reached and
+// the webhook fails open, a nil response is returned along with a warning.
+func (w *admissionWebhook) call(job *structs.Job) (*admissionWebhookResponse, []error, error) {
+	resp, err := w.post(job)
+	if err != nil {
+		if w.conf.FailOpen {
+			return nil, []error{fmt.Errorf("admission webhook %s skipped: %v", w.conf.Name, err)}, nil
+		}
+		return nil, nil, fmt.Errorf("admission webhook %s failed: %v", w.conf.Name, err)
+	}
+
+	var warnings []error
+	for _, warn := range resp.Warnings {
+		warnings = append(warnings, fmt.Errorf("%s: %s", w.conf.Name, warn))
+	}
+	if resp.Error != "" {
+		return nil, warnings, fmt.Errorf("admission webhook %s rejected job: %s", w.conf.Name, resp.Error)
+	}
+	return resp, warnings, nil
+}
+
+// post sends the job to the webhook and decodes its response.
+func (w *admissionWebhook) post(job *structs.Job) (*admissionWebhookResponse, error) {
+	body, err := json.Marshal(&admissionWebhookRequest{Job: job})
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := w.client.Post(w.conf.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(httpResp.Body)
+		return nil, fmt.Errorf("unexpected response code %d: %s", httpResp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var resp admissionWebhookResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return &resp, nil
+}
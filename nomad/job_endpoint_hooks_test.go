@@ -0,0 +1,203 @@
+package nomad
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func testAdmissionJob(conf *AdmissionConfig) *Job {
+	return NewJobEndpoints(&Server{config: &Config{Admission: conf}})
+}
+
+func TestJobAdmission_DefaultChain(t *testing.T) {
+	j := testAdmissionJob(nil)
+
+	job := mock.Job()
+	out, warnings, err := j.admissionControllers(job)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("bad: %v", warnings)
+	}
+	if out != job {
+		t.Fatalf("job should not be replaced")
+	}
+
+	// Invalid jobs are rejected
+	job = mock.Job()
+	job.Priority = 0
+	if _, _, err := j.admissionControllers(job); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Core jobs are rejected
+	job = mock.Job()
+	job.Type = structs.JobTypeCore
+	if _, _, err := j.admissionControllers(job); err == nil || !strings.Contains(err.Error(), "core") {
+		t.Fatalf("expected core error, got: %v", err)
+	}
+}
+
+func TestJobAdmission_DefaultMeta(t *testing.T) {
+	j := testAdmissionJob(&AdmissionConfig{
+		DefaultMeta: map[string]string{
+			"owner": "ops",
+			"team":  "infra",
+		},
+	})
+
+	job := mock.Job()
+	out, _, err := j.admissionControllers(job)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Keys set by the job are kept
+	if out.Meta["owner"] != "armon" {
+		t.Fatalf("bad: %v", out.Meta)
+	}
+	if out.Meta["team"] != "infra" {
+		t.Fatalf("bad: %v", out.Meta)
+	}
+}
+
+func TestJobAdmission_Policy(t *testing.T) {
+	j := testAdmissionJob(&AdmissionConfig{
+		RequiredMeta:    []string{"owner"},
+		MaxTaskMemoryMB: 16 * 1024,
+		DenyPrivileged:  true,
+	})
+
+	// The mock job passes the policy
+	if _, _, err := j.admissionControllers(mock.Job()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Missing required meta
+	job := mock.Job()
+	delete(job.Meta, "owner")
+	if _, _, err := j.admissionControllers(job); err == nil || !strings.Contains(err.Error(), "owner") {
+		t.Fatalf("expected meta error, got: %v", err)
+	}
+
+	// Too much memory
+	job = mock.Job()
+	job.TaskGroups[0].Tasks[0].Resources.MemoryMB = 32 * 1024
+	if _, _, err := j.admissionControllers(job); err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Fatalf("expected memory error, got: %v", err)
+	}
+
+	// Privileged docker containers
+	job = mock.Job()
+	task := job.TaskGroups[0].Tasks[0]
+	task.Driver = "docker"
+	task.Config = map[string]interface{}{
+		"image":      "redis",
+		"privileged": true,
+	}
+	if _, _, err := j.admissionControllers(job); err == nil || !strings.Contains(err.Error(), "privileged") {
+		t.Fatalf("expected privileged error, got: %v", err)
+	}
+}
+
+func TestJobAdmission_WebhookMutator(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req admissionWebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Job.Meta["mutated"] = "true"
+		json.NewEncoder(w).Encode(&admissionWebhookResponse{
+			Job:      req.Job,
+			Warnings: []string{"job was mutated"},
+		})
+	}))
+	defer ts.Close()
+
+	j := testAdmissionJob(&AdmissionConfig{
+		Webhooks: []*AdmissionWebhook{
+			{
+				Name: "mutate",
+				Type: AdmissionWebhookMutator,
+				URL:  ts.URL,
+			},
+		},
+	})
+
+	out, warnings, err := j.admissionControllers(mock.Job())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Meta["mutated"] != "true" {
+		t.Fatalf("bad: %v", out.Meta)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "job was mutated") {
+		t.Fatalf("bad: %v", warnings)
+	}
+}
+
+func TestJobAdmission_WebhookValidator(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&admissionWebhookResponse{
+			Error: "not allowed",
+		})
+	}))
+	defer ts.Close()
+
+	j := testAdmissionJob(&AdmissionConfig{
+		Webhooks: []*AdmissionWebhook{
+			{
+				Name:     "deny",
+				Type:     AdmissionWebhookValidator,
+				URL:      ts.URL,
+				FailOpen: true,
+			},
+		},
+	})
+
+	// A rejection is honored even if the webhook fails open
+	_, _, err := j.admissionControllers(mock.Job())
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("expected rejection, got: %v", err)
+	}
+}
+
+func TestJobAdmission_WebhookFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	hook := &AdmissionWebhook{
+		Name:    "slow",
+		Type:    AdmissionWebhookValidator,
+		URL:     ts.URL,
+		Timeout: 50 * time.Millisecond,
+	}
+	j := testAdmissionJob(&AdmissionConfig{
+		Webhooks: []*AdmissionWebhook{hook},
+	})
+
+	// Webhooks fail closed by default
+	if _, _, err := j.admissionControllers(mock.Job()); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Failing open admits the job with a warning
+	hook.FailOpen = true
+	_, warnings, err := j.admissionControllers(mock.Job())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "skipped") {
+		t.Fatalf("bad: %v", warnings)
+	}
+}
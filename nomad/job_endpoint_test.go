@@ -1,7 +1,9 @@
 package nomad
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -85,6 +87,320 @@ func TestJobEndpoint_Register(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_Multiregion(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.Region = "region1"
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+
+	s2 := testServer(t, func(c *Config) {
+		c.Region = "region2"
+	})
+	defer s2.Shutdown()
+
+	// Join the servers
+	s2Addr := fmt.Sprintf("127.0.0.1:%d",
+		s2.config.SerfConfig.MemberlistConfig.BindPort)
+	if n, err := s1.Join([]string{s2Addr}); err != nil || n != 1 {
+		t.Fatalf("Failed joining: %v (%d joined)", err, n)
+	}
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForLeader(t, s2.RPC)
+	testutil.WaitForResult(func() (bool, error) {
+		s1.peerLock.RLock()
+		defer s1.peerLock.RUnlock()
+		return len(s1.peers["region2"]) == 1, nil
+	}, func(err error) {
+		t.Fatalf("region2 not known to region1")
+	})
+
+	// Roll out to region2 before region1
+	job := mock.Job()
+	job.Region = "region1"
+	job.Multiregion = &structs.Multiregion{
+		Strategy: &structs.MultiregionStrategy{MaxParallel: 1},
+		Regions: []*structs.MultiregionRegion{
+			&structs.MultiregionRegion{
+				Name:        "region2",
+				Count:       2,
+				Datacenters: []string{"dc2"},
+			},
+			&structs.MultiregionRegion{
+				Name: "region1",
+			},
+		},
+	}
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "region1"},
+	}
+
+	// Fetch the response
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	// Wait for the rollout to finish
+	get := &structs.JobSpecificRequest{
+		JobID:        job.ID,
+		QueryOptions: structs.QueryOptions{Region: "region1"},
+	}
+	var status structs.MultiregionRolloutResponse
+	testutil.WaitForResult(func() (bool, error) {
+		if err := msgpackrpc.CallWithCodec(codec, "Job.MultiregionStatus", get, &status); err != nil {
+			return false, err
+		}
+		if status.Rollout == nil || !status.Rollout.Terminal() {
+			return false, fmt.Errorf("rollout not finished: %#v", status.Rollout)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	if status.Rollout.Status != structs.MultiregionStatusComplete || len(status.Rollout.Regions) != 2 {
+		t.Fatalf("bad: %#v", status.Rollout)
+	}
+	for i, region := range []string{"region2", "region1"} {
+		result := status.Rollout.Regions[i]
+		if result.Region != region || result.Status != structs.MultiregionStatusComplete || result.EvalID == "" {
+			t.Fatalf("bad result %d: %#v", i, result)
+		}
+	}
+
+	// Check the regional jobs
+	out, err := s2.fsm.State().JobByID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Region != "region2" || out.Multiregion != nil {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out.Datacenters[0] != "dc2" || out.TaskGroups[0].Count != 2 {
+		t.Fatalf("overrides not applied: %#v", out)
+	}
+
+	// The first region finished evaluating before the second was registered
+	eval, err := s2.fsm.State().EvalByID(status.Rollout.Regions[0].EvalID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if eval == nil || eval.Status != structs.EvalStatusComplete {
+		t.Fatalf("bad: %#v", eval)
+	}
+
+	out, err = s1.fsm.State().JobByID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Region != "region1" || out.Datacenters[0] != "dc1" {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestJobEndpoint_Register_Multiregion_Halt(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// The unknown region fails, so the rollout stops before "global"
+	job := mock.Job()
+	job.Multiregion = &structs.Multiregion{
+		Strategy: &structs.MultiregionStrategy{MaxParallel: 1},
+		Regions: []*structs.MultiregionRegion{
+			&structs.MultiregionRegion{Name: "unknown"},
+			&structs.MultiregionRegion{Name: "global"},
+		},
+	}
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	// Fetch the response
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Wait for the rollout to fail
+	var rollout *structs.MultiregionRollout
+	testutil.WaitForResult(func() (bool, error) {
+		var err error
+		rollout, err = s1.fsm.State().MultiregionRolloutByJobID(job.ID)
+		if err != nil {
+			return false, err
+		}
+		if rollout == nil || !rollout.Terminal() {
+			return false, fmt.Errorf("rollout not finished: %#v", rollout)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	if rollout.Status != structs.MultiregionStatusFailed {
+		t.Fatalf("bad: %#v", rollout)
+	}
+	if result := rollout.Regions[0]; result.Region != "unknown" || result.Status != structs.MultiregionStatusFailed || result.Error == "" {
+		t.Fatalf("bad: %#v", result)
+	}
+	if result := rollout.Regions[1]; result.Region != "global" || result.Status != structs.MultiregionStatusPending {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	// Check the job was not registered locally
+	out, err := s1.fsm.State().JobByID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("unexpected job: %#v", out)
+	}
+}
+
+func TestJobEndpoint_Register_Multiregion_Admission(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.Region = "region1"
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+
+	// Only region2 requires the team meta
+	s2 := testServer(t, func(c *Config) {
+		c.Region = "region2"
+		c.Admission = &AdmissionConfig{
+			RequiredMeta: []string{"team"},
+		}
+	})
+	defer s2.Shutdown()
+
+	// Join the servers
+	s2Addr := fmt.Sprintf("127.0.0.1:%d",
+		s2.config.SerfConfig.MemberlistConfig.BindPort)
+	if n, err := s1.Join([]string{s2Addr}); err != nil || n != 1 {
+		t.Fatalf("Failed joining: %v (%d joined)", err, n)
+	}
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForLeader(t, s2.RPC)
+	testutil.WaitForResult(func() (bool, error) {
+		s1.peerLock.RLock()
+		defer s1.peerLock.RUnlock()
+		return len(s1.peers["region2"]) == 1, nil
+	}, func(err error) {
+		t.Fatalf("region2 not known to region1")
+	})
+
+	// The job is admitted by region1 but rejected by region2, which halts
+	// the rollout before region1
+	job := mock.Job()
+	job.Region = "region1"
+	job.Multiregion = &structs.Multiregion{
+		Strategy: &structs.MultiregionStrategy{MaxParallel: 1},
+		Regions: []*structs.MultiregionRegion{
+			&structs.MultiregionRegion{Name: "region2"},
+			&structs.MultiregionRegion{Name: "region1"},
+		},
+	}
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "region1"},
+	}
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Wait for the rollout to fail
+	var rollout *structs.MultiregionRollout
+	testutil.WaitForResult(func() (bool, error) {
+		var err error
+		rollout, err = s1.fsm.State().MultiregionRolloutByJobID(job.ID)
+		if err != nil {
+			return false, err
+		}
+		if rollout == nil || !rollout.Terminal() {
+			return false, fmt.Errorf("rollout not finished: %#v", rollout)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	if rollout.Status != structs.MultiregionStatusFailed {
+		t.Fatalf("bad: %#v", rollout)
+	}
+	if result := rollout.Regions[0]; result.Status != structs.MultiregionStatusFailed || !strings.Contains(result.Error, "team") {
+		t.Fatalf("bad: %#v", result)
+	}
+	if result := rollout.Regions[1]; result.Status != structs.MultiregionStatusPending {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	// The job is registered in neither region
+	for _, s := range []*Server{s1, s2} {
+		out, err := s.fsm.State().JobByID(job.ID)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out != nil {
+			t.Fatalf("unexpected job: %#v", out)
+		}
+	}
+}
+
+func TestJobEndpoint_Register_Admission(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.Admission = &AdmissionConfig{
+			RequiredMeta: []string{"owner"},
+			Webhooks: []*AdmissionWebhook{
+				{
+					Name:     "unreachable",
+					Type:     AdmissionWebhookValidator,
+					URL:      "http://127.0.0.1:0/validate",
+					FailOpen: true,
+				},
+			},
+		}
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// A job without the required meta is rejected
+	job := mock.Job()
+	delete(job.Meta, "owner")
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// A valid job is registered with the webhook's warning
+	req.Job = mock.Job()
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.Contains(resp.Warnings, "unreachable") {
+		t.Fatalf("bad: %q", resp.Warnings)
+	}
+
+	out, err := s1.fsm.State().JobByID(req.Job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("expected job")
+	}
+}
+
 func TestJobEndpoint_Register_Existing(t *testing.T) {
 	s1 := testServer(t, func(c *Config) {
 		c.NumSchedulers = 0 // Prevent automatic dequeue
@@ -578,6 +894,64 @@ func TestJobEndpoint_GetJob(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Summary(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the register request
+	job := mock.Job()
+	reg := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	// Fetch the response
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", reg, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Lookup the job summary
+	get := &structs.JobSpecificRequest{
+		JobID:        job.ID,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp2 structs.JobSummaryResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Summary", get, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp2.Index != resp.JobModifyIndex {
+		t.Fatalf("Bad index: %d %d", resp2.Index, resp.Index)
+	}
+
+	expected := &structs.JobSummary{
+		JobID: job.ID,
+		Summary: map[string]structs.TaskGroupSummary{
+			"web": structs.TaskGroupSummary{
+				Queued: job.TaskGroups[0].Count,
+			},
+		},
+		CreateIndex: resp.JobModifyIndex,
+		ModifyIndex: resp.JobModifyIndex,
+	}
+	if !reflect.DeepEqual(resp2.JobSummary, expected) {
+		t.Fatalf("bad: %#v %#v", resp2.JobSummary, expected)
+	}
+
+	// Lookup non-existing job summary
+	get.JobID = "foobarbaz"
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Summary", get, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp2.JobSummary != nil {
+		t.Fatalf("unexpected job summary")
+	}
+}
+
 func TestJobEndpoint_GetJob_Blocking(t *testing.T) {
 	s1 := testServer(t, nil)
 	defer s1.Shutdown()
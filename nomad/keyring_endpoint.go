@@ -0,0 +1,71 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// Keyring endpoint is used to manage the root keys used to encrypt variables
+type Keyring struct {
+	srv *Server
+}
+
+// Rotate is used to create a new active root key. Variables written with the
+// previous keys can still be decrypted.
+func (k *Keyring) Rotate(args *structs.KeyringRotateRequest, reply *structs.KeyringRotateResponse) error {
+	if done, err := k.srv.forward("Keyring.Rotate", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "keyring", "rotate"}, time.Now())
+
+	meta, index, err := k.srv.rotateRootKey()
+	if err != nil {
+		k.srv.logger.Printf("[ERR] nomad.keyring: Rotate failed: %v", err)
+		return err
+	}
+	k.srv.logger.Printf("[INFO] nomad.keyring: rotated keyring, new root key %s", meta.KeyID)
+
+	reply.KeyID = meta.KeyID
+	reply.Index = index
+	return nil
+}
+
+// Get is used by the servers to fetch the material of a root key from each
+// other. The request must be signed with the key encryption key, and the key
+// is returned wrapped with it. It is never forwarded.
+func (k *Keyring) Get(args *structs.KeyringGetRequest, reply *structs.KeyringGetResponse) error {
+	defer metrics.MeasureSince([]string{"nomad", "keyring", "get"}, time.Now())
+
+	if err := k.srv.keystore.verify(args.KeyID, args.ServerName, args.Timestamp, args.Signature); err != nil {
+		k.srv.logger.Printf("[WARN] nomad.keyring: rejected request for root key %s from %q: %v",
+			args.KeyID, args.ServerName, err)
+		return fmt.Errorf("permission denied")
+	}
+
+	// Only serve the keys of the keyring
+	meta, err := k.srv.fsm.State().RootKeyMetaByID(args.KeyID)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return fmt.Errorf("root key %s not found", args.KeyID)
+	}
+
+	key, err := k.srv.keystore.Get(meta.KeyID)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return fmt.Errorf("root key %s not found in keystore", meta.KeyID)
+	}
+
+	wrapped, err := k.srv.keystore.wrap(meta.KeyID, key)
+	if err != nil {
+		return err
+	}
+	reply.WrappedKey = wrapped
+	return nil
+}
@@ -0,0 +1,134 @@
+package nomad
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestKeyringEndpoint_Rotate(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// The leader initializes the keyring
+	first, err := s1.fsm.State().ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if first == nil {
+		t.Fatalf("keyring not initialized")
+	}
+
+	req := &structs.KeyringRotateRequest{
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.KeyringRotateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 || resp.KeyID == "" || resp.KeyID == first.KeyID {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// The new key is active and the old key is kept
+	active, err := s1.fsm.State().ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if active == nil || active.KeyID != resp.KeyID {
+		t.Fatalf("bad: %#v", active)
+	}
+	old, err := s1.fsm.State().RootKeyMetaByID(first.KeyID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if old == nil || old.Active() {
+		t.Fatalf("bad: %#v", old)
+	}
+	if key, err := s1.keystore.Get(first.KeyID); err != nil || key == nil {
+		t.Fatalf("old root key missing: %v", err)
+	}
+}
+
+func TestKeyringEndpoint_Get(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	active, err := s1.fsm.State().ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if active == nil {
+		t.Fatalf("keyring not initialized")
+	}
+
+	// Fetch the key with a signed request
+	req := &structs.KeyringGetRequest{
+		KeyID:        active.KeyID,
+		ServerName:   "other",
+		Timestamp:    time.Now().UnixNano(),
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	req.Signature = s1.keystore.sign(req.KeyID, req.ServerName, req.Timestamp)
+	var resp structs.KeyringGetResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Keyring.Get", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The key is wrapped with the key encryption key
+	expected, err := s1.keystore.Get(active.KeyID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if bytes.Contains(resp.WrappedKey, expected) {
+		t.Fatalf("root key sent in plaintext")
+	}
+	out, err := s1.keystore.unwrap(active.KeyID, resp.WrappedKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(out, expected) {
+		t.Fatalf("bad: %v", out)
+	}
+
+	// Unsigned requests are rejected
+	req.Signature = nil
+	if err := msgpackrpc.CallWithCodec(codec, "Keyring.Get", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestKeyring_Replication(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	s2 := testServer(t, func(c *Config) {
+		c.DevDisableBootstrap = true
+	})
+	defer s2.Shutdown()
+	testJoin(t, s1, s2)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	active, err := s1.fsm.State().ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if active == nil {
+		t.Fatalf("keyring not initialized")
+	}
+
+	// The follower fetches the key from the leader
+	testutil.WaitForResult(func() (bool, error) {
+		key, err := s2.keystore.Get(active.KeyID)
+		return key != nil, err
+	}, func(err error) {
+		t.Fatalf("root key not replicated: %v", err)
+	})
+}
@@ -0,0 +1,228 @@
+package nomad
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// keystoreDir is the directory, in the data dir of the server, where the
+	// wrapped root keys are stored
+	keystoreDir = "keystore"
+
+	// keystoreKEKFile is the file the key encryption key is stored in when
+	// none is configured
+	keystoreKEKFile = "kek"
+
+	// keyEncryptionKeySize is the size of the AES-256 key encryption key
+	keyEncryptionKeySize = 32
+
+	// keyringRequestMaxSkew is how far from the local time the timestamp of
+	// a signed keyring request may be
+	keyringRequestMaxSkew = time.Minute
+)
+
+// keystore holds the material of the root keys known to this server. The
+// keys are wrapped with the key encryption key (KEK) before they are written
+// to disk or sent to another server. The KEK is also used to sign the
+// requests servers make to fetch keys from each other.
+type keystore struct {
+	// path is the directory the wrapped keys are stored in. No keys are
+	// written to disk if it is empty.
+	path string
+
+	kek     cipher.AEAD
+	authKey []byte
+
+	keys map[string][]byte
+	lock sync.RWMutex
+}
+
+// newKeystore returns a keystore storing the keys in the given directory,
+// wrapped with the key encryption key. The keystore is kept in memory only if
+// the path is empty.
+func newKeystore(path string, kek []byte) (*keystore, error) {
+	if len(kek) != keyEncryptionKeySize {
+		return nil, fmt.Errorf("key encryption key must be %d bytes, got %d", keyEncryptionKeySize, len(kek))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create keystore directory: %v", err)
+		}
+	}
+
+	// Derive a separate key to sign the keyring requests
+	mac := hmac.New(sha256.New, kek)
+	mac.Write([]byte("nomad keyring request"))
+
+	return &keystore{
+		path:    path,
+		kek:     aead,
+		authKey: mac.Sum(nil),
+		keys:    make(map[string][]byte),
+	}, nil
+}
+
+// loadKeyEncryptionKey returns the key encryption key stored in the given
+// directory, generating it if it does not exist yet. The key is only kept in
+// memory if the path is empty.
+func loadKeyEncryptionKey(path string) ([]byte, error) {
+	if path == "" {
+		return newKeyEncryptionKey()
+	}
+
+	file := filepath.Join(path, keystoreKEKFile)
+	kek, err := ioutil.ReadFile(file)
+	if err == nil {
+		return kek, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read key encryption key: %v", err)
+	}
+
+	if kek, err = newKeyEncryptionKey(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory: %v", err)
+	}
+	if err := writeFileAtomic(file, kek); err != nil {
+		return nil, fmt.Errorf("failed to write key encryption key: %v", err)
+	}
+	return kek, nil
+}
+
+// newKeyEncryptionKey generates a random key encryption key
+func newKeyEncryptionKey() ([]byte, error) {
+	kek := make([]byte, keyEncryptionKeySize)
+	if _, err := rand.Read(kek); err != nil {
+		return nil, fmt.Errorf("failed to generate key encryption key: %v", err)
+	}
+	return kek, nil
+}
+
+// Get returns the material of the root key, or nil if the key is not in the
+// keystore.
+func (k *keystore) Get(keyID string) ([]byte, error) {
+	k.lock.RLock()
+	key, ok := k.keys[keyID]
+	k.lock.RUnlock()
+	if ok || k.path == "" {
+		return key, nil
+	}
+
+	wrapped, err := ioutil.ReadFile(k.keyPath(keyID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read root key %s: %v", keyID, err)
+	}
+	if key, err = k.unwrap(keyID, wrapped); err != nil {
+		return nil, err
+	}
+
+	k.lock.Lock()
+	k.keys[keyID] = key
+	k.lock.Unlock()
+	return key, nil
+}
+
+// Put adds the material of a root key to the keystore
+func (k *keystore) Put(keyID string, key []byte) error {
+	if k.path != "" {
+		wrapped, err := k.wrap(keyID, key)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(k.keyPath(keyID), wrapped); err != nil {
+			return fmt.Errorf("failed to write root key %s: %v", keyID, err)
+		}
+	}
+
+	k.lock.Lock()
+	k.keys[keyID] = key
+	k.lock.Unlock()
+	return nil
+}
+
+// wrap encrypts the root key with the key encryption key. The key ID is
+// authenticated along with the key so a wrapped key can not be swapped for
+// another.
+func (k *keystore) wrap(keyID string, key []byte) ([]byte, error) {
+	nonce := make([]byte, k.kek.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return k.kek.Seal(nonce, nonce, key, []byte(keyID)), nil
+}
+
+// unwrap decrypts a root key wrapped with the key encryption key
+func (k *keystore) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	size := k.kek.NonceSize()
+	if len(wrapped) < size {
+		return nil, fmt.Errorf("wrapped root key %s is too short", keyID)
+	}
+	key, err := k.kek.Open(nil, wrapped[:size], wrapped[size:], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap root key %s: %v", keyID, err)
+	}
+	return key, nil
+}
+
+// sign returns the signature of a request for a root key made by the given
+// server
+func (k *keystore) sign(keyID, serverName string, timestamp int64) []byte {
+	mac := hmac.New(sha256.New, k.authKey)
+	fmt.Fprintf(mac, "%s\x00%s\x00%d", keyID, serverName, timestamp)
+	return mac.Sum(nil)
+}
+
+// verify checks that a request for a root key was signed by a server sharing
+// the key encryption key, and that it is recent.
+func (k *keystore) verify(keyID, serverName string, timestamp int64, signature []byte) error {
+	skew := time.Since(time.Unix(0, timestamp))
+	if skew < -keyringRequestMaxSkew || skew > keyringRequestMaxSkew {
+		return fmt.Errorf("request timestamp is outside the allowed clock skew")
+	}
+	if !hmac.Equal(signature, k.sign(keyID, serverName, timestamp)) {
+		return fmt.Errorf("invalid request signature")
+	}
+	return nil
+}
+
+// keyPath returns the path of the file the root key is stored in
+func (k *keystore) keyPath(keyID string) string {
+	return filepath.Join(k.path, keyID+".key")
+}
+
+// writeFileAtomic writes the data to a file readable only by its owner,
+// replacing it atomically.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package nomad
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKeystore(t *testing.T, path string) *keystore {
+	kek := bytes.Repeat([]byte{1}, keyEncryptionKeySize)
+	ks, err := newKeystore(path, kek)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return ks
+}
+
+func TestKeystore_PutGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := testKeystore(t, dir)
+	key := []byte("0123456789abcdef0123456789abcdef")
+	if err := ks.Put("foo", key); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The key is not stored in plaintext
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "foo.key"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if bytes.Contains(raw, key) {
+		t.Fatalf("root key stored in plaintext")
+	}
+
+	// A new keystore with the same key encryption key reads it back
+	out, err := testKeystore(t, dir).Get("foo")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(out, key) {
+		t.Fatalf("bad: %v", out)
+	}
+
+	// Missing keys are not an error
+	if out, err := ks.Get("bar"); err != nil || out != nil {
+		t.Fatalf("bad: %v %v", out, err)
+	}
+
+	// Another key encryption key can not unwrap it
+	other, err := newKeystore(dir, bytes.Repeat([]byte{2}, keyEncryptionKeySize))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := other.Get("foo"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestKeystore_Wrap(t *testing.T) {
+	ks := testKeystore(t, "")
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	wrapped, err := ks.wrap("foo", key)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err := ks.unwrap("foo", wrapped)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(out, key) {
+		t.Fatalf("bad: %v", out)
+	}
+
+	// A wrapped key can not be swapped for another key
+	if _, err := ks.unwrap("bar", wrapped); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestKeystore_Verify(t *testing.T) {
+	ks := testKeystore(t, "")
+	now := time.Now().UnixNano()
+
+	sig := ks.sign("foo", "server1", now)
+	if err := ks.verify("foo", "server1", now, sig); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The signature covers the whole request
+	if err := ks.verify("bar", "server1", now, sig); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := ks.verify("foo", "server2", now, sig); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Old requests are rejected
+	old := time.Now().Add(-2 * keyringRequestMaxSkew).UnixNano()
+	if err := ks.verify("foo", "server1", old, ks.sign("foo", "server1", old)); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Servers with another key encryption key are rejected
+	other, err := newKeystore("", bytes.Repeat([]byte{2}, keyEncryptionKeySize))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := ks.verify("foo", "server1", now, other.sign("foo", "server1", now)); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestKeystore_LoadKeyEncryptionKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	kek, err := loadKeyEncryptionKey(dir)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(kek) != keyEncryptionKeySize {
+		t.Fatalf("bad: %v", kek)
+	}
+
+	// The generated key is reused
+	again, err := loadKeyEncryptionKey(dir)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(kek, again) {
+		t.Fatalf("bad: %v", again)
+	}
+}
+
+func TestServer_SetupKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := DefaultConfig()
+	config.DataDir = dir
+	s := &Server{config: config, logger: log.New(os.Stderr, "", log.LstdFlags)}
+
+	// Servers joining a cluster need the shared key encryption key
+	if err := s.setupKeystore(); err == nil || !strings.Contains(err.Error(), "keyring encryption key") {
+		t.Fatalf("expected keyring encryption key error, got: %v", err)
+	}
+
+	// A single server can use a key of its own
+	config.Bootstrap = true
+	if err := s.setupKeystore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	config.Bootstrap = false
+	config.KeyringEncryptionKey = bytes.Repeat([]byte{1}, keyEncryptionKeySize)
+	if err := s.setupKeystore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
@@ -127,12 +127,26 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 		return err
 	}
 
+	// Create the keyring used to encrypt variables if this is a new cluster
+	if err := s.initializeKeyring(); err != nil {
+		s.logger.Printf("[ERR] nomad: keyring setup failed: %v", err)
+		return err
+	}
+
+	// Resume the rollouts of multi-region jobs
+	if err := s.restoreMultiregionRollouts(); err != nil {
+		return err
+	}
+
 	// Scheduler periodic jobs
 	go s.schedulePeriodic(stopCh)
 
 	// Reap any failed evaluations
 	go s.reapFailedEvaluations(stopCh)
 
+	// Migrate the allocations off draining nodes
+	go s.runNodeDrainer(stopCh)
+
 	// Setup the heartbeat timers. This is done both when starting up or when
 	// a leader fail over happens. Since the timers are maintained by the leader
 	// node, effectively this means all the timers are renewed at the time of failover.
@@ -178,10 +192,10 @@ func (s *Server) restoreEvalBroker() error {
 }
 
 // restorePeriodicDispatcher is used to restore all periodic jobs into the
-// periodic dispatcher. It also determines if a periodic job should have been
-// created during the leadership transition and force runs them. The periodic
-// dispatcher is maintained only by the leader, so it must be restored anytime a
-// leadership transition takes place.
+// periodic dispatcher. It also determines which launches of a periodic job were
+// missed during the leadership transition and force runs them according to the
+// job's catch-up policy. The periodic dispatcher is maintained only by the
+// leader, so it must be restored anytime a leadership transition takes place.
 func (s *Server) restorePeriodicDispatcher() error {
 	iter, err := s.fsm.State().JobsByPeriodic(true)
 	if err != nil {
@@ -201,23 +215,19 @@ func (s *Server) restorePeriodicDispatcher() error {
 			return fmt.Errorf("failed to get periodic launch time: %v", err)
 		}
 
-		// nextLaunch is the next launch that should occur.
-		nextLaunch := job.Periodic.Next(launch.Launch)
-
-		// We skip force launching the job if  there should be no next launch
-		// (the zero case) or if the next launch time is in the future. If it is
-		// in the future, it will be handled by the periodic dispatcher.
-		if nextLaunch.IsZero() || !nextLaunch.Before(now) {
-			continue
-		}
-
-		if _, err := s.periodicDispatcher.ForceRun(job.ID); err != nil {
-			msg := fmt.Sprintf("force run of periodic job %q failed: %v", job.ID, err)
-			s.logger.Printf("[ERR] nomad.periodic: %s", msg)
-			return errors.New(msg)
+		// missed are the launches that should have occurred before now and
+		// that the catch-up policy wants launched. Launches in the future are
+		// handled by the periodic dispatcher.
+		missed := job.Periodic.MissedLaunches(launch.Launch, now)
+		for _, missedLaunch := range missed {
+			if _, err := s.periodicDispatcher.ForceRunAt(job.ID, missedLaunch); err != nil {
+				msg := fmt.Sprintf("force run of periodic job %q failed: %v", job.ID, err)
+				s.logger.Printf("[ERR] nomad.periodic: %s", msg)
+				return errors.New(msg)
+			}
+			s.logger.Printf("[DEBUG] nomad.periodic: periodic job %q launch at %v"+
+				" force run during leadership establishment", job.ID, missedLaunch)
 		}
-		s.logger.Printf("[DEBUG] nomad.periodic: periodic job %q force"+
-			" run during leadership establishment", job.ID)
 	}
 
 	return nil
@@ -309,6 +319,9 @@ func (s *Server) revokeLeadership() error {
 	// Disable the periodic dispatcher, since it is only useful as a leader
 	s.periodicDispatcher.SetEnabled(false)
 
+	// Stop the multi-region rollouts, the next leader resumes them
+	s.stopMultiregionRollouts()
+
 	// Clear the heartbeat timers on either shutdown or step down,
 	// since we are no longer responsible for TTL expirations.
 	if err := s.clearAllHeartbeatTimers(); err != nil {
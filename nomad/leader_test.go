@@ -466,6 +466,105 @@ func TestLeader_PeriodicDispatcher_Restore_Evals(t *testing.T) {
 	}
 }
 
+func TestLeader_PeriodicDispatcher_Restore_CatchupAll(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Inject a periodic job that will be triggered three times soon.
+	now := time.Now()
+	launches := []time.Time{now.Add(1 * time.Second), now.Add(2 * time.Second), now.Add(3 * time.Second)}
+	job := testPeriodicJob(launches...)
+	job.Periodic.Catchup = structs.PeriodicCatchupAll
+	job.Periodic.CatchupLimit = 2
+	req := structs.JobRegisterRequest{
+		Job: job,
+	}
+	_, _, err := s1.raftApply(structs.JobRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Flush the periodic dispatcher, ensuring that no evals will be created.
+	s1.periodicDispatcher.SetEnabled(false)
+
+	// Sleep till after the job should have been launched.
+	time.Sleep(5 * time.Second)
+
+	// Restore the periodic dispatcher.
+	s1.periodicDispatcher.SetEnabled(true)
+	s1.periodicDispatcher.Start()
+	if err := s1.restorePeriodicDispatcher(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Only the last two launches should have been made.
+	for i, launch := range launches {
+		id := s1.periodicDispatcher.derivedJobID(job, launch.Round(1*time.Second))
+		child, err := s1.fsm.State().JobByID(id)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		if i == 0 && child != nil {
+			t.Fatalf("launch %v should have been skipped", launch)
+		} else if i != 0 && child == nil {
+			t.Fatalf("launch %v was not made", launch)
+		}
+	}
+}
+
+func TestLeader_PeriodicDispatcher_Restore_CatchupNone(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Inject a periodic job that will be triggered soon.
+	launch := time.Now().Add(1 * time.Second)
+	job := testPeriodicJob(launch)
+	job.Periodic.Catchup = structs.PeriodicCatchupNone
+	req := structs.JobRegisterRequest{
+		Job: job,
+	}
+	_, _, err := s1.raftApply(structs.JobRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Get the insertion time of the job.
+	inserted, err := s1.fsm.State().PeriodicLaunchByID(job.ID)
+	if err != nil || inserted == nil {
+		t.Fatalf("failed to get periodic launch time: %v", err)
+	}
+
+	// Flush the periodic dispatcher, ensuring that no evals will be created.
+	s1.periodicDispatcher.SetEnabled(false)
+
+	// Sleep till after the job should have been launched.
+	time.Sleep(3 * time.Second)
+
+	// Restore the periodic dispatcher.
+	s1.periodicDispatcher.SetEnabled(true)
+	s1.periodicDispatcher.Start()
+	if err := s1.restorePeriodicDispatcher(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Check that no launch was made.
+	last, err := s1.fsm.State().PeriodicLaunchByID(job.ID)
+	if err != nil || last == nil {
+		t.Fatalf("failed to get periodic launch time: %v", err)
+	}
+
+	if !last.Launch.Equal(inserted.Launch) {
+		t.Fatalf("restorePeriodicDispatcher launched a missed launch: last %v; want %v", last.Launch, inserted.Launch)
+	}
+}
+
 func TestLeader_PeriodicDispatch(t *testing.T) {
 	s1 := testServer(t, func(c *Config) {
 		c.NumSchedulers = 0
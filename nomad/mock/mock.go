@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -9,6 +10,7 @@ import (
 func Node() *structs.Node {
 	node := &structs.Node{
 		ID:         structs.GenerateUUID(),
+		SecretID:   structs.GenerateUUID(),
 		Datacenter: "dc1",
 		Name:       "foobar",
 		Attributes: map[string]string{
@@ -270,3 +272,17 @@ func Plan() *structs.Plan {
 func PlanResult() *structs.PlanResult {
 	return &structs.PlanResult{}
 }
+
+func ServiceRegistration() *structs.ServiceRegistration {
+	return &structs.ServiceRegistration{
+		ID:          fmt.Sprintf("%s-%s", structs.NomadConsulPrefix, structs.GenerateUUID()),
+		ServiceName: "frontend",
+		JobID:       structs.GenerateUUID(),
+		AllocID:     structs.GenerateUUID(),
+		NodeID:      "12345678-abcd-efab-cdef-123456789abc",
+		TaskName:    "web",
+		Tags:        []string{"pci:true"},
+		Address:     "192.168.0.100",
+		Port:        5000,
+	}
+}
@@ -0,0 +1,257 @@
+package nomad
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// errRolloutStopped is returned when the rollout of a multi-region job is
+// stopped, either because the job was submitted again or because the server
+// lost leadership.
+var errRolloutStopped = errors.New("multi-region rollout stopped")
+
+// createMultiregionRollout records a new rollout of the multi-region job and
+// starts it, stopping any previous rollout of the job. It returns the index
+// the rollout was created at.
+func (s *Server) createMultiregionRollout(job *structs.Job) (uint64, error) {
+	rollout := structs.NewMultiregionRollout(job)
+
+	s.multiregionRolloutsLock.Lock()
+	defer s.multiregionRolloutsLock.Unlock()
+
+	if stopCh, ok := s.multiregionRollouts[job.ID]; ok {
+		close(stopCh)
+		delete(s.multiregionRollouts, job.ID)
+	}
+
+	req := structs.MultiregionRolloutUpsertRequest{Rollout: rollout}
+	_, index, err := s.raftApply(structs.MultiregionRolloutUpsertRequestType, &req)
+	if err != nil {
+		return 0, err
+	}
+
+	stopCh := make(chan struct{})
+	s.multiregionRollouts[job.ID] = stopCh
+	go s.runMultiregionRollout(rollout, stopCh)
+	return index, nil
+}
+
+// restoreMultiregionRollouts resumes the rollouts that were not finished when
+// the previous leader stepped down.
+func (s *Server) restoreMultiregionRollouts() error {
+	iter, err := s.fsm.State().MultiregionRollouts()
+	if err != nil {
+		return err
+	}
+
+	s.multiregionRolloutsLock.Lock()
+	defer s.multiregionRolloutsLock.Unlock()
+
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		rollout := raw.(*structs.MultiregionRollout)
+		if rollout.Terminal() {
+			continue
+		}
+		if _, ok := s.multiregionRollouts[rollout.JobID]; ok {
+			continue
+		}
+
+		stopCh := make(chan struct{})
+		s.multiregionRollouts[rollout.JobID] = stopCh
+		go s.runMultiregionRollout(rollout, stopCh)
+	}
+	return nil
+}
+
+// stopMultiregionRollouts stops all the running rollouts. Their progress is
+// kept in the state store so the next leader can resume them.
+func (s *Server) stopMultiregionRollouts() {
+	s.multiregionRolloutsLock.Lock()
+	defer s.multiregionRolloutsLock.Unlock()
+
+	for jobID, stopCh := range s.multiregionRollouts {
+		close(stopCh)
+		delete(s.multiregionRollouts, jobID)
+	}
+}
+
+// upsertMultiregionRollout records the progress of a rollout, unless it was
+// stopped.
+func (s *Server) upsertMultiregionRollout(rollout *structs.MultiregionRollout, stopCh chan struct{}) error {
+	s.multiregionRolloutsLock.Lock()
+	defer s.multiregionRolloutsLock.Unlock()
+
+	if s.multiregionRollouts[rollout.JobID] != stopCh {
+		return errRolloutStopped
+	}
+
+	req := structs.MultiregionRolloutUpsertRequest{Rollout: rollout}
+	_, _, err := s.raftApply(structs.MultiregionRolloutUpsertRequestType, &req)
+	return err
+}
+
+// runMultiregionRollout registers a multi-region job in each of its regions,
+// following the rollout strategy. Regions already registered by a previous
+// leader are skipped. The rollout stops at the first batch in which a region
+// fails.
+func (s *Server) runMultiregionRollout(rollout *structs.MultiregionRollout, stopCh chan struct{}) {
+	defer func() {
+		s.multiregionRolloutsLock.Lock()
+		if s.multiregionRollouts[rollout.JobID] == stopCh {
+			delete(s.multiregionRollouts, rollout.JobID)
+		}
+		s.multiregionRolloutsLock.Unlock()
+	}()
+
+	job := rollout.Job
+	regions := job.Multiregion.Regions
+	batch := len(regions)
+	if st := job.Multiregion.Strategy; st != nil && st.MaxParallel > 0 && st.MaxParallel < batch {
+		batch = st.MaxParallel
+	}
+
+	for start := 0; start < len(regions); start += batch {
+		end := start + batch
+		if end > len(regions) {
+			end = len(regions)
+		}
+
+		var pending []int
+		for i := start; i < end; i++ {
+			if rollout.Regions[i].Status != structs.MultiregionStatusComplete {
+				pending = append(pending, i)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		// Mark the batch as running
+		rollout = rollout.Copy()
+		for _, i := range pending {
+			rollout.Regions[i].Status = structs.MultiregionStatusRunning
+		}
+		if err := s.upsertMultiregionRollout(rollout, stopCh); err != nil {
+			s.logMultiregionRolloutError(job.ID, err)
+			return
+		}
+
+		rollout = rollout.Copy()
+		var wg sync.WaitGroup
+		for _, i := range pending {
+			wg.Add(1)
+			go func(result *structs.MultiregionResult, region *structs.MultiregionRegion) {
+				defer wg.Done()
+				s.registerRegion(job, region, result, stopCh)
+			}(rollout.Regions[i], regions[i])
+		}
+		wg.Wait()
+
+		// The regions of a stopped rollout are registered again when it is
+		// resumed, so their outcome is not recorded.
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		for _, i := range pending {
+			if result := rollout.Regions[i]; result.Status == structs.MultiregionStatusFailed {
+				s.logger.Printf("[ERR] nomad.job: multi-region rollout of job %q halted: region %q failed: %v",
+					job.ID, result.Region, result.Error)
+				rollout.Status = structs.MultiregionStatusFailed
+			}
+		}
+		if err := s.upsertMultiregionRollout(rollout, stopCh); err != nil {
+			s.logMultiregionRolloutError(job.ID, err)
+			return
+		}
+		if rollout.Terminal() {
+			return
+		}
+	}
+
+	rollout = rollout.Copy()
+	rollout.Status = structs.MultiregionStatusComplete
+	if err := s.upsertMultiregionRollout(rollout, stopCh); err != nil {
+		s.logMultiregionRolloutError(job.ID, err)
+	}
+}
+
+// logMultiregionRolloutError logs a failure to record the progress of a
+// rollout, unless the rollout was stopped.
+func (s *Server) logMultiregionRolloutError(jobID string, err error) {
+	if err != errRolloutStopped {
+		s.logger.Printf("[ERR] nomad.job: failed to update multi-region rollout of job %q: %v", jobID, err)
+	}
+}
+
+// registerRegion registers the regional copy of a multi-region job in the
+// given region and blocks until the resulting evaluation completes. The copy
+// is registered like any job of the region, so its admission controllers may
+// modify or reject it. The outcome is recorded in the result.
+func (s *Server) registerRegion(job *structs.Job, region *structs.MultiregionRegion,
+	result *structs.MultiregionResult, stopCh chan struct{}) {
+	req := &structs.JobRegisterRequest{
+		Job:          job.RegionalJob(region),
+		WriteRequest: structs.WriteRequest{Region: region.Name},
+	}
+	var resp structs.JobRegisterResponse
+	if err := s.RPC("Job.Register", req, &resp); err != nil {
+		result.Status = structs.MultiregionStatusFailed
+		result.Error = err.Error()
+		return
+	}
+	result.EvalID = resp.EvalID
+	result.JobModifyIndex = resp.JobModifyIndex
+	result.Warnings = resp.Warnings
+
+	// Periodic jobs do not create an evaluation
+	if resp.EvalID != "" {
+		if err := s.waitForEval(region.Name, resp.EvalID, stopCh); err != nil {
+			result.Status = structs.MultiregionStatusFailed
+			result.Error = err.Error()
+			return
+		}
+	}
+	result.Status = structs.MultiregionStatusComplete
+	result.Error = ""
+}
+
+// waitForEval blocks until the evaluation in the given region reaches a
+// terminal status. An error is returned if it does not complete successfully.
+func (s *Server) waitForEval(region, evalID string, stopCh chan struct{}) error {
+	req := &structs.EvalSpecificRequest{
+		EvalID:       evalID,
+		QueryOptions: structs.QueryOptions{Region: region},
+	}
+	for {
+		var resp structs.SingleEvalResponse
+		if err := s.RPC("Eval.GetEval", req, &resp); err != nil {
+			return err
+		}
+
+		if eval := resp.Eval; eval != nil && eval.TerminalStatus() {
+			if eval.Status != structs.EvalStatusComplete {
+				return fmt.Errorf("evaluation %q finished with status %q", evalID, eval.Status)
+			}
+			return nil
+		}
+
+		select {
+		case <-stopCh:
+			return errRolloutStopped
+		case <-s.shutdownCh:
+			return fmt.Errorf("server shutting down")
+		default:
+		}
+		req.MinQueryIndex = resp.Index
+	}
+}
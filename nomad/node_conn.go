@@ -0,0 +1,213 @@
+package nomad
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/yamux"
+)
+
+// DialNodeConn opens a node connection to the server at the given address.
+// Clients keep a node connection to a server, over which the servers forward
+// the RPCs targeting their node. The client sends a NodeConnRequest
+// authenticating its node on the connection and then serves the streams
+// opened by the server on a yamux session.
+func DialNodeConn(addr net.Addr, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr.String(), timeout)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write([]byte{byte(rpcNodeConn)}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// HandleNodeConn accepts the node connection of a client running in the same
+// process as the server. It blocks until the connection is closed.
+func (s *Server) HandleNodeConn(conn net.Conn) {
+	s.handleNodeConn(conn)
+}
+
+// handleNodeConn registers the node connection of a client until it is
+// closed. The server opens a stream on the connection for each RPC forwarded
+// to the node.
+func (s *Server) handleNodeConn(conn net.Conn) {
+	defer conn.Close()
+
+	var args structs.NodeConnRequest
+	if err := codec.NewDecoder(conn, structs.MsgpackHandle).Decode(&args); err != nil {
+		s.logger.Printf("[ERR] nomad.rpc: failed to read node connection header: %v", err)
+		return
+	}
+	if err := s.authenticateNodeConn(&args); err != nil {
+		s.logger.Printf("[WARN] nomad.rpc: rejected node connection from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	conf := yamux.DefaultConfig()
+	conf.LogOutput = s.config.LogOutput
+	session, err := yamux.Client(conn, conf)
+	if err != nil {
+		s.logger.Printf("[ERR] nomad.rpc: failed to setup node connection of node %q: %v", args.NodeID, err)
+		return
+	}
+	defer session.Close()
+
+	if err := s.addNodeConn(args.NodeID, session); err != nil {
+		s.logger.Printf("[WARN] nomad.rpc: rejected node connection from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	defer s.removeNodeConn(args.NodeID, session)
+	s.logger.Printf("[DEBUG] nomad.rpc: node %q connected", args.NodeID)
+	metrics.IncrCounter([]string{"nomad", "rpc", "node_conn"}, 1)
+
+	// Clients never open streams, so accepting returns once the session is
+	// closed
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			if err != io.EOF && !session.IsClosed() {
+				s.logger.Printf("[DEBUG] nomad.rpc: node connection of node %q closed: %v", args.NodeID, err)
+			}
+			return
+		}
+		stream.Close()
+	}
+}
+
+// authenticateNodeConn checks that a node connection is opened by the client
+// of a registered node, using the secret ID of the node
+func (s *Server) authenticateNodeConn(args *structs.NodeConnRequest) error {
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID")
+	}
+	node, err := s.fsm.State().NodeByID(args.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("unknown node %q", args.NodeID)
+	}
+	if node.SecretID == "" || subtle.ConstantTimeCompare([]byte(node.SecretID), []byte(args.SecretID)) != 1 {
+		return fmt.Errorf("invalid secret ID for node %q", args.NodeID)
+	}
+	return nil
+}
+
+// addNodeConn registers the node connection of a node. A node connection
+// already held for the node is only replaced if it is dead, so that a live
+// one can not be taken over.
+func (s *Server) addNodeConn(nodeID string, session *yamux.Session) error {
+	old := s.nodeConn(nodeID)
+	if old != nil && !old.IsClosed() {
+		if _, err := old.Ping(); err == nil {
+			return fmt.Errorf("node %q already has a node connection", nodeID)
+		}
+		old.Close()
+	}
+
+	s.nodeConnsLock.Lock()
+	defer s.nodeConnsLock.Unlock()
+	if cur, ok := s.nodeConns[nodeID]; ok && cur != old {
+		return fmt.Errorf("node %q already has a node connection", nodeID)
+	}
+	s.nodeConns[nodeID] = session
+	return nil
+}
+
+// removeNodeConn removes the node connection of a node unless it was
+// replaced meanwhile
+func (s *Server) removeNodeConn(nodeID string, session *yamux.Session) {
+	s.nodeConnsLock.Lock()
+	defer s.nodeConnsLock.Unlock()
+	if s.nodeConns[nodeID] == session {
+		delete(s.nodeConns, nodeID)
+	}
+}
+
+// nodeConn returns the node connection of a node held by the server, or nil
+func (s *Server) nodeConn(nodeID string) *yamux.Session {
+	s.nodeConnsLock.RLock()
+	defer s.nodeConnsLock.RUnlock()
+	return s.nodeConns[nodeID]
+}
+
+// forwardNode forwards an RPC to a node, over its node connection if the
+// server holds it, or to the server of the region holding it
+func (s *Server) forwardNode(nodeID, method string, args interface{}, reply interface{}) error {
+	if session := s.nodeConn(nodeID); session != nil {
+		return nodeRPC(session, method, args, reply)
+	}
+
+	server, err := s.findNodeConnServer(nodeID)
+	if err != nil {
+		return err
+	}
+	return s.connPool.RPC(s.config.Region, server.Addr, server.Version, method, args, reply)
+}
+
+// findNodeConnServer returns the server of the region holding the node
+// connection of a node
+func (s *Server) findNodeConnServer(nodeID string) (*serverParts, error) {
+	s.peerLock.RLock()
+	peers := make([]*serverParts, 0, len(s.localPeers))
+	for _, server := range s.localPeers {
+		peers = append(peers, server)
+	}
+	s.peerLock.RUnlock()
+
+	req := structs.NodeSpecificRequest{
+		NodeID:       nodeID,
+		QueryOptions: structs.QueryOptions{Region: s.config.Region},
+	}
+	for _, server := range peers {
+		if server.Addr.String() == s.rpcAdvertise.String() {
+			continue
+		}
+
+		var resp structs.NodeConnQueryResponse
+		if err := s.connPool.RPC(s.config.Region, server.Addr, server.Version, "Status.HasNodeConn", &req, &resp); err != nil {
+			s.logger.Printf("[WARN] nomad.rpc: failed to query node connections of server %s: %v", server, err)
+			continue
+		}
+		if resp.Connected {
+			return server, nil
+		}
+	}
+	return nil, structs.ErrNoNodeConn
+}
+
+// nodeRPC makes an RPC to a node over its node connection
+func nodeRPC(session *yamux.Session, method string, args interface{}, reply interface{}) error {
+	stream, err := session.Open()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return msgpackrpc.CallWithCodec(NewClientCodec(stream), method, args, reply)
+}
+
+// allocNodeID returns the node running an allocation
+func (s *Server) allocNodeID(allocID string) (string, error) {
+	if allocID == "" {
+		return "", fmt.Errorf("missing allocation ID")
+	}
+	alloc, err := s.fsm.State().AllocByID(allocID)
+	if err != nil {
+		return "", err
+	}
+	if alloc == nil {
+		return "", fmt.Errorf("unknown allocation %q", allocID)
+	}
+	return alloc.NodeID, nil
+}
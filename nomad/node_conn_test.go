@@ -0,0 +1,125 @@
+package nomad
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/hashicorp/yamux"
+)
+
+func TestServer_NodeConn(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+
+	node := mock.Node()
+	if err := s1.fsm.State().UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Open a node connection as a client would
+	session := testNodeConn(t, s1, node.ID, node.SecretID)
+
+	req := &structs.NodeSpecificRequest{NodeID: node.ID}
+	var resp structs.NodeConnQueryResponse
+	testutil.WaitForResult(func() (bool, error) {
+		if err := s1.endpoints.Status.HasNodeConn(req, &resp); err != nil {
+			return false, err
+		}
+		return resp.Connected, fmt.Errorf("node connection not registered")
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// The connection is removed once closed
+	session.Close()
+	testutil.WaitForResult(func() (bool, error) {
+		return s1.nodeConn(node.ID) == nil, fmt.Errorf("node connection not removed")
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// RPCs to nodes without a node connection fail
+	var out structs.NodeConnQueryResponse
+	if err := s1.forwardNode(node.ID, "Status.HasNodeConn", req, &out); err != structs.ErrNoNodeConn {
+		t.Fatalf("expected no node connection error, got: %v", err)
+	}
+}
+
+func TestServer_NodeConn_Reject(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+
+	node := mock.Node()
+	if err := s1.fsm.State().UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Node connections of unknown nodes or with the wrong secret ID are
+	// closed by the server
+	for _, header := range []structs.NodeConnRequest{
+		{NodeID: structs.GenerateUUID(), SecretID: node.SecretID},
+		{NodeID: node.ID, SecretID: structs.GenerateUUID()},
+		{NodeID: node.ID},
+	} {
+		client, server := net.Pipe()
+		doneCh := make(chan struct{})
+		go func() {
+			s1.HandleNodeConn(server)
+			close(doneCh)
+		}()
+		if err := codec.NewEncoder(client, structs.MsgpackHandle).Encode(&header); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		select {
+		case <-doneCh:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("node connection %#v not rejected", header)
+		}
+		client.Close()
+		if s1.nodeConn(header.NodeID) != nil {
+			t.Fatalf("node connection %#v registered", header)
+		}
+	}
+
+	// A second node connection does not replace a live one
+	session := testNodeConn(t, s1, node.ID, node.SecretID)
+	defer session.Close()
+	testutil.WaitForResult(func() (bool, error) {
+		return s1.nodeConn(node.ID) != nil, fmt.Errorf("node connection not registered")
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	live := s1.nodeConn(node.ID)
+
+	other := testNodeConn(t, s1, node.ID, node.SecretID)
+	defer other.Close()
+	testutil.WaitForResult(func() (bool, error) {
+		return other.IsClosed(), fmt.Errorf("second node connection not rejected")
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	if s1.nodeConn(node.ID) != live {
+		t.Fatalf("live node connection replaced")
+	}
+}
+
+// testNodeConn opens a node connection to the server as the client of a node
+func testNodeConn(t *testing.T, s *Server, nodeID, secretID string) *yamux.Session {
+	client, server := net.Pipe()
+	go s.HandleNodeConn(server)
+	header := structs.NodeConnRequest{NodeID: nodeID, SecretID: secretID}
+	if err := codec.NewEncoder(client, structs.MsgpackHandle).Encode(&header); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	session, err := yamux.Server(client, yamux.DefaultConfig())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return session
+}
@@ -1,7 +1,9 @@
 package nomad
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -35,7 +37,6 @@ func (n *Node) Register(args *structs.NodeRegisterRequest, reply *structs.NodeUp
 	if args.Node.Name == "" {
 		return fmt.Errorf("missing node name for client registration")
 	}
-
 	// Default the status if none is given
 	if args.Node.Status == "" {
 		args.Node.Status = structs.NodeStatusInit
@@ -49,6 +50,32 @@ func (n *Node) Register(args *structs.NodeRegisterRequest, reply *structs.NodeUp
 		return fmt.Errorf("failed to computed node class: %v", err)
 	}
 
+	// Look for an existing registration, whose placements must be
+	// reconsidered if its meta or class changes while it is ready
+	snap, err := n.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	existing, err := snap.NodeByID(args.Node.ID)
+	if err != nil {
+		return err
+	}
+
+	// Only the client holding the secret ID of a node may update it. Nodes
+	// registered by older clients have no secret ID: those clients may keep
+	// registering without one, and the first secret ID provided is trusted.
+	switch {
+	case existing != nil && existing.SecretID != "":
+		if subtle.ConstantTimeCompare([]byte(existing.SecretID), []byte(args.Node.SecretID)) != 1 {
+			return fmt.Errorf("node secret ID does not match the registered node")
+		}
+	case existing == nil && args.Node.SecretID == "":
+		return fmt.Errorf("missing node secret ID for client registration; the client must be upgraded to register with this server")
+	}
+
+	changed := existing != nil && args.Node.Status == structs.NodeStatusReady &&
+		(existing.ComputedClass != args.Node.ComputedClass || !reflect.DeepEqual(existing.Meta, args.Node.Meta))
+
 	// Commit this update via Raft
 	_, index, err := n.srv.raftApply(structs.NodeRegisterRequestType, args)
 	if err != nil {
@@ -58,7 +85,7 @@ func (n *Node) Register(args *structs.NodeRegisterRequest, reply *structs.NodeUp
 	reply.NodeModifyIndex = index
 
 	// Check if we should trigger evaluations
-	if structs.ShouldDrainNode(args.Node.Status) {
+	if structs.ShouldDrainNode(args.Node.Status) || changed {
 		evalIDs, evalIndex, err := n.createNodeEvals(args.Node.ID, index)
 		if err != nil {
 			n.srv.logger.Printf("[ERR] nomad.client: eval creation failed: %v", err)
@@ -152,6 +179,7 @@ func (n *Node) UpdateStatus(args *structs.NodeUpdateStatusRequest, reply *struct
 	// Commit this update via Raft
 	var index uint64
 	if node.Status != args.Status {
+		args.UpdatedAt = time.Now().Unix()
 		_, index, err = n.srv.raftApply(structs.NodeUpdateStatusRequestType, args)
 		if err != nil {
 			n.srv.logger.Printf("[ERR] nomad.client: status update failed: %v", err)
@@ -189,7 +217,9 @@ func (n *Node) UpdateStatus(args *structs.NodeUpdateStatusRequest, reply *struct
 	return nil
 }
 
-// UpdateDrain is used to update the drain mode of a client node
+// UpdateDrain is used to update the drain mode of a client node. Draining
+// nodes are ineligible for scheduling and their allocations are migrated by
+// the node drainer run by the leader.
 func (n *Node) UpdateDrain(args *structs.NodeUpdateDrainRequest,
 	reply *structs.NodeDrainUpdateResponse) error {
 	if done, err := n.srv.forward("Node.UpdateDrain", args, args, reply); done {
@@ -202,6 +232,13 @@ func (n *Node) UpdateDrain(args *structs.NodeUpdateDrainRequest,
 		return fmt.Errorf("missing node ID for drain update")
 	}
 
+	// Requests from older clients only set the drain flag
+	if args.Drain && args.DrainStrategy == nil {
+		args.DrainStrategy = &structs.DrainStrategy{
+			DrainSpec: structs.DrainSpec{Deadline: -1},
+		}
+	}
+
 	// Look for the node
 	snap, err := n.srv.fsm.State().Snapshot()
 	if err != nil {
@@ -215,19 +252,37 @@ func (n *Node) UpdateDrain(args *structs.NodeUpdateDrainRequest,
 		return fmt.Errorf("node not found")
 	}
 
-	// Commit this update via Raft
-	var index uint64
-	if node.Drain != args.Drain {
-		_, index, err = n.srv.raftApply(structs.NodeUpdateDrainRequestType, args)
-		if err != nil {
-			n.srv.logger.Printf("[ERR] nomad.client: drain update failed: %v", err)
-			return err
+	// Start the deadline of a new drain now, and keep the deadline of a
+	// drain in progress if it is updated with the same spec
+	if args.DrainStrategy != nil {
+		now := time.Now().UTC()
+		if node.DrainStrategy != nil && node.DrainStrategy.DrainSpec == args.DrainStrategy.DrainSpec {
+			args.DrainStrategy.StartedAt = node.DrainStrategy.StartedAt
+			args.DrainStrategy.ForceDeadline = node.DrainStrategy.ForceDeadline
+		} else {
+			args.DrainStrategy.StartedAt = now
+			args.DrainStrategy.ForceDeadline = time.Time{}
+			if args.DrainStrategy.Deadline > 0 {
+				args.DrainStrategy.ForceDeadline = now.Add(args.DrainStrategy.Deadline)
+			}
 		}
-		reply.NodeModifyIndex = index
 	}
+	args.Drain = args.DrainStrategy != nil
 
-	// Check if we should trigger evaluations
-	if args.Drain {
+	// Commit this update via Raft
+	_, index, err := n.srv.raftApply(structs.NodeUpdateDrainRequestType, args)
+	if err != nil {
+		n.srv.logger.Printf("[ERR] nomad.client: drain update failed: %v", err)
+		return err
+	}
+	reply.NodeModifyIndex = index
+
+	// Evaluate the jobs of the node when a drain starts, and when the node
+	// becomes eligible again as placements may be possible
+	started := args.DrainStrategy != nil && node.DrainStrategy == nil
+	eligible := args.DrainStrategy == nil && args.MarkEligible &&
+		node.SchedulingEligibility != structs.NodeSchedulingEligible
+	if started || eligible {
 		evalIDs, evalIndex, err := n.createNodeEvals(args.NodeID, index)
 		if err != nil {
 			n.srv.logger.Printf("[ERR] nomad.client: eval creation failed: %v", err)
@@ -242,6 +297,68 @@ func (n *Node) UpdateDrain(args *structs.NodeUpdateDrainRequest,
 	return nil
 }
 
+// UpdateEligibility is used to update the scheduling eligibility of a
+// client node
+func (n *Node) UpdateEligibility(args *structs.NodeUpdateEligibilityRequest,
+	reply *structs.NodeEligibilityUpdateResponse) error {
+	if done, err := n.srv.forward("Node.UpdateEligibility", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "update_eligibility"}, time.Now())
+
+	// Verify the arguments
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID for eligibility update")
+	}
+	switch args.Eligibility {
+	case structs.NodeSchedulingEligible, structs.NodeSchedulingIneligible:
+	default:
+		return fmt.Errorf("invalid scheduling eligibility %q", args.Eligibility)
+	}
+
+	// Look for the node
+	snap, err := n.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	node, err := snap.NodeByID(args.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("node not found")
+	}
+	if node.DrainStrategy != nil && args.Eligibility == structs.NodeSchedulingEligible {
+		return fmt.Errorf("can not set node eligible while it is draining")
+	}
+
+	// Commit this update via Raft
+	var index uint64
+	if node.SchedulingEligibility != args.Eligibility {
+		_, index, err = n.srv.raftApply(structs.NodeUpdateEligibilityRequestType, args)
+		if err != nil {
+			n.srv.logger.Printf("[ERR] nomad.client: eligibility update failed: %v", err)
+			return err
+		}
+		reply.NodeModifyIndex = index
+
+		// Placements may be possible again if the node became eligible
+		if args.Eligibility == structs.NodeSchedulingEligible {
+			evalIDs, evalIndex, err := n.createNodeEvals(args.NodeID, index)
+			if err != nil {
+				n.srv.logger.Printf("[ERR] nomad.client: eval creation failed: %v", err)
+				return err
+			}
+			reply.EvalIDs = evalIDs
+			reply.EvalCreateIndex = evalIndex
+		}
+	}
+
+	// Set the reply index
+	reply.Index = index
+	return nil
+}
+
 // Evaluate is used to force a re-evaluation of the node
 func (n *Node) Evaluate(args *structs.NodeEvaluateRequest, reply *structs.NodeUpdateResponse) error {
 	if done, err := n.srv.forward("Node.Evaluate", args, args, reply); done {
@@ -310,7 +427,11 @@ func (n *Node) GetNode(args *structs.NodeSpecificRequest,
 				return err
 			}
 
-			// Setup the output
+			// Setup the output, without the secret ID of the node
+			if out != nil {
+				out = out.Copy()
+				out.SecretID = ""
+			}
 			reply.Node = out
 			if out != nil {
 				reply.Index = out.ModifyIndex
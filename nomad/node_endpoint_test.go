@@ -2,6 +2,7 @@ package nomad
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,6 +51,148 @@ func TestClientEndpoint_Register(t *testing.T) {
 	}
 }
 
+func TestClientEndpoint_Register_SecretID(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Nodes can not register without a secret ID
+	node := mock.Node()
+	node.SecretID = ""
+	req := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "missing node secret ID") {
+		t.Fatalf("expected error, got: %v", err)
+	}
+
+	node.SecretID = structs.GenerateUUID()
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The node can not be registered again with another secret ID
+	other := mock.Node()
+	other.ID = node.ID
+	req.Node = other
+	err = msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "secret ID does not match") {
+		t.Fatalf("expected error, got: %v", err)
+	}
+
+	out, err := s1.fsm.State().NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SecretID != node.SecretID {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestClientEndpoint_Register_SecretID_Upgrade(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// A node registered before secret IDs existed
+	node := mock.Node()
+	node.SecretID = ""
+	state := s1.fsm.State()
+	if err := state.UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The older client can keep registering without a secret ID
+	req := &structs.NodeRegisterRequest{
+		Node:         node.Copy(),
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The first secret ID provided after the upgrade is trusted
+	req.Node = node.Copy()
+	req.Node.SecretID = structs.GenerateUUID()
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err := state.NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SecretID != req.Node.SecretID {
+		t.Fatalf("secret ID not stored: %#v", out)
+	}
+
+	// From then on the node can not be registered with another or no secret ID
+	for _, secret := range []string{structs.GenerateUUID(), ""} {
+		other := node.Copy()
+		other.SecretID = secret
+		req.Node = other
+		err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp)
+		if err == nil || !strings.Contains(err.Error(), "secret ID does not match") {
+			t.Fatalf("expected error, got: %v", err)
+		}
+	}
+}
+
+func TestClientEndpoint_Register_MetaChange(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a ready node
+	node := mock.Node()
+	req := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeUpdateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Register a system job that can be placed on it
+	job := mock.SystemJob()
+	if err := s1.fsm.State().UpsertJob(1, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Registering the same node again triggers no evaluation
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(resp.EvalIDs) != 0 {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Changing its meta evaluates the jobs that may be placed on it
+	node.Meta = map[string]string{"rack": "r1"}
+	var resp2 structs.NodeUpdateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(resp2.EvalIDs) != 1 {
+		t.Fatalf("bad: %#v", resp2)
+	}
+
+	out, err := s1.fsm.State().NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Meta["rack"] != "r1" {
+		t.Fatalf("bad: %#v", out.Meta)
+	}
+}
+
 func TestClientEndpoint_Deregister(t *testing.T) {
 	s1 := testServer(t, nil)
 	defer s1.Shutdown()
@@ -323,6 +466,132 @@ func TestClientEndpoint_UpdateDrain(t *testing.T) {
 	}
 }
 
+func TestClientEndpoint_UpdateDrain_Strategy(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the register request
+	node := mock.Node()
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	// Fetch the response
+	var resp structs.NodeUpdateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Drain the node with a deadline
+	start := time.Now()
+	drain := &structs.NodeUpdateDrainRequest{
+		NodeID: node.ID,
+		DrainStrategy: &structs.DrainStrategy{
+			DrainSpec: structs.DrainSpec{
+				Deadline:         time.Hour,
+				IgnoreSystemJobs: true,
+			},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp2 structs.NodeDrainUpdateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.UpdateDrain", drain, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Check for the node in the FSM
+	state := s1.fsm.State()
+	out, err := state.NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !out.Drain || out.SchedulingEligibility != structs.NodeSchedulingIneligible {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out.DrainStrategy == nil || !out.DrainStrategy.IgnoreSystemJobs ||
+		out.DrainStrategy.ForceDeadline.Before(start.Add(time.Hour)) {
+		t.Fatalf("bad: %#v", out.DrainStrategy)
+	}
+
+	// The node can not be marked eligible while draining
+	elig := &structs.NodeUpdateEligibilityRequest{
+		NodeID:       node.ID,
+		Eligibility:  structs.NodeSchedulingEligible,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp3 structs.NodeEligibilityUpdateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.UpdateEligibility", elig, &resp3); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Stop the drain and mark the node eligible
+	undrain := &structs.NodeUpdateDrainRequest{
+		NodeID:       node.ID,
+		MarkEligible: true,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "Node.UpdateDrain", undrain, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = state.NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Drain || out.DrainStrategy != nil || out.SchedulingEligibility != structs.NodeSchedulingEligible {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestClientEndpoint_UpdateEligibility(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the register request
+	node := mock.Node()
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	// Fetch the response
+	var resp structs.NodeUpdateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	elig := &structs.NodeUpdateEligibilityRequest{
+		NodeID:       node.ID,
+		Eligibility:  structs.NodeSchedulingIneligible,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp2 structs.NodeEligibilityUpdateResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.UpdateEligibility", elig, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp2.Index == 0 {
+		t.Fatalf("bad index: %d", resp2.Index)
+	}
+
+	out, err := s1.fsm.State().NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SchedulingEligibility != structs.NodeSchedulingIneligible || out.Drain {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Invalid eligibilities are rejected
+	elig.Eligibility = "maybe"
+	if err := msgpackrpc.CallWithCodec(codec, "Node.UpdateEligibility", elig, &resp2); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
 func TestClientEndpoint_GetNode(t *testing.T) {
 	s1 := testServer(t, nil)
 	defer s1.Shutdown()
@@ -361,6 +630,12 @@ func TestClientEndpoint_GetNode(t *testing.T) {
 		t.Fatalf("bad ComputedClass: %#v", resp2.Node)
 	}
 
+	// The secret ID is not returned
+	if resp2.Node.SecretID != "" {
+		t.Fatalf("secret ID returned: %#v", resp2.Node)
+	}
+	node.SecretID = ""
+
 	if !reflect.DeepEqual(node, resp2.Node) {
 		t.Fatalf("bad: %#v %#v", node, resp2.Node)
 	}
@@ -948,7 +1223,7 @@ func TestClientEndpoint_ListNodes_Blocking(t *testing.T) {
 
 	// Node drain updates trigger watches.
 	time.AfterFunc(100*time.Millisecond, func() {
-		if err := state.UpdateNodeDrain(3, node.ID, true); err != nil {
+		if err := state.UpdateNodeDrain(3, node.ID, &structs.DrainStrategy{}, false); err != nil {
 			t.Fatalf("err: %v", err)
 		}
 	})
@@ -972,7 +1247,7 @@ func TestClientEndpoint_ListNodes_Blocking(t *testing.T) {
 
 	// Node status update triggers watches
 	time.AfterFunc(100*time.Millisecond, func() {
-		if err := state.UpdateNodeStatus(4, node.ID, structs.NodeStatusDown); err != nil {
+		if err := state.UpdateNodeStatus(4, node.ID, structs.NodeStatusDown, 0); err != nil {
 			t.Fatalf("err: %v", err)
 		}
 	})
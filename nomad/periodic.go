@@ -268,20 +268,29 @@ func (p *PeriodicDispatch) removeLocked(jobID string) error {
 // ForceRun causes the periodic job to be evaluated immediately and returns the
 // subsequent eval.
 func (p *PeriodicDispatch) ForceRun(jobID string) (*structs.Evaluation, error) {
+	return p.ForceRunAt(jobID, time.Now())
+}
+
+// ForceRunAt causes the periodic job to be evaluated immediately as the launch
+// at the passed time and returns the subsequent eval. It is used to launch
+// instances of the job that were missed.
+func (p *PeriodicDispatch) ForceRunAt(jobID string, launch time.Time) (*structs.Evaluation, error) {
 	p.l.Lock()
 
 	// Do nothing if not enabled
 	if !p.enabled {
+		p.l.Unlock()
 		return nil, fmt.Errorf("periodic dispatch disabled")
 	}
 
 	job, tracked := p.tracked[jobID]
 	if !tracked {
+		p.l.Unlock()
 		return nil, fmt.Errorf("can't force run non-tracked job %v", jobID)
 	}
 
 	p.l.Unlock()
-	return p.createEval(job, time.Now())
+	return p.createEval(job, launch)
 }
 
 // shouldRun returns whether the long lived run function should run.
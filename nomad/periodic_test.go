@@ -165,6 +165,26 @@ func TestPeriodicDispatch_Add_RemoveJob(t *testing.T) {
 	}
 }
 
+func TestPeriodicDispatch_Add_TimeZone(t *testing.T) {
+	t.Parallel()
+	p, _ := testPeriodicDispatcher()
+	job := mock.PeriodicJob()
+	job.Periodic.Spec = "0 2 * * *"
+	job.Periodic.TimeZone = "America/New_York"
+	if err := p.Add(job); err != nil {
+		t.Fatalf("Add failed %v", err)
+	}
+
+	// The next launch should be 2am in the job's time zone.
+	_, next := p.nextLaunch()
+	if next.Location().String() != job.Periodic.TimeZone {
+		t.Fatalf("next launch has location %v; want %v", next.Location(), job.Periodic.TimeZone)
+	}
+	if next.Hour() != 2 || next.Minute() != 0 {
+		t.Fatalf("next launch is %v; want 2am", next)
+	}
+}
+
 func TestPeriodicDispatch_Add_TriggersUpdate(t *testing.T) {
 	t.Parallel()
 	p, m := testPeriodicDispatcher()
@@ -26,6 +26,7 @@ const (
 	rpcRaft              = 0x02
 	rpcMultiplex         = 0x03
 	rpcTLS               = 0x04
+	rpcNodeConn          = 0x05
 )
 
 const (
@@ -114,6 +115,9 @@ func (s *Server) handleConn(conn net.Conn, isTLS bool) {
 	case rpcMultiplex:
 		s.handleMultiplex(conn)
 
+	case rpcNodeConn:
+		s.handleNodeConn(conn)
+
 	case rpcTLS:
 		if s.rpcTLS == nil {
 			s.logger.Printf("[WARN] nomad.rpc: TLS connection attempted, server not configured for TLS")
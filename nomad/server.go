@@ -20,6 +20,7 @@ import (
 	"github.com/hashicorp/raft"
 	"github.com/hashicorp/raft-boltdb"
 	"github.com/hashicorp/serf/serf"
+	"github.com/hashicorp/yamux"
 )
 
 const (
@@ -121,6 +122,20 @@ type Server struct {
 	heartbeatTimers     map[string]*time.Timer
 	heartbeatTimersLock sync.Mutex
 
+	// nodeConns holds the node connections of the clients connected to the
+	// server, by node ID. RPCs targeting a node are forwarded over them.
+	nodeConns     map[string]*yamux.Session
+	nodeConnsLock sync.RWMutex
+
+	// keystore holds the material of the root keys used to encrypt
+	// variables. Only the metadata of the keys is replicated via Raft.
+	keystore *keystore
+
+	// multiregionRollouts holds a channel for each rollout of a multi-region
+	// job run by the leader, by job ID. Closing it stops the rollout.
+	multiregionRollouts     map[string]chan struct{}
+	multiregionRolloutsLock sync.Mutex
+
 	// Worker used for processing
 	workers []*Worker
 
@@ -132,14 +147,21 @@ type Server struct {
 
 // Holds the RPC endpoints
 type endpoints struct {
-	Status   *Status
-	Node     *Node
-	Job      *Job
-	Eval     *Eval
-	Plan     *Plan
-	Alloc    *Alloc
-	Region   *Region
-	Periodic *Periodic
+	Status              *Status
+	Node                *Node
+	Job                 *Job
+	Eval                *Eval
+	Plan                *Plan
+	Alloc               *Alloc
+	Region              *Region
+	Periodic            *Periodic
+	System              *System
+	Variables           *Variables
+	Keyring             *Keyring
+	ServiceRegistration *ServiceRegistration
+	ClientFS            *ClientFS
+	ClientStats         *ClientStats
+	ClientAllocations   *ClientAllocations
 }
 
 // NewServer is used to construct a new Nomad server from the
@@ -172,22 +194,30 @@ func NewServer(config *Config) (*Server, error) {
 
 	// Create the server
 	s := &Server{
-		config:      config,
-		connPool:    NewPool(config.LogOutput, serverRPCCache, serverMaxStreams, nil),
-		logger:      logger,
-		rpcServer:   rpc.NewServer(),
-		peers:       make(map[string][]*serverParts),
-		localPeers:  make(map[string]*serverParts),
-		reconcileCh: make(chan serf.Member, 32),
-		eventCh:     make(chan serf.Event, 256),
-		evalBroker:  evalBroker,
-		planQueue:   planQueue,
-		shutdownCh:  make(chan struct{}),
+		config:              config,
+		connPool:            NewPool(config.LogOutput, serverRPCCache, serverMaxStreams, nil),
+		logger:              logger,
+		rpcServer:           rpc.NewServer(),
+		peers:               make(map[string][]*serverParts),
+		localPeers:          make(map[string]*serverParts),
+		nodeConns:           make(map[string]*yamux.Session),
+		multiregionRollouts: make(map[string]chan struct{}),
+		reconcileCh:         make(chan serf.Member, 32),
+		eventCh:             make(chan serf.Event, 256),
+		evalBroker:          evalBroker,
+		planQueue:           planQueue,
+		shutdownCh:          make(chan struct{}),
 	}
 
 	// Create the periodic dispatcher for launching periodic jobs.
 	s.periodicDispatcher = NewPeriodicDispatch(s.logger, s)
 
+	// Initialize the keystore holding the root keys
+	if err := s.setupKeystore(); err != nil {
+		logger.Printf("[ERR] nomad: failed to setup keystore: %s", err)
+		return nil, fmt.Errorf("Failed to setup keystore: %v", err)
+	}
+
 	// Initialize the RPC layer
 	// TODO: TLS...
 	if err := s.setupRPC(nil); err != nil {
@@ -236,6 +266,9 @@ func NewServer(config *Config) (*Server, error) {
 	// Emit metrics
 	go s.heartbeatStats()
 
+	// Fetch the root keys missing from the keystore
+	go s.replicateKeyring()
+
 	// Done
 	return s, nil
 }
@@ -358,12 +391,19 @@ func (s *Server) setupRPC(tlsWrap tlsutil.DCWrapper) error {
 	// Create endpoints
 	s.endpoints.Status = &Status{s}
 	s.endpoints.Node = &Node{s}
-	s.endpoints.Job = &Job{s}
+	s.endpoints.Job = NewJobEndpoints(s)
 	s.endpoints.Eval = &Eval{s}
 	s.endpoints.Plan = &Plan{s}
 	s.endpoints.Alloc = &Alloc{s}
 	s.endpoints.Region = &Region{s}
 	s.endpoints.Periodic = &Periodic{s}
+	s.endpoints.System = &System{s}
+	s.endpoints.Variables = &Variables{s}
+	s.endpoints.Keyring = &Keyring{s}
+	s.endpoints.ServiceRegistration = &ServiceRegistration{s}
+	s.endpoints.ClientFS = &ClientFS{s}
+	s.endpoints.ClientStats = &ClientStats{s}
+	s.endpoints.ClientAllocations = &ClientAllocations{s}
 
 	// Register the handlers
 	s.rpcServer.Register(s.endpoints.Status)
@@ -374,6 +414,13 @@ func (s *Server) setupRPC(tlsWrap tlsutil.DCWrapper) error {
 	s.rpcServer.Register(s.endpoints.Alloc)
 	s.rpcServer.Register(s.endpoints.Region)
 	s.rpcServer.Register(s.endpoints.Periodic)
+	s.rpcServer.Register(s.endpoints.System)
+	s.rpcServer.Register(s.endpoints.Variables)
+	s.rpcServer.Register(s.endpoints.Keyring)
+	s.rpcServer.Register(s.endpoints.ServiceRegistration)
+	s.rpcServer.Register(s.endpoints.ClientFS)
+	s.rpcServer.Register(s.endpoints.ClientStats)
+	s.rpcServer.Register(s.endpoints.ClientAllocations)
 
 	list, err := net.ListenTCP("tcp", s.config.RPCAddr)
 	if err != nil {
@@ -406,6 +453,41 @@ func (s *Server) setupRPC(tlsWrap tlsutil.DCWrapper) error {
 	return nil
 }
 
+// setupKeystore is used to setup the keystore holding the root keys used to
+// encrypt variables
+func (s *Server) setupKeystore() error {
+	var path string
+	if !s.config.DevMode {
+		path = filepath.Join(s.config.DataDir, keystoreDir)
+	}
+
+	// The root keys are replicated to the other servers wrapped with the key
+	// encryption key, so it must be shared unless this is the only server
+	kek := s.config.KeyringEncryptionKey
+	if len(kek) == 0 {
+		if !s.config.DevMode && !s.config.Bootstrap {
+			return fmt.Errorf("a keyring encryption key shared by all the servers must be configured " +
+				"when running more than one server")
+		}
+
+		var err error
+		if kek, err = loadKeyEncryptionKey(path); err != nil {
+			return err
+		}
+		if !s.config.DevMode {
+			s.logger.Printf("[WARN] nomad: no keyring encryption key configured, using a key local to this server; " +
+				"it must be configured before adding other servers")
+		}
+	}
+
+	ks, err := newKeystore(path, kek)
+	if err != nil {
+		return err
+	}
+	s.keystore = ks
+	return nil
+}
+
 // setupRaft is used to setup and initialize Raft
 func (s *Server) setupRaft() error {
 	// If we are in bootstrap mode, enable a single node cluster
@@ -1,6 +1,7 @@
 package nomad
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -36,6 +37,9 @@ func testServer(t *testing.T, cb func(*Config)) *Server {
 	}
 	config.NodeName = fmt.Sprintf("Node %d", config.RPCAddr.Port)
 
+	// Share the root keys between the test servers
+	config.KeyringEncryptionKey = bytes.Repeat([]byte{1}, keyEncryptionKeySize)
+
 	// Tighten the Serf timing
 	config.SerfConfig.MemberlistConfig.BindAddr = "127.0.0.1"
 	config.SerfConfig.MemberlistConfig.BindPort = getPort()
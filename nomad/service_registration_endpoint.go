@@ -0,0 +1,215 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/watch"
+)
+
+// ServiceRegistration endpoint is used for the services registered with the
+// Nomad servers instead of Consul
+type ServiceRegistration struct {
+	srv *Server
+}
+
+// Upsert is used by clients to register the services of their tasks
+func (s *ServiceRegistration) Upsert(args *structs.ServiceRegistrationUpsertRequest,
+	reply *structs.GenericResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.Upsert", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "upsert"}, time.Now())
+
+	// Validate the arguments
+	if len(args.Services) == 0 {
+		return fmt.Errorf("missing services for registration")
+	}
+	snap, err := s.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	var mErr multierror.Error
+	for _, service := range args.Services {
+		if err := validateServiceRegistration(snap, service); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	if err := mErr.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	// Commit this update via Raft
+	_, index, err := s.srv.raftApply(structs.ServiceRegistrationUpsertRequestType, args)
+	if err != nil {
+		s.srv.logger.Printf("[ERR] nomad.service_registration: Upsert failed: %v", err)
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// validateServiceRegistration checks that the service belongs to a running
+// allocation of the node registering it.
+func validateServiceRegistration(snap *state.StateSnapshot, service *structs.ServiceRegistration) error {
+	if service.ID == "" || service.ServiceName == "" {
+		return fmt.Errorf("service registration must have an ID and a name")
+	}
+	if service.NodeID == "" || service.AllocID == "" {
+		return fmt.Errorf("service %q must have a node and an allocation", service.ServiceName)
+	}
+
+	alloc, err := snap.AllocByID(service.AllocID)
+	if err != nil {
+		return err
+	}
+	if alloc == nil {
+		return fmt.Errorf("service %q: allocation %s not found", service.ServiceName, service.AllocID)
+	}
+	if alloc.NodeID != service.NodeID {
+		return fmt.Errorf("service %q: allocation %s is not placed on node %s",
+			service.ServiceName, service.AllocID, service.NodeID)
+	}
+	switch alloc.ClientStatus {
+	case structs.AllocClientStatusDead, structs.AllocClientStatusFailed:
+		return fmt.Errorf("service %q: allocation %s is no longer running", service.ServiceName, service.AllocID)
+	}
+	return nil
+}
+
+// Delete is used by clients to deregister services by ID
+func (s *ServiceRegistration) Delete(args *structs.ServiceRegistrationDeleteRequest,
+	reply *structs.GenericResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.Delete", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "delete"}, time.Now())
+
+	// Validate the arguments
+	if len(args.IDs) == 0 {
+		return fmt.Errorf("missing service IDs for deregistration")
+	}
+
+	// Commit this update via Raft
+	_, index, err := s.srv.raftApply(structs.ServiceRegistrationDeleteRequestType, args)
+	if err != nil {
+		s.srv.logger.Printf("[ERR] nomad.service_registration: Delete failed: %v", err)
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// List is used to list the names of the registered services along with the
+// tags of their instances
+func (s *ServiceRegistration) List(args *structs.ServiceRegistrationListRequest,
+	reply *structs.ServiceRegistrationListResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.List", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "list"}, time.Now())
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "service_registrations"}),
+		run: func() error {
+			// Capture all the services
+			snap, err := s.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			iter, err := snap.ServiceRegistrationsByNamePrefix(args.QueryOptions.Prefix)
+			if err != nil {
+				return err
+			}
+
+			// The iterator is ordered by service name so the instances of a
+			// service are adjacent
+			var services []*structs.ServiceRegistrationListStub
+			var seen map[string]struct{}
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				service := raw.(*structs.ServiceRegistration)
+
+				n := len(services)
+				if n == 0 || services[n-1].ServiceName != service.ServiceName {
+					services = append(services, &structs.ServiceRegistrationListStub{
+						ServiceName: service.ServiceName,
+					})
+					seen = make(map[string]struct{})
+					n++
+				}
+				for _, tag := range service.Tags {
+					if _, ok := seen[tag]; !ok {
+						seen[tag] = struct{}{}
+						services[n-1].Tags = append(services[n-1].Tags, tag)
+					}
+				}
+			}
+			reply.Services = services
+
+			// Use the last index that affected the services table
+			index, err := snap.Index("service_registrations")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			s.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return s.srv.blockingRPC(&opts)
+}
+
+// GetService is used to get the instances of a service
+func (s *ServiceRegistration) GetService(args *structs.ServiceRegistrationByNameRequest,
+	reply *structs.ServiceRegistrationByNameResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.GetService", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "get_service"}, time.Now())
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "service_registrations"}),
+		run: func() error {
+			// Look for the service instances
+			snap, err := s.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			services, err := snap.ServiceRegistrationsByName(args.ServiceName)
+			if err != nil {
+				return err
+			}
+			reply.Services = services
+
+			// Use the last index that affected the services table
+			index, err := snap.Index("service_registrations")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			s.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return s.srv.blockingRPC(&opts)
+}
@@ -0,0 +1,118 @@
+package nomad
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestServiceRegistrationEndpoint_UpsertListDelete(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the allocations providing the services
+	alloc1 := mock.Alloc()
+	alloc2 := mock.Alloc()
+	state := s1.fsm.State()
+	if err := state.UpsertAllocs(1000, []*structs.Allocation{alloc1, alloc2}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	s1Reg := mock.ServiceRegistration()
+	s1Reg.AllocID = alloc1.ID
+	s1Reg.Tags = []string{"a", "b"}
+	s2Reg := mock.ServiceRegistration()
+	s2Reg.AllocID = alloc2.ID
+	s2Reg.Tags = []string{"b", "c"}
+
+	// Register the services
+	req := &structs.ServiceRegistrationUpsertRequest{
+		Services:     []*structs.ServiceRegistration{s1Reg, s2Reg},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Upsert", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	// List the services
+	list := &structs.ServiceRegistrationListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var listResp structs.ServiceRegistrationListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.List", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(listResp.Services) != 1 {
+		t.Fatalf("bad: %#v", listResp.Services)
+	}
+	stub := listResp.Services[0]
+	if stub.ServiceName != "frontend" || len(stub.Tags) != 3 {
+		t.Fatalf("bad: %#v", stub)
+	}
+
+	// Get the instances of the service
+	get := &structs.ServiceRegistrationByNameRequest{
+		ServiceName:  "frontend",
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var getResp structs.ServiceRegistrationByNameResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.GetService", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(getResp.Services) != 2 {
+		t.Fatalf("bad: %#v", getResp.Services)
+	}
+
+	// Deregister the first instance
+	del := &structs.ServiceRegistrationDeleteRequest{
+		IDs:          []string{s1Reg.ID},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Delete", del, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.GetService", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(getResp.Services) != 1 || !reflect.DeepEqual(getResp.Services[0].Tags, s2Reg.Tags) {
+		t.Fatalf("bad: %#v", getResp.Services)
+	}
+}
+
+func TestServiceRegistrationEndpoint_Upsert_Invalid(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// The allocation of the service does not exist
+	req := &structs.ServiceRegistrationUpsertRequest{
+		Services:     []*structs.ServiceRegistration{mock.ServiceRegistration()},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Upsert", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// The allocation is placed on another node
+	alloc := mock.Alloc()
+	alloc.NodeID = structs.GenerateUUID()
+	if err := s1.fsm.State().UpsertAllocs(1000, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req.Services[0].AllocID = alloc.ID
+	if err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Upsert", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -19,9 +19,14 @@ func stateStoreSchema() *memdb.DBSchema {
 		indexTableSchema,
 		nodeTableSchema,
 		jobTableSchema,
+		jobSummarySchema,
 		periodicLaunchTableSchema,
+		multiregionRolloutTableSchema,
 		evalTableSchema,
 		allocTableSchema,
+		variablesTableSchema,
+		rootKeysTableSchema,
+		serviceRegistrationsTableSchema,
 	}
 
 	// Add each of the tables
@@ -147,6 +152,25 @@ func jobIsPeriodic(obj interface{}) (bool, error) {
 	return false, nil
 }
 
+// jobSummarySchema returns the memdb schema for the job summary table.
+// This table is used to store the allocation counts of each job.
+func jobSummarySchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "job_summary",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field:     "JobID",
+					Lowercase: true,
+				},
+			},
+		},
+	}
+}
+
 // periodicLaunchTableSchema returns the MemDB schema tracking the most recent
 // launch time for a perioidic job.
 func periodicLaunchTableSchema() *memdb.TableSchema {
@@ -169,6 +193,25 @@ func periodicLaunchTableSchema() *memdb.TableSchema {
 	}
 }
 
+// multiregionRolloutTableSchema returns the MemDB schema for the table of the
+// rollouts of multi-region jobs submitted to this region.
+func multiregionRolloutTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "multiregion_rollouts",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field:     "JobID",
+					Lowercase: true,
+				},
+			},
+		},
+	}
+}
+
 // evalTableSchema returns the MemDB schema for the eval table.
 // This table is used to store all the evaluations that are pending
 // or recently completed.
@@ -251,3 +294,114 @@ func allocTableSchema() *memdb.TableSchema {
 		},
 	}
 }
+
+// variablesTableSchema returns the MemDB schema for the variables table.
+// This table stores the variables in their encrypted form.
+func variablesTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "variables",
+		Indexes: map[string]*memdb.IndexSchema{
+			// Primary index is the path of the variable. Paths are case
+			// sensitive.
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Path",
+				},
+			},
+		},
+	}
+}
+
+// rootKeysTableSchema returns the MemDB schema for the root keys table.
+// This table stores the metadata of the keyring used to encrypt variables.
+func rootKeysTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "root_keys",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.UUIDFieldIndex{
+					Field: "KeyID",
+				},
+			},
+
+			// Active index is used to find the key new variables are
+			// encrypted with
+			"active": &memdb.IndexSchema{
+				Name:         "active",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.ConditionalIndex{
+					Conditional: rootKeyIsActive,
+				},
+			},
+		},
+	}
+}
+
+// rootKeyIsActive satisfies the ConditionalIndexFunc interface and creates an
+// index on whether a root key is active.
+func rootKeyIsActive(obj interface{}) (bool, error) {
+	k, ok := obj.(*structs.RootKeyMeta)
+	if !ok {
+		return false, fmt.Errorf("Unexpected type: %v", obj)
+	}
+
+	return k.Active(), nil
+}
+
+// serviceRegistrationsTableSchema returns the MemDB schema for the service
+// registrations table. This table stores the instances of the services that
+// are registered with the Nomad servers.
+func serviceRegistrationsTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "service_registrations",
+		Indexes: map[string]*memdb.IndexSchema{
+			// Primary index is the service ID generated for the allocation
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ID",
+				},
+			},
+
+			// Service index is used to lookup the instances of a service
+			"service": &memdb.IndexSchema{
+				Name:         "service",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ServiceName",
+				},
+			},
+
+			// Alloc index is used to lookup the services of an allocation
+			"alloc": &memdb.IndexSchema{
+				Name:         "alloc",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.UUIDFieldIndex{
+					Field: "AllocID",
+				},
+			},
+
+			// Node index is used to lookup the services running on a node
+			"node": &memdb.IndexSchema{
+				Name:         "node",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field:     "NodeID",
+					Lowercase: true,
+				},
+			},
+		},
+	}
+}
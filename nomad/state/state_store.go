@@ -108,9 +108,14 @@ func (s *StateStore) UpsertNode(index uint64, node *structs.Node) error {
 		node.CreateIndex = exist.CreateIndex
 		node.ModifyIndex = index
 		node.Drain = exist.Drain // Retain the drain mode
+		node.DrainStrategy = exist.DrainStrategy
+		node.SchedulingEligibility = exist.SchedulingEligibility
 	} else {
 		node.CreateIndex = index
 		node.ModifyIndex = index
+		if node.SchedulingEligibility == "" {
+			node.SchedulingEligibility = structs.NodeSchedulingEligible
+		}
 	}
 
 	// Insert the node
@@ -152,13 +157,18 @@ func (s *StateStore) DeleteNode(index uint64, nodeID string) error {
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// Remove the services registered by the node
+	if err := s.deleteServiceRegistrations(index, watcher, txn, "node", nodeID); err != nil {
+		return err
+	}
+
 	txn.Defer(func() { s.watch.notify(watcher) })
 	txn.Commit()
 	return nil
 }
 
 // UpdateNodeStatus is used to update the status of a node
-func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string) error {
+func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string, updatedAt int64) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
@@ -182,6 +192,7 @@ func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string) error
 
 	// Update the status in the copy
 	copyNode.Status = status
+	copyNode.StatusUpdatedAt = updatedAt
 	copyNode.ModifyIndex = index
 
 	// Insert the node
@@ -192,13 +203,81 @@ func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string) error
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// The status of allocations allowed to outlive a disconnect is no longer
+	// known once the node is down.
+	if status == structs.NodeStatusDown {
+		if err := s.markAllocsUnknown(index, nodeID, watcher, txn); err != nil {
+			return err
+		}
+
+		// The services of a down node can no longer be reached
+		if err := s.deleteServiceRegistrations(index, watcher, txn, "node", nodeID); err != nil {
+			return err
+		}
+	}
+
 	txn.Defer(func() { s.watch.notify(watcher) })
 	txn.Commit()
 	return nil
 }
 
-// UpdateNodeDrain is used to update the drain of a node
-func (s *StateStore) UpdateNodeDrain(index uint64, nodeID string, drain bool) error {
+// markAllocsUnknown sets the client status of the non-terminal allocations on
+// a node to unknown if their task group tolerates client disconnects.
+func (s *StateStore) markAllocsUnknown(index uint64, nodeID string,
+	watcher watch.Items, txn *memdb.Txn) error {
+	iter, err := txn.Get("allocs", "node", nodeID)
+	if err != nil {
+		return fmt.Errorf("alloc lookup failed: %v", err)
+	}
+
+	var unknown, previous []*structs.Allocation
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		alloc := raw.(*structs.Allocation)
+		if alloc.TerminalStatus() || alloc.Job == nil {
+			continue
+		}
+		tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+		if tg == nil || tg.MaxClientDisconnect == 0 {
+			continue
+		}
+
+		copyAlloc := new(structs.Allocation)
+		*copyAlloc = *alloc
+		copyAlloc.ClientStatus = structs.AllocClientStatusUnknown
+		copyAlloc.ClientDescription = "node is disconnected"
+		copyAlloc.ModifyIndex = index
+		unknown = append(unknown, copyAlloc)
+		previous = append(previous, alloc)
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	// Insert after iterating as modifying the table invalidates the iterator
+	for i, alloc := range unknown {
+		if err := txn.Insert("allocs", alloc); err != nil {
+			return fmt.Errorf("alloc insert failed: %v", err)
+		}
+		if err := s.updateSummaryWithAlloc(index, watcher, txn, previous[i], alloc); err != nil {
+			return err
+		}
+		watcher.Add(watch.Item{Alloc: alloc.ID})
+		watcher.Add(watch.Item{AllocEval: alloc.EvalID})
+		watcher.Add(watch.Item{AllocJob: alloc.JobID})
+		watcher.Add(watch.Item{AllocNode: alloc.NodeID})
+	}
+	watcher.Add(watch.Item{Table: "allocs"})
+	if err := txn.Insert("index", &IndexEntry{"allocs", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// UpdateNodeDrain is used to update the drain of a node. A draining node is
+// ineligible for scheduling. Stopping the drain leaves the node ineligible
+// unless markEligible is set.
+func (s *StateStore) UpdateNodeDrain(index uint64, nodeID string,
+	drain *structs.DrainStrategy, markEligible bool) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
@@ -221,7 +300,57 @@ func (s *StateStore) UpdateNodeDrain(index uint64, nodeID string, drain bool) er
 	*copyNode = *existingNode
 
 	// Update the drain in the copy
-	copyNode.Drain = drain
+	copyNode.DrainStrategy = drain
+	copyNode.Drain = drain != nil
+	if drain != nil {
+		copyNode.SchedulingEligibility = structs.NodeSchedulingIneligible
+	} else if markEligible {
+		copyNode.SchedulingEligibility = structs.NodeSchedulingEligible
+	}
+	copyNode.ModifyIndex = index
+
+	// Insert the node
+	if err := txn.Insert("nodes", copyNode); err != nil {
+		return fmt.Errorf("node update failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"nodes", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// UpdateNodeEligibility is used to update the scheduling eligibility of a
+// node
+func (s *StateStore) UpdateNodeEligibility(index uint64, nodeID, eligibility string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "nodes"})
+	watcher.Add(watch.Item{Node: nodeID})
+
+	// Lookup the node
+	existing, err := txn.First("nodes", "id", nodeID)
+	if err != nil {
+		return fmt.Errorf("node lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("node not found")
+	}
+
+	// Copy the existing node
+	existingNode := existing.(*structs.Node)
+	if existingNode.DrainStrategy != nil && eligibility == structs.NodeSchedulingEligible {
+		return fmt.Errorf("can not set node eligible while it is draining")
+	}
+	copyNode := new(structs.Node)
+	*copyNode = *existingNode
+
+	// Update the eligibility in the copy
+	copyNode.SchedulingEligibility = eligibility
 	copyNode.ModifyIndex = index
 
 	// Insert the node
@@ -292,7 +421,9 @@ func (s *StateStore) UpsertJob(index uint64, job *structs.Job) error {
 	}
 
 	// Setup the indexes correctly
+	oldStatus := ""
 	if existing != nil {
+		oldStatus = existing.(*structs.Job).Status
 		job.CreateIndex = existing.(*structs.Job).CreateIndex
 		job.ModifyIndex = index
 		job.JobModifyIndex = index
@@ -325,6 +456,14 @@ func (s *StateStore) UpsertJob(index uint64, job *structs.Job) error {
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// Update the summary of the job and the children counts of its parent
+	if err := s.updateSummaryWithJob(index, watcher, txn, job); err != nil {
+		return err
+	}
+	if err := s.updateParentSummary(index, watcher, txn, job.ParentID, oldStatus, job.Status); err != nil {
+		return err
+	}
+
 	txn.Defer(func() { s.watch.notify(watcher) })
 	txn.Commit()
 	return nil
@@ -356,6 +495,25 @@ func (s *StateStore) DeleteJob(index uint64, jobID string) error {
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// Delete the job summary and remove the job from the children counts of
+	// its parent
+	summary, err := txn.First("job_summary", "id", jobID)
+	if err != nil {
+		return fmt.Errorf("job summary lookup failed: %v", err)
+	}
+	if summary != nil {
+		if err := txn.Delete("job_summary", summary); err != nil {
+			return fmt.Errorf("job summary delete failed: %v", err)
+		}
+		if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+	}
+	job := existing.(*structs.Job)
+	if err := s.updateParentSummary(index, watcher, txn, job.ParentID, job.Status, ""); err != nil {
+		return err
+	}
+
 	txn.Defer(func() { s.watch.notify(watcher) })
 	txn.Commit()
 	return nil
@@ -436,6 +594,108 @@ func (s *StateStore) JobsByGC(gc bool) (memdb.ResultIterator, error) {
 	return iter, nil
 }
 
+// JobSummaryByID is used to lookup the summary of a job by its ID
+func (s *StateStore) JobSummaryByID(jobID string) (*structs.JobSummary, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("job_summary", "id", jobID)
+	if err != nil {
+		return nil, fmt.Errorf("job summary lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.JobSummary), nil
+	}
+	return nil, nil
+}
+
+// JobSummaries returns an iterator over all the job summaries
+func (s *StateStore) JobSummaries() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("job_summary", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// ReconcileJobSummaries rebuilds the summaries of all jobs from their
+// allocations. Allocations that were garbage collected are no longer counted.
+func (s *StateStore) ReconcileJobSummaries(index uint64) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+
+	// Collect the jobs first as inserting invalidates the iterator
+	iter, err := txn.Get("jobs", "id")
+	if err != nil {
+		return err
+	}
+	var jobs []*structs.Job
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		jobs = append(jobs, raw.(*structs.Job))
+	}
+
+	summaries := make(map[string]*structs.JobSummary, len(jobs))
+	for _, job := range jobs {
+		summary := &structs.JobSummary{
+			JobID:       job.ID,
+			Summary:     make(map[string]structs.TaskGroupSummary, len(job.TaskGroups)),
+			CreateIndex: index,
+			ModifyIndex: index,
+		}
+		if job.IsPeriodic() {
+			summary.Children = new(structs.JobChildrenSummary)
+		}
+		for _, tg := range job.TaskGroups {
+			summary.Summary[tg.Name] = structs.TaskGroupSummary{}
+		}
+
+		allocs, err := txn.Get("allocs", "job", job.ID)
+		if err != nil {
+			return err
+		}
+		for raw := allocs.Next(); raw != nil; raw = allocs.Next() {
+			alloc := raw.(*structs.Allocation)
+			tgSummary, ok := summary.Summary[alloc.TaskGroup]
+			if !ok {
+				continue
+			}
+			adjustTaskGroupSummary(&tgSummary, summaryStatus(alloc), 1)
+			summary.Summary[alloc.TaskGroup] = tgSummary
+		}
+		for name, tgSummary := range summary.Summary {
+			tgSummary.Queued = queuedAllocs(job, name, tgSummary)
+			summary.Summary[name] = tgSummary
+		}
+		summaries[job.ID] = summary
+	}
+
+	// Count the children of each job
+	for _, job := range jobs {
+		parent, ok := summaries[job.ParentID]
+		if !ok {
+			continue
+		}
+		if parent.Children == nil {
+			parent.Children = new(structs.JobChildrenSummary)
+		}
+		adjustChildrenSummary(parent.Children, job.Status, 1)
+	}
+
+	for _, summary := range summaries {
+		if err := s.insertJobSummary(index, watcher, txn, summary); err != nil {
+			return err
+		}
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
 // UpsertPeriodicLaunch is used to register a launch or update it.
 func (s *StateStore) UpsertPeriodicLaunch(index uint64, launch *structs.PeriodicLaunch) error {
 	txn := s.db.Txn(true)
@@ -532,6 +792,71 @@ func (s *StateStore) PeriodicLaunches() (memdb.ResultIterator, error) {
 	return iter, nil
 }
 
+// UpsertMultiregionRollout is used to create the rollout of a multi-region
+// job or record its progress.
+func (s *StateStore) UpsertMultiregionRollout(index uint64, rollout *structs.MultiregionRollout) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "multiregion_rollouts"})
+	watcher.Add(watch.Item{MultiregionRollout: rollout.JobID})
+
+	// Check if the rollout already exists
+	existing, err := txn.First("multiregion_rollouts", "id", rollout.JobID)
+	if err != nil {
+		return fmt.Errorf("multi-region rollout lookup failed: %v", err)
+	}
+
+	// Setup the indexes correctly
+	if existing != nil {
+		rollout.CreateIndex = existing.(*structs.MultiregionRollout).CreateIndex
+		rollout.ModifyIndex = index
+	} else {
+		rollout.CreateIndex = index
+		rollout.ModifyIndex = index
+	}
+
+	if err := txn.Insert("multiregion_rollouts", rollout); err != nil {
+		return fmt.Errorf("multi-region rollout insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"multiregion_rollouts", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// MultiregionRolloutByJobID is used to lookup the rollout of a multi-region
+// job by the job ID.
+func (s *StateStore) MultiregionRolloutByJobID(jobID string) (*structs.MultiregionRollout, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("multiregion_rollouts", "id", jobID)
+	if err != nil {
+		return nil, fmt.Errorf("multi-region rollout lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.MultiregionRollout), nil
+	}
+	return nil, nil
+}
+
+// MultiregionRollouts returns an iterator over all the multi-region rollouts
+func (s *StateStore) MultiregionRollouts() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("multiregion_rollouts", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
 // UpsertEvaluation is used to upsert an evaluation
 func (s *StateStore) UpsertEvals(index uint64, evals []*structs.Evaluation) error {
 	txn := s.db.Txn(true)
@@ -758,6 +1083,20 @@ func (s *StateStore) UpdateAllocFromClient(index uint64, alloc *structs.Allocati
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// Update the job summary
+	if err := s.updateSummaryWithAlloc(index, watcher, txn, exist, copyAlloc); err != nil {
+		return err
+	}
+
+	// Remove the services of allocations that are no longer running in case
+	// the client failed to deregister them
+	switch copyAlloc.ClientStatus {
+	case structs.AllocClientStatusDead, structs.AllocClientStatusFailed:
+		if err := s.deleteServiceRegistrations(index, watcher, txn, "alloc", alloc.ID); err != nil {
+			return err
+		}
+	}
+
 	// Set the job's status
 	forceStatus := ""
 	if !copyAlloc.TerminalStatus() {
@@ -773,6 +1112,64 @@ func (s *StateStore) UpdateAllocFromClient(index uint64, alloc *structs.Allocati
 	return nil
 }
 
+// UpdateAllocsDesiredTransitions is used to set the desired transitions of
+// allocations and to create the evaluations that act on them in a single
+// transaction
+func (s *StateStore) UpdateAllocsDesiredTransitions(index uint64,
+	allocs map[string]*structs.DesiredTransition, evals []*structs.Evaluation) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "allocs"})
+
+	for allocID, transition := range allocs {
+		existing, err := txn.First("allocs", "id", allocID)
+		if err != nil {
+			return fmt.Errorf("alloc lookup failed: %v", err)
+		}
+		if existing == nil {
+			continue
+		}
+		exist := existing.(*structs.Allocation)
+
+		copyAlloc := new(structs.Allocation)
+		*copyAlloc = *exist
+		copyAlloc.DesiredTransition = *transition
+		copyAlloc.ModifyIndex = index
+
+		watcher.Add(watch.Item{Alloc: exist.ID})
+		watcher.Add(watch.Item{AllocEval: exist.EvalID})
+		watcher.Add(watch.Item{AllocJob: exist.JobID})
+		watcher.Add(watch.Item{AllocNode: exist.NodeID})
+		if err := txn.Insert("allocs", copyAlloc); err != nil {
+			return fmt.Errorf("alloc insert failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"allocs", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	if len(evals) != 0 {
+		watcher.Add(watch.Item{Table: "evals"})
+		jobs := make(map[string]string, len(evals))
+		for _, eval := range evals {
+			watcher.Add(watch.Item{Eval: eval.ID})
+			if err := s.nestedUpsertEval(txn, index, eval); err != nil {
+				return err
+			}
+			jobs[eval.JobID] = ""
+		}
+		if err := s.setJobStatuses(index, watcher, txn, jobs, false); err != nil {
+			return fmt.Errorf("setting job status failed: %v", err)
+		}
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
 // UpsertAllocs is used to evict a set of allocations
 // and allocate new ones at the same time.
 func (s *StateStore) UpsertAllocs(index uint64, allocs []*structs.Allocation) error {
@@ -790,19 +1187,29 @@ func (s *StateStore) UpsertAllocs(index uint64, allocs []*structs.Allocation) er
 			return fmt.Errorf("alloc lookup failed: %v", err)
 		}
 
+		var exist *structs.Allocation
 		if existing == nil {
 			alloc.CreateIndex = index
 			alloc.ModifyIndex = index
 		} else {
-			exist := existing.(*structs.Allocation)
+			exist = existing.(*structs.Allocation)
 			alloc.CreateIndex = exist.CreateIndex
 			alloc.ModifyIndex = index
 			alloc.ClientStatus = exist.ClientStatus
 			alloc.ClientDescription = exist.ClientDescription
+
+			// The client of a down node will never report that the
+			// allocations it is asked to stop are stopped
+			if err := s.markAllocLost(txn, alloc); err != nil {
+				return err
+			}
 		}
 		if err := txn.Insert("allocs", alloc); err != nil {
 			return fmt.Errorf("alloc insert failed: %v", err)
 		}
+		if err := s.updateSummaryWithAlloc(index, watcher, txn, exist, alloc); err != nil {
+			return err
+		}
 
 		// If the allocation is running, force the job to running status.
 		forceStatus := ""
@@ -934,27 +1341,418 @@ func (s *StateStore) Allocs() (memdb.ResultIterator, error) {
 	return iter, nil
 }
 
-// Index finds the matching index value
-func (s *StateStore) Index(name string) (uint64, error) {
-	txn := s.db.Txn(false)
+// UpsertVariable is used to create or update an encrypted variable
+func (s *StateStore) UpsertVariable(index uint64, variable *structs.VariableEncrypted) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
 
-	// Lookup the first matching index
-	out, err := txn.First("index", "id", name)
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "variables"})
+
+	// Check if the variable already exists
+	existing, err := txn.First("variables", "id", variable.Path)
 	if err != nil {
-		return 0, err
+		return fmt.Errorf("variable lookup failed: %v", err)
 	}
-	if out == nil {
-		return 0, nil
+
+	// Setup the indexes correctly
+	if existing != nil {
+		variable.CreateIndex = existing.(*structs.VariableEncrypted).CreateIndex
+		variable.ModifyIndex = index
+	} else {
+		variable.CreateIndex = index
+		variable.ModifyIndex = index
 	}
-	return out.(*IndexEntry).Value, nil
+
+	// Insert the variable
+	if err := txn.Insert("variables", variable); err != nil {
+		return fmt.Errorf("variable insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"variables", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
 }
 
-// Indexes returns an iterator over all the indexes
-func (s *StateStore) Indexes() (memdb.ResultIterator, error) {
-	txn := s.db.Txn(false)
+// DeleteVariable is used to delete the variable at the given path
+func (s *StateStore) DeleteVariable(index uint64, path string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
 
-	// Walk the entire nodes table
-	iter, err := txn.Get("index", "id")
+	// Lookup the variable
+	existing, err := txn.First("variables", "id", path)
+	if err != nil {
+		return fmt.Errorf("variable lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("variable not found")
+	}
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "variables"})
+
+	// Delete the variable
+	if err := txn.Delete("variables", existing); err != nil {
+		return fmt.Errorf("variable delete failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"variables", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// VariableByPath is used to lookup an encrypted variable by its path
+func (s *StateStore) VariableByPath(path string) (*structs.VariableEncrypted, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("variables", "id", path)
+	if err != nil {
+		return nil, fmt.Errorf("variable lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.VariableEncrypted), nil
+	}
+	return nil, nil
+}
+
+// VariablesByPathPrefix is used to lookup the variables whose path starts
+// with the prefix
+func (s *StateStore) VariablesByPathPrefix(prefix string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("variables", "id_prefix", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("variable lookup failed: %v", err)
+	}
+	return iter, nil
+}
+
+// Variables returns an iterator over all the variables
+func (s *StateStore) Variables() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire variables table
+	iter, err := txn.Get("variables", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// UpsertRootKeyMeta is used to add the metadata of a key to the keyring or
+// update it. If the key is active, any other active key is deactivated.
+func (s *StateStore) UpsertRootKeyMeta(index uint64, key *structs.RootKeyMeta) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "root_keys"})
+
+	// Check if the key already exists
+	existing, err := txn.First("root_keys", "id", key.KeyID)
+	if err != nil {
+		return fmt.Errorf("root key lookup failed: %v", err)
+	}
+
+	// Setup the indexes correctly
+	if existing != nil {
+		key.CreateIndex = existing.(*structs.RootKeyMeta).CreateIndex
+		key.ModifyIndex = index
+	} else {
+		key.CreateIndex = index
+		key.ModifyIndex = index
+	}
+
+	// Deactivate the previously active keys
+	if key.Active() {
+		iter, err := txn.Get("root_keys", "active", true)
+		if err != nil {
+			return fmt.Errorf("root key lookup failed: %v", err)
+		}
+		var active []*structs.RootKeyMeta
+		for {
+			raw := iter.Next()
+			if raw == nil {
+				break
+			}
+			if k := raw.(*structs.RootKeyMeta); k.KeyID != key.KeyID {
+				active = append(active, k)
+			}
+		}
+		for _, k := range active {
+			inactive := new(structs.RootKeyMeta)
+			*inactive = *k
+			inactive.State = structs.RootKeyStateInactive
+			inactive.ModifyIndex = index
+			if err := txn.Insert("root_keys", inactive); err != nil {
+				return fmt.Errorf("root key insert failed: %v", err)
+			}
+		}
+	}
+
+	// Insert the key
+	if err := txn.Insert("root_keys", key); err != nil {
+		return fmt.Errorf("root key insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"root_keys", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// RootKeyMetaByID is used to lookup the metadata of a root key by its ID
+func (s *StateStore) RootKeyMetaByID(id string) (*structs.RootKeyMeta, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("root_keys", "id", id)
+	if err != nil {
+		return nil, fmt.Errorf("root key lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.RootKeyMeta), nil
+	}
+	return nil, nil
+}
+
+// ActiveRootKeyMeta returns the metadata of the key new variables are
+// encrypted with, or nil if the keyring has not been initialized.
+func (s *StateStore) ActiveRootKeyMeta() (*structs.RootKeyMeta, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("root_keys", "active", true)
+	if err != nil {
+		return nil, fmt.Errorf("root key lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.RootKeyMeta), nil
+	}
+	return nil, nil
+}
+
+// RootKeyMetas returns an iterator over the metadata of all the root keys
+func (s *StateStore) RootKeyMetas() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire root keys table
+	iter, err := txn.Get("root_keys", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// UpsertServiceRegistrations is used to register service instances
+func (s *StateStore) UpsertServiceRegistrations(index uint64, services []*structs.ServiceRegistration) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "service_registrations"})
+
+	for _, service := range services {
+		// Check if the service already exists
+		existing, err := txn.First("service_registrations", "id", service.ID)
+		if err != nil {
+			return fmt.Errorf("service registration lookup failed: %v", err)
+		}
+
+		// Setup the indexes correctly
+		if existing != nil {
+			exist := existing.(*structs.ServiceRegistration)
+			if exist.Equals(service) {
+				continue
+			}
+			service.CreateIndex = exist.CreateIndex
+			service.ModifyIndex = index
+		} else {
+			service.CreateIndex = index
+			service.ModifyIndex = index
+		}
+
+		// Insert the service
+		if err := txn.Insert("service_registrations", service); err != nil {
+			return fmt.Errorf("service registration insert failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"service_registrations", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// DeleteServiceRegistrations is used to deregister service instances by ID.
+// Unknown IDs are ignored as clients may retry deregistrations.
+func (s *StateStore) DeleteServiceRegistrations(index uint64, ids []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "service_registrations"})
+
+	for _, id := range ids {
+		existing, err := txn.First("service_registrations", "id", id)
+		if err != nil {
+			return fmt.Errorf("service registration lookup failed: %v", err)
+		}
+		if existing == nil {
+			continue
+		}
+		if err := txn.Delete("service_registrations", existing); err != nil {
+			return fmt.Errorf("service registration delete failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"service_registrations", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// deleteServiceRegistrations removes the service instances matching the
+// value of the index within the transaction.
+func (s *StateStore) deleteServiceRegistrations(index uint64, watcher watch.Items,
+	txn *memdb.Txn, indexName, value string) error {
+	iter, err := txn.Get("service_registrations", indexName, value)
+	if err != nil {
+		return fmt.Errorf("service registration lookup failed: %v", err)
+	}
+
+	var services []interface{}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		services = append(services, raw)
+	}
+	if len(services) == 0 {
+		return nil
+	}
+
+	// Delete after iterating as modifying the table invalidates the iterator
+	for _, service := range services {
+		if err := txn.Delete("service_registrations", service); err != nil {
+			return fmt.Errorf("service registration delete failed: %v", err)
+		}
+	}
+	watcher.Add(watch.Item{Table: "service_registrations"})
+	if err := txn.Insert("index", &IndexEntry{"service_registrations", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// ServiceRegistrationByID is used to lookup a service instance by its ID
+func (s *StateStore) ServiceRegistrationByID(id string) (*structs.ServiceRegistration, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("service_registrations", "id", id)
+	if err != nil {
+		return nil, fmt.Errorf("service registration lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.ServiceRegistration), nil
+	}
+	return nil, nil
+}
+
+// ServiceRegistrationsByName is used to lookup the instances of a service
+func (s *StateStore) ServiceRegistrationsByName(name string) ([]*structs.ServiceRegistration, error) {
+	return s.serviceRegistrationsBy("service", name)
+}
+
+// ServiceRegistrationsByNamePrefix is used to lookup the service instances
+// whose service name starts with the prefix. The instances are ordered by
+// service name.
+func (s *StateStore) ServiceRegistrationsByNamePrefix(prefix string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("service_registrations", "service_prefix", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("service registration lookup failed: %v", err)
+	}
+	return iter, nil
+}
+
+// ServiceRegistrationsByAlloc is used to lookup the services of an allocation
+func (s *StateStore) ServiceRegistrationsByAlloc(allocID string) ([]*structs.ServiceRegistration, error) {
+	return s.serviceRegistrationsBy("alloc", allocID)
+}
+
+// ServiceRegistrationsByNode is used to lookup the services running on a node
+func (s *StateStore) ServiceRegistrationsByNode(nodeID string) ([]*structs.ServiceRegistration, error) {
+	return s.serviceRegistrationsBy("node", nodeID)
+}
+
+// serviceRegistrationsBy returns the service instances matching the value of
+// the index
+func (s *StateStore) serviceRegistrationsBy(indexName, value string) ([]*structs.ServiceRegistration, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("service_registrations", indexName, value)
+	if err != nil {
+		return nil, fmt.Errorf("service registration lookup failed: %v", err)
+	}
+
+	var out []*structs.ServiceRegistration
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		out = append(out, raw.(*structs.ServiceRegistration))
+	}
+	return out, nil
+}
+
+// ServiceRegistrations returns an iterator over all the service instances,
+// ordered by their ID
+func (s *StateStore) ServiceRegistrations() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire service registrations table
+	iter, err := txn.Get("service_registrations", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// Index finds the matching index value
+func (s *StateStore) Index(name string) (uint64, error) {
+	txn := s.db.Txn(false)
+
+	// Lookup the first matching index
+	out, err := txn.First("index", "id", name)
+	if err != nil {
+		return 0, err
+	}
+	if out == nil {
+		return 0, nil
+	}
+	return out.(*IndexEntry).Value, nil
+}
+
+// Indexes returns an iterator over all the indexes
+func (s *StateStore) Indexes() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire nodes table
+	iter, err := txn.Get("index", "id")
 	if err != nil {
 		return nil, err
 	}
@@ -1026,7 +1824,7 @@ func (s *StateStore) setJobStatus(index uint64, watcher watch.Items, txn *memdb.
 	if err := txn.Insert("index", &IndexEntry{"jobs", index}); err != nil {
 		return fmt.Errorf("index update failed: %v", err)
 	}
-	return nil
+	return s.updateParentSummary(index, watcher, txn, job.ParentID, oldStatus, newStatus)
 }
 
 func (s *StateStore) getJobStatus(txn *memdb.Txn, job *structs.Job, evalDelete bool) (string, error) {
@@ -1072,6 +1870,208 @@ func (s *StateStore) getJobStatus(txn *memdb.Txn, job *structs.Job, evalDelete b
 	return structs.JobStatusPending, nil
 }
 
+// markAllocLost sets the client status of an allocation being stopped to
+// lost if its node is down or gone
+func (s *StateStore) markAllocLost(txn *memdb.Txn, alloc *structs.Allocation) error {
+	switch alloc.DesiredStatus {
+	case structs.AllocDesiredStatusStop, structs.AllocDesiredStatusEvict:
+	default:
+		return nil
+	}
+	switch alloc.ClientStatus {
+	case structs.AllocClientStatusDead, structs.AllocClientStatusFailed, structs.AllocClientStatusLost:
+		return nil
+	}
+
+	node, err := txn.First("nodes", "id", alloc.NodeID)
+	if err != nil {
+		return fmt.Errorf("node lookup failed: %v", err)
+	}
+	if node != nil && node.(*structs.Node).Status != structs.NodeStatusDown {
+		return nil
+	}
+	alloc.ClientStatus = structs.AllocClientStatusLost
+	alloc.ClientDescription = "alloc is lost since its node is down"
+	return nil
+}
+
+// updateSummaryWithJob creates the summary of a job or updates its task
+// groups when the job is updated
+func (s *StateStore) updateSummaryWithJob(index uint64, watcher watch.Items,
+	txn *memdb.Txn, job *structs.Job) error {
+	existing, err := txn.First("job_summary", "id", job.ID)
+	if err != nil {
+		return fmt.Errorf("job summary lookup failed: %v", err)
+	}
+
+	var summary *structs.JobSummary
+	if existing != nil {
+		summary = existing.(*structs.JobSummary).Copy()
+	} else {
+		summary = &structs.JobSummary{
+			JobID:       job.ID,
+			Summary:     make(map[string]structs.TaskGroupSummary),
+			CreateIndex: index,
+		}
+		if job.IsPeriodic() {
+			summary.Children = new(structs.JobChildrenSummary)
+		}
+	}
+
+	// Only keep the task groups of the current version of the job
+	groups := make(map[string]structs.TaskGroupSummary, len(job.TaskGroups))
+	for _, tg := range job.TaskGroups {
+		tgSummary := summary.Summary[tg.Name]
+		tgSummary.Queued = queuedAllocs(job, tg.Name, tgSummary)
+		groups[tg.Name] = tgSummary
+	}
+	summary.Summary = groups
+	summary.ModifyIndex = index
+	return s.insertJobSummary(index, watcher, txn, summary)
+}
+
+// updateSummaryWithAlloc moves an allocation between the counts of the
+// summary of its job when its client status changes. The previous version
+// of the allocation is nil if it is new.
+func (s *StateStore) updateSummaryWithAlloc(index uint64, watcher watch.Items,
+	txn *memdb.Txn, previous, alloc *structs.Allocation) error {
+	from, to := "", summaryStatus(alloc)
+	if previous != nil {
+		from = summaryStatus(previous)
+	}
+	if from == to {
+		return nil
+	}
+
+	// Allocations of jobs that are gone are no longer summarized
+	existing, err := txn.First("job_summary", "id", alloc.JobID)
+	if err != nil {
+		return fmt.Errorf("job summary lookup failed: %v", err)
+	}
+	if existing == nil {
+		return nil
+	}
+	job, err := txn.First("jobs", "id", alloc.JobID)
+	if err != nil {
+		return fmt.Errorf("job lookup failed: %v", err)
+	}
+	if job == nil {
+		return nil
+	}
+
+	summary := existing.(*structs.JobSummary).Copy()
+	tgSummary, ok := summary.Summary[alloc.TaskGroup]
+	if !ok {
+		// Allocations of task groups removed from the job are not counted
+		return nil
+	}
+	adjustTaskGroupSummary(&tgSummary, from, -1)
+	adjustTaskGroupSummary(&tgSummary, to, 1)
+	tgSummary.Queued = queuedAllocs(job.(*structs.Job), alloc.TaskGroup, tgSummary)
+	summary.Summary[alloc.TaskGroup] = tgSummary
+	summary.ModifyIndex = index
+	return s.insertJobSummary(index, watcher, txn, summary)
+}
+
+// updateParentSummary moves a child job between the children counts of the
+// summary of its parent when its status changes. An empty status means the
+// job is new or deleted.
+func (s *StateStore) updateParentSummary(index uint64, watcher watch.Items,
+	txn *memdb.Txn, parentID, from, to string) error {
+	if parentID == "" || from == to {
+		return nil
+	}
+	existing, err := txn.First("job_summary", "id", parentID)
+	if err != nil {
+		return fmt.Errorf("job summary lookup failed: %v", err)
+	}
+	if existing == nil {
+		return nil
+	}
+
+	summary := existing.(*structs.JobSummary).Copy()
+	if summary.Children == nil {
+		summary.Children = new(structs.JobChildrenSummary)
+	}
+	adjustChildrenSummary(summary.Children, from, -1)
+	adjustChildrenSummary(summary.Children, to, 1)
+	summary.ModifyIndex = index
+	return s.insertJobSummary(index, watcher, txn, summary)
+}
+
+// insertJobSummary inserts the summary and updates the index
+func (s *StateStore) insertJobSummary(index uint64, watcher watch.Items,
+	txn *memdb.Txn, summary *structs.JobSummary) error {
+	watcher.Add(watch.Item{Table: "job_summary"})
+	watcher.Add(watch.Item{JobSummary: summary.JobID})
+	if err := txn.Insert("job_summary", summary); err != nil {
+		return fmt.Errorf("job summary insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// summaryStatus returns the client status under which an allocation is
+// counted in the summary of its job. Failed placements are not counted.
+func summaryStatus(alloc *structs.Allocation) string {
+	if alloc.DesiredStatus == structs.AllocDesiredStatusFailed {
+		return ""
+	}
+	if alloc.ClientStatus == structs.AllocClientStatusUnknown {
+		return structs.AllocClientStatusRunning
+	}
+	return alloc.ClientStatus
+}
+
+func adjustTaskGroupSummary(summary *structs.TaskGroupSummary, status string, delta int) {
+	switch status {
+	case structs.AllocClientStatusPending:
+		summary.Starting += delta
+	case structs.AllocClientStatusRunning:
+		summary.Running += delta
+	case structs.AllocClientStatusDead:
+		summary.Complete += delta
+	case structs.AllocClientStatusFailed:
+		summary.Failed += delta
+	case structs.AllocClientStatusLost:
+		summary.Lost += delta
+	}
+}
+
+func adjustChildrenSummary(summary *structs.JobChildrenSummary, status string, delta int) {
+	switch status {
+	case structs.JobStatusPending:
+		summary.Pending += delta
+	case structs.JobStatusRunning:
+		summary.Running += delta
+	case structs.JobStatusDead:
+		summary.Dead += delta
+	}
+}
+
+// queuedAllocs returns the number of allocations a task group still needs
+// to reach its count. Completed allocations of batch jobs count towards it,
+// and system jobs have no count to reach.
+func queuedAllocs(job *structs.Job, taskGroup string, summary structs.TaskGroupSummary) int {
+	if job.Type == structs.JobTypeSystem {
+		return 0
+	}
+	tg := job.LookupTaskGroup(taskGroup)
+	if tg == nil {
+		return 0
+	}
+	queued := tg.Count - summary.Starting - summary.Running
+	if job.Type == structs.JobTypeBatch {
+		queued -= summary.Complete
+	}
+	if queued < 0 {
+		return 0
+	}
+	return queued
+}
+
 // StateSnapshot is used to provide a point-in-time snapshot
 type StateSnapshot struct {
 	StateStore
@@ -1117,6 +2117,16 @@ func (r *StateRestore) JobRestore(job *structs.Job) error {
 	return nil
 }
 
+// JobSummaryRestore is used to restore a job summary
+func (r *StateRestore) JobSummaryRestore(summary *structs.JobSummary) error {
+	r.items.Add(watch.Item{Table: "job_summary"})
+	r.items.Add(watch.Item{JobSummary: summary.JobID})
+	if err := r.txn.Insert("job_summary", summary); err != nil {
+		return fmt.Errorf("job summary insert failed: %v", err)
+	}
+	return nil
+}
+
 // EvalRestore is used to restore an evaluation
 func (r *StateRestore) EvalRestore(eval *structs.Evaluation) error {
 	r.items.Add(watch.Item{Table: "evals"})
@@ -1158,6 +2168,43 @@ func (r *StateRestore) PeriodicLaunchRestore(launch *structs.PeriodicLaunch) err
 	return nil
 }
 
+// MultiregionRolloutRestore is used to restore a multi-region rollout.
+func (r *StateRestore) MultiregionRolloutRestore(rollout *structs.MultiregionRollout) error {
+	r.items.Add(watch.Item{Table: "multiregion_rollouts"})
+	r.items.Add(watch.Item{MultiregionRollout: rollout.JobID})
+	if err := r.txn.Insert("multiregion_rollouts", rollout); err != nil {
+		return fmt.Errorf("multi-region rollout insert failed: %v", err)
+	}
+	return nil
+}
+
+// VariableRestore is used to restore an encrypted variable
+func (r *StateRestore) VariableRestore(variable *structs.VariableEncrypted) error {
+	r.items.Add(watch.Item{Table: "variables"})
+	if err := r.txn.Insert("variables", variable); err != nil {
+		return fmt.Errorf("variable insert failed: %v", err)
+	}
+	return nil
+}
+
+// RootKeyMetaRestore is used to restore the metadata of a root key
+func (r *StateRestore) RootKeyMetaRestore(key *structs.RootKeyMeta) error {
+	r.items.Add(watch.Item{Table: "root_keys"})
+	if err := r.txn.Insert("root_keys", key); err != nil {
+		return fmt.Errorf("root key insert failed: %v", err)
+	}
+	return nil
+}
+
+// ServiceRegistrationRestore is used to restore a service instance
+func (r *StateRestore) ServiceRegistrationRestore(service *structs.ServiceRegistration) error {
+	r.items.Add(watch.Item{Table: "service_registrations"})
+	if err := r.txn.Insert("service_registrations", service); err != nil {
+		return fmt.Errorf("service registration insert failed: %v", err)
+	}
+	return nil
+}
+
 // stateWatch holds shared state for watching updates. This is
 // outside of StateStore so it can be shared with snapshots.
 type stateWatch struct {
@@ -111,7 +111,7 @@ func TestStateStore_UpdateNodeStatus_Node(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	err = state.UpdateNodeStatus(1001, node.ID, structs.NodeStatusReady)
+	err = state.UpdateNodeStatus(1001, node.ID, structs.NodeStatusReady, 0)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -139,6 +139,73 @@ func TestStateStore_UpdateNodeStatus_Node(t *testing.T) {
 	notify.verify(t)
 }
 
+func TestStateStore_UpdateNodeStatus_Disconnect(t *testing.T) {
+	state := testStateStore(t)
+	node := mock.Node()
+	err := state.UpsertNode(1000, node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Only the first alloc tolerates client disconnects
+	tolerant := mock.Alloc()
+	tolerant.NodeID = node.ID
+	tolerant.ClientStatus = structs.AllocClientStatusRunning
+	tolerant.Job.TaskGroups[0].MaxClientDisconnect = time.Hour
+	intolerant := mock.Alloc()
+	intolerant.NodeID = node.ID
+	intolerant.ClientStatus = structs.AllocClientStatusRunning
+	err = state.UpsertAllocs(1001, []*structs.Allocation{tolerant, intolerant})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "allocs"},
+		watch.Item{Alloc: tolerant.ID},
+		watch.Item{AllocNode: node.ID})
+
+	err = state.UpdateNodeStatus(1002, node.ID, structs.NodeStatusDown, 1234)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.StatusUpdatedAt != 1234 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	outA, err := state.AllocByID(tolerant.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if outA.ClientStatus != structs.AllocClientStatusUnknown || outA.ModifyIndex != 1002 {
+		t.Fatalf("bad: %#v", outA)
+	}
+
+	outA, err = state.AllocByID(intolerant.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if outA.ClientStatus != structs.AllocClientStatusRunning {
+		t.Fatalf("bad: %#v", outA)
+	}
+
+	index, err := state.Index("allocs")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1002 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
 func TestStateStore_UpdateNodeDrain_Node(t *testing.T) {
 	state := testStateStore(t)
 	node := mock.Node()
@@ -153,7 +220,10 @@ func TestStateStore_UpdateNodeDrain_Node(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	err = state.UpdateNodeDrain(1001, node.ID, true)
+	drain := &structs.DrainStrategy{
+		DrainSpec: structs.DrainSpec{Deadline: time.Hour},
+	}
+	err = state.UpdateNodeDrain(1001, node.ID, drain, false)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -163,7 +233,7 @@ func TestStateStore_UpdateNodeDrain_Node(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	if !out.Drain {
+	if !out.Drain || out.DrainStrategy == nil || out.SchedulingEligibility != structs.NodeSchedulingIneligible {
 		t.Fatalf("bad: %#v", out)
 	}
 	if out.ModifyIndex != 1001 {
@@ -179,6 +249,118 @@ func TestStateStore_UpdateNodeDrain_Node(t *testing.T) {
 	}
 
 	notify.verify(t)
+
+	// Stopping the drain keeps the node ineligible unless asked otherwise
+	if err := state.UpdateNodeDrain(1002, node.ID, nil, false); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, _ = state.NodeByID(node.ID)
+	if out.Drain || out.DrainStrategy != nil || out.SchedulingEligibility != structs.NodeSchedulingIneligible {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	if err := state.UpdateNodeDrain(1003, node.ID, drain, false); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpdateNodeDrain(1004, node.ID, nil, true); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, _ = state.NodeByID(node.ID)
+	if out.Drain || out.SchedulingEligibility != structs.NodeSchedulingEligible {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestStateStore_UpdateNodeEligibility(t *testing.T) {
+	state := testStateStore(t)
+	node := mock.Node()
+
+	if err := state.UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "nodes"},
+		watch.Item{Node: node.ID})
+
+	if err := state.UpdateNodeEligibility(1001, node.ID, structs.NodeSchedulingIneligible); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.NodeByID(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SchedulingEligibility != structs.NodeSchedulingIneligible || out.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", out)
+	}
+	notify.verify(t)
+
+	// Re-registering the node keeps its eligibility
+	node2 := mock.Node()
+	node2.ID = node.ID
+	if err := state.UpsertNode(1002, node2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, _ = state.NodeByID(node.ID)
+	if out.SchedulingEligibility != structs.NodeSchedulingIneligible {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// A draining node can not be marked eligible
+	drain := &structs.DrainStrategy{}
+	if err := state.UpdateNodeDrain(1003, node.ID, drain, false); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpdateNodeEligibility(1004, node.ID, structs.NodeSchedulingEligible); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestStateStore_UpdateAllocsDesiredTransitions(t *testing.T) {
+	state := testStateStore(t)
+	alloc := mock.Alloc()
+
+	if err := state.UpsertJob(999, alloc.Job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertAllocs(1000, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "allocs"},
+		watch.Item{Alloc: alloc.ID},
+		watch.Item{AllocNode: alloc.NodeID},
+		watch.Item{Table: "evals"})
+
+	eval := mock.Eval()
+	eval.JobID = alloc.JobID
+	transitions := map[string]*structs.DesiredTransition{
+		alloc.ID: &structs.DesiredTransition{Migrate: true},
+	}
+	if err := state.UpdateAllocsDesiredTransitions(1001, transitions, []*structs.Evaluation{eval}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.AllocByID(alloc.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !out.DesiredTransition.ShouldMigrate() || out.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	outEval, err := state.EvalByID(eval.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if outEval == nil || outEval.CreateIndex != 1001 {
+		t.Fatalf("bad: %#v", outEval)
+	}
+	notify.verify(t)
 }
 
 func TestStateStore_Nodes(t *testing.T) {
@@ -766,158 +948,513 @@ func TestStateStore_RestoreJob(t *testing.T) {
 	notify.verify(t)
 }
 
-func TestStateStore_UpsertPeriodicLaunch(t *testing.T) {
+func TestStateStore_JobSummary(t *testing.T) {
 	state := testStateStore(t)
 	job := mock.Job()
-	launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
 
 	notify := setupNotifyTest(
 		state,
-		watch.Item{Table: "periodic_launch"},
-		watch.Item{Job: job.ID})
+		watch.Item{Table: "job_summary"},
+		watch.Item{JobSummary: job.ID})
 
-	err := state.UpsertPeriodicLaunch(1000, launch)
+	if err := state.UpsertJob(1000, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	summary, err := state.JobSummaryByID(job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
+	expected := &structs.JobSummary{
+		JobID: job.ID,
+		Summary: map[string]structs.TaskGroupSummary{
+			"web": structs.TaskGroupSummary{Queued: 10},
+		},
+		CreateIndex: 1000,
+		ModifyIndex: 1000,
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Fatalf("bad: %#v %#v", summary, expected)
+	}
 
-	out, err := state.PeriodicLaunchByID(job.ID)
+	// Place two allocations
+	alloc1 := mock.Alloc()
+	alloc1.Job = job
+	alloc1.JobID = job.ID
+	alloc2 := mock.Alloc()
+	alloc2.Job = job
+	alloc2.JobID = job.ID
+	if err := state.UpsertAllocs(1001, []*structs.Allocation{alloc1, alloc2}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The client starts one and fails the other
+	update1 := new(structs.Allocation)
+	*update1 = *alloc1
+	update1.ClientStatus = structs.AllocClientStatusRunning
+	if err := state.UpdateAllocFromClient(1002, update1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	update2 := new(structs.Allocation)
+	*update2 = *alloc2
+	update2.ClientStatus = structs.AllocClientStatusFailed
+	if err := state.UpdateAllocFromClient(1003, update2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	summary, err = state.JobSummaryByID(job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if out.CreateIndex != 1000 {
-		t.Fatalf("bad: %#v", out)
+	expected.Summary["web"] = structs.TaskGroupSummary{
+		Queued:  9,
+		Running: 1,
+		Failed:  1,
 	}
-	if out.ModifyIndex != 1000 {
-		t.Fatalf("bad: %#v", out)
+	expected.ModifyIndex = 1003
+	if !reflect.DeepEqual(summary, expected) {
+		t.Fatalf("bad: %#v %#v", summary, expected)
 	}
 
-	if !reflect.DeepEqual(launch, out) {
-		t.Fatalf("bad: %#v %#v", job, out)
+	// Removing the task group from the job drops its counts
+	job2 := job.Copy()
+	job2.TaskGroups[0].Name = "api"
+	if err := state.UpsertJob(1004, job2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	summary, err = state.JobSummaryByID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected.Summary = map[string]structs.TaskGroupSummary{
+		"api": structs.TaskGroupSummary{Queued: 10},
+	}
+	expected.ModifyIndex = 1004
+	if !reflect.DeepEqual(summary, expected) {
+		t.Fatalf("bad: %#v %#v", summary, expected)
 	}
 
-	index, err := state.Index("periodic_launch")
+	// Deleting the job deletes its summary
+	if err := state.DeleteJob(1005, job.ID); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	summary, err = state.JobSummaryByID(job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if index != 1000 {
+	if summary != nil {
+		t.Fatalf("bad: %#v", summary)
+	}
+
+	index, err := state.Index("job_summary")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1005 {
 		t.Fatalf("bad: %d", index)
 	}
 
 	notify.verify(t)
 }
 
-func TestStateStore_UpdateUpsertPeriodicLaunch(t *testing.T) {
+func TestStateStore_JobSummary_Lost(t *testing.T) {
 	state := testStateStore(t)
+	node := mock.Node()
 	job := mock.Job()
-	launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
-
-	notify := setupNotifyTest(
-		state,
-		watch.Item{Table: "periodic_launch"},
-		watch.Item{Job: job.ID})
 
-	err := state.UpsertPeriodicLaunch(1000, launch)
-	if err != nil {
+	if err := state.UpsertNode(999, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertJob(1000, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	alloc := mock.Alloc()
+	alloc.Job = job
+	alloc.JobID = job.ID
+	alloc.NodeID = node.ID
+	alloc.ClientStatus = structs.AllocClientStatusRunning
+	if err := state.UpsertAllocs(1001, []*structs.Allocation{alloc}); err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
-	launch2 := &structs.PeriodicLaunch{
-		ID:     job.ID,
-		Launch: launch.Launch.Add(1 * time.Second),
+	// Stop the allocation once its node is down
+	if err := state.UpdateNodeStatus(1002, node.ID, structs.NodeStatusDown, 0); err != nil {
+		t.Fatalf("err: %v", err)
 	}
-	err = state.UpsertPeriodicLaunch(1001, launch2)
-	if err != nil {
+	stop := new(structs.Allocation)
+	*stop = *alloc
+	stop.DesiredStatus = structs.AllocDesiredStatusStop
+	if err := state.UpsertAllocs(1003, []*structs.Allocation{stop}); err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
-	out, err := state.PeriodicLaunchByID(job.ID)
+	out, err := state.AllocByID(alloc.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if out.CreateIndex != 1000 {
-		t.Fatalf("bad: %#v", out)
-	}
-	if out.ModifyIndex != 1001 {
+	if out.ClientStatus != structs.AllocClientStatusLost {
 		t.Fatalf("bad: %#v", out)
 	}
 
-	if !reflect.DeepEqual(launch2, out) {
-		t.Fatalf("bad: %#v %#v", launch2, out)
-	}
-
-	index, err := state.Index("periodic_launch")
+	summary, err := state.JobSummaryByID(job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if index != 1001 {
-		t.Fatalf("bad: %d", index)
+	expected := structs.TaskGroupSummary{Queued: 10, Lost: 1}
+	if !reflect.DeepEqual(summary.Summary["web"], expected) {
+		t.Fatalf("bad: %#v %#v", summary.Summary["web"], expected)
 	}
-
-	notify.verify(t)
 }
 
-func TestStateStore_DeletePeriodicLaunch(t *testing.T) {
+func TestStateStore_JobSummary_Children(t *testing.T) {
 	state := testStateStore(t)
-	job := mock.Job()
-	launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
-
-	notify := setupNotifyTest(
-		state,
-		watch.Item{Table: "periodic_launch"},
-		watch.Item{Job: job.ID})
+	parent := mock.PeriodicJob()
+	if err := state.UpsertJob(1000, parent); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 
-	err := state.UpsertPeriodicLaunch(1000, launch)
-	if err != nil {
+	child := mock.Job()
+	child.ParentID = parent.ID
+	if err := state.UpsertJob(1001, child); err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
-	err = state.DeletePeriodicLaunch(1001, job.ID)
+	summary, err := state.JobSummaryByID(parent.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
+	expected := &structs.JobChildrenSummary{Pending: 1}
+	if !reflect.DeepEqual(summary.Children, expected) {
+		t.Fatalf("bad: %#v %#v", summary.Children, expected)
+	}
 
-	out, err := state.PeriodicLaunchByID(job.ID)
+	// Running the child moves it to running
+	alloc := mock.Alloc()
+	alloc.Job = child
+	alloc.JobID = child.ID
+	if err := state.UpsertAllocs(1002, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	summary, err = state.JobSummaryByID(parent.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-
-	if out != nil {
-		t.Fatalf("bad: %#v %#v", job, out)
+	expected = &structs.JobChildrenSummary{Running: 1}
+	if !reflect.DeepEqual(summary.Children, expected) {
+		t.Fatalf("bad: %#v %#v", summary.Children, expected)
 	}
 
-	index, err := state.Index("periodic_launch")
+	// Deleting the child removes it from the counts
+	if err := state.DeleteJob(1003, child.ID); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	summary, err = state.JobSummaryByID(parent.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if index != 1001 {
-		t.Fatalf("bad: %d", index)
+	expected = &structs.JobChildrenSummary{}
+	if !reflect.DeepEqual(summary.Children, expected) {
+		t.Fatalf("bad: %#v %#v", summary.Children, expected)
 	}
-
-	notify.verify(t)
 }
 
-func TestStateStore_PeriodicLaunches(t *testing.T) {
+func TestStateStore_ReconcileJobSummaries(t *testing.T) {
 	state := testStateStore(t)
-	var launches []*structs.PeriodicLaunch
-
-	for i := 0; i < 10; i++ {
-		job := mock.Job()
-		launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
-		launches = append(launches, launch)
+	parent := mock.PeriodicJob()
+	child := mock.Job()
+	child.ParentID = parent.ID
+	child.Status = structs.JobStatusRunning
+	alloc := mock.Alloc()
+	alloc.Job = child
+	alloc.JobID = child.ID
+	alloc.ClientStatus = structs.AllocClientStatusRunning
 
-		err := state.UpsertPeriodicLaunch(1000+uint64(i), launch)
-		if err != nil {
+	// Restore the jobs and allocations without summaries, as from a
+	// snapshot that predates them
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, job := range []*structs.Job{parent, child} {
+		if err := restore.JobRestore(job); err != nil {
 			t.Fatalf("err: %v", err)
 		}
 	}
+	if err := restore.AllocRestore(alloc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
 
-	iter, err := state.PeriodicLaunches()
+	if err := state.ReconcileJobSummaries(1000); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	summary, err := state.JobSummaryByID(child.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
+	expected := &structs.JobSummary{
+		JobID: child.ID,
+		Summary: map[string]structs.TaskGroupSummary{
+			"web": structs.TaskGroupSummary{Queued: 9, Running: 1},
+		},
+		CreateIndex: 1000,
+		ModifyIndex: 1000,
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Fatalf("bad: %#v %#v", summary, expected)
+	}
 
-	out := make(map[string]*structs.PeriodicLaunch, 10)
+	summary, err = state.JobSummaryByID(parent.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if summary.Children == nil || summary.Children.Running != 1 {
+		t.Fatalf("bad: %#v", summary.Children)
+	}
+}
+
+func TestStateStore_RestoreJobSummary(t *testing.T) {
+	state := testStateStore(t)
+	summary := &structs.JobSummary{
+		JobID: structs.GenerateUUID(),
+		Summary: map[string]structs.TaskGroupSummary{
+			"web": structs.TaskGroupSummary{Running: 1},
+		},
+		CreateIndex: 1000,
+		ModifyIndex: 1000,
+	}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "job_summary"},
+		watch.Item{JobSummary: summary.JobID})
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	err = restore.JobSummaryRestore(summary)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	out, err := state.JobSummaryByID(summary.JobID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !reflect.DeepEqual(out, summary) {
+		t.Fatalf("Bad: %#v %#v", out, summary)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_UpsertPeriodicLaunch(t *testing.T) {
+	state := testStateStore(t)
+	job := mock.Job()
+	launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "periodic_launch"},
+		watch.Item{Job: job.ID})
+
+	err := state.UpsertPeriodicLaunch(1000, launch)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.PeriodicLaunchByID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out.ModifyIndex != 1000 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	if !reflect.DeepEqual(launch, out) {
+		t.Fatalf("bad: %#v %#v", job, out)
+	}
+
+	index, err := state.Index("periodic_launch")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1000 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_UpsertMultiregionRollout(t *testing.T) {
+	state := testStateStore(t)
+	job := mock.Job()
+	job.Multiregion = &structs.Multiregion{
+		Regions: []*structs.MultiregionRegion{
+			&structs.MultiregionRegion{Name: "east"},
+			&structs.MultiregionRegion{Name: "west"},
+		},
+	}
+	rollout := structs.NewMultiregionRollout(job)
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "multiregion_rollouts"},
+		watch.Item{MultiregionRollout: job.ID})
+
+	if err := state.UpsertMultiregionRollout(1000, rollout); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Record the progress of the first region
+	update := rollout.Copy()
+	update.Regions[0].Status = structs.MultiregionStatusComplete
+	if err := state.UpsertMultiregionRollout(1001, update); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.MultiregionRolloutByJobID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 || out.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out.Regions[0].Status != structs.MultiregionStatusComplete ||
+		out.Regions[1].Status != structs.MultiregionStatusPending {
+		t.Fatalf("bad: %#v", out.Regions)
+	}
+
+	index, err := state.Index("multiregion_rollouts")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1001 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_UpdateUpsertPeriodicLaunch(t *testing.T) {
+	state := testStateStore(t)
+	job := mock.Job()
+	launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "periodic_launch"},
+		watch.Item{Job: job.ID})
+
+	err := state.UpsertPeriodicLaunch(1000, launch)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	launch2 := &structs.PeriodicLaunch{
+		ID:     job.ID,
+		Launch: launch.Launch.Add(1 * time.Second),
+	}
+	err = state.UpsertPeriodicLaunch(1001, launch2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.PeriodicLaunchByID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	if !reflect.DeepEqual(launch2, out) {
+		t.Fatalf("bad: %#v %#v", launch2, out)
+	}
+
+	index, err := state.Index("periodic_launch")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1001 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_DeletePeriodicLaunch(t *testing.T) {
+	state := testStateStore(t)
+	job := mock.Job()
+	launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "periodic_launch"},
+		watch.Item{Job: job.ID})
+
+	err := state.UpsertPeriodicLaunch(1000, launch)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	err = state.DeletePeriodicLaunch(1001, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.PeriodicLaunchByID(job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if out != nil {
+		t.Fatalf("bad: %#v %#v", job, out)
+	}
+
+	index, err := state.Index("periodic_launch")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1001 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_PeriodicLaunches(t *testing.T) {
+	state := testStateStore(t)
+	var launches []*structs.PeriodicLaunch
+
+	for i := 0; i < 10; i++ {
+		job := mock.Job()
+		launch := &structs.PeriodicLaunch{ID: job.ID, Launch: time.Now()}
+		launches = append(launches, launch)
+
+		err := state.UpsertPeriodicLaunch(1000+uint64(i), launch)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	iter, err := state.PeriodicLaunches()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out := make(map[string]*structs.PeriodicLaunch, 10)
 	for {
 		raw := iter.Next()
 		if raw == nil {
@@ -982,6 +1519,341 @@ func TestStateStore_RestorePeriodicLaunch(t *testing.T) {
 	notify.verify(t)
 }
 
+func TestStateStore_Variables(t *testing.T) {
+	state := testStateStore(t)
+
+	v1 := &structs.VariableEncrypted{Path: "app/db", KeyID: "key", Data: []byte("one")}
+	v2 := &structs.VariableEncrypted{Path: "app/cache", KeyID: "key", Data: []byte("two")}
+	v3 := &structs.VariableEncrypted{Path: "other", KeyID: "key", Data: []byte("three")}
+
+	notify := setupNotifyTest(state, watch.Item{Table: "variables"})
+
+	for i, v := range []*structs.VariableEncrypted{v1, v2, v3} {
+		if err := state.UpsertVariable(uint64(1000+i), v); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	// Update the first variable
+	update := &structs.VariableEncrypted{Path: "app/db", KeyID: "key", Data: []byte("four")}
+	if err := state.UpsertVariable(1003, update); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.VariableByPath("app/db")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 || out.ModifyIndex != 1003 {
+		t.Fatalf("bad: %#v", out)
+	}
+	if !reflect.DeepEqual(update, out) {
+		t.Fatalf("bad: %#v %#v", update, out)
+	}
+
+	iter, err := state.VariablesByPathPrefix("app/")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var paths []string
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		paths = append(paths, raw.(*structs.VariableEncrypted).Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"app/cache", "app/db"}) {
+		t.Fatalf("bad: %v", paths)
+	}
+
+	if err := state.DeleteVariable(1004, "app/db"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = state.VariableByPath("app/db")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("bad: %#v", out)
+	}
+	if err := state.DeleteVariable(1005, "app/db"); err == nil {
+		t.Fatalf("expected error deleting missing variable")
+	}
+
+	index, err := state.Index("variables")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1004 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_UpsertRootKeyMeta(t *testing.T) {
+	state := testStateStore(t)
+
+	// The keyring starts out empty
+	active, err := state.ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if active != nil {
+		t.Fatalf("bad: %#v", active)
+	}
+
+	k1 := &structs.RootKeyMeta{KeyID: structs.GenerateUUID(), State: structs.RootKeyStateActive}
+	if err := state.UpsertRootKeyMeta(1000, k1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Adding a new active key deactivates the old one
+	k2 := &structs.RootKeyMeta{KeyID: structs.GenerateUUID(), State: structs.RootKeyStateActive}
+	if err := state.UpsertRootKeyMeta(1001, k2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	active, err = state.ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if active == nil || active.KeyID != k2.KeyID {
+		t.Fatalf("bad: %#v", active)
+	}
+
+	old, err := state.RootKeyMetaByID(k1.KeyID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if old == nil || old.Active() || old.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", old)
+	}
+}
+
+func TestStateStore_RestoreVariablesAndRootKeys(t *testing.T) {
+	state := testStateStore(t)
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	key := &structs.RootKeyMeta{KeyID: structs.GenerateUUID(), State: structs.RootKeyStateActive}
+	variable := &structs.VariableEncrypted{Path: "app/db", KeyID: key.KeyID, Data: []byte("one")}
+	if err := restore.RootKeyMetaRestore(key); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := restore.VariableRestore(variable); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	outKey, err := state.ActiveRootKeyMeta()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(outKey, key) {
+		t.Fatalf("Bad: %#v %#v", outKey, key)
+	}
+
+	outVar, err := state.VariableByPath("app/db")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(outVar, variable) {
+		t.Fatalf("Bad: %#v %#v", outVar, variable)
+	}
+}
+
+func TestStateStore_ServiceRegistrations(t *testing.T) {
+	state := testStateStore(t)
+
+	s1 := mock.ServiceRegistration()
+	s2 := mock.ServiceRegistration()
+	s3 := mock.ServiceRegistration()
+	s3.ServiceName = "backend"
+
+	notify := setupNotifyTest(state, watch.Item{Table: "service_registrations"})
+
+	services := []*structs.ServiceRegistration{s1, s2, s3}
+	if err := state.UpsertServiceRegistrations(1000, services); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Re-registering an unchanged instance keeps its indexes
+	same := new(structs.ServiceRegistration)
+	*same = *s1
+	moved := new(structs.ServiceRegistration)
+	*moved = *s2
+	moved.Port = 6000
+	update := []*structs.ServiceRegistration{same, moved}
+	if err := state.UpsertServiceRegistrations(1001, update); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.ServiceRegistrationByID(s1.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 || out.ModifyIndex != 1000 {
+		t.Fatalf("bad: %#v", out)
+	}
+	out, err = state.ServiceRegistrationByID(s2.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 || out.ModifyIndex != 1001 || out.Port != 6000 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	frontend, err := state.ServiceRegistrationsByName("frontend")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(frontend) != 2 {
+		t.Fatalf("bad: %#v", frontend)
+	}
+
+	// Deleting unknown IDs is not an error
+	if err := state.DeleteServiceRegistrations(1002, []string{s1.ID, "unknown"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	frontend, err = state.ServiceRegistrationsByName("frontend")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(frontend) != 1 || frontend[0].ID != s2.ID {
+		t.Fatalf("bad: %#v", frontend)
+	}
+
+	index, err := state.Index("service_registrations")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1002 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_ServiceRegistrations_NodeDown(t *testing.T) {
+	state := testStateStore(t)
+	node := mock.Node()
+
+	if err := state.UpsertNode(1000, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	s1 := mock.ServiceRegistration()
+	s1.NodeID = node.ID
+	s2 := mock.ServiceRegistration()
+	services := []*structs.ServiceRegistration{s1, s2}
+	if err := state.UpsertServiceRegistrations(1001, services); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	notify := setupNotifyTest(state, watch.Item{Table: "service_registrations"})
+
+	if err := state.UpdateNodeStatus(1002, node.ID, structs.NodeStatusDown, 0); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.ServiceRegistrationsByNode(node.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Services of other nodes are kept
+	other, err := state.ServiceRegistrationByID(s2.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if other == nil {
+		t.Fatalf("service of other node removed")
+	}
+
+	index, err := state.Index("service_registrations")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1002 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_ServiceRegistrations_AllocStopped(t *testing.T) {
+	state := testStateStore(t)
+	alloc := mock.Alloc()
+
+	if err := state.UpsertAllocs(1000, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	service := mock.ServiceRegistration()
+	service.AllocID = alloc.ID
+	if err := state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{service}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A running allocation keeps its services
+	update := new(structs.Allocation)
+	*update = *alloc
+	update.ClientStatus = structs.AllocClientStatusRunning
+	if err := state.UpdateAllocFromClient(1002, update); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err := state.ServiceRegistrationsByAlloc(alloc.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	update.ClientStatus = structs.AllocClientStatusDead
+	if err := state.UpdateAllocFromClient(1003, update); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = state.ServiceRegistrationsByAlloc(alloc.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestStateStore_RestoreServiceRegistration(t *testing.T) {
+	state := testStateStore(t)
+	service := mock.ServiceRegistration()
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if err := restore.ServiceRegistrationRestore(service); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	out, err := state.ServiceRegistrationByID(service.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out, service) {
+		t.Fatalf("Bad: %#v %#v", out, service)
+	}
+}
+
 func TestStateStore_Indexes(t *testing.T) {
 	state := testStateStore(t)
 	node := mock.Node()
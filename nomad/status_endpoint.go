@@ -61,3 +61,10 @@ func (s *Status) Peers(args *structs.GenericRequest, reply *[]string) error {
 	*reply = peers
 	return nil
 }
+
+// HasNodeConn is used to check whether the server holds the node connection
+// of a client
+func (s *Status) HasNodeConn(args *structs.NodeSpecificRequest, reply *structs.NodeConnQueryResponse) error {
+	reply.Connected = s.srv.nodeConn(args.NodeID) != nil
+	return nil
+}
@@ -73,6 +73,36 @@ func TestNode_ComputedClass(t *testing.T) {
 	}
 }
 
+func TestNode_ComputedClass_HostVolumes(t *testing.T) {
+	// Create a node and gets it computed class
+	n := testNode()
+	if err := n.ComputeClass(); err != nil {
+		t.Fatalf("ComputeClass() failed: %v", err)
+	}
+	old := n.ComputedClass
+
+	// Add a host volume and compute the class again
+	n.HostVolumes = map[string]*ClientHostVolumeConfig{
+		"data": &ClientHostVolumeConfig{Name: "data", Path: "/srv/data"},
+	}
+	if err := n.ComputeClass(); err != nil {
+		t.Fatalf("ComputeClass() failed: %v", err)
+	}
+	if old == n.ComputedClass {
+		t.Fatal("ComputeClass() returned same computed class")
+	}
+	old = n.ComputedClass
+
+	// Making the volume read only changes the class
+	n.HostVolumes["data"].ReadOnly = true
+	if err := n.ComputeClass(); err != nil {
+		t.Fatalf("ComputeClass() failed: %v", err)
+	}
+	if old == n.ComputedClass {
+		t.Fatal("ComputeClass() returned same computed class")
+	}
+}
+
 func TestNode_ComputedClass_Ignore(t *testing.T) {
 	// Create a node and gets it computed class
 	n := testNode()
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -23,6 +24,7 @@ import (
 var (
 	ErrNoLeader     = fmt.Errorf("No cluster leader")
 	ErrNoRegionPath = fmt.Errorf("No path to region")
+	ErrNoNodeConn   = fmt.Errorf("No path to node")
 )
 
 type MessageType uint8
@@ -38,6 +40,14 @@ const (
 	EvalDeleteRequestType
 	AllocUpdateRequestType
 	AllocClientUpdateRequestType
+	VariableUpsertRequestType
+	VariableDeleteRequestType
+	RootKeyUpsertRequestType
+	ServiceRegistrationUpsertRequestType
+	ServiceRegistrationDeleteRequestType
+	NodeUpdateEligibilityRequestType
+	AllocUpdateDesiredTransitionRequestType
+	MultiregionRolloutUpsertRequestType
 )
 
 const (
@@ -149,13 +159,37 @@ type NodeDeregisterRequest struct {
 type NodeUpdateStatusRequest struct {
 	NodeID string
 	Status string
+
+	// UpdatedAt is the unix time the status was changed at. It is set by
+	// the leader.
+	UpdatedAt int64
 	WriteRequest
 }
 
 // NodeUpdateDrainRequest is used for updatin the drain status
 type NodeUpdateDrainRequest struct {
 	NodeID string
-	Drain  bool
+
+	// DrainStrategy is the strategy of the drain, or nil to stop draining
+	// the node.
+	DrainStrategy *DrainStrategy
+
+	// MarkEligible marks the node as eligible for scheduling again when
+	// the drain is stopped.
+	MarkEligible bool
+
+	// Drain is kept for compatibility with older API clients. Setting it
+	// without a drain strategy forces the migration of all the allocations
+	// of the node.
+	Drain bool
+	WriteRequest
+}
+
+// NodeUpdateEligibilityRequest is used for updating the scheduling
+// eligibility of a node
+type NodeUpdateEligibilityRequest struct {
+	NodeID      string
+	Eligibility string
 	WriteRequest
 }
 
@@ -171,6 +205,13 @@ type NodeSpecificRequest struct {
 	QueryOptions
 }
 
+// NodeConnRequest is sent by a client when it opens a node connection. The
+// secret ID authenticates the client as the node.
+type NodeConnRequest struct {
+	NodeID   string
+	SecretID string
+}
+
 // JobRegisterRequest is used for Job.Register endpoint
 // to register a job as being a schedulable entity.
 type JobRegisterRequest struct {
@@ -261,6 +302,17 @@ type AllocUpdateRequest struct {
 	WriteRequest
 }
 
+// AllocUpdateDesiredTransitionRequest is used to set the desired
+// transitions of allocations, along with the evaluations that act on them
+type AllocUpdateDesiredTransitionRequest struct {
+	// Allocs maps alloc IDs to their desired transition
+	Allocs map[string]*DesiredTransition
+
+	// Evals are the evaluations to create
+	Evals []*Evaluation
+	WriteRequest
+}
+
 // AllocListRequest is used to request a list of allocations
 type AllocListRequest struct {
 	QueryOptions
@@ -272,12 +324,105 @@ type AllocSpecificRequest struct {
 	QueryOptions
 }
 
+// AllocStopRequest is used to stop an allocation and have it replaced
+type AllocStopRequest struct {
+	AllocID string
+	WriteRequest
+}
+
 // PeriodicForceReqeuest is used to force a specific periodic job.
 type PeriodicForceRequest struct {
 	JobID string
 	WriteRequest
 }
 
+// VariableUpsertRequest is used to create or update a variable
+type VariableUpsertRequest struct {
+	Variable *Variable
+	WriteRequest
+}
+
+// VariableEncryptedUpsertRequest is used to commit an encrypted variable
+// via Raft. The plaintext of the variable is never written to the log.
+type VariableEncryptedUpsertRequest struct {
+	Variable *VariableEncrypted
+	WriteRequest
+}
+
+// VariableDeleteRequest is used to delete a variable
+type VariableDeleteRequest struct {
+	Path string
+	WriteRequest
+}
+
+// VariableSpecificRequest is used to read a variable by path
+type VariableSpecificRequest struct {
+	Path string
+	QueryOptions
+}
+
+// VariableListRequest is used to list variables. The Prefix of the query
+// options restricts the listing to the paths starting with it.
+type VariableListRequest struct {
+	QueryOptions
+}
+
+// RootKeyUpsertRequest is used to add a key to the keyring. Only the
+// metadata of the key is committed via Raft.
+type RootKeyUpsertRequest struct {
+	RootKeyMeta *RootKeyMeta
+	WriteRequest
+}
+
+// KeyringRotateRequest is used to create a new active root key
+type KeyringRotateRequest struct {
+	WriteRequest
+}
+
+// KeyringGetRequest is used by a server to fetch the material of a root key
+// from another server. The request is signed with the key encryption key
+// shared by the servers.
+type KeyringGetRequest struct {
+	KeyID      string
+	ServerName string
+	Timestamp  int64
+	Signature  []byte
+	QueryOptions
+}
+
+// MultiregionRolloutUpsertRequest is used to record the progress of the
+// rollout of a multi-region job
+type MultiregionRolloutUpsertRequest struct {
+	Rollout *MultiregionRollout
+	WriteRequest
+}
+
+// ServiceRegistrationUpsertRequest is used by clients to register the
+// services of their tasks
+type ServiceRegistrationUpsertRequest struct {
+	Services []*ServiceRegistration
+	WriteRequest
+}
+
+// ServiceRegistrationDeleteRequest is used to deregister services by ID
+type ServiceRegistrationDeleteRequest struct {
+	IDs []string
+	WriteRequest
+}
+
+// ServiceRegistrationListRequest is used to list the registered services.
+// The Prefix of the query options restricts the listing to the service names
+// starting with it.
+type ServiceRegistrationListRequest struct {
+	QueryOptions
+}
+
+// ServiceRegistrationByNameRequest is used to get the instances of a service
+type ServiceRegistrationByNameRequest struct {
+	ServiceName string
+	QueryOptions
+}
+
 // GenericRequest is used to request where no
 // specific information is needed.
 type GenericRequest struct {
@@ -308,6 +453,10 @@ type JobRegisterResponse struct {
 	EvalID          string
 	EvalCreateIndex uint64
 	JobModifyIndex  uint64
+
+	// Warnings contains the warnings raised by the admission controllers
+	// while the job was registered.
+	Warnings string
 	QueryMeta
 }
 
@@ -336,6 +485,15 @@ type NodeDrainUpdateResponse struct {
 	QueryMeta
 }
 
+// NodeEligibilityUpdateResponse is used to respond to a node eligibility
+// update
+type NodeEligibilityUpdateResponse struct {
+	EvalIDs         []string
+	EvalCreateIndex uint64
+	NodeModifyIndex uint64
+	QueryMeta
+}
+
 // NodeAllocsResponse is used to return allocs for a single node
 type NodeAllocsResponse struct {
 	Allocs []*Allocation
@@ -348,6 +506,13 @@ type SingleNodeResponse struct {
 	QueryMeta
 }
 
+// NodeConnQueryResponse is used to return whether a server holds the node
+// connection of a client
+type NodeConnQueryResponse struct {
+	Connected bool
+	QueryMeta
+}
+
 // JobListResponse is used for a list request
 type NodeListResponse struct {
 	Nodes []*NodeListStub
@@ -360,6 +525,19 @@ type SingleJobResponse struct {
 	QueryMeta
 }
 
+// MultiregionRolloutResponse is used to return the rollout of a multi-region
+// job
+type MultiregionRolloutResponse struct {
+	Rollout *MultiregionRollout
+	QueryMeta
+}
+
+// JobSummaryResponse is used to return the summary of a job
+type JobSummaryResponse struct {
+	JobSummary *JobSummary
+	QueryMeta
+}
+
 // JobListResponse is used for a list request
 type JobListResponse struct {
 	Jobs []*JobListStub
@@ -372,6 +550,13 @@ type SingleAllocResponse struct {
 	QueryMeta
 }
 
+// AllocStopResponse is the response to stopping an allocation
+type AllocStopResponse struct {
+	// EvalID is the evaluation that stops and replaces the allocation
+	EvalID string
+	WriteMeta
+}
+
 // JobAllocationsResponse is used to return the allocations for a job
 type JobAllocationsResponse struct {
 	Allocations []*AllocListStub
@@ -428,6 +613,43 @@ type PeriodicForceResponse struct {
 	WriteMeta
 }
 
+// SingleVariableResponse is used to return a single decrypted variable
+type SingleVariableResponse struct {
+	Variable *Variable
+	QueryMeta
+}
+
+// VariableListResponse is used for a list request
+type VariableListResponse struct {
+	Variables []*VariableListStub
+	QueryMeta
+}
+
+// KeyringRotateResponse is used to return the key created by a rotation
+type KeyringRotateResponse struct {
+	KeyID string
+	WriteMeta
+}
+
+// KeyringGetResponse is used to return the material of a root key, wrapped
+// with the key encryption key shared by the servers
+type KeyringGetResponse struct {
+	WrappedKey []byte
+}
+
+// ServiceRegistrationListResponse is used for a list request
+type ServiceRegistrationListResponse struct {
+	Services []*ServiceRegistrationListStub
+	QueryMeta
+}
+
+// ServiceRegistrationByNameResponse is used to return the instances of a
+// service
+type ServiceRegistrationByNameResponse struct {
+	Services []*ServiceRegistration
+	QueryMeta
+}
+
 const (
 	NodeStatusInit  = "initializing"
 	NodeStatusReady = "ready"
@@ -447,6 +669,56 @@ func ShouldDrainNode(status string) bool {
 	}
 }
 
+const (
+	// NodeSchedulingEligible and NodeSchedulingIneligible are the
+	// scheduling eligibilities of a node. Ineligible nodes keep their
+	// allocations but receive no new ones.
+	NodeSchedulingEligible   = "eligible"
+	NodeSchedulingIneligible = "ineligible"
+)
+
+// DrainSpec describes how a node should be drained
+type DrainSpec struct {
+	// Deadline is how long the allocations may take to migrate before the
+	// remaining ones are stopped. Zero means there is no deadline and a
+	// negative value forces the migration of all the allocations at once.
+	Deadline time.Duration
+
+	// IgnoreSystemJobs leaves the allocations of system jobs on the node
+	IgnoreSystemJobs bool
+}
+
+// DrainStrategy is the drain of a node in progress
+type DrainStrategy struct {
+	DrainSpec
+
+	// ForceDeadline is the time after which the remaining allocations are
+	// stopped. It is set from the deadline when the drain starts and is
+	// zero if there is no deadline.
+	ForceDeadline time.Time
+
+	// StartedAt is the time the drain started
+	StartedAt time.Time
+}
+
+// Copy returns a copy of the drain strategy
+func (d *DrainStrategy) Copy() *DrainStrategy {
+	if d == nil {
+		return nil
+	}
+	nd := new(DrainStrategy)
+	*nd = *d
+	return nd
+}
+
+// DeadlineReached returns whether the remaining allocations must be stopped
+func (d *DrainStrategy) DeadlineReached(now time.Time) bool {
+	if d.Deadline < 0 {
+		return true
+	}
+	return !d.ForceDeadline.IsZero() && !now.Before(d.ForceDeadline)
+}
+
 // ValidNodeStatus is used to check if a node status is valid
 func ValidNodeStatus(status string) bool {
 	switch status {
@@ -464,6 +736,11 @@ type Node struct {
 	// approach. Alternatively a UUID may be used.
 	ID string
 
+	// SecretID is a secret only known to the client of the node and the
+	// servers. It is required to register the node again and to open its
+	// node connection, and is never returned by the API.
+	SecretID string
+
 	// Datacenter for this node
 	Datacenter string
 
@@ -504,6 +781,10 @@ type Node struct {
 	// together for the purpose of determining scheduling pressure.
 	NodeClass string
 
+	// HostVolumes are the host directories the client exposes to task
+	// groups as named volumes.
+	HostVolumes map[string]*ClientHostVolumeConfig
+
 	// ComputedClass is a unique id that identifies nodes with a common set of
 	// attributes and capabilities.
 	ComputedClass uint64
@@ -513,17 +794,35 @@ type Node struct {
 	// allocations will be drained.
 	Drain bool
 
+	// DrainStrategy is the drain in progress on the node, if any. It is
+	// controlled by the servers.
+	DrainStrategy *DrainStrategy
+
+	// SchedulingEligibility is whether new allocations may be placed on
+	// the node. It is controlled by the servers and draining nodes are
+	// always ineligible.
+	SchedulingEligibility string
+
 	// Status of this node
 	Status string
 
 	// StatusDescription is meant to provide more human useful information
 	StatusDescription string
 
+	// StatusUpdatedAt is the unix time of the last status change
+	StatusUpdatedAt int64
+
 	// Raft Indexes
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
+// Ready returns whether new allocations may be placed on the node
+func (n *Node) Ready() bool {
+	return n.Status == NodeStatusReady && !n.Drain &&
+		n.SchedulingEligibility != NodeSchedulingIneligible
+}
+
 // TerminalStatus returns if the current status is terminal and
 // will no longer transition.
 func (n *Node) TerminalStatus() bool {
@@ -535,33 +834,82 @@ func (n *Node) TerminalStatus() bool {
 	}
 }
 
+// Copy returns a deep copy of the node
+func (n *Node) Copy() *Node {
+	if n == nil {
+		return nil
+	}
+	nn := new(Node)
+	*nn = *n
+	nn.Attributes = copyMapStringString(n.Attributes)
+	nn.Links = copyMapStringString(n.Links)
+	nn.Meta = copyMapStringString(n.Meta)
+	if n.Resources != nil {
+		nn.Resources = n.Resources.Copy()
+	}
+	if n.Reserved != nil {
+		nn.Reserved = n.Reserved.Copy()
+	}
+	if n.HostVolumes != nil {
+		nn.HostVolumes = make(map[string]*ClientHostVolumeConfig, len(n.HostVolumes))
+		for name, v := range n.HostVolumes {
+			nv := *v
+			nn.HostVolumes[name] = &nv
+		}
+	}
+	nn.DrainStrategy = n.DrainStrategy.Copy()
+	return nn
+}
+
+// copyMapStringString returns a copy of the map, or nil if it is nil
+func copyMapStringString(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
 // Stub returns a summarized version of the node
 func (n *Node) Stub() *NodeListStub {
 	return &NodeListStub{
-		ID:                n.ID,
-		Datacenter:        n.Datacenter,
-		Name:              n.Name,
-		NodeClass:         n.NodeClass,
-		Drain:             n.Drain,
-		Status:            n.Status,
-		StatusDescription: n.StatusDescription,
-		CreateIndex:       n.CreateIndex,
-		ModifyIndex:       n.ModifyIndex,
+		ID:                    n.ID,
+		Datacenter:            n.Datacenter,
+		Name:                  n.Name,
+		NodeClass:             n.NodeClass,
+		Drain:                 n.Drain,
+		SchedulingEligibility: n.SchedulingEligibility,
+		Status:                n.Status,
+		StatusDescription:     n.StatusDescription,
+		CreateIndex:           n.CreateIndex,
+		ModifyIndex:           n.ModifyIndex,
 	}
 }
 
+// ClientHostVolumeConfig is a host directory a client exposes to task groups
+// as a named volume.
+type ClientHostVolumeConfig struct {
+	Name     string
+	Path     string
+	ReadOnly bool
+}
+
 // NodeListStub is used to return a subset of job information
 // for the job list
 type NodeListStub struct {
-	ID                string
-	Datacenter        string
-	Name              string
-	NodeClass         string
-	Drain             bool
-	Status            string
-	StatusDescription string
-	CreateIndex       uint64
-	ModifyIndex       uint64
+	ID                    string
+	Datacenter            string
+	Name                  string
+	NodeClass             string
+	Drain                 bool
+	SchedulingEligibility string
+	Status                string
+	StatusDescription     string
+	CreateIndex           uint64
+	ModifyIndex           uint64
 }
 
 // Resources is used to define the resources available
@@ -783,10 +1131,18 @@ type Job struct {
 	// Periodic is used to define the interval the job is run at.
 	Periodic *PeriodicConfig
 
+	// Multiregion is used to register the job in multiple regions from a
+	// single submission.
+	Multiregion *Multiregion
+
 	// GC is used to mark the job as available for garbage collection after it
 	// has no outstanding evaluations or allocations.
 	GC bool
 
+	// GCThreshold optionally overrides the server's job GC threshold for
+	// this job. It only applies to jobs that are garbage collectable.
+	GCThreshold time.Duration `mapstructure:"gc_threshold"`
+
 	// Meta is used to associate arbitrary metadata with this
 	// job. This is opaque to Nomad.
 	Meta map[string]string
@@ -847,12 +1203,15 @@ func (j *Job) Validate() error {
 	if j.Priority < JobMinPriority || j.Priority > JobMaxPriority {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("Job priority must be between [%d, %d]", JobMinPriority, JobMaxPriority))
 	}
-	if len(j.Datacenters) == 0 {
+	if len(j.Datacenters) == 0 && j.Multiregion == nil {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing job datacenters"))
 	}
 	if len(j.TaskGroups) == 0 {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing job task groups"))
 	}
+	if j.GCThreshold < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Job GC threshold must not be negative"))
+	}
 	for idx, constr := range j.Constraints {
 		if err := constr.Validate(); err != nil {
 			outer := fmt.Errorf("Constraint %d validation failed: %s", idx+1, err)
@@ -876,6 +1235,12 @@ func (j *Job) Validate() error {
 				fmt.Errorf("Job task group %d has count %d. Only count of 1 is supported with system scheduler",
 					idx+1, tg.Count))
 		}
+
+		if tg.Migrate != nil && j.Type != JobTypeService {
+			mErr.Errors = append(mErr.Errors,
+				fmt.Errorf("Job task group %d sets migrate, which can only be used with %q scheduler",
+					idx+1, JobTypeService))
+		}
 	}
 
 	// Validate the task group
@@ -898,6 +1263,12 @@ func (j *Job) Validate() error {
 		}
 	}
 
+	if j.Multiregion != nil {
+		if err := j.Multiregion.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -931,6 +1302,183 @@ func (j *Job) IsPeriodic() bool {
 	return j.Periodic != nil
 }
 
+// IsMultiregion returns whether a job is registered in multiple regions.
+func (j *Job) IsMultiregion() bool {
+	return j.Multiregion != nil
+}
+
+// RegionalJob returns the copy of a multi-region job that is registered in
+// the passed region, with the region's overrides applied.
+func (j *Job) RegionalJob(region *MultiregionRegion) *Job {
+	job := j.Copy()
+	job.Region = region.Name
+	job.Multiregion = nil
+
+	if len(region.Datacenters) != 0 {
+		job.Datacenters = region.Datacenters
+	}
+	if region.Count != 0 {
+		for _, tg := range job.TaskGroups {
+			tg.Count = region.Count
+		}
+	}
+	if len(region.Meta) != 0 {
+		if job.Meta == nil {
+			job.Meta = make(map[string]string, len(region.Meta))
+		}
+		for k, v := range region.Meta {
+			job.Meta[k] = v
+		}
+	}
+	return job
+}
+
+// Multiregion is used to deploy a job to a set of regions from a single
+// submission.
+type Multiregion struct {
+	// Strategy controls the order in which the regions are rolled out.
+	Strategy *MultiregionStrategy
+
+	// Regions is the ordered list of regions the job is registered in.
+	Regions []*MultiregionRegion
+}
+
+// Validate is used to sanity check a multi-region configuration
+func (m *Multiregion) Validate() error {
+	var mErr multierror.Error
+	if len(m.Regions) == 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Multiregion must specify at least one region"))
+	}
+
+	regions := make(map[string]int)
+	for idx, r := range m.Regions {
+		if r.Name == "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Multiregion region %d missing name", idx+1))
+		} else if existing, ok := regions[r.Name]; ok {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Multiregion region %d redefines '%s' from region %d", idx+1, r.Name, existing+1))
+		} else {
+			regions[r.Name] = idx
+		}
+
+		if r.Count < 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Multiregion region %d has negative count", idx+1))
+		}
+	}
+
+	if m.Strategy != nil && m.Strategy.MaxParallel < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Multiregion max_parallel must not be negative"))
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// MultiregionStrategy is used to control the rollout of a multi-region job.
+type MultiregionStrategy struct {
+	// MaxParallel is how many regions are registered at once. Each batch of
+	// regions must finish evaluating before the next batch is started. Zero
+	// registers the job in all regions at once.
+	MaxParallel int `mapstructure:"max_parallel"`
+}
+
+// MultiregionRegion holds the overrides applied to a multi-region job when it
+// is registered in a region.
+type MultiregionRegion struct {
+	// Name is the name of the region
+	Name string
+
+	// Count overrides the count of every task group, if set.
+	Count int
+
+	// Datacenters overrides the datacenters of the job, if set.
+	Datacenters []string
+
+	// Meta is merged into the meta of the job.
+	Meta map[string]string
+}
+
+// MultiregionResult is the outcome of registering a multi-region job in a
+// single region.
+type MultiregionResult struct {
+	Region         string
+	Status         string
+	EvalID         string
+	JobModifyIndex uint64
+
+	// Warnings contains the admission warnings raised in the region.
+	Warnings string
+
+	// Error is set if the registration or its evaluation failed.
+	Error string
+}
+
+const (
+	MultiregionStatusPending  = "pending"
+	MultiregionStatusRunning  = "running"
+	MultiregionStatusComplete = "complete"
+	MultiregionStatusFailed   = "failed"
+)
+
+// MultiregionRollout tracks the rollout of a multi-region job to its regions.
+// It is stored in the region the job was submitted to and advanced by its
+// leader, which resumes running rollouts after a leader election.
+type MultiregionRollout struct {
+	JobID string
+
+	// Job is the multi-region job as submitted
+	Job *Job
+
+	// Status is the status of the rollout as a whole
+	Status string
+
+	// Regions holds the outcome of each region, in rollout order
+	Regions []*MultiregionResult
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// NewMultiregionRollout returns a pending rollout of the multi-region job.
+func NewMultiregionRollout(job *Job) *MultiregionRollout {
+	r := &MultiregionRollout{
+		JobID:  job.ID,
+		Job:    job,
+		Status: MultiregionStatusRunning,
+	}
+	for _, region := range job.Multiregion.Regions {
+		r.Regions = append(r.Regions, &MultiregionResult{
+			Region: region.Name,
+			Status: MultiregionStatusPending,
+		})
+	}
+	return r
+}
+
+// Copy returns a copy of the rollout. The job is shared as it is not
+// modified by the rollout.
+func (r *MultiregionRollout) Copy() *MultiregionRollout {
+	if r == nil {
+		return nil
+	}
+	nr := new(MultiregionRollout)
+	*nr = *r
+	nr.Regions = make([]*MultiregionResult, len(r.Regions))
+	for i, result := range r.Regions {
+		nresult := *result
+		nr.Regions[i] = &nresult
+	}
+	return nr
+}
+
+// Terminal returns whether the rollout has finished.
+func (r *MultiregionRollout) Terminal() bool {
+	switch r.Status {
+	case MultiregionStatusComplete, MultiregionStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
 // JobListStub is used to return a subset of job information
 // for the job list
 type JobListStub struct {
@@ -945,6 +1493,68 @@ type JobListStub struct {
 	ModifyIndex       uint64
 }
 
+// JobSummary summarizes the allocations of a job by task group, and the
+// jobs it launched if it is periodic. It is maintained by the state store as
+// allocations are updated, so that the state of large jobs can be shown
+// without listing their allocations.
+type JobSummary struct {
+	JobID string
+
+	// Summary contains the allocation counts of each task group
+	Summary map[string]TaskGroupSummary
+
+	// Children contains the status counts of the jobs launched by the job
+	Children *JobChildrenSummary
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Copy returns a copy of the job summary
+func (js *JobSummary) Copy() *JobSummary {
+	if js == nil {
+		return nil
+	}
+	nj := new(JobSummary)
+	*nj = *js
+	nj.Summary = make(map[string]TaskGroupSummary, len(js.Summary))
+	for name, summary := range js.Summary {
+		nj.Summary[name] = summary
+	}
+	if js.Children != nil {
+		children := *js.Children
+		nj.Children = &children
+	}
+	return nj
+}
+
+// TaskGroupSummary counts the allocations of a task group by state.
+// Allocations that were placed are counted once in the state of their last
+// client status, even after they are garbage collected.
+type TaskGroupSummary struct {
+	// Queued is the number of allocations the task group still needs to
+	// reach its count
+	Queued int
+
+	// Starting, Running, Complete and Failed count the allocations whose
+	// client status is pending, running, dead and failed. Allocations in an
+	// unknown state while their node is disconnected are counted as running.
+	Starting int
+	Running  int
+	Complete int
+	Failed   int
+
+	// Lost is the number of allocations stopped while their node was down
+	Lost int
+}
+
+// JobChildrenSummary counts the jobs launched by a periodic job by status
+type JobChildrenSummary struct {
+	Pending int
+	Running int
+	Dead    int
+}
+
 // UpdateStrategy is used to modify how updates are done
 type UpdateStrategy struct {
 	// Stagger is the amount of time between the updates
@@ -968,6 +1578,23 @@ const (
 	PeriodicSpecTest = "_internal_test"
 )
 
+const (
+	// PeriodicCatchupNone skips launches that were missed, for example because
+	// there was no leader at the time.
+	PeriodicCatchupNone = "none"
+
+	// PeriodicCatchupLatest launches only the most recent missed launch.
+	PeriodicCatchupLatest = "latest"
+
+	// PeriodicCatchupAll launches every missed launch, up to the catch-up
+	// limit.
+	PeriodicCatchupAll = "all"
+
+	// DefaultPeriodicCatchupLimit is the number of missed launches the
+	// PeriodicCatchupAll policy launches if no limit is specified.
+	DefaultPeriodicCatchupLimit = 10
+)
+
 // Periodic defines the interval a job should be run at.
 type PeriodicConfig struct {
 	// Enabled determines if the job should be run periodically.
@@ -982,6 +1609,18 @@ type PeriodicConfig struct {
 
 	// ProhibitOverlap enforces that spawned jobs do not run in parallel.
 	ProhibitOverlap bool `mapstructure:"prohibit_overlap"`
+
+	// TimeZone is the name of the IANA time zone the spec is evaluated in,
+	// such as "America/New_York". If empty, the spec is evaluated in UTC.
+	TimeZone string `mapstructure:"time_zone"`
+
+	// Catchup is the policy applied to launches that were missed while there
+	// was no leader. If empty, PeriodicCatchupLatest is used.
+	Catchup string
+
+	// CatchupLimit is the maximum number of missed launches that are launched
+	// when using the PeriodicCatchupAll policy.
+	CatchupLimit int `mapstructure:"catchup_limit"`
 }
 
 func (p *PeriodicConfig) Validate() error {
@@ -1005,18 +1644,50 @@ func (p *PeriodicConfig) Validate() error {
 		return fmt.Errorf("Unknown periodic specification type %q", p.SpecType)
 	}
 
+	// Validate the time zone
+	if p.TimeZone != "" {
+		if _, err := time.LoadLocation(p.TimeZone); err != nil {
+			return fmt.Errorf("Invalid time zone %q: %v", p.TimeZone, err)
+		}
+	}
+
+	// Validate the catch-up policy
+	switch p.Catchup {
+	case "", PeriodicCatchupNone, PeriodicCatchupLatest, PeriodicCatchupAll:
+	default:
+		return fmt.Errorf("Unknown catchup policy %q", p.Catchup)
+	}
+
+	if p.CatchupLimit < 0 {
+		return fmt.Errorf("Catchup limit must be non-negative: %d", p.CatchupLimit)
+	}
+
 	return nil
 }
 
+// GetLocation returns the location the periodic spec is evaluated in. If the
+// time zone is unset or invalid, UTC is returned.
+func (p *PeriodicConfig) GetLocation() *time.Location {
+	if p.TimeZone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // Next returns the closest time instant matching the spec that is after the
 // passed time. If no matching instance exists, the zero value of time.Time is
-// returned. The `time.Location` of the returned value matches that of the
-// passed time.
+// returned. Cron specs are evaluated against the wall clock of the configured
+// time zone and the returned value is in that time zone.
 func (p *PeriodicConfig) Next(fromTime time.Time) time.Time {
 	switch p.SpecType {
 	case PeriodicSpecCron:
 		if e, err := cronexpr.Parse(p.Spec); err == nil {
-			return e.Next(fromTime)
+			return cronNext(e, fromTime, p.GetLocation())
 		}
 	case PeriodicSpecTest:
 		split := strings.Split(p.Spec, ",")
@@ -1035,15 +1706,78 @@ func (p *PeriodicConfig) Next(fromTime time.Time) time.Time {
 			times[i] = time.Unix(int64(unix), 0)
 		}
 
-		// Find the next match
-		for _, next := range times {
-			if fromTime.Before(next) {
-				return next
-			}
+		// Find the next match
+		for _, next := range times {
+			if fromTime.Before(next) {
+				return next
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// MissedLaunches returns the launches after the last launch and before now that
+// should still be launched according to the catch-up policy, oldest first.
+// Jobs that prohibit overlap catch up at most one launch since the catch-up
+// launches would otherwise run concurrently.
+func (p *PeriodicConfig) MissedLaunches(last, now time.Time) []time.Time {
+	limit := 1
+	switch p.Catchup {
+	case PeriodicCatchupNone:
+		return nil
+	case PeriodicCatchupAll:
+		limit = p.CatchupLimit
+		if limit == 0 {
+			limit = DefaultPeriodicCatchupLimit
+		}
+		if p.ProhibitOverlap {
+			limit = 1
+		}
+	}
+
+	var missed []time.Time
+	for next := p.Next(last); !next.IsZero() && next.Before(now); next = p.Next(next) {
+		missed = append(missed, next)
+		if len(missed) > limit {
+			missed = missed[1:]
 		}
 	}
+	return missed
+}
+
+// cronNext returns the next launch of the cron expression after fromTime. The
+// expression is matched against the wall clock of the passed location, so a
+// launch that falls into a daylight saving gap is shifted forward by the length
+// of the gap, and a launch in an hour that is repeated when clocks are set back
+// only happens once.
+func cronNext(e *cronexpr.Expression, fromTime time.Time, loc *time.Location) time.Time {
+	from := fromTime.In(loc)
+	wall := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(),
+		from.Minute(), from.Second(), from.Nanosecond(), time.UTC)
+	for {
+		wall = e.Next(wall)
+		if wall.IsZero() {
+			return time.Time{}
+		}
 
-	return time.Time{}
+		next := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(),
+			wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+
+		// If the wall clock time doesn't exist because it is in a daylight
+		// saving gap, it may have been resolved to an instant before the gap.
+		// Interpret it with the offset in effect before the gap instead.
+		shown := time.Date(next.Year(), next.Month(), next.Day(), next.Hour(),
+			next.Minute(), next.Second(), next.Nanosecond(), time.UTC)
+		if shown.Before(wall) {
+			_, offset := next.Zone()
+			next = wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		}
+
+		if next.After(fromTime) {
+			return next
+		}
+	}
 }
 
 const (
@@ -1089,6 +1823,85 @@ const (
 	RestartPolicyModeFail = "fail"
 )
 
+// DefaultMigrateStrategy returns the migrate strategy of task groups that
+// do not set one
+func DefaultMigrateStrategy() *MigrateStrategy {
+	return &MigrateStrategy{
+		MaxParallel:     1,
+		MinHealthyTime:  10 * time.Second,
+		HealthyDeadline: 5 * time.Minute,
+	}
+}
+
+// MigrateStrategy configures how the allocations of a task group are moved
+// off draining nodes.
+type MigrateStrategy struct {
+	// MaxParallel is the number of allocations of the group that may be
+	// migrating at once. An allocation is migrating from the time it is
+	// marked for migration until its replacement is healthy.
+	MaxParallel int `mapstructure:"max_parallel"`
+
+	// MinHealthyTime is how long all the tasks of a replacement must have
+	// been running for it to be healthy.
+	MinHealthyTime time.Duration `mapstructure:"min_healthy_time"`
+
+	// HealthyDeadline is how long unhealthy replacements hold back the
+	// next migrations of the group.
+	HealthyDeadline time.Duration `mapstructure:"healthy_deadline"`
+}
+
+func (m *MigrateStrategy) Validate() error {
+	var mErr multierror.Error
+	if m.MaxParallel < 1 {
+		mErr.Errors = append(mErr.Errors, errors.New("Migrate max_parallel must be positive"))
+	}
+	if m.MinHealthyTime < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Migrate min_healthy_time must not be negative"))
+	}
+	if m.HealthyDeadline < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Migrate healthy_deadline must not be negative"))
+	}
+	if m.HealthyDeadline != 0 && m.HealthyDeadline <= m.MinHealthyTime {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Migrate healthy_deadline %v must be greater than min_healthy_time %v",
+			m.HealthyDeadline, m.MinHealthyTime))
+	}
+	return mErr.ErrorOrNil()
+}
+
+const (
+	// VolumeTypeHost is the type of the volumes backed by the host volumes
+	// of the clients.
+	VolumeTypeHost = "host"
+)
+
+// VolumeRequest is a volume requested by a task group, which its tasks may
+// mount.
+type VolumeRequest struct {
+	// Name of the volume in the task group
+	Name string
+
+	// Type of the volume. Only host volumes are supported.
+	Type string
+
+	// Source is the name of the host volume on the client.
+	Source string
+
+	// ReadOnly requests the volume to be mounted read only, so that it can
+	// be placed on clients exposing the host volume read only.
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+func (v *VolumeRequest) Validate() error {
+	var mErr multierror.Error
+	if v.Type != VolumeTypeHost {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Unsupported volume type '%s'", v.Type))
+	}
+	if v.Source == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Volume must have a source"))
+	}
+	return mErr.ErrorOrNil()
+}
+
 // RestartPolicy configures how Tasks are restarted when they crash or fail.
 type RestartPolicy struct {
 	// Attempts is the number of restart that will occur in an interval.
@@ -1162,6 +1975,18 @@ type TaskGroup struct {
 	// Meta is used to associate arbitrary metadata with this
 	// task group. This is opaque to Nomad.
 	Meta map[string]string
+
+	// MaxClientDisconnect is how long allocations on a node that stopped
+	// heartbeating are left in place, with an unknown status, before they
+	// are replaced. Zero replaces them as soon as the node is marked down.
+	MaxClientDisconnect time.Duration `mapstructure:"max_client_disconnect"`
+
+	// Migrate controls how the allocations of the group are moved off
+	// draining nodes. It only applies to service jobs.
+	Migrate *MigrateStrategy
+
+	// Volumes are the volumes the tasks of the group may mount, by name.
+	Volumes map[string]*VolumeRequest
 }
 
 // InitFields is used to initialize fields in the TaskGroup.
@@ -1171,6 +1996,13 @@ func (tg *TaskGroup) InitFields(job *Job) {
 		tg.RestartPolicy = NewRestartPolicy(job.Type)
 	}
 
+	// Set the default volume type.
+	for _, volume := range tg.Volumes {
+		if volume.Type == "" {
+			volume.Type = VolumeTypeHost
+		}
+	}
+
 	for _, task := range tg.Tasks {
 		task.InitFields(job, tg)
 	}
@@ -1188,6 +2020,14 @@ func (tg *TaskGroup) Validate() error {
 	if len(tg.Tasks) == 0 {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing tasks for task group"))
 	}
+	if tg.MaxClientDisconnect < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Task group max_client_disconnect must not be negative"))
+	}
+	if tg.Migrate != nil {
+		if err := tg.Migrate.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
 	for idx, constr := range tg.Constraints {
 		if err := constr.Validate(); err != nil {
 			outer := fmt.Errorf("Constraint %d validation failed: %s", idx+1, err)
@@ -1215,12 +2055,28 @@ func (tg *TaskGroup) Validate() error {
 		}
 	}
 
+	// Validate the volumes
+	for name, volume := range tg.Volumes {
+		if err := volume.Validate(); err != nil {
+			outer := fmt.Errorf("Volume '%s' validation failed: %s", name, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
 	// Validate the tasks
 	for idx, task := range tg.Tasks {
 		if err := task.Validate(); err != nil {
 			outer := fmt.Errorf("Task %d validation failed: %s", idx+1, err)
 			mErr.Errors = append(mErr.Errors, outer)
 		}
+
+		// Tasks can only mount the volumes of the group
+		for _, mount := range task.VolumeMounts {
+			if _, ok := tg.Volumes[mount.Volume]; mount.Volume != "" && !ok {
+				outer := fmt.Errorf("Task %d mounts unknown volume '%s'", idx+1, mount.Volume)
+				mErr.Errors = append(mErr.Errors, outer)
+			}
+		}
 	}
 	return mErr.ErrorOrNil()
 }
@@ -1295,11 +2151,20 @@ const (
 	NomadConsulPrefix = "nomad-registered-service"
 )
 
+const (
+	// ServiceProviderConsul registers the service with the local Consul agent
+	ServiceProviderConsul = "consul"
+
+	// ServiceProviderNomad registers the service with the Nomad servers
+	ServiceProviderNomad = "nomad"
+)
+
 // The Service model represents a Consul service defintion
 type Service struct {
 	Name      string          // Name of the service, defaults to id
 	Tags      []string        // List of tags for the service
 	PortLabel string          `mapstructure:"port"` // port for the service
+	Provider  string          // Provider the service is registered with, defaults to consul
 	Checks    []*ServiceCheck // List of checks associated with the service
 }
 
@@ -1324,6 +2189,15 @@ func (s *Service) InitFields(job string, taskGroup string, task string) {
 // Validate checks if the Check definition is valid
 func (s *Service) Validate() error {
 	var mErr multierror.Error
+	switch s.Provider {
+	case "", ServiceProviderConsul:
+	case ServiceProviderNomad:
+		if len(s.Checks) != 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("service %q: checks are only supported by the %q provider", s.Name, ServiceProviderConsul))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("service %q: invalid provider %q", s.Name, s.Provider))
+	}
 	for _, c := range s.Checks {
 		if err := c.Validate(); err != nil {
 			mErr.Errors = append(mErr.Errors, err)
@@ -1339,9 +2213,70 @@ func (s *Service) Hash() string {
 	io.WriteString(h, s.Name)
 	io.WriteString(h, strings.Join(s.Tags, ""))
 	io.WriteString(h, s.PortLabel)
+	io.WriteString(h, s.Provider)
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// IsNomadProvided returns whether the service is registered with the Nomad
+// servers instead of Consul.
+func (s *Service) IsNomadProvided() bool {
+	return s.Provider == ServiceProviderNomad
+}
+
+// ServiceRegistration is an instance of a service registered with the Nomad
+// servers by the client running its task.
+type ServiceRegistration struct {
+	// ID is the ID of the service of the allocation
+	ID string
+
+	// ServiceName is the name of the service
+	ServiceName string
+
+	// JobID, AllocID, NodeID and TaskName identify the task providing the
+	// service
+	JobID    string
+	AllocID  string
+	NodeID   string
+	TaskName string
+
+	// Tags are the tags of the service
+	Tags []string
+
+	// Address and Port are where the service can be reached
+	Address string
+	Port    int
+
+	// Raft Indexes
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Equals returns whether the registrations describe the same instance,
+// ignoring the Raft indexes.
+func (s *ServiceRegistration) Equals(o *ServiceRegistration) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	if s.ID != o.ID || s.ServiceName != o.ServiceName || s.JobID != o.JobID ||
+		s.AllocID != o.AllocID || s.NodeID != o.NodeID || s.TaskName != o.TaskName ||
+		s.Address != o.Address || s.Port != o.Port || len(s.Tags) != len(o.Tags) {
+		return false
+	}
+	for i, tag := range s.Tags {
+		if o.Tags[i] != tag {
+			return false
+		}
+	}
+	return true
+}
+
+// ServiceRegistrationListStub is used to return the names of the registered
+// services along with the tags of their instances
+type ServiceRegistrationListStub struct {
+	ServiceName string
+	Tags        []string
+}
+
 const (
 	// DefaultKillTimeout is the default timeout between signaling a task it
 	// will be killed and killing it.
@@ -1379,6 +2314,17 @@ type Task struct {
 	// KillTimeout is the time between signaling a task that it will be
 	// killed and killing it.
 	KillTimeout time.Duration `mapstructure:"kill_timeout"`
+
+	// Variables are the variables the client renders for the task before it
+	// is started.
+	Variables []*TaskVariable
+
+	// LogConfig configures the rotation of the stdout and stderr logs of the
+	// task.
+	LogConfig *LogConfig
+
+	// VolumeMounts are the volumes of the task group mounted in the task.
+	VolumeMounts []*VolumeMount
 }
 
 // InitFields initializes fields in the task.
@@ -1444,6 +2390,17 @@ const (
 
 	// Task Killed indicates a user has killed the task.
 	TaskKilled = "Killed"
+
+	// Task Restart Signaled indicates a user has requested the task to be
+	// restarted. The restart does not count against the restart policy.
+	TaskRestartSignal = "Restart Signaled"
+
+	// Task Signaling indicates a user has sent a signal to the task.
+	TaskSignaling = "Signaling"
+
+	// Task Disk Exceeded indicates that the task was killed because the
+	// allocation used more disk space than it was allocated.
+	TaskDiskExceeded = "Disk Exceeded"
 )
 
 // TaskEvent is an event that effects the state of a task and contains meta-data
@@ -1462,6 +2419,16 @@ type TaskEvent struct {
 
 	// Task Killed Fields.
 	KillError string // Error killing the task.
+
+	// Restart Signaled Fields.
+	RestartReason string // The reason the task was restarted.
+
+	// Signaling Fields.
+	TaskSignal string // The signal sent to the task.
+
+	// Disk Exceeded Fields.
+	DiskLimit int64 // The disk space allocated to the allocation in bytes.
+	DiskSize  int64 // The disk space used by the allocation in bytes.
 }
 
 func NewTaskEvent(event string) *TaskEvent {
@@ -1502,6 +2469,26 @@ func (e *TaskEvent) SetKillError(err error) *TaskEvent {
 	return e
 }
 
+func (e *TaskEvent) SetRestartReason(reason string) *TaskEvent {
+	e.RestartReason = reason
+	return e
+}
+
+func (e *TaskEvent) SetTaskSignal(s string) *TaskEvent {
+	e.TaskSignal = s
+	return e
+}
+
+func (e *TaskEvent) SetDiskLimit(limit int64) *TaskEvent {
+	e.DiskLimit = limit
+	return e
+}
+
+func (e *TaskEvent) SetDiskSize(size int64) *TaskEvent {
+	e.DiskSize = size
+	return e
+}
+
 // Validate is used to sanity check a task group
 func (t *Task) Validate() error {
 	var mErr multierror.Error
@@ -1529,9 +2516,184 @@ func (t *Task) Validate() error {
 			mErr.Errors = append(mErr.Errors, err)
 		}
 	}
+
+	for idx, mount := range t.VolumeMounts {
+		if err := mount.Validate(); err != nil {
+			outer := fmt.Errorf("Volume mount %d validation failed: %s", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
+	for idx, v := range t.Variables {
+		if err := v.Validate(); err != nil {
+			outer := fmt.Errorf("Variable %d validation failed: %s", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
+	if t.LogConfig != nil {
+		if err := t.LogConfig.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+
+		// The logs must fit in the disk reserved by the task, if any. Tasks
+		// without a log config use the default rotation, which the client
+		// shrinks to fit the disk of the task.
+		if t.Resources != nil && t.Resources.DiskMB > 0 {
+			if usage := t.LogConfig.DiskMB(); usage > t.Resources.DiskMB {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf(
+					"Logs may use up to %d MB, which exceeds the %d MB of disk reserved by the task",
+					usage, t.Resources.DiskMB))
+			}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+const (
+	// DefaultMaxLogFiles and DefaultMaxLogFileSizeMB are the default number
+	// and size of the rotated files kept for each log of a task.
+	DefaultMaxLogFiles      = 10
+	DefaultMaxLogFileSizeMB = 10
+)
+
+// LogConfig configures the rotation of the stdout and stderr logs of a task.
+// Each log is written to a set of files in the shared logs directory of the
+// allocation, and the oldest files are deleted once there are more than
+// MaxFiles.
+type LogConfig struct {
+	// MaxFiles is the number of rotated files kept for each log
+	MaxFiles int `mapstructure:"max_files"`
+
+	// MaxFileSizeMB is the size of a log file in MB before it is rotated
+	MaxFileSizeMB int `mapstructure:"max_file_size"`
+}
+
+// DefaultLogConfig returns the log rotation the client uses for tasks that do
+// not configure one.
+func DefaultLogConfig() *LogConfig {
+	return &LogConfig{
+		MaxFiles:      DefaultMaxLogFiles,
+		MaxFileSizeMB: DefaultMaxLogFileSizeMB,
+	}
+}
+
+// LogRotation returns the log rotation of the task. Tasks without a log
+// config use the default rotation, shrunk to use at most half of the disk
+// reserved by the task so that the logs can not exceed it.
+func (t *Task) LogRotation() *LogConfig {
+	if t.LogConfig != nil {
+		return t.LogConfig
+	}
+
+	l := DefaultLogConfig()
+	if t.Resources == nil || t.Resources.DiskMB <= 0 {
+		return l
+	}
+	budget := t.Resources.DiskMB / 2
+	if l.DiskMB() <= budget {
+		return l
+	}
+
+	// Keep as many files as possible, smaller files first
+	l.MaxFileSizeMB = budget / (2 * l.MaxFiles)
+	if l.MaxFileSizeMB < 1 {
+		l.MaxFileSizeMB = 1
+		l.MaxFiles = budget / 2
+		if l.MaxFiles < 1 {
+			l.MaxFiles = 1
+		}
+	}
+	return l
+}
+
+// DiskMB returns the maximum disk space used by the stdout and stderr logs
+func (l *LogConfig) DiskMB() int {
+	return 2 * l.MaxFiles * l.MaxFileSizeMB
+}
+
+func (l *LogConfig) Validate() error {
+	var mErr multierror.Error
+	if l.MaxFiles < 1 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Logs must keep at least one file; got %d", l.MaxFiles))
+	}
+	if l.MaxFileSizeMB < 1 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Log files must be at least 1 MB; got %d", l.MaxFileSizeMB))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// TaskVariable references a variable that is rendered for a task. The items
+// of the variable are exposed as environment variables, written to a file in
+// the task directory, or both.
+type TaskVariable struct {
+	// Path of the variable
+	Path string
+
+	// Env exposes the items of the variable as environment variables.
+	Env bool
+
+	// Destination is the file, relative to the task directory, the items
+	// are written to as a JSON object.
+	Destination string
+}
+
+func (v *TaskVariable) Validate() error {
+	var mErr multierror.Error
+	if err := ValidateVariablePath(v.Path); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	if !v.Env && v.Destination == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Variable must set env or a destination"))
+	}
+	if v.Destination != "" {
+		if filepath.IsAbs(v.Destination) {
+			mErr.Errors = append(mErr.Errors, errors.New("Destination must be relative to the task directory"))
+		} else if escapes(v.Destination) {
+			mErr.Errors = append(mErr.Errors, errors.New("Destination can not escape the task directory"))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// VolumeMount mounts a volume of the task group in a task.
+type VolumeMount struct {
+	// Volume is the name of the volume in the task group
+	Volume string
+
+	// Destination is the absolute path the volume is mounted at in the
+	// task.
+	Destination string
+
+	// ReadOnly mounts the volume read only.
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+func (m *VolumeMount) Validate() error {
+	var mErr multierror.Error
+	if m.Volume == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Volume mount must reference a volume"))
+	}
+	if m.Destination == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Volume mount must have a destination"))
+	} else if !filepath.IsAbs(m.Destination) {
+		mErr.Errors = append(mErr.Errors, errors.New("Volume mount destination must be an absolute path"))
+	} else if rel := strings.TrimLeft(m.Destination, "/"); escapes(rel) {
+		// The destination is joined to the task directory, so it must not
+		// leave it once relative
+		mErr.Errors = append(mErr.Errors, errors.New("Volume mount destination can not escape the task directory"))
+	} else if filepath.Clean(rel) == "." {
+		mErr.Errors = append(mErr.Errors, errors.New("Volume mount destination can not be the task directory"))
+	}
 	return mErr.ErrorOrNil()
 }
 
+// escapes returns whether the relative path leaves its base directory
+func escapes(path string) bool {
+	clean := filepath.Clean(path)
+	return clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
 const (
 	ConstraintDistinctHosts = "distinct_hosts"
 	ConstraintRegex         = "regexp"
@@ -1581,6 +2743,14 @@ const (
 	AllocClientStatusRunning = "running"
 	AllocClientStatusDead    = "dead"
 	AllocClientStatusFailed  = "failed"
+
+	// AllocClientStatusUnknown is used for allocations on a node that stopped
+	// heartbeating, while within the task group's max_client_disconnect.
+	AllocClientStatusUnknown = "unknown"
+
+	// AllocClientStatusLost is used for allocations stopped while their node
+	// is down, whose client will never report that they stopped.
+	AllocClientStatusLost = "lost"
 )
 
 // Allocation is used to allocate the placement of a task group to a node.
@@ -1635,11 +2805,27 @@ type Allocation struct {
 	// TaskStates stores the state of each task,
 	TaskStates map[string]*TaskState
 
+	// DesiredTransition is set by the servers to move the allocation, such
+	// as when its node is drained.
+	DesiredTransition DesiredTransition
+
 	// Raft Indexes
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
+// DesiredTransition is a transition of an allocation requested by the
+// servers, which the schedulers act on.
+type DesiredTransition struct {
+	// Migrate is set when the allocation must be moved to another node
+	Migrate bool
+}
+
+// ShouldMigrate returns whether the allocation must be migrated
+func (d DesiredTransition) ShouldMigrate() bool {
+	return d.Migrate
+}
+
 // TerminalStatus returns if the desired or actual status is terminal and
 // will no longer transition.
 func (a *Allocation) TerminalStatus() bool {
@@ -1652,7 +2838,7 @@ func (a *Allocation) TerminalStatus() bool {
 	}
 
 	switch a.ClientStatus {
-	case AllocClientStatusDead, AllocClientStatusFailed:
+	case AllocClientStatusDead, AllocClientStatusFailed, AllocClientStatusLost:
 		return true
 	default:
 		return false
@@ -1825,6 +3011,13 @@ const (
 	EvalTriggerNodeUpdate    = "node-update"
 	EvalTriggerScheduled     = "scheduled"
 	EvalTriggerRollingUpdate = "rolling-update"
+	EvalTriggerNodeDrain     = "node-drain"
+	EvalTriggerAllocStop     = "alloc-stop"
+
+	// EvalTriggerMaxDisconnectTimeout is used for the follow-up evaluation
+	// created when the max_client_disconnect of allocations on a down node
+	// expires.
+	EvalTriggerMaxDisconnectTimeout = "max-disconnect-timeout"
 )
 
 const (
@@ -1844,6 +3037,11 @@ const (
 	// evaluations and allocations are terminal. If so, we delete these out of
 	// the system.
 	CoreJobJobGC = "job-gc"
+
+	// CoreJobForceGC is used to force garbage collection of all GCable objects.
+	// Unlike the periodic core jobs, it ignores the configured thresholds and
+	// collects everything that is eligible at the time it is created.
+	CoreJobForceGC = "force-gc"
 )
 
 // Evaluation is used anytime we need to apply business logic as a result
@@ -1969,6 +3167,22 @@ func (e *Evaluation) NextRollingEval(wait time.Duration) *Evaluation {
 	}
 }
 
+// NextDisconnectEval creates an evaluation that runs once the
+// max_client_disconnect window of allocations on a down node expires.
+func (e *Evaluation) NextDisconnectEval(wait time.Duration) *Evaluation {
+	return &Evaluation{
+		ID:             GenerateUUID(),
+		Priority:       e.Priority,
+		Type:           e.Type,
+		TriggeredBy:    EvalTriggerMaxDisconnectTimeout,
+		JobID:          e.JobID,
+		JobModifyIndex: e.JobModifyIndex,
+		Status:         EvalStatusPending,
+		Wait:           wait,
+		PreviousEval:   e.ID,
+	}
+}
+
 // Plan is used to submit a commit plan for task allocations. These
 // are submitted to the leader which verifies that resources have
 // not been overcommitted before admiting the plan.
@@ -2087,6 +3301,131 @@ func (p *PlanResult) FullCommit(plan *Plan) (bool, int, int) {
 	return actual == expected, expected, actual
 }
 
+const (
+	// MaxVariablePathLength is the longest path a variable may be stored at.
+	MaxVariablePathLength = 128
+)
+
+var (
+	// validVariablePath matches the paths of variables, which are slash
+	// separated segments of letters, digits, dashes, underscores and dots.
+	validVariablePath = regexp.MustCompile("^[a-zA-Z0-9-_.]+(/[a-zA-Z0-9-_.]+)*$")
+)
+
+// ValidateVariablePath returns an error if the path can not store a variable
+func ValidateVariablePath(path string) error {
+	switch {
+	case path == "":
+		return errors.New("Missing variable path")
+	case len(path) > MaxVariablePathLength:
+		return fmt.Errorf("Variable path longer than %d characters", MaxVariablePathLength)
+	case !validVariablePath.MatchString(path):
+		return fmt.Errorf("Invalid variable path %q", path)
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("Invalid variable path %q", path)
+		}
+	}
+	return nil
+}
+
+// Variable is a set of secret key/value items stored at a path. Variables
+// are only held in plaintext in memory; the state store and the Raft log
+// hold their encrypted form.
+type Variable struct {
+	Path  string
+	Items map[string]string
+
+	// Raft Indexes
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Validate is used to sanity check a variable
+func (v *Variable) Validate() error {
+	var mErr multierror.Error
+	if err := ValidateVariablePath(v.Path); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	if len(v.Items) == 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Variable must have at least one item"))
+	}
+	for k := range v.Items {
+		if k == "" {
+			mErr.Errors = append(mErr.Errors, errors.New("Variable item keys can not be empty"))
+			break
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// VariableEncrypted is the form a variable is stored in
+type VariableEncrypted struct {
+	Path string
+
+	// KeyID is the ID of the root key the data was encrypted with
+	KeyID string
+
+	// Data holds the nonce followed by the encrypted items
+	Data []byte
+
+	// Raft Indexes
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Stub returns a summary of the variable that doesn't include its items
+func (v *VariableEncrypted) Stub() *VariableListStub {
+	return &VariableListStub{
+		Path:        v.Path,
+		CreateIndex: v.CreateIndex,
+		ModifyIndex: v.ModifyIndex,
+	}
+}
+
+// VariableListStub is used to return a subset of a variable, without its
+// items, when listing variables
+type VariableListStub struct {
+	Path        string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+const (
+	RootKeyAlgorithmAES256GCM = "aes256-gcm"
+)
+
+const (
+	RootKeyStateActive   = "active"
+	RootKeyStateInactive = "inactive"
+)
+
+// RootKeyMeta is the metadata of a key of the keyring used to encrypt
+// variables. The leader creates the keyring, and only a single key is active
+// and used to encrypt new variables at a time. Older keys are kept to decrypt
+// the variables written with them. The key material itself is never
+// replicated via Raft; each server keeps it in its local keystore.
+type RootKeyMeta struct {
+	KeyID     string
+	Algorithm string
+
+	// State marks whether the key is used to encrypt new variables
+	State string
+
+	// CreateTime is the Unix nano time the key was created at
+	CreateTime int64
+
+	// Raft Indexes
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Active returns whether the key is used to encrypt new variables
+func (k *RootKeyMeta) Active() bool {
+	return k.State == RootKeyStateActive
+}
+
 // msgpackHandle is a shared handle for encoding/decoding of structs
 var MsgpackHandle = func() *codec.MsgpackHandle {
 	h := &codec.MsgpackHandle{RawToString: true}
@@ -47,6 +47,16 @@ func TestJob_Validate(t *testing.T) {
 		t.Fatalf("err: %s", err)
 	}
 
+	j = &Job{
+		Type:        JobTypeBatch,
+		GCThreshold: -1 * time.Minute,
+	}
+	err = j.Validate()
+	mErr = err.(*multierror.Error)
+	if !strings.Contains(mErr.Error(), "GC threshold") {
+		t.Fatalf("err: %s", err)
+	}
+
 	j = &Job{
 		Region:      "global",
 		ID:          GenerateUUID(),
@@ -93,6 +103,85 @@ func TestJob_Validate(t *testing.T) {
 	}
 }
 
+func TestJob_Validate_DefaultLogConfig(t *testing.T) {
+	// A task with little disk and no logs block uses the default log
+	// rotation without failing validation
+	j := &Job{
+		Region:      "global",
+		ID:          GenerateUUID(),
+		Name:        "my-job",
+		Type:        JobTypeService,
+		Priority:    50,
+		Datacenters: []string{"dc1"},
+		TaskGroups: []*TaskGroup{
+			&TaskGroup{
+				Name:  "web",
+				Count: 1,
+				RestartPolicy: &RestartPolicy{
+					Interval: 5 * time.Minute,
+					Delay:    10 * time.Second,
+					Attempts: 10,
+					Mode:     RestartPolicyModeDelay,
+				},
+				Tasks: []*Task{
+					&Task{
+						Name:   "web",
+						Driver: "exec",
+						Resources: &Resources{
+							CPU:      500,
+							MemoryMB: 256,
+							DiskMB:   100,
+						},
+					},
+				},
+			},
+		},
+	}
+	j.InitFields()
+	if err := j.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if j.TaskGroups[0].Tasks[0].LogConfig != nil {
+		t.Fatalf("bad: %#v", j.TaskGroups[0].Tasks[0].LogConfig)
+	}
+
+	// The client shrinks the default rotation to fit the disk of the task
+	if usage := j.TaskGroups[0].Tasks[0].LogRotation().DiskMB(); usage > 50 {
+		t.Fatalf("logs may use %d MB of the 100 MB of the task", usage)
+	}
+}
+
+func TestTask_LogRotation(t *testing.T) {
+	cases := []struct {
+		disk  int
+		files int
+		size  int
+	}{
+		{0, DefaultMaxLogFiles, DefaultMaxLogFileSizeMB},
+		{1000, DefaultMaxLogFiles, DefaultMaxLogFileSizeMB},
+		{400, DefaultMaxLogFiles, DefaultMaxLogFileSizeMB},
+		{100, DefaultMaxLogFiles, 2},
+		{20, 5, 1},
+		{1, 1, 1},
+	}
+	for _, c := range cases {
+		task := &Task{Resources: &Resources{DiskMB: c.disk}}
+		l := task.LogRotation()
+		if l.MaxFiles != c.files || l.MaxFileSizeMB != c.size {
+			t.Fatalf("disk %d: got %#v; want %d files of %d MB", c.disk, l, c.files, c.size)
+		}
+	}
+
+	// An explicit log config is used as is
+	task := &Task{
+		Resources: &Resources{DiskMB: 100},
+		LogConfig: &LogConfig{MaxFiles: 2, MaxFileSizeMB: 20},
+	}
+	if l := task.LogRotation(); l != task.LogConfig {
+		t.Fatalf("bad: %#v", l)
+	}
+}
+
 func TestJob_Copy(t *testing.T) {
 	j := &Job{
 		Region:      "global",
@@ -186,6 +275,115 @@ func TestJob_IsPeriodic(t *testing.T) {
 	}
 }
 
+func TestJob_RegionalJob(t *testing.T) {
+	j := &Job{
+		Region:      "global",
+		ID:          "foo",
+		Datacenters: []string{"dc1"},
+		TaskGroups: []*TaskGroup{
+			&TaskGroup{Name: "web", Count: 10},
+			&TaskGroup{Name: "cache", Count: 1},
+		},
+		Meta: map[string]string{
+			"owner": "armon",
+			"tier":  "default",
+		},
+		Multiregion: &Multiregion{
+			Regions: []*MultiregionRegion{
+				&MultiregionRegion{Name: "east"},
+				&MultiregionRegion{
+					Name:        "west",
+					Count:       2,
+					Datacenters: []string{"west-1"},
+					Meta: map[string]string{
+						"tier": "primary",
+					},
+				},
+			},
+		},
+	}
+
+	// No overrides
+	east := j.RegionalJob(j.Multiregion.Regions[0])
+	if east.Region != "east" || east.Multiregion != nil {
+		t.Fatalf("bad: %#v", east)
+	}
+	if !reflect.DeepEqual(east.Datacenters, j.Datacenters) {
+		t.Fatalf("bad: %#v", east.Datacenters)
+	}
+	if east.TaskGroups[0].Count != 10 || east.TaskGroups[1].Count != 1 {
+		t.Fatalf("bad: %#v", east.TaskGroups)
+	}
+
+	// All overrides
+	west := j.RegionalJob(j.Multiregion.Regions[1])
+	if west.Region != "west" || west.Multiregion != nil {
+		t.Fatalf("bad: %#v", west)
+	}
+	if !reflect.DeepEqual(west.Datacenters, []string{"west-1"}) {
+		t.Fatalf("bad: %#v", west.Datacenters)
+	}
+	if west.TaskGroups[0].Count != 2 || west.TaskGroups[1].Count != 2 {
+		t.Fatalf("bad: %#v", west.TaskGroups)
+	}
+	expected := map[string]string{"owner": "armon", "tier": "primary"}
+	if !reflect.DeepEqual(west.Meta, expected) {
+		t.Fatalf("bad: %#v", west.Meta)
+	}
+
+	// The original job is untouched
+	if j.Region != "global" || j.TaskGroups[0].Count != 10 || j.Meta["tier"] != "default" {
+		t.Fatalf("original job modified: %#v", j)
+	}
+}
+
+func TestMultiregion_Validate(t *testing.T) {
+	m := &Multiregion{}
+	if err := m.Validate(); err == nil || !strings.Contains(err.Error(), "at least one region") {
+		t.Fatalf("err: %v", err)
+	}
+
+	m = &Multiregion{
+		Strategy: &MultiregionStrategy{MaxParallel: -1},
+		Regions: []*MultiregionRegion{
+			&MultiregionRegion{Name: "east"},
+			&MultiregionRegion{Name: "east"},
+			&MultiregionRegion{Count: -1},
+		},
+	}
+	err := m.Validate()
+	mErr := err.(*multierror.Error)
+	if !strings.Contains(mErr.Errors[0].Error(), "redefines 'east'") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[1].Error(), "missing name") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[2].Error(), "negative count") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[3].Error(), "max_parallel") {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Datacenters may be left to the regions
+	j := &Job{
+		Region:   "global",
+		ID:       "foo",
+		Name:     "foo",
+		Type:     JobTypeService,
+		Priority: 50,
+		Multiregion: &Multiregion{
+			Regions: []*MultiregionRegion{
+				&MultiregionRegion{Name: "east", Datacenters: []string{"east-1"}},
+			},
+		},
+	}
+	if err := j.Validate(); err != nil && strings.Contains(err.Error(), "datacenters") {
+		t.Fatalf("err: %s", err)
+	}
+}
+
 func TestTaskGroup_Validate(t *testing.T) {
 	tg := &TaskGroup{
 		RestartPolicy: &RestartPolicy{
@@ -237,6 +435,109 @@ func TestTaskGroup_Validate(t *testing.T) {
 	}
 }
 
+func TestTaskGroup_Validate_Volumes(t *testing.T) {
+	tg := &TaskGroup{
+		Name:  "web",
+		Count: 1,
+		Tasks: []*Task{
+			&Task{
+				Name:      "web",
+				Driver:    "exec",
+				Resources: &Resources{},
+				VolumeMounts: []*VolumeMount{
+					&VolumeMount{Volume: "data", Destination: "/data"},
+					&VolumeMount{Volume: "logs", Destination: "/logs"},
+				},
+			},
+		},
+		RestartPolicy: NewRestartPolicy(JobTypeService),
+		Volumes: map[string]*VolumeRequest{
+			"data": &VolumeRequest{Name: "data", Type: VolumeTypeHost},
+		},
+	}
+	err := tg.Validate()
+	mErr := err.(*multierror.Error)
+	if len(mErr.Errors) != 2 {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[0].Error(), "must have a source") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[1].Error(), "Task 1 mounts unknown volume 'logs'") {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestVolumeMount_Validate(t *testing.T) {
+	m := &VolumeMount{}
+	err := m.Validate()
+	mErr := err.(*multierror.Error)
+	if !strings.Contains(mErr.Errors[0].Error(), "reference a volume") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[1].Error(), "have a destination") {
+		t.Fatalf("err: %s", err)
+	}
+
+	m = &VolumeMount{Volume: "data", Destination: "data"}
+	if err := m.Validate(); err == nil || !strings.Contains(err.Error(), "absolute path") {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Destinations leaving the task directory once joined to it are
+	// rejected
+	for _, dest := range []string{"/../../../../../etc", "/srv/../../etc", "/.."} {
+		m = &VolumeMount{Volume: "data", Destination: dest}
+		if err := m.Validate(); err == nil || !strings.Contains(err.Error(), "escape") {
+			t.Fatalf("%s: err: %v", dest, err)
+		}
+	}
+
+	for _, dest := range []string{"/", "//", "/srv/.."} {
+		m = &VolumeMount{Volume: "data", Destination: dest}
+		if err := m.Validate(); err == nil || !strings.Contains(err.Error(), "be the task directory") {
+			t.Fatalf("%s: err: %v", dest, err)
+		}
+	}
+
+	m = &VolumeMount{Volume: "data", Destination: "/srv/data/../cache"}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestMigrateStrategy_Validate(t *testing.T) {
+	if err := DefaultMigrateStrategy().Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	m := &MigrateStrategy{
+		MaxParallel:     0,
+		MinHealthyTime:  -1,
+		HealthyDeadline: -1,
+	}
+	err := m.Validate()
+	mErr := err.(*multierror.Error)
+	if !strings.Contains(mErr.Errors[0].Error(), "max_parallel") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[1].Error(), "min_healthy_time") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[2].Error(), "healthy_deadline must not be negative") {
+		t.Fatalf("err: %s", err)
+	}
+
+	m = &MigrateStrategy{
+		MaxParallel:     1,
+		MinHealthyTime:  time.Minute,
+		HealthyDeadline: 30 * time.Second,
+	}
+	if err := m.Validate(); err == nil || !strings.Contains(err.Error(), "greater than min_healthy_time") {
+		t.Fatalf("err: %v", err)
+	}
+}
+
 func TestTask_Validate(t *testing.T) {
 	task := &Task{}
 	err := task.Validate()
@@ -262,6 +563,82 @@ func TestTask_Validate(t *testing.T) {
 	}
 }
 
+func TestTask_Validate_LogConfig(t *testing.T) {
+	task := &Task{
+		Name:      "web",
+		Driver:    "docker",
+		Resources: &Resources{DiskMB: 100},
+		LogConfig: &LogConfig{MaxFiles: 0, MaxFileSizeMB: 10},
+	}
+	err := task.Validate()
+	if err == nil || !strings.Contains(err.Error(), "at least one file") {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The logs must fit in the reserved disk
+	task.LogConfig = DefaultLogConfig()
+	err = task.Validate()
+	if err == nil || !strings.Contains(err.Error(), "exceeds the 100 MB") {
+		t.Fatalf("err: %v", err)
+	}
+
+	task.LogConfig.MaxFiles = 5
+	if err := task.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestTaskVariable_Validate(t *testing.T) {
+	cases := []struct {
+		Variable *TaskVariable
+		Err      string
+	}{
+		{&TaskVariable{Path: "app/db", Env: true}, ""},
+		{&TaskVariable{Path: "app/db", Destination: "secrets/db.json"}, ""},
+		{&TaskVariable{Path: "app/db"}, "env or a destination"},
+		{&TaskVariable{Path: "../db", Env: true}, "Invalid variable path"},
+		{&TaskVariable{Path: "app/db", Destination: "/etc/db.json"}, "relative"},
+		{&TaskVariable{Path: "app/db", Destination: "../../db.json"}, "escape"},
+	}
+
+	for _, c := range cases {
+		err := c.Variable.Validate()
+		if c.Err == "" {
+			if err != nil {
+				t.Fatalf("%#v: unexpected err: %v", c.Variable, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.Err) {
+			t.Fatalf("%#v: expected err containing %q, got: %v", c.Variable, c.Err, err)
+		}
+	}
+}
+
+func TestVariable_Validate(t *testing.T) {
+	v := &Variable{}
+	err := v.Validate()
+	mErr := err.(*multierror.Error)
+	if !strings.Contains(mErr.Errors[0].Error(), "Missing variable path") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[1].Error(), "at least one item") {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, path := range []string{"/app", "app/", "app//db", "app/../db", "a b"} {
+		v = &Variable{Path: path, Items: map[string]string{"a": "b"}}
+		if err := v.Validate(); err == nil {
+			t.Fatalf("expected error for path %q", path)
+		}
+	}
+
+	v = &Variable{Path: "app/db.prod", Items: map[string]string{"password": "hunter2"}}
+	if err := v.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
 func TestConstraint_Validate(t *testing.T) {
 	c := &Constraint{}
 	err := c.Validate()
@@ -299,6 +676,40 @@ func TestConstraint_Validate(t *testing.T) {
 	}
 }
 
+func TestNode_Copy(t *testing.T) {
+	n := &Node{
+		ID:         GenerateUUID(),
+		Attributes: map[string]string{"kernel.name": "linux"},
+		Meta:       map[string]string{"rack": "r1"},
+		Resources: &Resources{
+			CPU:      1000,
+			Networks: []*NetworkResource{{Device: "eth0", MBits: 100}},
+		},
+		HostVolumes: map[string]*ClientHostVolumeConfig{
+			"data": {Name: "data", Path: "/srv/data"},
+		},
+	}
+
+	c := n.Copy()
+	if !reflect.DeepEqual(n, c) {
+		t.Fatalf("bad: %#v", c)
+	}
+
+	c.Attributes["kernel.name"] = "darwin"
+	c.Meta["rack"] = "r2"
+	c.Resources.Networks[0].MBits = 10
+	c.HostVolumes["data"].ReadOnly = true
+	if n.Attributes["kernel.name"] != "linux" || n.Meta["rack"] != "r1" ||
+		n.Resources.Networks[0].MBits != 100 || n.HostVolumes["data"].ReadOnly {
+		t.Fatalf("copy modified the node: %#v", n)
+	}
+
+	var nilNode *Node
+	if nilNode.Copy() != nil {
+		t.Fatalf("expected nil copy")
+	}
+}
+
 func TestResource_NetIndex(t *testing.T) {
 	r := &Resources{
 		Networks: []*NetworkResource{
@@ -484,6 +895,35 @@ func TestInvalidServiceCheck(t *testing.T) {
 	}
 }
 
+func TestService_ValidateProvider(t *testing.T) {
+	s := Service{
+		Name:      "service-name",
+		PortLabel: "bar",
+		Provider:  ServiceProviderNomad,
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Checks are run by Consul
+	s.Checks = []*ServiceCheck{
+		{
+			Name:     "check-name",
+			Type:     ServiceCheckTCP,
+			Interval: 10 * time.Second,
+		},
+	}
+	if err := s.Validate(); err == nil {
+		t.Fatalf("Service should be invalid")
+	}
+
+	s.Checks = nil
+	s.Provider = "dns"
+	if err := s.Validate(); err == nil {
+		t.Fatalf("Service should be invalid")
+	}
+}
+
 func TestDistinctCheckID(t *testing.T) {
 	c1 := ServiceCheck{
 		Name:     "web-health",
@@ -649,3 +1089,135 @@ func TestPeriodicConfig_NextCron(t *testing.T) {
 		}
 	}
 }
+
+func TestPeriodicConfig_ValidTimeZone(t *testing.T) {
+	zones := []string{"Africa/Abidjan", "America/Chicago", "Europe/Minsk", "UTC"}
+	for _, zone := range zones {
+		p := &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "0 0 29 2 * 1980", TimeZone: zone}
+		if err := p.Validate(); err != nil {
+			t.Fatalf("Valid tz errored: %v", err)
+		}
+	}
+}
+
+func TestPeriodicConfig_InvalidTimeZone(t *testing.T) {
+	zones := []string{"America/Raleigh", "Atlantis/Ocean", "Nope"}
+	for _, zone := range zones {
+		p := &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "0 0 29 2 * 1980", TimeZone: zone}
+		if err := p.Validate(); err == nil {
+			t.Fatalf("Invalid tz %q shouldn't be valid", zone)
+		}
+	}
+}
+
+func TestPeriodicConfig_NextCron_TimeZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	from := time.Date(2009, time.November, 10, 23, 22, 30, 0, time.UTC)
+	p := &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "0 2 * * *", TimeZone: "America/New_York"}
+	n := p.Next(from)
+	expected := time.Date(2009, time.November, 11, 2, 0, 0, 0, ny)
+	if !n.Equal(expected) {
+		t.Fatalf("Next(%v) returned %v; want %v", from, n, expected)
+	}
+	if n.Location().String() != "America/New_York" {
+		t.Fatalf("Next(%v) returned location %v", from, n.Location())
+	}
+}
+
+func TestPeriodicConfig_NextCron_DST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	p := &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "30 1,2 * * *", TimeZone: "America/New_York"}
+
+	// Clocks are set back at 2:00am on November 1, 2015 so 1:30am happens
+	// twice. The job should only launch once.
+	from := time.Date(2015, time.November, 1, 0, 0, 0, 0, ny)
+	var launches []time.Time
+	for next := p.Next(from); len(launches) < 3; next = p.Next(next) {
+		launches = append(launches, next)
+	}
+	expected := []time.Time{
+		time.Date(2015, time.November, 1, 5, 30, 0, 0, time.UTC),
+		time.Date(2015, time.November, 1, 7, 30, 0, 0, time.UTC),
+		time.Date(2015, time.November, 2, 6, 30, 0, 0, time.UTC),
+	}
+	for i, launch := range launches {
+		if !launch.Equal(expected[i]) {
+			t.Fatalf("launch %d was %v; want %v", i, launch, expected[i])
+		}
+	}
+
+	// Clocks are set forward at 2:00am on March 13, 2016 so 2:30am doesn't
+	// exist. The launch should be shifted forward rather than skipped.
+	from = time.Date(2016, time.March, 13, 0, 0, 0, 0, ny)
+	launches = nil
+	for next := p.Next(from); len(launches) < 3; next = p.Next(next) {
+		launches = append(launches, next)
+	}
+	expected = []time.Time{
+		time.Date(2016, time.March, 13, 6, 30, 0, 0, time.UTC),
+		time.Date(2016, time.March, 13, 7, 30, 0, 0, time.UTC),
+		time.Date(2016, time.March, 14, 5, 30, 0, 0, time.UTC),
+	}
+	for i, launch := range launches {
+		if !launch.Equal(expected[i]) {
+			t.Fatalf("launch %d was %v; want %v", i, launch, expected[i])
+		}
+	}
+}
+
+func TestPeriodicConfig_InvalidCatchup(t *testing.T) {
+	p := &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "@hourly", Catchup: "foo"}
+	if err := p.Validate(); err == nil {
+		t.Fatal("Unknown catchup policy shouldn't be valid")
+	}
+
+	p = &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "@hourly", Catchup: PeriodicCatchupAll, CatchupLimit: -1}
+	if err := p.Validate(); err == nil {
+		t.Fatal("Negative catchup limit shouldn't be valid")
+	}
+}
+
+func TestPeriodicConfig_MissedLaunches(t *testing.T) {
+	last := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	now := time.Date(2009, time.November, 11, 4, 30, 0, 0, time.UTC)
+	hour := func(h int) time.Time {
+		return time.Date(2009, time.November, 11, h, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		catchup         string
+		limit           int
+		prohibitOverlap bool
+		expected        []time.Time
+	}{
+		{"", 0, false, []time.Time{hour(4)}},
+		{PeriodicCatchupNone, 0, false, nil},
+		{PeriodicCatchupLatest, 0, false, []time.Time{hour(4)}},
+		{PeriodicCatchupAll, 0, false, []time.Time{hour(0), hour(1), hour(2), hour(3), hour(4)}},
+		{PeriodicCatchupAll, 2, false, []time.Time{hour(3), hour(4)}},
+		{PeriodicCatchupAll, 0, true, []time.Time{hour(4)}},
+	}
+
+	for _, c := range cases {
+		p := &PeriodicConfig{
+			Enabled:         true,
+			SpecType:        PeriodicSpecCron,
+			Spec:            "0 * * * *",
+			Catchup:         c.catchup,
+			CatchupLimit:    c.limit,
+			ProhibitOverlap: c.prohibitOverlap,
+		}
+
+		missed := p.MissedLaunches(last, now)
+		if !reflect.DeepEqual(missed, c.expected) {
+			t.Fatalf("MissedLaunches(%q, %d) returned %v; want %v", c.catchup, c.limit, missed, c.expected)
+		}
+	}
+}
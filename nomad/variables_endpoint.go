@@ -0,0 +1,191 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/watch"
+)
+
+// Variables endpoint is used for interacting with the encrypted variables
+// store
+type Variables struct {
+	srv *Server
+}
+
+// Upsert is used to create or update a variable. The variable is encrypted
+// before it is committed via Raft.
+func (v *Variables) Upsert(args *structs.VariableUpsertRequest, reply *structs.GenericResponse) error {
+	if done, err := v.srv.forward("Variables.Upsert", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "upsert"}, time.Now())
+
+	// Validate the arguments
+	if args.Variable == nil {
+		return fmt.Errorf("missing variable for upsert")
+	}
+	if err := args.Variable.Validate(); err != nil {
+		return err
+	}
+
+	encrypted, err := v.srv.encryptVariable(args.Variable)
+	if err != nil {
+		return err
+	}
+
+	// Commit this update via Raft
+	req := &structs.VariableEncryptedUpsertRequest{
+		Variable:     encrypted,
+		WriteRequest: args.WriteRequest,
+	}
+	_, index, err := v.srv.raftApply(structs.VariableUpsertRequestType, req)
+	if err != nil {
+		v.srv.logger.Printf("[ERR] nomad.variables: Upsert failed: %v", err)
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// Delete is used to delete a variable
+func (v *Variables) Delete(args *structs.VariableDeleteRequest, reply *structs.GenericResponse) error {
+	if done, err := v.srv.forward("Variables.Delete", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "delete"}, time.Now())
+
+	// Validate the arguments
+	if args.Path == "" {
+		return fmt.Errorf("missing variable path for delete")
+	}
+
+	// Make sure the variable exists
+	existing, err := v.srv.fsm.State().VariableByPath(args.Path)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("variable %q not found", args.Path)
+	}
+
+	// Commit this update via Raft
+	_, index, err := v.srv.raftApply(structs.VariableDeleteRequestType, args)
+	if err != nil {
+		v.srv.logger.Printf("[ERR] nomad.variables: Delete failed: %v", err)
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// Read is used to read and decrypt a variable
+func (v *Variables) Read(args *structs.VariableSpecificRequest,
+	reply *structs.SingleVariableResponse) error {
+	if done, err := v.srv.forward("Variables.Read", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "read"}, time.Now())
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "variables"}),
+		run: func() error {
+			// Look for the variable
+			snap, err := v.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			out, err := snap.VariableByPath(args.Path)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Variable = nil
+			if out != nil {
+				variable, err := v.srv.decryptVariable(out)
+				if err != nil {
+					return err
+				}
+				reply.Variable = variable
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the variables table
+				index, err := snap.Index("variables")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			v.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return v.srv.blockingRPC(&opts)
+}
+
+// List is used to list the variables. The items of the variables are not
+// returned.
+func (v *Variables) List(args *structs.VariableListRequest,
+	reply *structs.VariableListResponse) error {
+	if done, err := v.srv.forward("Variables.List", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "list"}, time.Now())
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "variables"}),
+		run: func() error {
+			// Capture all the variables
+			snap, err := v.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			var iter memdb.ResultIterator
+			if prefix := args.QueryOptions.Prefix; prefix != "" {
+				iter, err = snap.VariablesByPathPrefix(prefix)
+			} else {
+				iter, err = snap.Variables()
+			}
+			if err != nil {
+				return err
+			}
+
+			var variables []*structs.VariableListStub
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				variable := raw.(*structs.VariableEncrypted)
+				variables = append(variables, variable.Stub())
+			}
+			reply.Variables = variables
+
+			// Use the last index that affected the variables table
+			index, err := snap.Index("variables")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			v.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return v.srv.blockingRPC(&opts)
+}
@@ -0,0 +1,107 @@
+package nomad
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestVariablesEndpoint_UpsertReadDelete(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the variable
+	variable := &structs.Variable{
+		Path:  "app/db",
+		Items: map[string]string{"password": "hunter2"},
+	}
+	req := &structs.VariableUpsertRequest{
+		Variable:     variable,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	// The state store only holds the encrypted form
+	stored, err := s1.fsm.State().VariableByPath(variable.Path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if stored == nil || stored.KeyID == "" {
+		t.Fatalf("bad: %#v", stored)
+	}
+	if reflect.DeepEqual(stored.Data, []byte(`{"password":"hunter2"}`)) {
+		t.Fatalf("variable stored in plaintext")
+	}
+
+	// Read it back
+	get := &structs.VariableSpecificRequest{
+		Path:         variable.Path,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var getResp structs.SingleVariableResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Variable == nil || !reflect.DeepEqual(getResp.Variable.Items, variable.Items) {
+		t.Fatalf("bad: %#v", getResp.Variable)
+	}
+
+	// List the variables
+	list := &structs.VariableListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global", Prefix: "app"},
+	}
+	var listResp structs.VariableListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Variables.List", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(listResp.Variables) != 1 || listResp.Variables[0].Path != variable.Path {
+		t.Fatalf("bad: %#v", listResp.Variables)
+	}
+
+	// Delete it
+	del := &structs.VariableDeleteRequest{
+		Path:         variable.Path,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "Variables.Delete", del, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Variable != nil {
+		t.Fatalf("bad: %#v", getResp.Variable)
+	}
+
+	// Deleting a missing variable fails
+	if err := msgpackrpc.CallWithCodec(codec, "Variables.Delete", del, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestVariablesEndpoint_Upsert_Invalid(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	req := &structs.VariableUpsertRequest{
+		Variable:     &structs.Variable{Path: "../escape", Items: map[string]string{"a": "b"}},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+}
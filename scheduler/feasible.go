@@ -142,6 +142,52 @@ func (c *DriverChecker) hasDrivers(option *structs.Node) bool {
 	return true
 }
 
+// HostVolumeChecker is a FeasibilityChecker which returns whether a node has
+// the host volumes requested by a task group.
+type HostVolumeChecker struct {
+	ctx     Context
+	volumes map[string]*structs.VolumeRequest
+}
+
+// NewHostVolumeChecker creates a HostVolumeChecker from a set of volumes
+func NewHostVolumeChecker(ctx Context, volumes map[string]*structs.VolumeRequest) *HostVolumeChecker {
+	return &HostVolumeChecker{
+		ctx:     ctx,
+		volumes: volumes,
+	}
+}
+
+func (c *HostVolumeChecker) SetVolumes(volumes map[string]*structs.VolumeRequest) {
+	c.volumes = volumes
+}
+
+func (c *HostVolumeChecker) Feasible(option *structs.Node) bool {
+	if c.hasVolumes(option) {
+		return true
+	}
+	c.ctx.Metrics().FilterNode(option, "missing compatible host volumes")
+	return false
+}
+
+// hasVolumes is used to check if the node exposes all the host volumes of
+// the task group. Volumes that are not requested read only can not be placed
+// on read only host volumes.
+func (c *HostVolumeChecker) hasVolumes(option *structs.Node) bool {
+	for _, req := range c.volumes {
+		if req.Type != structs.VolumeTypeHost {
+			continue
+		}
+		volume, ok := option.HostVolumes[req.Source]
+		if !ok {
+			return false
+		}
+		if volume.ReadOnly && !req.ReadOnly {
+			return false
+		}
+	}
+	return true
+}
+
 // ProposedAllocConstraintIterator is a FeasibleIterator which returns nodes that
 // match constraints that are not static such as Node attributes but are
 // effected by proposed alloc placements. Examples are distinct_hosts and
@@ -123,6 +123,56 @@ func TestDriverChecker(t *testing.T) {
 	}
 }
 
+func TestHostVolumeChecker(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*structs.Node{
+		mock.Node(),
+		mock.Node(),
+		mock.Node(),
+	}
+	nodes[1].HostVolumes = map[string]*structs.ClientHostVolumeConfig{
+		"foo": &structs.ClientHostVolumeConfig{Name: "foo", Path: "/foo"},
+	}
+	nodes[2].HostVolumes = map[string]*structs.ClientHostVolumeConfig{
+		"foo": &structs.ClientHostVolumeConfig{Name: "foo", Path: "/foo", ReadOnly: true},
+	}
+
+	volumes := map[string]*structs.VolumeRequest{
+		"data": &structs.VolumeRequest{Name: "data", Type: structs.VolumeTypeHost, Source: "foo"},
+	}
+	checker := NewHostVolumeChecker(ctx, volumes)
+	cases := []struct {
+		Node     *structs.Node
+		ReadOnly bool
+		Result   bool
+	}{
+		{
+			Node:   nodes[0],
+			Result: false,
+		},
+		{
+			Node:   nodes[1],
+			Result: true,
+		},
+		{
+			Node:   nodes[2],
+			Result: false,
+		},
+		{
+			Node:     nodes[2],
+			ReadOnly: true,
+			Result:   true,
+		},
+	}
+
+	for i, c := range cases {
+		volumes["data"].ReadOnly = c.ReadOnly
+		if act := checker.Feasible(c.Node); act != c.Result {
+			t.Fatalf("case(%d) failed: got %v; want %v", i, act, c.Result)
+		}
+	}
+}
+
 func TestConstraintChecker(t *testing.T) {
 	_, ctx := testContext(t)
 	nodes := []*structs.Node{
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -56,8 +57,9 @@ type GenericScheduler struct {
 	ctx   *EvalContext
 	stack *GenericStack
 
-	limitReached bool
-	nextEval     *structs.Evaluation
+	limitReached   bool
+	nextEval       *structs.Evaluation
+	disconnectEval *structs.Evaluation
 }
 
 // NewServiceScheduler is a factory function to instantiate a new service scheduler
@@ -90,7 +92,8 @@ func (s *GenericScheduler) Process(eval *structs.Evaluation) error {
 	// Verify the evaluation trigger reason is understood
 	switch eval.TriggeredBy {
 	case structs.EvalTriggerJobRegister, structs.EvalTriggerNodeUpdate,
-		structs.EvalTriggerJobDeregister, structs.EvalTriggerRollingUpdate:
+		structs.EvalTriggerJobDeregister, structs.EvalTriggerRollingUpdate,
+		structs.EvalTriggerMaxDisconnectTimeout:
 	default:
 		desc := fmt.Sprintf("scheduler cannot handle '%s' evaluation reason",
 			eval.TriggeredBy)
@@ -211,6 +214,25 @@ func (s *GenericScheduler) computeJobAllocs() error {
 
 	// Diff the required and existing allocations
 	diff := diffAllocs(s.job, tainted, groups, allocs)
+
+	// Leave the allocations of disconnected nodes in place until their
+	// max_client_disconnect expires, and evaluate the job again at that point.
+	migrate, disconnecting, wait, err := filterDisconnecting(s.state, diff.migrate, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to filter disconnected allocs for job '%s': %v",
+			s.eval.JobID, err)
+	}
+	diff.migrate = migrate
+	diff.ignore = append(diff.ignore, disconnecting...)
+	if len(disconnecting) != 0 && s.disconnectEval == nil {
+		s.disconnectEval = s.eval.NextDisconnectEval(wait)
+		if err := s.planner.CreateEval(s.disconnectEval); err != nil {
+			s.logger.Printf("[ERR] sched: %#v failed to make eval for disconnected allocs: %v", s.eval, err)
+			return err
+		}
+		s.logger.Printf("[DEBUG] sched: %#v: %d allocs on disconnected nodes, next eval '%s' in %v",
+			s.eval, len(disconnecting), s.disconnectEval.ID, wait)
+	}
 	s.logger.Printf("[DEBUG] sched: %#v: %#v", s.eval, diff)
 
 	// Add all the allocs to stop
@@ -39,10 +39,11 @@ type GenericStack struct {
 	ctx    Context
 	source *StaticIterator
 
-	wrappedChecks       *FeasibilityWrapper
-	jobConstraint       *ConstraintChecker
-	taskGroupDrivers    *DriverChecker
-	taskGroupConstraint *ConstraintChecker
+	wrappedChecks        *FeasibilityWrapper
+	jobConstraint        *ConstraintChecker
+	taskGroupDrivers     *DriverChecker
+	taskGroupConstraint  *ConstraintChecker
+	taskGroupHostVolumes *HostVolumeChecker
 
 	proposedAllocConstraint *ProposedAllocConstraintIterator
 	binPack                 *BinPackIterator
@@ -73,12 +74,15 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 	// Filter on task group constraints second
 	s.taskGroupConstraint = NewConstraintChecker(ctx, nil)
 
+	// Filter on task group host volumes
+	s.taskGroupHostVolumes = NewHostVolumeChecker(ctx, nil)
+
 	// Create the feasibility wrapper which wraps all feasibility checks in
 	// which feasibility checking can be skipped if the computed node class has
 	// previously been marked as eligible or ineligible. Generally this will be
 	// checks that only needs to examine the single node to determine feasibility.
 	jobs := []FeasibilityChecker{s.jobConstraint}
-	tgs := []FeasibilityChecker{s.taskGroupDrivers, s.taskGroupConstraint}
+	tgs := []FeasibilityChecker{s.taskGroupDrivers, s.taskGroupConstraint, s.taskGroupHostVolumes}
 	s.wrappedChecks = NewFeasibilityWrapper(ctx, s.source, jobs, tgs)
 
 	// Filter on constraints that are affected by propsed allocations.
@@ -152,6 +156,7 @@ func (s *GenericStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Reso
 	// Update the parameters of iterators
 	s.taskGroupDrivers.SetDrivers(tgConstr.drivers)
 	s.taskGroupConstraint.SetConstraints(tgConstr.constraints)
+	s.taskGroupHostVolumes.SetVolumes(tg.Volumes)
 	s.proposedAllocConstraint.SetTaskGroup(tg)
 	s.binPack.SetTasks(tg.Tasks)
 
@@ -173,13 +178,14 @@ func (s *GenericStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Reso
 // SystemStack is the Stack used for the System scheduler. It is designed to
 // attempt to make placements on all nodes.
 type SystemStack struct {
-	ctx                 Context
-	source              *StaticIterator
-	wrappedChecks       *FeasibilityWrapper
-	jobConstraint       *ConstraintChecker
-	taskGroupDrivers    *DriverChecker
-	taskGroupConstraint *ConstraintChecker
-	binPack             *BinPackIterator
+	ctx                  Context
+	source               *StaticIterator
+	wrappedChecks        *FeasibilityWrapper
+	jobConstraint        *ConstraintChecker
+	taskGroupDrivers     *DriverChecker
+	taskGroupConstraint  *ConstraintChecker
+	taskGroupHostVolumes *HostVolumeChecker
+	binPack              *BinPackIterator
 }
 
 // NewSystemStack constructs a stack used for selecting service placements
@@ -200,12 +206,15 @@ func NewSystemStack(ctx Context) *SystemStack {
 	// Filter on task group constraints second
 	s.taskGroupConstraint = NewConstraintChecker(ctx, nil)
 
+	// Filter on task group host volumes
+	s.taskGroupHostVolumes = NewHostVolumeChecker(ctx, nil)
+
 	// Create the feasibility wrapper which wraps all feasibility checks in
 	// which feasibility checking can be skipped if the computed node class has
 	// previously been marked as eligible or ineligible. Generally this will be
 	// checks that only needs to examine the single node to determine feasibility.
 	jobs := []FeasibilityChecker{s.jobConstraint}
-	tgs := []FeasibilityChecker{s.taskGroupDrivers, s.taskGroupConstraint}
+	tgs := []FeasibilityChecker{s.taskGroupDrivers, s.taskGroupConstraint, s.taskGroupHostVolumes}
 	s.wrappedChecks = NewFeasibilityWrapper(ctx, s.source, jobs, tgs)
 
 	// Upgrade from feasible to rank iterator
@@ -241,6 +250,7 @@ func (s *SystemStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Resou
 	// Update the parameters of iterators
 	s.taskGroupDrivers.SetDrivers(tgConstr.drivers)
 	s.taskGroupConstraint.SetConstraints(tgConstr.constraints)
+	s.taskGroupHostVolumes.SetVolumes(tg.Volumes)
 	s.binPack.SetTasks(tg.Tasks)
 
 	// Get the next option that satisfies the constraints.
@@ -189,6 +189,44 @@ func TestServiceStack_Select_DriverFilter(t *testing.T) {
 	}
 }
 
+func TestServiceStack_Select_HostVolumeFilter(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*structs.Node{
+		mock.Node(),
+		mock.Node(),
+	}
+	zero := nodes[0]
+	zero.HostVolumes = map[string]*structs.ClientHostVolumeConfig{
+		"foo": &structs.ClientHostVolumeConfig{Name: "foo", Path: "/foo"},
+	}
+	if err := zero.ComputeClass(); err != nil {
+		t.Fatalf("ComputedClass() failed: %v", err)
+	}
+
+	stack := NewGenericStack(false, ctx)
+	stack.SetNodes(nodes)
+
+	job := mock.Job()
+	job.TaskGroups[0].Volumes = map[string]*structs.VolumeRequest{
+		"data": &structs.VolumeRequest{Name: "data", Type: structs.VolumeTypeHost, Source: "foo"},
+	}
+	stack.SetJob(job)
+
+	node, _ := stack.Select(job.TaskGroups[0])
+	if node == nil {
+		t.Fatalf("missing node %#v", ctx.Metrics())
+	}
+
+	if node.Node != zero {
+		t.Fatalf("bad")
+	}
+
+	met := ctx.Metrics()
+	if met.NodesFiltered != 1 {
+		t.Fatalf("bad: %#v", met)
+	}
+}
+
 func TestServiceStack_Select_ConstraintFilter(t *testing.T) {
 	_, ctx := testContext(t)
 	nodes := []*structs.Node{
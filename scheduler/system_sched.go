@@ -3,6 +3,7 @@ package scheduler
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -33,8 +34,9 @@ type SystemScheduler struct {
 	nodes     []*structs.Node
 	nodesByDC map[string]int
 
-	limitReached bool
-	nextEval     *structs.Evaluation
+	limitReached   bool
+	nextEval       *structs.Evaluation
+	disconnectEval *structs.Evaluation
 }
 
 // NewSystemScheduler is a factory function to instantiate a new system
@@ -55,7 +57,8 @@ func (s *SystemScheduler) Process(eval *structs.Evaluation) error {
 	// Verify the evaluation trigger reason is understood
 	switch eval.TriggeredBy {
 	case structs.EvalTriggerJobRegister, structs.EvalTriggerNodeUpdate,
-		structs.EvalTriggerJobDeregister, structs.EvalTriggerRollingUpdate:
+		structs.EvalTriggerJobDeregister, structs.EvalTriggerRollingUpdate,
+		structs.EvalTriggerMaxDisconnectTimeout:
 	default:
 		desc := fmt.Sprintf("scheduler cannot handle '%s' evaluation reason",
 			eval.TriggeredBy)
@@ -172,8 +175,17 @@ func (s *SystemScheduler) computeJobAllocs() error {
 			s.eval.JobID, err)
 	}
 
+	// Leave the allocations of disconnected nodes in place until their
+	// max_client_disconnect expires, and evaluate the job again at that point.
+	allocs, disconnecting, err := s.filterDisconnecting(allocs, tainted)
+	if err != nil {
+		return fmt.Errorf("failed to filter disconnected allocs for job '%s': %v",
+			s.eval.JobID, err)
+	}
+
 	// Diff the required and existing allocations
 	diff := diffSystemAllocs(s.job, s.nodes, tainted, allocs)
+	diff.ignore = append(diff.ignore, disconnecting...)
 	s.logger.Printf("[DEBUG] sched: %#v: %#v", s.eval, diff)
 
 	// Add all the allocs to stop
@@ -202,6 +214,47 @@ func (s *SystemScheduler) computeJobAllocs() error {
 	return s.computePlacements(diff.place)
 }
 
+// filterDisconnecting removes the allocations on down nodes that are still
+// within the max_client_disconnect window of their task group, and creates an
+// evaluation for when the first window expires. The allocations of tainted
+// nodes that are left are stopped by the diff.
+func (s *SystemScheduler) filterDisconnecting(allocs []*structs.Allocation, tainted map[string]bool) (
+	[]*structs.Allocation, []allocTuple, error) {
+	if s.job == nil {
+		return allocs, nil, nil
+	}
+
+	var keep []*structs.Allocation
+	var candidates []allocTuple
+	for _, alloc := range allocs {
+		tg := s.job.LookupTaskGroup(alloc.TaskGroup)
+		if !tainted[alloc.NodeID] || tg == nil {
+			keep = append(keep, alloc)
+			continue
+		}
+		candidates = append(candidates, allocTuple{Name: alloc.Name, TaskGroup: tg, Alloc: alloc})
+	}
+
+	remaining, disconnecting, wait, err := filterDisconnecting(s.state, candidates, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, tuple := range remaining {
+		keep = append(keep, tuple.Alloc)
+	}
+
+	if len(disconnecting) != 0 && s.disconnectEval == nil {
+		s.disconnectEval = s.eval.NextDisconnectEval(wait)
+		if err := s.planner.CreateEval(s.disconnectEval); err != nil {
+			s.logger.Printf("[ERR] sched: %#v failed to make eval for disconnected allocs: %v", s.eval, err)
+			return nil, nil, err
+		}
+		s.logger.Printf("[DEBUG] sched: %#v: %d allocs on disconnected nodes, next eval '%s' in %v",
+			s.eval, len(disconnecting), s.disconnectEval.ID, wait)
+	}
+	return keep, disconnecting, nil
+}
+
 // computePlacements computes placements for allocations
 func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 	nodeByID := make(map[string]*structs.Node, len(s.nodes))
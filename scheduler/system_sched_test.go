@@ -610,6 +610,82 @@ func TestSystemSched_NodeDrain(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestSystemSched_NodeDown_MaxClientDisconnect(t *testing.T) {
+	for _, expired := range []bool{false, true} {
+		h := NewHarness(t)
+
+		// Register a node that stopped heartbeating
+		node := mock.Node()
+		node.Status = structs.NodeStatusDown
+		node.StatusUpdatedAt = time.Now().Unix()
+		if expired {
+			node.StatusUpdatedAt = time.Now().Add(-2 * time.Hour).Unix()
+		}
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+
+		// Generate a fake job that tolerates disconnects allocated on that
+		// node
+		job := mock.SystemJob()
+		job.TaskGroups[0].MaxClientDisconnect = time.Hour
+		noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = node.ID
+		alloc.Name = "my-job.web[0]"
+		alloc.ClientStatus = structs.AllocClientStatusUnknown
+		noErr(t, h.State.UpsertAllocs(h.NextIndex(), []*structs.Allocation{alloc}))
+
+		// Create a mock evaluation to deal with the node going down
+		eval := &structs.Evaluation{
+			ID:          structs.GenerateUUID(),
+			Priority:    50,
+			TriggeredBy: structs.EvalTriggerNodeUpdate,
+			JobID:       job.ID,
+			NodeID:      node.ID,
+		}
+
+		// Process the evaluation
+		err := h.Process(NewSystemScheduler, eval)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		h.AssertEvalStatus(t, structs.EvalStatusComplete)
+
+		if expired {
+			// Ensure the plan stopped the alloc
+			if len(h.Plans) != 1 {
+				t.Fatalf("bad: %#v", h.Plans)
+			}
+			if len(h.Plans[0].NodeUpdate[node.ID]) != 1 {
+				t.Fatalf("bad: %#v", h.Plans[0])
+			}
+			if len(h.CreateEvals) != 0 {
+				t.Fatalf("bad: %#v", h.CreateEvals)
+			}
+			continue
+		}
+
+		// Ensure the alloc was left in place
+		if len(h.Plans) != 0 {
+			t.Fatalf("bad: %#v", h.Plans)
+		}
+
+		// Ensure a follow-up eval for when the window expires
+		if len(h.CreateEvals) != 1 {
+			t.Fatalf("bad: %#v", h.CreateEvals)
+		}
+		create := h.CreateEvals[0]
+		if create.TriggeredBy != structs.EvalTriggerMaxDisconnectTimeout {
+			t.Fatalf("bad: %#v", create)
+		}
+		if create.Wait <= 58*time.Minute || create.Wait > time.Hour {
+			t.Fatalf("bad wait: %v", create.Wait)
+		}
+	}
+}
+
 func TestSystemSched_RetryLimit(t *testing.T) {
 	h := NewHarness(t)
 	h.Planner = &RejectPlan{h}
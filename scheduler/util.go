@@ -5,6 +5,7 @@ import (
 	"log"
 	"math/rand"
 	"reflect"
+	"time"
 
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -81,8 +82,9 @@ func diffAllocs(job *structs.Job, taintedNodes map[string]bool,
 			continue
 		}
 
-		// If we are on a tainted node, we must migrate
-		if taintedNodes[exist.NodeID] {
+		// If we are on a tainted node or the allocation was marked for
+		// migration, we must migrate
+		if taintedNodes[exist.NodeID] || exist.DesiredTransition.ShouldMigrate() {
 			result.migrate = append(result.migrate, allocTuple{
 				Name:      name,
 				TaskGroup: tg,
@@ -190,12 +192,9 @@ func readyNodesInDCs(state State, dcs []string) ([]*structs.Node, map[string]int
 			break
 		}
 
-		// Filter on datacenter and status
+		// Filter on datacenter, status and eligibility
 		node := raw.(*structs.Node)
-		if node.Status != structs.NodeStatusReady {
-			continue
-		}
-		if node.Drain {
+		if !node.Ready() {
 			continue
 		}
 		if _, ok := dcMap[node.Datacenter]; !ok {
@@ -247,11 +246,53 @@ func taintedNodes(state State, allocs []*structs.Allocation) (map[string]bool, e
 			continue
 		}
 
-		out[alloc.NodeID] = structs.ShouldDrainNode(node.Status) || node.Drain
+		// Nodes drained with a strategy are handled by the drainer, which
+		// marks the allocations to migrate, so only the allocations of nodes
+		// drained without one are all migrated at once
+		out[alloc.NodeID] = structs.ShouldDrainNode(node.Status) || (node.Drain && node.DrainStrategy == nil)
 	}
 	return out, nil
 }
 
+// filterDisconnecting splits the allocations to migrate into those that must
+// be migrated and those on down nodes that are still within their task group's
+// max_client_disconnect window. It also returns how long it is until the first
+// of those windows expires.
+func filterDisconnecting(state State, migrate []allocTuple, now time.Time) (
+	remaining, disconnecting []allocTuple, wait time.Duration, err error) {
+	for _, tuple := range migrate {
+		window := tuple.TaskGroup.MaxClientDisconnect
+		if window == 0 {
+			remaining = append(remaining, tuple)
+			continue
+		}
+
+		node, err := state.NodeByID(tuple.Alloc.NodeID)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		// Only nodes that stopped heartbeating are tolerated. Draining nodes
+		// and nodes that no longer exist are migrated away from immediately.
+		if node == nil || node.Status != structs.NodeStatusDown || node.Drain {
+			remaining = append(remaining, tuple)
+			continue
+		}
+
+		left := time.Unix(node.StatusUpdatedAt, 0).Add(window).Sub(now)
+		if left <= 0 {
+			remaining = append(remaining, tuple)
+			continue
+		}
+
+		disconnecting = append(disconnecting, tuple)
+		if wait == 0 || left < wait {
+			wait = left
+		}
+	}
+	return remaining, disconnecting, wait, nil
+}
+
 // shuffleNodes randomizes the slice order with the Fisher-Yates algorithm
 func shuffleNodes(nodes []*structs.Node) {
 	n := len(nodes)
@@ -110,6 +110,38 @@ func TestDiffAllocs(t *testing.T) {
 	}
 }
 
+func TestDiffAllocs_DesiredTransition(t *testing.T) {
+	job := mock.Job()
+	required := materializeTaskGroups(job)
+
+	allocs := []*structs.Allocation{
+		// Migrate the 1st, which was marked by the drainer
+		&structs.Allocation{
+			ID:                structs.GenerateUUID(),
+			NodeID:            "draining",
+			Name:              "my-job.web[0]",
+			Job:               job,
+			DesiredTransition: structs.DesiredTransition{Migrate: true},
+		},
+
+		// Ignore the 2nd
+		&structs.Allocation{
+			ID:     structs.GenerateUUID(),
+			NodeID: "draining",
+			Name:   "my-job.web[1]",
+			Job:    job,
+		},
+	}
+
+	diff := diffAllocs(job, map[string]bool{"draining": false}, required, allocs)
+	if len(diff.migrate) != 1 || diff.migrate[0].Alloc != allocs[0] {
+		t.Fatalf("bad: %#v", diff.migrate)
+	}
+	if len(diff.ignore) != 1 || diff.ignore[0].Alloc != allocs[1] {
+		t.Fatalf("bad: %#v", diff.ignore)
+	}
+}
+
 func TestDiffSystemAllocs(t *testing.T) {
 	job := mock.SystemJob()
 
@@ -198,11 +230,14 @@ func TestReadyNodesInDCs(t *testing.T) {
 	node3.Status = structs.NodeStatusDown
 	node4 := mock.Node()
 	node4.Drain = true
+	node5 := mock.Node()
 
 	noErr(t, state.UpsertNode(1000, node1))
 	noErr(t, state.UpsertNode(1001, node2))
 	noErr(t, state.UpsertNode(1002, node3))
 	noErr(t, state.UpsertNode(1003, node4))
+	noErr(t, state.UpsertNode(1004, node5))
+	noErr(t, state.UpdateNodeEligibility(1005, node5.ID, structs.NodeSchedulingIneligible))
 
 	nodes, dc, err := readyNodesInDCs(state, []string{"dc1", "dc2"})
 	if err != nil {
@@ -265,16 +300,20 @@ func TestTaintedNodes(t *testing.T) {
 	node3.Status = structs.NodeStatusDown
 	node4 := mock.Node()
 	node4.Drain = true
+	node5 := mock.Node()
 	noErr(t, state.UpsertNode(1000, node1))
 	noErr(t, state.UpsertNode(1001, node2))
 	noErr(t, state.UpsertNode(1002, node3))
 	noErr(t, state.UpsertNode(1003, node4))
+	noErr(t, state.UpsertNode(1004, node5))
+	noErr(t, state.UpdateNodeDrain(1005, node5.ID, &structs.DrainStrategy{}, false))
 
 	allocs := []*structs.Allocation{
 		&structs.Allocation{NodeID: node1.ID},
 		&structs.Allocation{NodeID: node2.ID},
 		&structs.Allocation{NodeID: node3.ID},
 		&structs.Allocation{NodeID: node4.ID},
+		&structs.Allocation{NodeID: node5.ID},
 		&structs.Allocation{NodeID: "12345678-abcd-efab-cdef-123456789abc"},
 	}
 	tainted, err := taintedNodes(state, allocs)
@@ -282,10 +321,13 @@ func TestTaintedNodes(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	if len(tainted) != 5 {
+	if len(tainted) != 6 {
 		t.Fatalf("bad: %v", tainted)
 	}
-	if tainted[node1.ID] || tainted[node2.ID] {
+
+	// Nodes draining with a strategy have their allocations migrated by the
+	// drainer rather than all at once
+	if tainted[node1.ID] || tainted[node2.ID] || tainted[node5.ID] {
 		t.Fatalf("Bad: %v", tainted)
 	}
 	if !tainted[node3.ID] || !tainted[node4.ID] || !tainted["12345678-abcd-efab-cdef-123456789abc"] {